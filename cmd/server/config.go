@@ -1,11 +1,27 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/your-org/go-backend-template/internal/app/server/service/account"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/health"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+)
+
+// Supported DB_DRIVER values.
+const (
+	dbDriverPostgres = "postgres"
+	dbDriverSQLite   = "sqlite"
+	dbDriverMemory   = "memory"
 )
 
 // AppConfig holds all application configuration.
@@ -15,20 +31,119 @@ type AppConfig struct {
 	ServerPort int
 	ServerMode string // debug, release, test
 
+	// Logging
+	LogLevel  string // debug, info, warn, error
+	LogFormat string // json, text
+
+	// Metrics
+	MetricsEnabled bool
+	MetricsPort    int // serve /metrics on a separate admin port; 0 serves it on ServerPort
+
+	// Tracing; the otlp exporter reads the standard OTEL_EXPORTER_OTLP_* variables
+	TracingExporter    string // none, otlp, stdout, file
+	TracingServiceName string
+	TracingSampleRatio float64 // fraction of new traces to record, 0 to 1
+	TracingFilePath    string
+
+	// Request deadline
+	RequestTimeout time.Duration
+
+	// Graceful shutdown
+	ShutdownTimeout time.Duration
+	ShutdownDelay   time.Duration
+
+	// Health checks
+	HealthCheckTimeout time.Duration
+
 	// Database
+	DBDriver   string // postgres, sqlite, memory
 	DBHost     string
 	DBPort     int
 	DBUser     string
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+	DBPath     string // database file for the sqlite driver
+
+	DBAutoMigrate bool // apply pending migrations on startup
 
 	// JWT
 	JWTSecretKey     string
 	JWTTokenDuration time.Duration
+	JWTSigningKeys   []string // "kid=path/to/key.pem" entries; HS256 with JWTSecretKey when empty
+	JWTActiveKeyId   string   // kid that signs new tokens; the others only verify
+
+	// Password hashing
+	PasswordHashAlgorithm string // argon2id, bcrypt; existing hashes of either kind keep working
+	BcryptCost            int
+	Argon2Memory          int // KiB
+	Argon2Iterations      int
+	Argon2Parallelism     int
+
+	// Refresh token
+	RefreshTokenDuration time.Duration
+
+	// Token revocation
+	RevocationCacheTTL time.Duration
+
+	// Role permissions
+	PermissionCacheTTL time.Duration // 0 disables the cache
+
+	// Password reset
+	PasswordResetTokenDuration time.Duration
+	PasswordResetURL           string // page that completes a reset; the token is added as ?token=
+
+	// Email verification
+	EmailVerificationRequired      bool // reject logins of users with an unverified email
+	EmailVerificationTokenDuration time.Duration
+	EmailVerificationURL           string // page that completes a verification; the token is added as ?token=
+	EmailRateLimit                 int    // password reset and email verification requests per client IP and window; 0 disables the limit
+	EmailRateWindow                time.Duration
+
+	// Self-registration
+	RegistrationMode           string   // disabled, open, invite, domain
+	RegistrationAllowedDomains []string // email domains that may register in domain mode
+	RegistrationRole           string   // user, viewer
+	RegistrationRateLimit      int      // registrations per client IP and window; 0 disables the limit
+	RegistrationRateWindow     time.Duration
+	InvitationTokenDuration    time.Duration
+	InvitationURL              string // page that completes an invited registration; the token is added as ?token=
+
+	// Login protection
+	LoginRateLimit          int // login attempts per client IP and window; 0 disables the limit
+	LoginRateWindow         time.Duration
+	LoginMaxFailedAttempts  int // consecutive failed logins before a user is locked; 0 disables lockout
+	LoginLockoutDuration    time.Duration
+	LoginMaxLockoutDuration time.Duration
+
+	// Two-factor authentication
+	MFAIssuer            string   // issuer shown by authenticator apps
+	MFARequiredRoles     []string // roles that must use a second factor
+	MFAChallengeDuration time.Duration
+	MFARateLimit         int // second-step requests per client IP and window; 0 disables the limit
+	MFARateWindow        time.Duration
+
+	// Passkeys (WebAuthn)
+	WebAuthnRPID              string   // domain that scopes passkeys; changing it orphans registered passkeys
+	WebAuthnRPName            string   // service name shown by authenticators
+	WebAuthnOrigins           []string // origins of the web apps that use passkeys
+	WebAuthnChallengeDuration time.Duration
+	PasskeyRateLimit          int // passkey login requests per client IP and window; 0 disables the limit
+	PasskeyRateWindow         time.Duration
+
+	// API keys
+	APIKeyMaxPerUser int
+
+	// Mail
+	MailTransport string // log, file
+	MailFrom      string
+	MailFileDir   string // output directory of the file transport
 
 	// CORS
 	CORSAllowOrigins []string
+
+	// Reverse proxies
+	TrustedProxies []string // IPs or CIDRs whose X-Forwarded-For is trusted for the client IP
 }
 
 // LoadConfig loads configuration from environment variables.
@@ -39,27 +154,170 @@ func LoadConfig() *AppConfig {
 		ServerPort: getEnvAsInt("SERVER_PORT", 8080),
 		ServerMode: getEnv("SERVER_MODE", "debug"),
 
+		// Logging
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", logger.FormatJSON),
+
+		// Metrics
+		MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
+		MetricsPort:    getEnvAsInt("METRICS_PORT", 0),
+
+		// Tracing
+		TracingExporter:    getEnv("TRACING_EXPORTER", telemetry.ExporterNone),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "go-backend-template"),
+		TracingSampleRatio: getEnvAsFloat("TRACING_SAMPLE_RATIO", 1),
+		TracingFilePath:    getEnv("TRACING_FILE_PATH", "traces.jsonl"),
+
+		// Request deadline
+		RequestTimeout: getEnvAsDuration("REQUEST_TIMEOUT", 30*time.Second),
+
+		// Graceful shutdown
+		ShutdownTimeout: getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		ShutdownDelay:   getEnvAsDuration("SHUTDOWN_DELAY", 0),
+
+		// Health checks
+		HealthCheckTimeout: getEnvAsDuration("HEALTH_CHECK_TIMEOUT", health.DefaultTimeout),
+
 		// Database
+		DBDriver:   getEnv("DB_DRIVER", dbDriverPostgres),
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnvAsInt("DB_PORT", 5432),
 		DBUser:     getEnv("DB_USER", "postgres"),
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "go_backend_template"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+		DBPath:     getEnv("DB_PATH", "go_backend_template.db"),
+
+		DBAutoMigrate: getEnvAsBool("DB_AUTO_MIGRATE", true),
 
 		// JWT
 		JWTSecretKey:     getEnv("JWT_SECRET_KEY", "your-secret-key-change-in-production"),
-		JWTTokenDuration: getEnvAsDuration("JWT_TOKEN_DURATION", 24*time.Hour),
+		JWTTokenDuration: getEnvAsDuration("JWT_TOKEN_DURATION", 15*time.Minute),
+		JWTSigningKeys:   getEnvAsSlice("JWT_SIGNING_KEYS", nil),
+		JWTActiveKeyId:   getEnv("JWT_ACTIVE_KEY_ID", ""),
+
+		// Password hashing
+		PasswordHashAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		BcryptCost:            getEnvAsInt("BCRYPT_COST", 12),
+		Argon2Memory:          getEnvAsInt("ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:      getEnvAsInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:     getEnvAsInt("ARGON2_PARALLELISM", 2),
+
+		// Refresh token
+		RefreshTokenDuration: getEnvAsDuration("REFRESH_TOKEN_DURATION", 30*24*time.Hour),
+
+		// Token revocation
+		RevocationCacheTTL: getEnvAsDuration("REVOCATION_CACHE_TTL", 30*time.Second),
+
+		// Role permissions
+		PermissionCacheTTL: getEnvAsDuration("PERMISSION_CACHE_TTL", 30*time.Second),
+
+		// Password reset
+		PasswordResetTokenDuration: getEnvAsDuration("PASSWORD_RESET_TOKEN_DURATION", time.Hour),
+		PasswordResetURL:           getEnv("PASSWORD_RESET_URL", ""),
+
+		// Email verification
+		EmailVerificationRequired:      getEnvAsBool("EMAIL_VERIFICATION_REQUIRED", false),
+		EmailVerificationTokenDuration: getEnvAsDuration("EMAIL_VERIFICATION_TOKEN_DURATION", 24*time.Hour),
+		EmailVerificationURL:           getEnv("EMAIL_VERIFICATION_URL", ""),
+		EmailRateLimit:                 getEnvAsInt("EMAIL_RATE_LIMIT", 10),
+		EmailRateWindow:                getEnvAsDuration("EMAIL_RATE_LIMIT_WINDOW", time.Hour),
+
+		// Self-registration
+		RegistrationMode:           getEnv("REGISTRATION_MODE", account.RegistrationModeDisabled),
+		RegistrationAllowedDomains: getEnvAsSlice("REGISTRATION_ALLOWED_DOMAINS", nil),
+		RegistrationRole:           getEnv("REGISTRATION_ROLE", entity.RoleUser),
+		RegistrationRateLimit:      getEnvAsInt("REGISTRATION_RATE_LIMIT", 5),
+		RegistrationRateWindow:     getEnvAsDuration("REGISTRATION_RATE_LIMIT_WINDOW", time.Hour),
+		InvitationTokenDuration:    getEnvAsDuration("INVITATION_TOKEN_DURATION", 7*24*time.Hour),
+		InvitationURL:              getEnv("INVITATION_URL", ""),
+
+		// Login protection
+		LoginRateLimit:          getEnvAsInt("LOGIN_RATE_LIMIT", 20),
+		LoginRateWindow:         getEnvAsDuration("LOGIN_RATE_LIMIT_WINDOW", time.Minute),
+		LoginMaxFailedAttempts:  getEnvAsInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		LoginLockoutDuration:    getEnvAsDuration("LOGIN_LOCKOUT_DURATION", time.Minute),
+		LoginMaxLockoutDuration: getEnvAsDuration("LOGIN_MAX_LOCKOUT_DURATION", time.Hour),
+
+		// Two-factor authentication
+		MFAIssuer:            getEnv("MFA_ISSUER", "Go Backend Template"),
+		MFARequiredRoles:     getEnvAsSlice("MFA_REQUIRED_ROLES", nil),
+		MFAChallengeDuration: getEnvAsDuration("MFA_CHALLENGE_DURATION", 5*time.Minute),
+		MFARateLimit:         getEnvAsInt("MFA_RATE_LIMIT", 10),
+		MFARateWindow:        getEnvAsDuration("MFA_RATE_LIMIT_WINDOW", time.Minute),
+
+		// Passkeys (WebAuthn)
+		WebAuthnRPID:              getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:            getEnv("WEBAUTHN_RP_NAME", "Go Backend Template"),
+		WebAuthnOrigins:           getEnvAsSlice("WEBAUTHN_ORIGINS", []string{"http://localhost:8080"}),
+		WebAuthnChallengeDuration: getEnvAsDuration("WEBAUTHN_CHALLENGE_DURATION", 5*time.Minute),
+		PasskeyRateLimit:          getEnvAsInt("PASSKEY_RATE_LIMIT", 40),
+		PasskeyRateWindow:         getEnvAsDuration("PASSKEY_RATE_LIMIT_WINDOW", time.Minute),
+
+		// API keys
+		APIKeyMaxPerUser: getEnvAsInt("API_KEY_MAX_PER_USER", 25),
+
+		// Mail
+		MailTransport: getEnv("MAIL_TRANSPORT", mailer.TransportLog),
+		MailFrom:      getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailFileDir:   getEnv("MAIL_FILE_DIR", "mail"),
 
 		// CORS
 		CORSAllowOrigins: getEnvAsSlice("CORS_ALLOW_ORIGINS", []string{"*"}),
+
+		// Reverse proxies
+		TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", nil),
 	}
 }
 
 // Validate checks if the configuration is valid.
 func (c *AppConfig) Validate() error {
-	if c.JWTSecretKey == "your-secret-key-change-in-production" && c.ServerMode == "release" {
-		log.Println("WARNING: Using default JWT secret key in production mode!")
+	switch c.DBDriver {
+	case dbDriverPostgres, dbDriverSQLite, dbDriverMemory:
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q", c.DBDriver)
+	}
+	switch c.RegistrationMode {
+	case account.RegistrationModeDisabled, account.RegistrationModeOpen, account.RegistrationModeInvite:
+	case account.RegistrationModeDomain:
+		if len(c.RegistrationAllowedDomains) == 0 {
+			return errors.New("REGISTRATION_ALLOWED_DOMAINS is required when REGISTRATION_MODE=domain")
+		}
+	default:
+		return fmt.Errorf("unsupported REGISTRATION_MODE %q", c.RegistrationMode)
+	}
+	if c.RegistrationRole != entity.RoleUser && c.RegistrationRole != entity.RoleViewer {
+		return fmt.Errorf("unsupported REGISTRATION_ROLE %q, must be user or viewer", c.RegistrationRole)
+	}
+	for _, role := range c.MFARequiredRoles {
+		if !entity.IsValidRoleName(role) {
+			return fmt.Errorf("invalid role %q in MFA_REQUIRED_ROLES", role)
+		}
+	}
+	switch c.TracingExporter {
+	case telemetry.ExporterNone, telemetry.ExporterOTLP, telemetry.ExporterStdout, telemetry.ExporterFile:
+	default:
+		return fmt.Errorf("unsupported TRACING_EXPORTER %q", c.TracingExporter)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		return errors.New("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	switch c.MailTransport {
+	case mailer.TransportLog, mailer.TransportFile:
+	default:
+		return fmt.Errorf("unsupported MAIL_TRANSPORT %q", c.MailTransport)
+	}
+	if c.MailTransport == mailer.TransportLog && c.ServerMode == "release" {
+		slog.Warn("MAIL_TRANSPORT=log writes password reset and verification links to the log in production mode")
+	}
+	if c.Argon2Memory <= 0 || c.Argon2Iterations <= 0 || c.Argon2Parallelism <= 0 || c.Argon2Parallelism > 255 {
+		return errors.New("ARGON2_MEMORY, ARGON2_ITERATIONS and ARGON2_PARALLELISM must be positive (parallelism at most 255)")
+	}
+	if len(c.JWTSigningKeys) > 0 && c.JWTActiveKeyId == "" {
+		return errors.New("JWT_ACTIVE_KEY_ID is required when JWT_SIGNING_KEYS is set")
+	}
+	if len(c.JWTSigningKeys) == 0 && c.JWTSecretKey == "your-secret-key-change-in-production" && c.ServerMode == "release" {
+		slog.Warn("using the default JWT secret key in production mode")
 	}
 	return nil
 }
@@ -82,6 +340,24 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
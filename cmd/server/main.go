@@ -1,55 +1,115 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/your-org/go-backend-template/internal/app/server"
 	"github.com/your-org/go-backend-template/internal/pkg/auth"
-	"github.com/your-org/go-backend-template/internal/pkg/repository/postgres"
+	"github.com/your-org/go-backend-template/internal/pkg/health"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/metrics"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
 )
 
 func main() {
 	// Load configuration
 	config := LoadConfig()
+
+	// Initialize logging first, so that everything below logs in the configured format.
+	// Logs go to stderr; stdout is left to the output of the migrate command.
+	log, err := logger.New(os.Stderr, logger.Config{Level: config.LogLevel, Format: config.LogFormat})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(log)
+
 	if err := config.Validate(); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+		fatal("invalid configuration", err)
 	}
 
-	// Initialize database repository
-	repo, err := postgres.New(&postgres.Config{
-		Host:     config.DBHost,
-		Port:     config.DBPort,
-		User:     config.DBUser,
-		Password: config.DBPassword,
-		DBName:   config.DBName,
-		SSLMode:  config.DBSSLMode,
-	})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// "server migrate <command>" manages the database schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(config, os.Args[2:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
 	}
-	defer repo.Close()
 
-	log.Println("Connected to database")
+	// Initialize the storage backend
+	// Metrics are collected by the repository and the server, and served on /metrics;
+	// components register readiness checks, reported by /readyz
+	registry := metrics.NewRegistry()
+	checks := health.NewRegistry(config.HealthCheckTimeout)
 
-	// Create tables if not exists
-	if err := repo.CreateTables(); err != nil {
-		log.Fatalf("Failed to create tables: %v", err)
+	repo, txManager, err := openRepository(config, registry, checks)
+	if err != nil {
+		fatal("failed to initialize database", err)
+	}
+	// Once created, the server closes the repository on shutdown; until then, close it before exiting
+	fatalClosing := func(msg string, err error) {
+		repo.Close()
+		fatal(msg, err)
+	}
+
+	// Load asymmetric signing keys (optional)
+	signingKeys, err := loadSigningKeys(config.JWTSigningKeys, config.JWTActiveKeyId)
+	if err != nil {
+		fatalClosing("failed to load JWT signing keys", err)
 	}
 
 	// Initialize JWT service
 	jwtService, err := auth.NewJWTService(auth.JWTConfig{
 		SecretKey:     config.JWTSecretKey,
+		Keys:          signingKeys,
 		TokenDuration: config.JWTTokenDuration,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create JWT service: %v", err)
+		fatalClosing("failed to create JWT service", err)
 	}
+	checks.Register("jwt_keys", jwtService.Check)
 
-	// Initialize password hasher
-	passwordHasher := auth.NewPasswordHasher(12) // bcrypt cost 12
+	// Initialize password hasher; outdated hashes are upgraded on login
+	passwordHasher, err := auth.NewPasswordHasherWithConfig(auth.PasswordConfig{
+		Algorithm:  config.PasswordHashAlgorithm,
+		BcryptCost: config.BcryptCost,
+		Argon2id: auth.Argon2idParams{
+			Memory:      uint32(config.Argon2Memory),
+			Iterations:  uint32(config.Argon2Iterations),
+			Parallelism: uint8(config.Argon2Parallelism),
+		},
+	})
+	if err != nil {
+		fatalClosing("failed to create password hasher", err)
+	}
+
+	// Initialize mailer
+	mail, err := mailer.New(mailer.Config{
+		Transport: config.MailTransport,
+		From:      config.MailFrom,
+		Dir:       config.MailFileDir,
+	})
+	if err != nil {
+		fatalClosing("failed to create mailer", err)
+	}
+
+	// Initialize tracing (nil when TRACING_EXPORTER=none)
+	tracerProvider, err := telemetry.NewTracerProvider(context.Background(), telemetry.Config{
+		Exporter:    config.TracingExporter,
+		ServiceName: config.TracingServiceName,
+		SampleRatio: config.TracingSampleRatio,
+		FilePath:    config.TracingFilePath,
+	})
+	if err != nil {
+		fatalClosing("failed to create tracer provider", err)
+	}
 
 	// Create server
 	srv, err := server.New(
@@ -58,32 +118,134 @@ func main() {
 			Port:             config.ServerPort,
 			Mode:             config.ServerMode,
 			CORSAllowOrigins: config.CORSAllowOrigins,
+			TrustedProxies:   config.TrustedProxies,
+
+			MetricsEnabled: config.MetricsEnabled,
+			MetricsPort:    config.MetricsPort,
+
+			RefreshTokenDuration: config.RefreshTokenDuration,
+			RevocationCacheTTL:   config.RevocationCacheTTL,
+			PermissionCacheTTL:   config.PermissionCacheTTL,
+
+			PasswordResetTokenDuration:     config.PasswordResetTokenDuration,
+			PasswordResetURL:               config.PasswordResetURL,
+			EmailVerificationTokenDuration: config.EmailVerificationTokenDuration,
+			EmailVerificationURL:           config.EmailVerificationURL,
+			RequireVerifiedEmail:           config.EmailVerificationRequired,
+			EmailRateLimit:                 config.EmailRateLimit,
+			EmailRateWindow:                config.EmailRateWindow,
+
+			RegistrationMode:           config.RegistrationMode,
+			RegistrationAllowedDomains: config.RegistrationAllowedDomains,
+			RegistrationRole:           config.RegistrationRole,
+			RegistrationRateLimit:      config.RegistrationRateLimit,
+			RegistrationRateWindow:     config.RegistrationRateWindow,
+			InvitationTokenDuration:    config.InvitationTokenDuration,
+			InvitationURL:              config.InvitationURL,
+
+			LoginRateLimit:         config.LoginRateLimit,
+			LoginRateWindow:        config.LoginRateWindow,
+			MaxFailedLoginAttempts: config.LoginMaxFailedAttempts,
+			LockoutDuration:        config.LoginLockoutDuration,
+			MaxLockoutDuration:     config.LoginMaxLockoutDuration,
+
+			MFAIssuer:            config.MFAIssuer,
+			MFARequiredRoles:     config.MFARequiredRoles,
+			MFAChallengeDuration: config.MFAChallengeDuration,
+			MFARateLimit:         config.MFARateLimit,
+			MFARateWindow:        config.MFARateWindow,
+
+			WebAuthnRPID:              config.WebAuthnRPID,
+			WebAuthnRPName:            config.WebAuthnRPName,
+			WebAuthnOrigins:           config.WebAuthnOrigins,
+			WebAuthnChallengeDuration: config.WebAuthnChallengeDuration,
+			PasskeyRateLimit:          config.PasskeyRateLimit,
+			PasskeyRateWindow:         config.PasskeyRateWindow,
+
+			APIKeyMaxPerUser: config.APIKeyMaxPerUser,
+
+			RequestTimeout: config.RequestTimeout,
+
+			ShutdownTimeout: config.ShutdownTimeout,
+			ShutdownDelay:   config.ShutdownDelay,
 		},
 		&server.Dependencies{
 			Repository:     repo,
+			TxManager:      txManager,
 			JWTService:     jwtService,
 			PasswordHasher: passwordHasher,
+			Mailer:         mail,
+			Logger:         log,
+			Metrics:        registry,
+			TracerProvider: tracerProvider,
+			Health:         checks,
 		},
 	)
 	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+		fatalClosing("failed to create server", err)
 	}
 
 	// Setup routes
 	srv.SetupRoutes()
 
 	// Start server in a goroutine
+	serverErr := make(chan error, 1)
 	go func() {
-		if err := srv.Run(); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
-		}
+		serverErr <- srv.Run()
 	}()
 
-	// Wait for interrupt signal
+	// Wait for interrupt signal or server failure
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
 
-	log.Println("Shutting down server...")
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			// Run the shutdown hooks, which release the repository and flush traces
+			if shutdownErr := srv.Shutdown(context.Background()); shutdownErr != nil {
+				slog.Error("server shutdown failed", "error", shutdownErr)
+			}
+			fatal("failed to start server", err)
+		}
+	case <-quit:
+	}
+
+	log.Info("shutting down server")
+
+	// Drain in-flight requests and release resources (bounded by SHUTDOWN_TIMEOUT)
+	if err := srv.Shutdown(context.Background()); err != nil {
+		fatal("server shutdown failed", err)
+	}
+
+	log.Info("server stopped")
 }
 
+// fatal logs err and exits, like log.Fatal.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// loadSigningKeys loads "kid=path" entries from PEM files.
+// The key matching activeKeyId signs new tokens; the rest are retiring and only verify.
+func loadSigningKeys(entries []string, activeKeyId string) ([]*auth.SigningKey, error) {
+	keys := make([]*auth.SigningKey, 0, len(entries))
+	for _, entry := range entries {
+		kid, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid signing key entry %q, expected kid=path", entry)
+		}
+
+		status := auth.KeyStatusRetiring
+		if kid == activeKeyId {
+			status = auth.KeyStatusActive
+		}
+
+		key, err := auth.LoadSigningKeyFromPEM(kid, path, status)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
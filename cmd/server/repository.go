@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/your-org/go-backend-template/internal/app/server"
+	userService "github.com/your-org/go-backend-template/internal/app/server/service/user"
+	"github.com/your-org/go-backend-template/internal/pkg/health"
+	"github.com/your-org/go-backend-template/internal/pkg/metrics"
+	"github.com/your-org/go-backend-template/internal/pkg/migrate"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"github.com/your-org/go-backend-template/internal/pkg/repository/memory"
+	"github.com/your-org/go-backend-template/internal/pkg/repository/postgres"
+	"github.com/your-org/go-backend-template/internal/pkg/repository/sqlite"
+)
+
+// migratableRepository is a SQL backend that manages its own schema.
+type migratableRepository interface {
+	server.Repository
+	Migrator() (*migrate.Migrator, error)
+	Migrate(ctx context.Context) error
+	DB() *sql.DB
+}
+
+// openRepository creates the storage backend selected by DB_DRIVER
+// together with the transaction manager that goes with it.
+// SQL backends report their query latency and connection pool statistics to registry,
+// and register database and migration readiness checks with checks.
+func openRepository(config *AppConfig, registry prometheus.Registerer, checks *health.Registry) (server.Repository, userService.ITxManager, error) {
+	if config.DBDriver == dbDriverMemory {
+		slog.Warn("using in-memory storage; all data will be lost on restart")
+		repo := memory.New()
+		return repo, repo, nil
+	}
+
+	queryDuration := promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Latency of repository methods.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"method"})
+	observer := func(method string, duration time.Duration) {
+		queryDuration.WithLabelValues(method).Observe(duration.Seconds())
+	}
+
+	repo, txManager, err := openSQLRepository(config, observer)
+	if err != nil {
+		return nil, nil, err
+	}
+	metrics.RegisterDBStats(registry, repo.DB().Stats)
+
+	migrator, err := repo.Migrator()
+	if err != nil {
+		repo.Close()
+		return nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	checks.Register("database", repo.DB().PingContext)
+	checks.Register("migrations", migrator.Check)
+
+	slog.Info("connected to database", "driver", config.DBDriver)
+
+	// Apply pending migrations on startup unless disabled
+	if config.DBAutoMigrate {
+		if err := repo.Migrate(context.Background()); err != nil {
+			repo.Close()
+			return nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	return repo, txManager, nil
+}
+
+// openSQLRepository connects to the SQL backend selected by DB_DRIVER.
+// observer may be nil.
+func openSQLRepository(config *AppConfig, observer repository.QueryObserver) (migratableRepository, userService.ITxManager, error) {
+	switch config.DBDriver {
+	case dbDriverPostgres:
+		repo, err := postgres.New(&postgres.Config{
+			Host:     config.DBHost,
+			Port:     config.DBPort,
+			User:     config.DBUser,
+			Password: config.DBPassword,
+			DBName:   config.DBName,
+			SSLMode:  config.DBSSLMode,
+
+			QueryObserver: observer,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, postgres.NewTxManager(repo, postgres.TxConfig{}), nil
+
+	case dbDriverSQLite:
+		repo, err := sqlite.New(&sqlite.Config{Path: config.DBPath, QueryObserver: observer})
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, sqlite.NewTxManager(repo), nil
+
+	default:
+		return nil, nil, fmt.Errorf("DB_DRIVER=%s has no SQL schema", config.DBDriver)
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"github.com/go-webauthn/webauthn/protocol"
+	userHandler "github.com/your-org/go-backend-template/internal/app/server/handler/user"
+)
+
+// ========== Request DTOs ==========
+
+// LoginRequest represents the request body for user login.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest represents the request body for refreshing tokens.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents the request body for logout.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // optional
+}
+
+// ForgotPasswordRequest represents the request body for requesting a password reset.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the request body for resetting a password.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=100"`
+}
+
+// VerifyEmailRequest represents the request body for verifying an email address.
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendEmailVerificationRequest represents the request body for requesting a new verification email.
+type ResendEmailVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// RegisterRequest represents the request body for self-registration.
+// The fields are validated like userHandler.CreateUserRequest; the role is chosen by the server.
+type RegisterRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	Username        string `json:"username" binding:"required,min=3,max=50"`
+	Password        string `json:"password" binding:"required,min=8,max=100"`
+	Name            string `json:"name" binding:"required,min=1,max=100"`
+	InvitationToken string `json:"invitation_token"` // required in invite mode
+}
+
+// VerifyMFARequest represents the request body for completing a login with a second factor.
+type VerifyMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func (r *VerifyMFARequest) Validate() error {
+	return userHandler.ValidateMFACode(r.Code, r.RecoveryCode)
+}
+
+// BeginMFAEnrollmentRequest represents the request body for enrolling a second factor during login.
+type BeginMFAEnrollmentRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// ConfirmMFAEnrollmentRequest represents the request body for confirming an enrollment during login.
+type ConfirmMFAEnrollmentRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// FinishPasskeyLoginRequest represents the request body for completing a passkey login.
+// Credential is the PublicKeyCredential returned by navigator.credentials.get, in its JSON form.
+type FinishPasskeyLoginRequest struct {
+	Credential *protocol.CredentialAssertionResponse `json:"credential" binding:"required"`
+}
+
+// ========== Response DTOs ==========
+
+// LoginResponse represents the response for user login.
+type LoginResponse struct {
+	Token        string                    `json:"token"`
+	RefreshToken string                    `json:"refresh_token"`
+	User         *userHandler.UserResponse `json:"user"`
+}
+
+// MFAChallengeResponse represents the response for a login that needs a second factor.
+// The login completes through POST /auth/mfa/verify, or through POST /auth/mfa/enroll and
+// POST /auth/mfa/enroll/confirm if enrollment is required.
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ExpiresAt          int64  `json:"expires_at"`
+}
+
+// MFAEnrollmentLoginResponse represents the response for a login completed by enrolling a second factor.
+type MFAEnrollmentLoginResponse struct {
+	LoginResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshResponse represents the response for token refresh.
+type RefreshResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RegisterResponse represents the response for self-registration.
+type RegisterResponse struct {
+	Id int `json:"id"`
+}
+
+// forgotPasswordMessage is returned for every forgot password request,
+// so that the response does not reveal whether the email is registered.
+const forgotPasswordMessage = "if the email is registered, a password reset link has been sent"
+
+// resendVerificationMessage is returned for every resend request, like forgotPasswordMessage.
+const resendVerificationMessage = "if the email is registered and unverified, a verification link has been sent"
+
+// MessageResponse represents a simple message response.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
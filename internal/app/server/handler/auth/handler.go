@@ -0,0 +1,444 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	userHandler "github.com/your-org/go-backend-template/internal/app/server/handler/user"
+	"github.com/your-org/go-backend-template/internal/app/server/service/account"
+	"github.com/your-org/go-backend-template/internal/app/server/service/auth"
+	"github.com/your-org/go-backend-template/internal/app/server/service/mfa"
+	"github.com/your-org/go-backend-template/internal/app/server/service/passkey"
+	"github.com/your-org/go-backend-template/internal/app/server/service/user"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+)
+
+// IKeySetProvider provides the public keys used to verify access tokens.
+type IKeySetProvider interface {
+	JWKS() *pkgAuth.JWKS
+}
+
+// ILoginRecorder records the outcome of login attempts, e.g. as metrics.
+type ILoginRecorder interface {
+	RecordLogin(method string, err error)
+}
+
+// Handler handles authentication-related HTTP requests.
+type Handler struct {
+	handler.BaseHandler
+	userService    *user.Service
+	authService    *auth.Service
+	accountService *account.Service
+	mfaService     *mfa.Service
+	passkeyService *passkey.Service
+	keySet         IKeySetProvider
+	loginRecorder  ILoginRecorder
+}
+
+// NewHandler creates a new auth handler. loginRecorder may be nil.
+func NewHandler(
+	userService *user.Service,
+	authService *auth.Service,
+	accountService *account.Service,
+	mfaService *mfa.Service,
+	passkeyService *passkey.Service,
+	keySet IKeySetProvider,
+	loginRecorder ILoginRecorder,
+) *Handler {
+	return &Handler{
+		BaseHandler:    handler.BaseHandler{},
+		userService:    userService,
+		authService:    authService,
+		accountService: accountService,
+		mfaService:     mfaService,
+		passkeyService: passkeyService,
+		keySet:         keySet,
+		loginRecorder:  loginRecorder,
+	}
+}
+
+// Login handles POST /auth/login
+func (h *Handler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &user.LoginInput{
+		Email:    req.Email,
+		Password: req.Password,
+	}
+
+	loggedInUser, err := h.userService.Login(c.Request.Context(), input)
+	if err != nil {
+		h.recordLogin(pkgAuth.LoginMethodPassword, err)
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	// Users with a second factor, or whose role requires one, get a challenge instead of tokens.
+	// The login is recorded once the challenge is completed.
+	challenge, err := h.mfaService.StartChallenge(c.Request.Context(), loggedInUser)
+	if err != nil {
+		h.recordLogin(pkgAuth.LoginMethodPassword, err)
+		h.HandleDomainError(c, err)
+		return
+	}
+	if challenge != nil {
+		h.HandleSuccess(c, http.StatusOK, &MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           challenge.Token,
+			EnrollmentRequired: challenge.EnrollmentRequired,
+			ExpiresAt:          challenge.ExpiresAt.Unix(),
+		})
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), pkgAuth.LoginMethodPassword, loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// VerifyMFA handles POST /auth/mfa/verify
+func (h *Handler) VerifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.HandleValidationError(c, err.Error())
+		return
+	}
+
+	input := &mfa.VerifyChallengeInput{
+		Token:        req.MFAToken,
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+	}
+
+	loggedInUser, err := h.mfaService.VerifyChallenge(c.Request.Context(), input)
+	if err != nil {
+		h.recordFailedSecondFactor(c.Request.Context(), err)
+		h.recordLogin(pkgAuth.LoginMethodMFA, err)
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), pkgAuth.LoginMethodMFA, loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// BeginMFAEnrollment handles POST /auth/mfa/enroll
+func (h *Handler) BeginMFAEnrollment(c *gin.Context) {
+	var req BeginMFAEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginChallengeEnrollment(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &userHandler.MFAEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// ConfirmMFAEnrollment handles POST /auth/mfa/enroll/confirm
+func (h *Handler) ConfirmMFAEnrollment(c *gin.Context) {
+	var req ConfirmMFAEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &mfa.ConfirmChallengeEnrollmentInput{
+		Token: req.MFAToken,
+		Code:  req.Code,
+	}
+
+	loggedInUser, recoveryCodes, err := h.mfaService.ConfirmChallengeEnrollment(c.Request.Context(), input)
+	if err != nil {
+		h.recordFailedSecondFactor(c.Request.Context(), err)
+		h.recordLogin(pkgAuth.LoginMethodMFA, err)
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), pkgAuth.LoginMethodMFA, loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MFAEnrollmentLoginResponse{
+		LoginResponse: *resp,
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// BeginPasskeyLogin handles POST /auth/passkey/login/begin
+// The response holds the options for navigator.credentials.get.
+func (h *Handler) BeginPasskeyLogin(c *gin.Context) {
+	options, err := h.passkeyService.BeginLogin(c.Request.Context())
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, options)
+}
+
+// FinishPasskeyLogin handles POST /auth/passkey/login/finish
+// A passkey verifies the user, so the login completes without a second factor challenge.
+func (h *Handler) FinishPasskeyLogin(c *gin.Context) {
+	var req FinishPasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &passkey.FinishLoginInput{
+		Response: req.Credential,
+	}
+
+	loggedInUser, err := h.passkeyService.FinishLogin(c.Request.Context(), input)
+	if err != nil {
+		h.recordLogin(pkgAuth.LoginMethodPasskey, err)
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), pkgAuth.LoginMethodPasskey, loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// issueLoginTokens issues access and refresh tokens for a user who completed the login with method.
+func (h *Handler) issueLoginTokens(ctx context.Context, method string, loggedInUser *entity.User) (*LoginResponse, error) {
+	tokens, err := h.authService.IssueTokens(ctx, loggedInUser)
+	h.recordLogin(method, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         userHandler.ToUserResponse(loggedInUser),
+	}, nil
+}
+
+// recordLogin reports a login attempt to the login recorder, if any.
+func (h *Handler) recordLogin(method string, err error) {
+	if h.loginRecorder != nil {
+		h.loginRecorder.RecordLogin(method, err)
+	}
+}
+
+// recordFailedSecondFactor counts a wrong second factor towards the login lockout,
+// like a wrong password; a locked out user's pending challenge becomes invalid.
+func (h *Handler) recordFailedSecondFactor(ctx context.Context, err error) {
+	var codeErr domain.InvalidMFACodeError
+	if errors.As(err, &codeErr) && codeErr.UserId != 0 {
+		h.userService.RecordFailedLogin(ctx, codeErr.UserId)
+	}
+}
+
+// Register handles POST /auth/register
+func (h *Handler) Register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &account.RegisterInput{
+		Email:           req.Email,
+		Username:        req.Username,
+		Password:        req.Password,
+		Name:            req.Name,
+		InvitationToken: req.InvitationToken,
+	}
+
+	userId, err := h.accountService.Register(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	// An invitation already proved the email; otherwise ask the user to verify it.
+	// The user exists from here on, so a failure is only logged: the user can ask for a new
+	// verification email through POST /auth/verify-email/resend.
+	if req.InvitationToken == "" {
+		if err := h.accountService.SendEmailVerification(c.Request.Context(), userId); err != nil {
+			logger.FromContext(c.Request.Context()).Error("failed to send verification email", "user_id", userId, "error", err)
+		}
+	}
+
+	h.HandleSuccess(c, http.StatusCreated, &RegisterResponse{Id: userId})
+}
+
+// Refresh handles POST /auth/refresh
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &auth.RefreshInput{
+		RefreshToken: req.RefreshToken,
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	resp := &RefreshResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+// Logout handles POST /auth/logout
+func (h *Handler) Logout(c *gin.Context) {
+	// The body is optional; without a refresh token only the access token is revoked
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.HandleBindingError(c, err)
+			return
+		}
+	}
+
+	input := &auth.LogoutInput{
+		UserId:         handler.GetUserId(c),
+		TokenId:        handler.GetTokenId(c),
+		TokenExpiresAt: handler.GetTokenExpiresAt(c),
+		RefreshToken:   req.RefreshToken,
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), input); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "logged out successfully"})
+}
+
+// LogoutAll handles POST /auth/logout-all
+func (h *Handler) LogoutAll(c *gin.Context) {
+	if err := h.authService.LogoutAll(c.Request.Context(), handler.GetUserId(c)); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "logged out from all sessions"})
+}
+
+// ForgotPassword handles POST /auth/password/forgot
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	// Same response whether or not the email is registered; the email is sent in the background
+	h.accountService.ForgotPassword(c.Request.Context(), req.Email)
+	h.HandleSuccess(c, http.StatusAccepted, &MessageResponse{Message: forgotPasswordMessage})
+}
+
+// ResetPassword handles POST /auth/password/reset
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &account.ResetPasswordInput{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	}
+
+	userId, err := h.accountService.ResetPassword(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+	// The reset revoked the user's sessions
+	h.authService.ForgetUserTokenState(userId)
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "password has been reset"})
+}
+
+// VerifyEmail handles POST /auth/verify-email
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	userId, err := h.accountService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+	// Verifying a changed email revoked the user's sessions
+	h.authService.ForgetUserTokenState(userId)
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "email verified successfully"})
+}
+
+// ResendEmailVerification handles POST /auth/verify-email/resend
+func (h *Handler) ResendEmailVerification(c *gin.Context) {
+	var req ResendEmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	// Same response whether or not the email is registered; the email is sent in the background
+	h.accountService.ResendEmailVerification(c.Request.Context(), req.Email)
+	h.HandleSuccess(c, http.StatusAccepted, &MessageResponse{Message: resendVerificationMessage})
+}
+
+// JWKS handles GET /.well-known/jwks.json
+func (h *Handler) JWKS(c *gin.Context) {
+	// Keys change only on rotation; let verifiers cache the set briefly
+	c.Header("Cache-Control", "public, max-age=300")
+	h.HandleSuccess(c, http.StatusOK, h.keySet.JWKS())
+}
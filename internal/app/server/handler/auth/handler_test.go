@@ -0,0 +1,1160 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	userHandler "github.com/your-org/go-backend-template/internal/app/server/handler/user"
+	"github.com/your-org/go-backend-template/internal/app/server/service/account"
+	"github.com/your-org/go-backend-template/internal/app/server/service/auth"
+	"github.com/your-org/go-backend-template/internal/app/server/service/mfa"
+	"github.com/your-org/go-backend-template/internal/app/server/service/passkey"
+	"github.com/your-org/go-backend-template/internal/app/server/service/user"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Mock Services ==========
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Login(ctx context.Context, input *user.LoginInput) (*entity.User, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserService) RecordFailedLogin(ctx context.Context, userId int) {
+	m.Called(userId)
+}
+
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) IssueTokens(ctx context.Context, u *entity.User) (*auth.TokenPair, error) {
+	args := m.Called(u)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, input *auth.RefreshInput) (*auth.TokenPair, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, input *auth.LogoutInput) error {
+	args := m.Called(input)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ForgetUserTokenState(userId int) {
+	m.Called(userId)
+}
+
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) ForgotPassword(ctx context.Context, email string) {
+	m.Called(email)
+}
+
+func (m *MockAccountService) ResetPassword(ctx context.Context, input *account.ResetPasswordInput) (int, error) {
+	args := m.Called(input)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountService) Register(ctx context.Context, input *account.RegisterInput) (int, error) {
+	args := m.Called(input)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountService) SendEmailVerification(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) VerifyEmail(ctx context.Context, token string) (int, error) {
+	args := m.Called(token)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountService) ResendEmailVerification(ctx context.Context, email string) {
+	m.Called(email)
+}
+
+type MockMFAService struct {
+	mock.Mock
+}
+
+func (m *MockMFAService) StartChallenge(ctx context.Context, u *entity.User) (*mfa.Challenge, error) {
+	args := m.Called(u)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mfa.Challenge), args.Error(1)
+}
+
+func (m *MockMFAService) VerifyChallenge(ctx context.Context, input *mfa.VerifyChallengeInput) (*entity.User, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+type MockPasskeyService struct {
+	mock.Mock
+}
+
+func (m *MockPasskeyService) FinishLogin(ctx context.Context, input *passkey.FinishLoginInput) (*entity.User, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+type MockKeySetProvider struct {
+	mock.Mock
+}
+
+func (m *MockKeySetProvider) JWKS() *pkgAuth.JWKS {
+	args := m.Called()
+	return args.Get(0).(*pkgAuth.JWKS)
+}
+
+// ========== Test Helpers ==========
+
+// testHandler mirrors Handler but uses mocked services
+type testHandler struct {
+	handler.BaseHandler
+	mockUserService    *MockUserService
+	mockAuthService    *MockAuthService
+	mockAccountService *MockAccountService
+	mockMFAService     *MockMFAService
+	mockPasskeyService *MockPasskeyService
+}
+
+func newTestHandler() (*testHandler, *MockUserService, *MockAuthService) {
+	mockUserSvc := new(MockUserService)
+	mockAuthSvc := new(MockAuthService)
+	return &testHandler{
+		BaseHandler:        handler.BaseHandler{},
+		mockUserService:    mockUserSvc,
+		mockAuthService:    mockAuthSvc,
+		mockAccountService: new(MockAccountService),
+		mockMFAService:     new(MockMFAService),
+		mockPasskeyService: new(MockPasskeyService),
+	}, mockUserSvc, mockAuthSvc
+}
+
+func (h *testHandler) login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &user.LoginInput{
+		Email:    req.Email,
+		Password: req.Password,
+	}
+
+	loggedInUser, err := h.mockUserService.Login(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	challenge, err := h.mockMFAService.StartChallenge(c.Request.Context(), loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+	if challenge != nil {
+		h.HandleSuccess(c, http.StatusOK, &MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           challenge.Token,
+			EnrollmentRequired: challenge.EnrollmentRequired,
+			ExpiresAt:          challenge.ExpiresAt.Unix(),
+		})
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+func (h *testHandler) verifyMFA(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.HandleValidationError(c, err.Error())
+		return
+	}
+
+	input := &mfa.VerifyChallengeInput{
+		Token:        req.MFAToken,
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+	}
+
+	loggedInUser, err := h.mockMFAService.VerifyChallenge(c.Request.Context(), input)
+	if err != nil {
+		var codeErr domain.InvalidMFACodeError
+		if errors.As(err, &codeErr) && codeErr.UserId != 0 {
+			h.mockUserService.RecordFailedLogin(c.Request.Context(), codeErr.UserId)
+		}
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+func (h *testHandler) finishPasskeyLogin(c *gin.Context) {
+	var req FinishPasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &passkey.FinishLoginInput{
+		Response: req.Credential,
+	}
+
+	loggedInUser, err := h.mockPasskeyService.FinishLogin(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	resp, err := h.issueLoginTokens(c.Request.Context(), loggedInUser)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, resp)
+}
+
+func (h *testHandler) issueLoginTokens(ctx context.Context, loggedInUser *entity.User) (*LoginResponse, error) {
+	tokens, err := h.mockAuthService.IssueTokens(ctx, loggedInUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         userHandler.ToUserResponse(loggedInUser),
+	}, nil
+}
+
+func (h *testHandler) register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &account.RegisterInput{
+		Email:           req.Email,
+		Username:        req.Username,
+		Password:        req.Password,
+		Name:            req.Name,
+		InvitationToken: req.InvitationToken,
+	}
+
+	userId, err := h.mockAccountService.Register(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	if req.InvitationToken == "" {
+		_ = h.mockAccountService.SendEmailVerification(c.Request.Context(), userId)
+	}
+
+	h.HandleSuccess(c, http.StatusCreated, &RegisterResponse{Id: userId})
+}
+
+func (h *testHandler) refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	tokens, err := h.mockAuthService.Refresh(c.Request.Context(), &auth.RefreshInput{RefreshToken: req.RefreshToken})
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &RefreshResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+func (h *testHandler) logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.HandleBindingError(c, err)
+			return
+		}
+	}
+
+	input := &auth.LogoutInput{
+		UserId:         handler.GetUserId(c),
+		TokenId:        handler.GetTokenId(c),
+		TokenExpiresAt: handler.GetTokenExpiresAt(c),
+		RefreshToken:   req.RefreshToken,
+	}
+
+	if err := h.mockAuthService.Logout(c.Request.Context(), input); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "logged out successfully"})
+}
+
+func (h *testHandler) forgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	h.mockAccountService.ForgotPassword(c.Request.Context(), req.Email)
+	h.HandleSuccess(c, http.StatusAccepted, &MessageResponse{Message: forgotPasswordMessage})
+}
+
+func (h *testHandler) resetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &account.ResetPasswordInput{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	}
+
+	userId, err := h.mockAccountService.ResetPassword(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+	h.mockAuthService.ForgetUserTokenState(userId)
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "password has been reset"})
+}
+
+func (h *testHandler) verifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	userId, err := h.mockAccountService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+	h.mockAuthService.ForgetUserTokenState(userId)
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "email verified successfully"})
+}
+
+func (h *testHandler) resendEmailVerification(c *gin.Context) {
+	var req ResendEmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	h.mockAccountService.ResendEmailVerification(c.Request.Context(), req.Email)
+	h.HandleSuccess(c, http.StatusAccepted, &MessageResponse{Message: resendVerificationMessage})
+}
+
+// withAuthContext simulates RequireAuth having accepted an access token
+func withAuthContext(userId int, tokenId string, expiresAt time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler.SetUserId(c, userId)
+		handler.SetTokenId(c, tokenId)
+		handler.SetTokenExpiresAt(c, expiresAt)
+		c.Next()
+	}
+}
+
+// ========== Login Tests ==========
+
+func TestHandler_Login_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, mockUserSvc, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/login", h.login)
+
+	now := time.Now()
+	mockUser := &entity.User{
+		Id:        1,
+		Email:     "test@example.com",
+		Username:  "testuser",
+		Name:      "Test User",
+		Role:      "user",
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	mockUserSvc.On("Login", mock.AnythingOfType("*user.LoginInput")).Return(mockUser, nil)
+	h.mockMFAService.On("StartChallenge", mockUser).Return(nil, nil)
+	mockAuthSvc.On("IssueTokens", mockUser).Return(&auth.TokenPair{
+		AccessToken:  "mock_jwt_token",
+		RefreshToken: "mock_refresh_token",
+	}, nil)
+
+	reqBody := LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp LoginResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock_jwt_token", resp.Token)
+	assert.Equal(t, "mock_refresh_token", resp.RefreshToken)
+	assert.Equal(t, 1, resp.User.Id)
+	mockUserSvc.AssertExpectations(t)
+	mockAuthSvc.AssertExpectations(t)
+}
+
+func TestHandler_Login_InvalidCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, mockUserSvc, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/login", h.login)
+
+	mockUserSvc.On("Login", mock.AnythingOfType("*user.LoginInput")).
+		Return(nil, domain.InvalidCredentialsError{})
+
+	reqBody := LoginRequest{
+		Email:    "test@example.com",
+		Password: "wrong_password",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUserSvc.AssertExpectations(t)
+	mockAuthSvc.AssertNotCalled(t, "IssueTokens", mock.Anything)
+}
+
+func TestHandler_Login_MFAChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, mockUserSvc, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/login", h.login)
+
+	mockUser := &entity.User{Id: 1, Email: "test@example.com", Role: "user", IsActive: true}
+	expiresAt := time.Now().Add(5 * time.Minute)
+
+	mockUserSvc.On("Login", mock.AnythingOfType("*user.LoginInput")).Return(mockUser, nil)
+	h.mockMFAService.On("StartChallenge", mockUser).Return(&mfa.Challenge{
+		Token:     "mock_challenge_token",
+		ExpiresAt: expiresAt,
+	}, nil)
+
+	jsonBody, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "password123"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp MFAChallengeResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.True(t, resp.MFARequired)
+	assert.Equal(t, "mock_challenge_token", resp.MFAToken)
+	assert.False(t, resp.EnrollmentRequired)
+	assert.Equal(t, expiresAt.Unix(), resp.ExpiresAt)
+	assert.NotContains(t, w.Body.String(), `"token"`, "no access token before the second factor")
+	mockAuthSvc.AssertNotCalled(t, "IssueTokens", mock.Anything)
+}
+
+// ========== MFA Verify Tests ==========
+
+func newVerifyMFARequest(body VerifyMFARequest) *http.Request {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/auth/mfa/verify", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestHandler_VerifyMFA_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/mfa/verify", h.verifyMFA)
+
+	mockUser := &entity.User{Id: 1, Email: "test@example.com", Role: "user", IsActive: true}
+	h.mockMFAService.On("VerifyChallenge", &mfa.VerifyChallengeInput{Token: "challenge", Code: "123456"}).Return(mockUser, nil)
+	mockAuthSvc.On("IssueTokens", mockUser).Return(&auth.TokenPair{
+		AccessToken:  "mock_jwt_token",
+		RefreshToken: "mock_refresh_token",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newVerifyMFARequest(VerifyMFARequest{MFAToken: "challenge", Code: "123456"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp LoginResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock_jwt_token", resp.Token)
+	assert.Equal(t, 1, resp.User.Id)
+	h.mockMFAService.AssertExpectations(t)
+}
+
+func TestHandler_VerifyMFA_WrongCodeCountsAsFailedLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, mockUserSvc, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/mfa/verify", h.verifyMFA)
+
+	h.mockMFAService.On("VerifyChallenge", mock.Anything).Return(nil, domain.InvalidMFACodeError{UserId: 1})
+	mockUserSvc.On("RecordFailedLogin", 1).Return()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newVerifyMFARequest(VerifyMFARequest{MFAToken: "challenge", Code: "000000"}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUserSvc.AssertExpectations(t)
+	mockAuthSvc.AssertNotCalled(t, "IssueTokens", mock.Anything)
+}
+
+func TestHandler_VerifyMFA_InvalidChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, mockUserSvc, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/mfa/verify", h.verifyMFA)
+
+	h.mockMFAService.On("VerifyChallenge", mock.Anything).Return(nil, domain.InvalidTokenError{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newVerifyMFARequest(VerifyMFARequest{MFAToken: "expired", RecoveryCode: "abcdefgh-ijklmnop"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUserSvc.AssertNotCalled(t, "RecordFailedLogin", mock.Anything)
+}
+
+func TestHandler_VerifyMFA_RequiresOneCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/mfa/verify", h.verifyMFA)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newVerifyMFARequest(VerifyMFARequest{MFAToken: "challenge"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	h.mockMFAService.AssertNotCalled(t, "VerifyChallenge", mock.Anything)
+}
+
+// ========== Passkey Login Tests ==========
+
+func newFinishPasskeyLoginRequest(body any) *http.Request {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/auth/passkey/login/finish", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func testAssertion() *protocol.CredentialAssertionResponse {
+	return &protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: "AQID", Type: "public-key"},
+			RawID:      protocol.URLEncodedBase64{1, 2, 3},
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{
+				ClientDataJSON: protocol.URLEncodedBase64(`{"type":"webauthn.get"}`),
+			},
+			AuthenticatorData: protocol.URLEncodedBase64{4, 5, 6},
+			Signature:         protocol.URLEncodedBase64{7, 8, 9},
+			UserHandle:        protocol.URLEncodedBase64{0, 0, 0, 0, 0, 0, 0, 1},
+		},
+	}
+}
+
+func TestHandler_FinishPasskeyLogin_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/passkey/login/finish", h.finishPasskeyLogin)
+
+	mockUser := &entity.User{Id: 1, Email: "test@example.com", Role: "user", IsActive: true}
+	h.mockPasskeyService.On("FinishLogin", &passkey.FinishLoginInput{Response: testAssertion()}).Return(mockUser, nil)
+	mockAuthSvc.On("IssueTokens", mockUser).Return(&auth.TokenPair{
+		AccessToken:  "mock_jwt_token",
+		RefreshToken: "mock_refresh_token",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newFinishPasskeyLoginRequest(FinishPasskeyLoginRequest{Credential: testAssertion()}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp LoginResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock_jwt_token", resp.Token)
+	assert.Equal(t, "mock_refresh_token", resp.RefreshToken)
+	assert.Equal(t, 1, resp.User.Id)
+	h.mockPasskeyService.AssertExpectations(t)
+	h.mockMFAService.AssertNotCalled(t, "StartChallenge", mock.Anything)
+}
+
+func TestHandler_FinishPasskeyLogin_InvalidPasskey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/passkey/login/finish", h.finishPasskeyLogin)
+
+	h.mockPasskeyService.On("FinishLogin", mock.Anything).Return(nil, domain.InvalidPasskeyError{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newFinishPasskeyLoginRequest(FinishPasskeyLoginRequest{Credential: testAssertion()}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockAuthSvc.AssertNotCalled(t, "IssueTokens", mock.Anything)
+}
+
+func TestHandler_FinishPasskeyLogin_MissingCredential(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/passkey/login/finish", h.finishPasskeyLogin)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newFinishPasskeyLoginRequest(map[string]any{}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	h.mockPasskeyService.AssertNotCalled(t, "FinishLogin", mock.Anything)
+}
+
+// ========== Refresh Tests ==========
+
+func TestHandler_Refresh_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/refresh", h.refresh)
+
+	mockAuthSvc.On("Refresh", &auth.RefreshInput{RefreshToken: "old_refresh_token"}).Return(&auth.TokenPair{
+		AccessToken:  "new_access_token",
+		RefreshToken: "new_refresh_token",
+	}, nil)
+
+	jsonBody, _ := json.Marshal(RefreshRequest{RefreshToken: "old_refresh_token"})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp RefreshResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "new_access_token", resp.Token)
+	assert.Equal(t, "new_refresh_token", resp.RefreshToken)
+	mockAuthSvc.AssertExpectations(t)
+}
+
+func TestHandler_Refresh_MissingToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/refresh", h.refresh)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandler_Refresh_ReuseDetected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/refresh", h.refresh)
+
+	mockAuthSvc.On("Refresh", mock.AnythingOfType("*auth.RefreshInput")).
+		Return(nil, domain.UnauthorizedError{Reason: "refresh token reuse detected"})
+
+	jsonBody, _ := json.Marshal(RefreshRequest{RefreshToken: "rotated_token"})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "refresh token reuse detected")
+	mockAuthSvc.AssertExpectations(t)
+}
+
+// ========== Logout Tests ==========
+
+func TestHandler_Logout_WithoutBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	expiresAt := time.Now().Add(time.Minute)
+	router := gin.New()
+	router.POST("/auth/logout", withAuthContext(1, "jti-1", expiresAt), h.logout)
+
+	mockAuthSvc.On("Logout", &auth.LogoutInput{
+		UserId:         1,
+		TokenId:        "jti-1",
+		TokenExpiresAt: expiresAt,
+	}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAuthSvc.AssertExpectations(t)
+}
+
+func TestHandler_Logout_WithRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/logout", withAuthContext(1, "jti-1", time.Now()), h.logout)
+
+	mockAuthSvc.On("Logout", mock.MatchedBy(func(input *auth.LogoutInput) bool {
+		return input.RefreshToken == "refresh_token" && input.TokenId == "jti-1"
+	})).Return(nil)
+
+	jsonBody, _ := json.Marshal(LogoutRequest{RefreshToken: "refresh_token"})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "logged out successfully")
+	mockAuthSvc.AssertExpectations(t)
+}
+
+func TestHandler_LogoutAll_UserNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/logout-all", withAuthContext(999, "jti-1", time.Now()), func(c *gin.Context) {
+		if err := h.mockAuthService.LogoutAll(c.Request.Context(), handler.GetUserId(c)); err != nil {
+			h.HandleDomainError(c, err)
+			return
+		}
+		h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "logged out from all sessions"})
+	})
+
+	mockAuthSvc.On("LogoutAll", 999).Return(domain.UserNotFoundError{Id: 999})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockAuthSvc.AssertExpectations(t)
+}
+
+// ========== Register Tests ==========
+
+func newRegisterRequest(invitationToken string) *http.Request {
+	body, _ := json.Marshal(RegisterRequest{
+		Email:           "new@example.com",
+		Username:        "newuser",
+		Password:        "password123",
+		Name:            "New User",
+		InvitationToken: invitationToken,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestHandler_Register_SendsVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/register", h.register)
+
+	h.mockAccountService.On("Register", mock.MatchedBy(func(input *account.RegisterInput) bool {
+		return input.Email == "new@example.com" && input.Password == "password123" && input.InvitationToken == ""
+	})).Return(5, nil)
+	h.mockAccountService.On("SendEmailVerification", 5).Return(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRegisterRequest(""))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":5}`, w.Body.String())
+	h.mockAccountService.AssertExpectations(t)
+}
+
+func TestHandler_Register_WithInvitation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/register", h.register)
+
+	h.mockAccountService.On("Register", mock.MatchedBy(func(input *account.RegisterInput) bool {
+		return input.InvitationToken == "invite-token"
+	})).Return(5, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRegisterRequest("invite-token"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	h.mockAccountService.AssertExpectations(t)
+	// The invitation already proved the email
+	h.mockAccountService.AssertNotCalled(t, "SendEmailVerification", mock.Anything)
+}
+
+func TestHandler_Register_NotAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/register", h.register)
+
+	h.mockAccountService.On("Register", mock.Anything).
+		Return(0, domain.ForbiddenError{Reason: "self-registration is disabled"})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRegisterRequest(""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	h.mockAccountService.AssertNotCalled(t, "SendEmailVerification", mock.Anything)
+}
+
+func TestHandler_Register_InvitationAlreadyUsed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/register", h.register)
+
+	// Consuming the invitation failed, so no user was created
+	h.mockAccountService.On("Register", mock.Anything).Return(0, domain.InvalidTokenError{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRegisterRequest("invite-token"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	h.mockAccountService.AssertNotCalled(t, "SendEmailVerification", mock.Anything)
+}
+
+// ========== Password Reset Tests ==========
+
+func TestHandler_ForgotPassword_SameResponseForAnyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/password/forgot", h.forgotPassword)
+
+	// The service returns nil for unknown emails as well
+	h.mockAccountService.On("ForgotPassword", "known@example.com")
+	h.mockAccountService.On("ForgotPassword", "unknown@example.com")
+
+	var bodies []string
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		body, _ := json.Marshal(ForgotPasswordRequest{Email: email})
+		req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		bodies = append(bodies, w.Body.String())
+	}
+
+	assert.Equal(t, bodies[0], bodies[1])
+	h.mockAccountService.AssertExpectations(t)
+}
+
+func TestHandler_ForgotPassword_InvalidEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/password/forgot", h.forgotPassword)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewBufferString(`{"email":"not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	h.mockAccountService.AssertNotCalled(t, "ForgotPassword", mock.Anything)
+}
+
+func TestHandler_ResetPassword_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/password/reset", h.resetPassword)
+
+	input := &account.ResetPasswordInput{Token: "reset-token", NewPassword: "new_password123"}
+	h.mockAccountService.On("ResetPassword", input).Return(1, nil)
+	mockAuthSvc.On("ForgetUserTokenState", 1).Return()
+
+	body, _ := json.Marshal(ResetPasswordRequest{Token: "reset-token", NewPassword: "new_password123"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	h.mockAccountService.AssertExpectations(t)
+	mockAuthSvc.AssertExpectations(t)
+}
+
+func TestHandler_ResetPassword_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/password/reset", h.resetPassword)
+
+	h.mockAccountService.On("ResetPassword", mock.Anything).Return(0, domain.InvalidTokenError{})
+
+	body, _ := json.Marshal(ResetPasswordRequest{Token: "used-token", NewPassword: "new_password123"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAuthSvc.AssertNotCalled(t, "ForgetUserTokenState", mock.Anything)
+}
+
+func TestHandler_ResetPassword_ShortPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/password/reset", h.resetPassword)
+
+	body, _ := json.Marshal(ResetPasswordRequest{Token: "reset-token", NewPassword: "short"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	h.mockAccountService.AssertNotCalled(t, "ResetPassword", mock.Anything)
+}
+
+// ========== Email Verification Tests ==========
+
+func TestHandler_VerifyEmail_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, mockAuthSvc := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/verify-email", h.verifyEmail)
+
+	h.mockAccountService.On("VerifyEmail", "verify-token").Return(1, nil)
+	mockAuthSvc.On("ForgetUserTokenState", 1).Return()
+
+	body, _ := json.Marshal(VerifyEmailRequest{Token: "verify-token"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/verify-email", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	h.mockAccountService.AssertExpectations(t)
+	mockAuthSvc.AssertExpectations(t)
+}
+
+func TestHandler_VerifyEmail_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/verify-email", h.verifyEmail)
+
+	h.mockAccountService.On("VerifyEmail", "used-token").Return(0, domain.InvalidTokenError{})
+
+	body, _ := json.Marshal(VerifyEmailRequest{Token: "used-token"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/verify-email", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandler_ResendEmailVerification_SameResponseForAnyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/auth/verify-email/resend", h.resendEmailVerification)
+
+	h.mockAccountService.On("ResendEmailVerification", "known@example.com")
+	h.mockAccountService.On("ResendEmailVerification", "unknown@example.com")
+
+	var bodies []string
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		body, _ := json.Marshal(ResendEmailVerificationRequest{Email: email})
+		req := httptest.NewRequest(http.MethodPost, "/auth/verify-email/resend", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		bodies = append(bodies, w.Body.String())
+	}
+
+	assert.Equal(t, bodies[0], bodies[1])
+	h.mockAccountService.AssertExpectations(t)
+}
+
+func TestHandler_JWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockKeySet := new(MockKeySetProvider)
+	h := NewHandler(nil, nil, nil, nil, nil, mockKeySet, nil)
+
+	router := gin.New()
+	router.GET("/.well-known/jwks.json", h.JWKS)
+
+	mockKeySet.On("JWKS").Return(&pkgAuth.JWKS{Keys: []pkgAuth.JWK{
+		{Kty: "OKP", Use: "sig", Kid: "2024-06", Alg: "EdDSA", Crv: "Ed25519", X: "abc"},
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+	var resp pkgAuth.JWKS
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Keys, 1)
+	assert.Equal(t, "2024-06", resp.Keys[0].Kid)
+	mockKeySet.AssertExpectations(t)
+}
@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // BaseHandler provides common response and error handling methods.
@@ -19,27 +24,43 @@ func (b *BaseHandler) HandleBindingError(c *gin.Context, err error) {
 		for _, e := range validationErrs {
 			messages = append(messages, e.Error())
 		}
-		b.responseError(c, http.StatusBadRequest, "invalid request format", messages)
+		AbortWithProblem(c, http.StatusBadRequest, CodeInvalidRequest, "invalid request format", messages...)
 		return
 	}
 
-	b.responseError(c, http.StatusBadRequest, "invalid request format", err.Error())
+	AbortWithProblem(c, http.StatusBadRequest, CodeInvalidRequest, "invalid request format", err.Error())
 }
 
 // HandleValidationError handles DTO validation errors (req.IsValid() fails).
 func (b *BaseHandler) HandleValidationError(c *gin.Context, message string) {
-	b.responseError(c, http.StatusBadRequest, message)
+	AbortWithProblem(c, http.StatusBadRequest, domain.CodeValidation, message)
 }
 
 // HandleDomainError handles domain errors returned from service layer.
 func (b *BaseHandler) HandleDomainError(c *gin.Context, err error) {
-	if domainErr, ok := err.(domain.DomainError); ok {
-		b.responseError(c, domainErr.HTTPStatus(), domainErr.Error())
+	// Classify the error on the request's server span
+	telemetry.RecordError(trace.SpanFromContext(c.Request.Context()), err)
+
+	// The request deadline passed while waiting on a dependency (e.g. the database)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.FromContext(c.Request.Context()).Warn("request timed out", "error", err)
+		AbortWithProblem(c, http.StatusGatewayTimeout, CodeRequestTimeout, "request timed out")
+		return
+	}
+
+	// Domain errors may come wrapped with context, e.g. by fmt.Errorf("...: %w", err)
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		if domainErr.HTTPStatus() >= http.StatusInternalServerError {
+			logger.FromContext(c.Request.Context()).Error("request failed", "error", err)
+		}
+		AbortWithProblem(c, domainErr.HTTPStatus(), domainErr.Code(), domainErr.Error())
 		return
 	}
 
 	// Default to internal server error for unknown errors
-	b.responseError(c, http.StatusInternalServerError, err.Error())
+	logger.FromContext(c.Request.Context()).Error("request failed", "error", err)
+	AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, err.Error())
 }
 
 // HandleSuccess sends a success response.
@@ -50,12 +71,3 @@ func (b *BaseHandler) HandleSuccess(c *gin.Context, status int, data ...any) {
 		c.Status(status)
 	}
 }
-
-// responseError sends an error response with the given status code and message.
-func (b *BaseHandler) responseError(c *gin.Context, code int, msg string, data ...any) {
-	response := gin.H{"message": msg}
-	if len(data) > 0 {
-		response["data"] = data[0]
-	}
-	c.AbortWithStatusJSON(code, response)
-}
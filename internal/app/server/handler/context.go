@@ -1,11 +1,21 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
 
 // Context key constants
 const (
-	ContextKeyUserId   = "user_id"
-	ContextKeyUserRole = "user_role"
+	ContextKeyUserId         = "user_id"
+	ContextKeyUserRole       = "user_role"
+	ContextKeyPermissions    = "permissions"
+	ContextKeyTokenId        = "token_id"
+	ContextKeyTokenExpiresAt = "token_expires_at"
+	ContextKeyAPIKeyId       = "api_key_id"
+	ContextKeyAPIKeyScopes   = "api_key_scopes"
+	ContextKeyRequestId      = "request_id"
 )
 
 // GetUserId retrieves the user ID from the gin context.
@@ -28,3 +38,64 @@ func SetUserRole(c *gin.Context, role string) {
 	c.Set(ContextKeyUserRole, role)
 }
 
+// GetPermissions retrieves the permissions of the user role from the gin context.
+// They are only set once a permission check has resolved them.
+func GetPermissions(c *gin.Context) []string {
+	return c.GetStringSlice(ContextKeyPermissions)
+}
+
+// SetPermissions sets the permissions of the user role in the gin context.
+func SetPermissions(c *gin.Context, permissions []string) {
+	c.Set(ContextKeyPermissions, permissions)
+}
+
+// GetTokenId retrieves the access token ID (jti) from the gin context.
+func GetTokenId(c *gin.Context) string {
+	return c.GetString(ContextKeyTokenId)
+}
+
+// GetTokenExpiresAt retrieves the access token expiry from the gin context.
+func GetTokenExpiresAt(c *gin.Context) time.Time {
+	return c.GetTime(ContextKeyTokenExpiresAt)
+}
+
+// SetTokenId sets the access token ID (jti) in the gin context.
+func SetTokenId(c *gin.Context, tokenId string) {
+	c.Set(ContextKeyTokenId, tokenId)
+}
+
+// SetTokenExpiresAt sets the access token expiry in the gin context.
+func SetTokenExpiresAt(c *gin.Context, expiresAt time.Time) {
+	c.Set(ContextKeyTokenExpiresAt, expiresAt)
+}
+
+// GetAPIKeyId retrieves the ID of the API key that authenticated the request from the gin context.
+// It returns 0 for requests authenticated with a JWT.
+func GetAPIKeyId(c *gin.Context) int {
+	return c.GetInt(ContextKeyAPIKeyId)
+}
+
+// GetAPIKeyScopes retrieves the scopes of the API key that authenticated the request from the gin context.
+func GetAPIKeyScopes(c *gin.Context) []string {
+	return c.GetStringSlice(ContextKeyAPIKeyScopes)
+}
+
+// SetAPIKeyId sets the ID of the API key that authenticated the request in the gin context.
+func SetAPIKeyId(c *gin.Context, apiKeyId int) {
+	c.Set(ContextKeyAPIKeyId, apiKeyId)
+}
+
+// SetAPIKeyScopes sets the scopes of the API key that authenticated the request in the gin context.
+func SetAPIKeyScopes(c *gin.Context, scopes []string) {
+	c.Set(ContextKeyAPIKeyScopes, scopes)
+}
+
+// GetRequestId retrieves the correlation ID of the request from the gin context.
+func GetRequestId(c *gin.Context) string {
+	return c.GetString(ContextKeyRequestId)
+}
+
+// SetRequestId sets the correlation ID of the request in the gin context.
+func SetRequestId(c *gin.Context, requestId string) {
+	c.Set(ContextKeyRequestId, requestId)
+}
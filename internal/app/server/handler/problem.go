@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentTypeProblem is the media type of RFC 7807 problem details.
+const ContentTypeProblem = "application/problem+json"
+
+// problemTypePrefix turns an error code into the problem type URI.
+const problemTypePrefix = "urn:problem-type:"
+
+// Error codes of problems raised by the HTTP layer rather than by domain errors.
+// Like domain error codes they are part of the API.
+const (
+	CodeInvalidRequest          = "invalid_request"
+	CodeRequestTimeout          = "request_timeout"
+	CodeRateLimited             = "rate_limited"
+	CodeInvalidAccessToken      = "invalid_access_token"
+	CodeTokenRevoked            = "token_revoked"
+	CodeInsufficientPermissions = "insufficient_permissions"
+	CodeInsufficientScope       = "insufficient_scope"
+	CodeSessionRequired         = "session_required"
+)
+
+// Problem is an RFC 7807 problem details response.
+// Code and RequestId are extension members; Errors lists invalid request fields.
+type Problem struct {
+	Type      string   `json:"type"`
+	Title     string   `json:"title"`
+	Status    int      `json:"status"`
+	Detail    string   `json:"detail,omitempty"`
+	Instance  string   `json:"instance,omitempty"`
+	Code      string   `json:"code"`
+	RequestId string   `json:"request_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// AbortWithProblem aborts the request with a problem details response.
+// The detail of server errors may reveal internals (e.g. wrapped SQL errors),
+// so it is left out in release mode.
+func AbortWithProblem(c *gin.Context, status int, code, detail string, errs ...string) {
+	if status >= http.StatusInternalServerError && gin.Mode() == gin.ReleaseMode {
+		detail = ""
+	}
+
+	c.Header("Content-Type", ContentTypeProblem)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestId: GetRequestId(c),
+		Errors:    errs,
+	})
+}
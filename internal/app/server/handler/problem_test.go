@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+)
+
+// ========== Test Helpers ==========
+
+// serveError handles err with HandleDomainError and decodes the problem response.
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+
+	router := gin.New()
+	router.GET("/users/:id", func(c *gin.Context) {
+		SetRequestId(c, "req-1")
+		var base BaseHandler
+		base.HandleDomainError(c, err)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	return w, problem
+}
+
+// ========== Tests ==========
+
+func TestHandleDomainError_Problem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w, problem := serveError(t, domain.UserNotFoundError{Id: 7})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentTypeProblem, w.Header().Get("Content-Type"))
+	assert.Equal(t, Problem{
+		Type:      "urn:problem-type:user_not_found",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "user not found with id: 7",
+		Instance:  "/users/7",
+		Code:      domain.CodeUserNotFound,
+		RequestId: "req-1",
+	}, problem)
+}
+
+func TestHandleDomainError_WrappedError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w, problem := serveError(t, fmt.Errorf("verify email: %w", domain.InvalidTokenError{}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, domain.CodeInvalidToken, problem.Code)
+	assert.Equal(t, domain.InvalidTokenError{}.Error(), problem.Detail)
+}
+
+func TestHandleDomainError_HidesInternalDetailsInReleaseMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		err        error
+		wantDetail string
+	}{
+		{
+			name:       "internal error in debug mode",
+			mode:       gin.DebugMode,
+			err:        domain.InternalServerError{Msg: "failed to get user", Err: errors.New("pq: relation \"users\" does not exist")},
+			wantDetail: "failed to get user: pq: relation \"users\" does not exist",
+		},
+		{
+			name: "internal error in release mode",
+			mode: gin.ReleaseMode,
+			err:  domain.InternalServerError{Msg: "failed to get user", Err: errors.New("pq: relation \"users\" does not exist")},
+		},
+		{
+			name: "unknown error in release mode",
+			mode: gin.ReleaseMode,
+			err:  errors.New("sql: connection is already closed"),
+		},
+		{
+			name:       "client error in release mode",
+			mode:       gin.ReleaseMode,
+			err:        domain.InvalidCredentialsError{},
+			wantDetail: "invalid email or password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(tt.mode)
+			defer gin.SetMode(gin.TestMode)
+
+			_, problem := serveError(t, tt.err)
+
+			assert.Equal(t, tt.wantDetail, problem.Detail)
+			assert.NotEmpty(t, problem.Code)
+		})
+	}
+}
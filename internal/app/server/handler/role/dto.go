@@ -0,0 +1,78 @@
+package role
+
+import (
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Request DTOs ==========
+
+// CreateRoleRequest represents the request body for creating a role.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions"`
+}
+
+func (r *CreateRoleRequest) Validate() error {
+	if !entity.IsValidRoleName(r.Name) {
+		return fmt.Errorf("invalid role name: %s, must be lowercase letters, digits and hyphens, starting with a letter", r.Name)
+	}
+	return nil
+}
+
+// UpdateRoleRequest represents the request body for updating a role.
+// Permissions replaces every permission of the role; omit it to leave them unchanged.
+type UpdateRoleRequest struct {
+	Description *string   `json:"description" binding:"omitempty,max=200"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// ========== Response DTOs ==========
+
+// RoleResponse represents a role in API responses.
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	IsSystem    bool     `json:"is_system"`  // built-in roles cannot be deleted
+	CreatedAt   int64    `json:"created_at"` // Unix timestamp
+	UpdatedAt   int64    `json:"updated_at"` // Unix timestamp
+}
+
+// ToRoleResponse converts an entity.Role to RoleResponse.
+func ToRoleResponse(role *entity.Role) *RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return &RoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		IsSystem:    role.IsSystem,
+		CreatedAt:   role.CreatedAt.Unix(),
+		UpdatedAt:   role.UpdatedAt.Unix(),
+	}
+}
+
+// ToRoleResponseList converts a list of entity.Role to RoleResponse list.
+func ToRoleResponseList(roles []*entity.Role) []*RoleResponse {
+	result := make([]*RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, ToRoleResponse(role))
+	}
+	return result
+}
+
+// PermissionsResponse represents the permissions that roles can grant.
+type PermissionsResponse struct {
+	Permissions []string `json:"permissions"`
+}
+
+// MessageResponse represents a simple message response.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
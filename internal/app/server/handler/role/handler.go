@@ -0,0 +1,108 @@
+package role
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/app/server/service/role"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// Handler handles role-related HTTP requests.
+type Handler struct {
+	handler.BaseHandler
+	roleService *role.Service
+}
+
+// NewHandler creates a new role handler.
+func NewHandler(roleService *role.Service) *Handler {
+	return &Handler{
+		BaseHandler: handler.BaseHandler{},
+		roleService: roleService,
+	}
+}
+
+// GetRoles handles GET /roles
+func (h *Handler) GetRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, ToRoleResponseList(roles))
+}
+
+// GetRole handles GET /roles/:name
+func (h *Handler) GetRole(c *gin.Context) {
+	gotRole, err := h.roleService.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, ToRoleResponse(gotRole))
+}
+
+// CreateRole handles POST /roles
+func (h *Handler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.HandleValidationError(c, err.Error())
+		return
+	}
+
+	created, err := h.roleService.CreateRole(c.Request.Context(), &role.CreateRoleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusCreated, ToRoleResponse(created))
+}
+
+// UpdateRole handles PATCH /roles/:name
+func (h *Handler) UpdateRole(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	updated, err := h.roleService.UpdateRole(c.Request.Context(), &role.UpdateRoleInput{
+		Name:        c.Param("name"),
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, ToRoleResponse(updated))
+}
+
+// DeleteRole handles DELETE /roles/:name
+func (h *Handler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "role deleted successfully"})
+}
+
+// GetPermissions handles GET /permissions
+func (h *Handler) GetPermissions(c *gin.Context) {
+	h.HandleSuccess(c, http.StatusOK, &PermissionsResponse{Permissions: entity.AllPermissions()})
+}
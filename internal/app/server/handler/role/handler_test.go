@@ -0,0 +1,96 @@
+package role
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== DTO Tests ==========
+
+func TestCreateRoleRequest_Validate(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"support":     true,
+		"team-lead-2": true,
+		"Support":     false,
+		"2nd-line":    false,
+		"super admin": false,
+	} {
+		req := &CreateRoleRequest{Name: name}
+		assert.Equal(t, valid, req.Validate() == nil, name)
+	}
+}
+
+func TestToRoleResponse(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour)
+	updatedAt := time.Now()
+
+	resp := ToRoleResponse(&entity.Role{
+		Name:        entity.RoleAdmin,
+		Description: "Manages users and roles",
+		Permissions: entity.AllPermissions(),
+		IsSystem:    true,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+	})
+	assert.Equal(t, entity.RoleAdmin, resp.Name)
+	assert.Equal(t, "Manages users and roles", resp.Description)
+	assert.Equal(t, entity.AllPermissions(), resp.Permissions)
+	assert.True(t, resp.IsSystem)
+	assert.Equal(t, createdAt.Unix(), resp.CreatedAt)
+	assert.Equal(t, updatedAt.Unix(), resp.UpdatedAt)
+
+	body, err := json.Marshal(ToRoleResponse(&entity.Role{Name: "support"}))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"permissions":[]`, "a role without permissions lists none rather than null")
+}
+
+func TestHandler_UpdateRole_Binding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(nil)
+
+	var bound UpdateRoleRequest
+	router := gin.New()
+	router.PATCH("/roles/:name", func(c *gin.Context) {
+		bound = UpdateRoleRequest{}
+		if err := c.ShouldBindJSON(&bound); err != nil {
+			h.HandleBindingError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name            string
+		body            string
+		expectedCode    int
+		wantPermissions *[]string
+	}{
+		{"permissions omitted", `{"description":"Support staff"}`, http.StatusOK, nil},
+		{"permissions cleared", `{"permissions":[]}`, http.StatusOK, &[]string{}},
+		{"permissions set", `{"permissions":["users:read"]}`, http.StatusOK, &[]string{"users:read"}},
+		{"description too long", `{"description":"` + string(bytes.Repeat([]byte("a"), 201)) + `"}`, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/roles/support", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, tt.wantPermissions, bound.Permissions)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
 )
 
@@ -20,8 +22,8 @@ type CreateUserRequest struct {
 }
 
 func (r *CreateUserRequest) Validate() error {
-	if !entity.IsValidRole(r.Role) {
-		return fmt.Errorf("invalid role: %s, must be one of: admin, user, viewer", r.Role)
+	if !entity.IsValidRoleName(r.Role) {
+		return fmt.Errorf("invalid role: %s", r.Role)
 	}
 	return nil
 }
@@ -36,8 +38,21 @@ type UpdateUserRequest struct {
 }
 
 func (r *UpdateUserRequest) Validate() error {
-	if r.Role != nil && !entity.IsValidRole(*r.Role) {
-		return fmt.Errorf("invalid role: %s, must be one of: admin, user, viewer", *r.Role)
+	if r.Role != nil && !entity.IsValidRoleName(*r.Role) {
+		return fmt.Errorf("invalid role: %s", *r.Role)
+	}
+	return nil
+}
+
+// InviteUserRequest represents the request body for inviting a user to self-register.
+type InviteUserRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"` // user or viewer; defaults to the configured registration role
+}
+
+func (r *InviteUserRequest) Validate() error {
+	if r.Role != "" && r.Role != entity.RoleUser && r.Role != entity.RoleViewer {
+		return fmt.Errorf("invalid role: %s, must be one of: user, viewer", r.Role)
 	}
 	return nil
 }
@@ -55,10 +70,34 @@ func (r *ChangePasswordRequest) Validate() error {
 	return nil
 }
 
-// LoginRequest represents the request body for user login.
-type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+// ConfirmMFAEnrollmentRequest represents the request body for confirming a TOTP enrollment.
+type ConfirmMFAEnrollmentRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFACodeRequest represents a request body proving possession of the second factor,
+// with either a TOTP code or a recovery code.
+type MFACodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func (r *MFACodeRequest) Validate() error {
+	return ValidateMFACode(r.Code, r.RecoveryCode)
+}
+
+// FinishPasskeyRegistrationRequest represents the request body for completing a passkey registration.
+// Credential is the PublicKeyCredential returned by navigator.credentials.create, in its JSON form.
+type FinishPasskeyRegistrationRequest struct {
+	Name       string                               `json:"name" binding:"max=100"`
+	Credential *protocol.CredentialCreationResponse `json:"credential" binding:"required"`
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key.
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Scopes    []string `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *int64   `json:"expires_at"` // Unix timestamp; omit for a key that never expires
 }
 
 // GetUsersQuery represents query parameters for listing users.
@@ -93,27 +132,39 @@ func (q *GetUsersQuery) GetOnlyActive() bool {
 
 // UserResponse represents a user in API responses.
 type UserResponse struct {
-	Id        int    `json:"id"`
-	Email     string `json:"email"`
-	Username  string `json:"username"`
-	Name      string `json:"name"`
-	Role      string `json:"role"`
-	IsActive  bool   `json:"is_active"`
-	CreatedAt int64  `json:"created_at"` // Unix timestamp
-	UpdatedAt int64  `json:"updated_at"` // Unix timestamp
+	Id            int     `json:"id"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"email_verified"`
+	PendingEmail  *string `json:"pending_email,omitempty"` // requested new email, awaiting verification
+	Username      string  `json:"username"`
+	Name          string  `json:"name"`
+	Role          string  `json:"role"`
+	IsActive      bool    `json:"is_active"`
+	LockedUntil   *int64  `json:"locked_until,omitempty"` // Unix timestamp; set after too many failed logins
+	CreatedAt     int64   `json:"created_at"`             // Unix timestamp
+	UpdatedAt     int64   `json:"updated_at"`             // Unix timestamp
 }
 
 // ToUserResponse converts an entity.User to UserResponse.
 func ToUserResponse(user *entity.User) *UserResponse {
+	var lockedUntil *int64
+	if user.IsLocked(time.Now()) {
+		unix := user.LockedUntil.Unix()
+		lockedUntil = &unix
+	}
+
 	return &UserResponse{
-		Id:        user.Id,
-		Email:     user.Email,
-		Username:  user.Username,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt.Unix(),
-		UpdatedAt: user.UpdatedAt.Unix(),
+		Id:            user.Id,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		PendingEmail:  user.PendingEmail,
+		Username:      user.Username,
+		Name:          user.Name,
+		Role:          user.Role,
+		IsActive:      user.IsActive,
+		LockedUntil:   lockedUntil,
+		CreatedAt:     user.CreatedAt.Unix(),
+		UpdatedAt:     user.UpdatedAt.Unix(),
 	}
 }
 
@@ -138,17 +189,113 @@ type GetUsersResponse struct {
 	Data       []*UserResponse `json:"data"`
 }
 
-// LoginResponse represents the response for user login.
-type LoginResponse struct {
-	Token string        `json:"token"`
-	User  *UserResponse `json:"user"`
-}
-
 // MessageResponse represents a simple message response.
 type MessageResponse struct {
 	Message string `json:"message"`
 }
 
+// MFAStatusResponse represents the two-factor authentication status of a user.
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollmentResponse represents a started TOTP enrollment.
+// The secret is shown once, for authenticator apps that cannot scan the URI.
+type MFAEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse represents newly generated recovery codes, shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// PasskeyResponse represents a registered passkey.
+type PasskeyResponse struct {
+	Id         int    `json:"id"`
+	Name       string `json:"name"`
+	CreatedAt  int64  `json:"created_at"`             // Unix timestamp
+	LastUsedAt *int64 `json:"last_used_at,omitempty"` // Unix timestamp; unset until the first login
+}
+
+// ToPasskeyResponse converts an entity.Passkey to PasskeyResponse.
+func ToPasskeyResponse(passkey *entity.Passkey) *PasskeyResponse {
+	var lastUsedAt *int64
+	if passkey.LastUsedAt != nil {
+		unix := passkey.LastUsedAt.Unix()
+		lastUsedAt = &unix
+	}
+
+	return &PasskeyResponse{
+		Id:         passkey.Id,
+		Name:       passkey.Name,
+		CreatedAt:  passkey.CreatedAt.Unix(),
+		LastUsedAt: lastUsedAt,
+	}
+}
+
+// ToPasskeyResponseList converts a list of entity.Passkey to PasskeyResponse list.
+func ToPasskeyResponseList(passkeys []*entity.Passkey) []*PasskeyResponse {
+	result := make([]*PasskeyResponse, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		result = append(result, ToPasskeyResponse(passkey))
+	}
+	return result
+}
+
+// APIKeyResponse represents an API key, without the key itself.
+type APIKeyResponse struct {
+	Id         int      `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"` // identifies the key in lists and logs
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *int64   `json:"expires_at,omitempty"`   // Unix timestamp; unset for keys that never expire
+	LastUsedAt *int64   `json:"last_used_at,omitempty"` // Unix timestamp; unset until the first use
+	RevokedAt  *int64   `json:"revoked_at,omitempty"`   // Unix timestamp
+	CreatedAt  int64    `json:"created_at"`             // Unix timestamp
+}
+
+// CreateAPIKeyResponse represents a newly created API key.
+// The key is shown only once: only its hash is stored.
+type CreateAPIKeyResponse struct {
+	*APIKeyResponse
+	Key string `json:"key"`
+}
+
+// ToAPIKeyResponse converts an entity.APIKey to APIKeyResponse.
+func ToAPIKeyResponse(key *entity.APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		Id:         key.Id,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  unixOrNil(key.ExpiresAt),
+		LastUsedAt: unixOrNil(key.LastUsedAt),
+		RevokedAt:  unixOrNil(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.Unix(),
+	}
+}
+
+// ToAPIKeyResponseList converts a list of entity.APIKey to APIKeyResponse list.
+func ToAPIKeyResponseList(keys []*entity.APIKey) []*APIKeyResponse {
+	result := make([]*APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, ToAPIKeyResponse(key))
+	}
+	return result
+}
+
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	unix := t.Unix()
+	return &unix
+}
+
 // ========== Validation Helpers ==========
 
 // ValidateEmail performs additional email validation if needed.
@@ -163,3 +310,13 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
+// ValidateMFACode checks that exactly one of a TOTP code and a recovery code is given.
+func ValidateMFACode(code, recoveryCode string) error {
+	if code == "" && recoveryCode == "" {
+		return errors.New("code or recovery_code is required")
+	}
+	if code != "" && recoveryCode != "" {
+		return errors.New("only one of code and recovery_code may be given")
+	}
+	return nil
+}
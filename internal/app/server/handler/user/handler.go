@@ -1,32 +1,50 @@
 package user
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/app/server/service/account"
+	"github.com/your-org/go-backend-template/internal/app/server/service/apikey"
+	"github.com/your-org/go-backend-template/internal/app/server/service/auth"
+	"github.com/your-org/go-backend-template/internal/app/server/service/mfa"
+	"github.com/your-org/go-backend-template/internal/app/server/service/passkey"
 	"github.com/your-org/go-backend-template/internal/app/server/service/user"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
 )
 
 // Handler handles user-related HTTP requests.
 type Handler struct {
 	handler.BaseHandler
-	userService *user.Service
-	jwtService  IJWTService
-}
-
-// IJWTService defines the interface for JWT operations.
-type IJWTService interface {
-	GenerateToken(userId int, role string) (string, error)
+	userService    *user.Service
+	authService    *auth.Service
+	accountService *account.Service
+	mfaService     *mfa.Service
+	passkeyService *passkey.Service
+	apiKeyService  *apikey.Service
 }
 
 // NewHandler creates a new user handler.
-func NewHandler(userService *user.Service, jwtService IJWTService) *Handler {
+func NewHandler(
+	userService *user.Service,
+	authService *auth.Service,
+	accountService *account.Service,
+	mfaService *mfa.Service,
+	passkeyService *passkey.Service,
+	apiKeyService *apikey.Service,
+) *Handler {
 	return &Handler{
-		BaseHandler: handler.BaseHandler{},
-		userService: userService,
-		jwtService:  jwtService,
+		BaseHandler:    handler.BaseHandler{},
+		userService:    userService,
+		authService:    authService,
+		accountService: accountService,
+		mfaService:     mfaService,
+		passkeyService: passkeyService,
+		apiKeyService:  apiKeyService,
 	}
 }
 
@@ -49,17 +67,46 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		Password: req.Password,
 		Name:     req.Name,
 		Role:     req.Role,
+		Actor:    actor(c),
 	}
 
-	userId, err := h.userService.CreateUser(input)
+	userId, err := h.userService.CreateUser(c.Request.Context(), input)
 	if err != nil {
 		h.HandleDomainError(c, err)
 		return
 	}
 
+	h.sendEmailVerification(c.Request.Context(), userId)
+
 	h.HandleSuccess(c, http.StatusCreated, &CreateUserResponse{Id: userId})
 }
 
+// InviteUser handles POST /users/invitations
+func (h *Handler) InviteUser(c *gin.Context) {
+	var req InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.HandleValidationError(c, err.Error())
+		return
+	}
+
+	input := &account.InviteUserInput{
+		Email: req.Email,
+		Role:  req.Role,
+	}
+
+	if err := h.accountService.InviteUser(c.Request.Context(), input); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusAccepted, &MessageResponse{Message: "invitation sent"})
+}
+
 // GetUser handles GET /users/:id
 func (h *Handler) GetUser(c *gin.Context) {
 	userId, err := strconv.Atoi(c.Param("id"))
@@ -68,7 +115,7 @@ func (h *Handler) GetUser(c *gin.Context) {
 		return
 	}
 
-	gotUser, err := h.userService.GetUserById(userId)
+	gotUser, err := h.userService.GetUserById(c.Request.Context(), userId)
 	if err != nil {
 		h.HandleDomainError(c, err)
 		return
@@ -91,7 +138,7 @@ func (h *Handler) GetUsers(c *gin.Context) {
 		OnlyActive: query.GetOnlyActive(),
 	}
 
-	result, err := h.userService.GetUsers(input)
+	result, err := h.userService.GetUsers(c.Request.Context(), input)
 	if err != nil {
 		h.HandleDomainError(c, err)
 		return
@@ -132,12 +179,19 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		Name:     req.Name,
 		Role:     req.Role,
 		IsActive: req.IsActive,
+		Actor:    actor(c),
 	}
 
-	if err := h.userService.UpdateUser(input); err != nil {
+	if err := h.userService.UpdateUser(c.Request.Context(), input); err != nil {
 		h.HandleDomainError(c, err)
 		return
 	}
+	h.authService.ForgetUserTokenState(userId)
+
+	// A changed email is pending until the new address is verified
+	if req.Email != nil {
+		h.sendEmailVerification(c.Request.Context(), userId)
+	}
 
 	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "user updated successfully"})
 }
@@ -150,14 +204,31 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(userId); err != nil {
+	if err := h.userService.DeleteUser(c.Request.Context(), userId, actor(c)); err != nil {
 		h.HandleDomainError(c, err)
 		return
 	}
+	h.authService.ForgetUserTokenState(userId)
 
 	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "user deleted successfully"})
 }
 
+// UnlockUser handles POST /users/:id/unlock
+func (h *Handler) UnlockUser(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.HandleValidationError(c, "invalid user id")
+		return
+	}
+
+	if err := h.userService.UnlockUser(c.Request.Context(), userId, actor(c)); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "user unlocked successfully"})
+}
+
 // ChangePassword handles POST /users/:id/change-password
 func (h *Handler) ChangePassword(c *gin.Context) {
 	userId, err := strconv.Atoi(c.Param("id"))
@@ -183,10 +254,12 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		NewPassword:     req.NewPassword,
 	}
 
-	if err := h.userService.ChangePassword(input); err != nil {
+	// Revokes every session of the user, including the current one
+	if err := h.userService.ChangePassword(c.Request.Context(), input); err != nil {
 		h.HandleDomainError(c, err)
 		return
 	}
+	h.authService.ForgetUserTokenState(userId)
 
 	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "password changed successfully"})
 }
@@ -195,7 +268,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 func (h *Handler) GetMe(c *gin.Context) {
 	userId := handler.GetUserId(c)
 
-	gotUser, err := h.userService.GetUserById(userId)
+	gotUser, err := h.userService.GetUserById(c.Request.Context(), userId)
 	if err != nil {
 		h.HandleDomainError(c, err)
 		return
@@ -204,37 +277,261 @@ func (h *Handler) GetMe(c *gin.Context) {
 	h.HandleSuccess(c, http.StatusOK, ToUserResponse(gotUser))
 }
 
-// Login handles POST /auth/login
-func (h *Handler) Login(c *gin.Context) {
-	var req LoginRequest
+// GetMyMFA handles GET /me/mfa
+func (h *Handler) GetMyMFA(c *gin.Context) {
+	status, err := h.mfaService.GetStatus(c.Request.Context(), handler.GetUserId(c))
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MFAStatusResponse{
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+// BeginMFAEnrollment handles POST /me/mfa/totp
+func (h *Handler) BeginMFAEnrollment(c *gin.Context) {
+	enrollment, err := h.mfaService.BeginEnrollment(c.Request.Context(), handler.GetUserId(c))
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MFAEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// ConfirmMFAEnrollment handles POST /me/mfa/totp/confirm
+func (h *Handler) ConfirmMFAEnrollment(c *gin.Context) {
+	var req ConfirmMFAEnrollmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.HandleBindingError(c, err)
 		return
 	}
 
-	input := &user.LoginInput{
-		Email:    req.Email,
-		Password: req.Password,
+	input := &mfa.ConfirmEnrollmentInput{
+		UserId: handler.GetUserId(c),
+		Code:   req.Code,
 	}
 
-	loggedInUser, err := h.userService.Login(input)
+	recoveryCodes, err := h.mfaService.ConfirmEnrollment(c.Request.Context(), input)
 	if err != nil {
 		h.HandleDomainError(c, err)
 		return
 	}
 
-	// Generate JWT token
-	token, err := h.jwtService.GenerateToken(loggedInUser.Id, loggedInUser.Role)
+	h.HandleSuccess(c, http.StatusOK, &RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableMFA handles POST /me/mfa/disable
+func (h *Handler) DisableMFA(c *gin.Context) {
+	input, ok := h.bindMFACode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), input); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles POST /me/mfa/recovery-codes
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	input, ok := h.bindMFACode(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), input)
 	if err != nil {
 		h.HandleDomainError(c, err)
 		return
 	}
 
-	resp := &LoginResponse{
-		Token: token,
-		User:  ToUserResponse(loggedInUser),
+	h.HandleSuccess(c, http.StatusOK, &RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// ResetMFA handles DELETE /users/:id/mfa
+func (h *Handler) ResetMFA(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.HandleValidationError(c, "invalid user id")
+		return
 	}
 
-	h.HandleSuccess(c, http.StatusOK, resp)
+	// The second factor is the MFA service's, but whose it may reset is up to the user service
+	if err := h.userService.AuthorizeUserChange(c.Request.Context(), userId, actor(c)); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+	if err := h.mfaService.Reset(c.Request.Context(), userId); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "two-factor authentication reset"})
+}
+
+// GetMyPasskeys handles GET /me/passkeys
+func (h *Handler) GetMyPasskeys(c *gin.Context) {
+	passkeys, err := h.passkeyService.ListPasskeys(c.Request.Context(), handler.GetUserId(c))
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, ToPasskeyResponseList(passkeys))
+}
+
+// BeginPasskeyRegistration handles POST /me/passkeys/register/begin
+// The response holds the options for navigator.credentials.create.
+func (h *Handler) BeginPasskeyRegistration(c *gin.Context) {
+	options, err := h.passkeyService.BeginRegistration(c.Request.Context(), handler.GetUserId(c))
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, options)
+}
+
+// FinishPasskeyRegistration handles POST /me/passkeys/register/finish
+func (h *Handler) FinishPasskeyRegistration(c *gin.Context) {
+	var req FinishPasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &passkey.FinishRegistrationInput{
+		UserId:   handler.GetUserId(c),
+		Name:     req.Name,
+		Response: req.Credential,
+	}
+
+	registered, err := h.passkeyService.FinishRegistration(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusCreated, ToPasskeyResponse(registered))
+}
+
+// DeleteMyPasskey handles DELETE /me/passkeys/:id
+func (h *Handler) DeleteMyPasskey(c *gin.Context) {
+	passkeyId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.HandleValidationError(c, "invalid passkey id")
+		return
+	}
+
+	if err := h.passkeyService.DeletePasskey(c.Request.Context(), handler.GetUserId(c), passkeyId); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "passkey deleted"})
+}
+
+// GetMyAPIKeys handles GET /me/api-keys
+func (h *Handler) GetMyAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), handler.GetUserId(c))
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, ToAPIKeyResponseList(keys))
 }
 
+// CreateMyAPIKey handles POST /me/api-keys
+// The response holds the key itself, which is shown only once.
+func (h *Handler) CreateMyAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return
+	}
+
+	input := &apikey.CreateAPIKeyInput{
+		UserId: handler.GetUserId(c),
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := time.Unix(*req.ExpiresAt, 0)
+		input.ExpiresAt = &expiresAt
+	}
+
+	key, created, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), input)
+	if err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusCreated, &CreateAPIKeyResponse{
+		APIKeyResponse: ToAPIKeyResponse(created),
+		Key:            key,
+	})
+}
+
+// RevokeMyAPIKey handles DELETE /me/api-keys/:id
+func (h *Handler) RevokeMyAPIKey(c *gin.Context) {
+	apiKeyId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.HandleValidationError(c, "invalid api key id")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), handler.GetUserId(c), apiKeyId); err != nil {
+		h.HandleDomainError(c, err)
+		return
+	}
+
+	h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "api key revoked"})
+}
+
+// bindMFACode binds and validates an MFACodeRequest for the signed-in user.
+// It writes the error response and returns false if the request is invalid.
+func (h *Handler) bindMFACode(c *gin.Context) (*mfa.VerifyInput, bool) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.HandleBindingError(c, err)
+		return nil, false
+	}
+
+	if err := req.Validate(); err != nil {
+		h.HandleValidationError(c, err.Error())
+		return nil, false
+	}
+
+	return &mfa.VerifyInput{
+		UserId:       handler.GetUserId(c),
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+	}, true
+}
+
+// sendEmailVerification sends a verification email after the user was saved.
+// Failures do not fail the request: the change is already committed, and the user
+// can ask for a new email through POST /auth/verify-email/resend.
+func (h *Handler) sendEmailVerification(ctx context.Context, userId int) {
+	if err := h.accountService.SendEmailVerification(ctx, userId); err != nil {
+		logger.FromContext(ctx).Error("failed to send verification email", "user_id", userId, "error", err)
+	}
+}
+
+// actor returns the signed-in user for authorizing role assignments and changes to other users.
+// The permissions are resolved by the RequirePermission middleware of the route.
+func actor(c *gin.Context) *user.Actor {
+	return &user.Actor{Permissions: handler.GetPermissions(c)}
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -23,12 +24,12 @@ type MockUserService struct {
 	mock.Mock
 }
 
-func (m *MockUserService) CreateUser(input *user.CreateUserInput) (int, error) {
+func (m *MockUserService) CreateUser(ctx context.Context, input *user.CreateUserInput) (int, error) {
 	args := m.Called(input)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockUserService) GetUserById(id int) (*entity.User, error) {
+func (m *MockUserService) GetUserById(ctx context.Context, id int) (*entity.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,7 +37,7 @@ func (m *MockUserService) GetUserById(id int) (*entity.User, error) {
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
-func (m *MockUserService) GetUserByEmail(email string) (*entity.User, error) {
+func (m *MockUserService) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,7 +45,7 @@ func (m *MockUserService) GetUserByEmail(email string) (*entity.User, error) {
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
-func (m *MockUserService) GetUsers(input *user.GetUsersInput) (*user.GetUsersResult, error) {
+func (m *MockUserService) GetUsers(ctx context.Context, input *user.GetUsersInput) (*user.GetUsersResult, error) {
 	args := m.Called(input)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -52,22 +53,27 @@ func (m *MockUserService) GetUsers(input *user.GetUsersInput) (*user.GetUsersRes
 	return args.Get(0).(*user.GetUsersResult), args.Error(1)
 }
 
-func (m *MockUserService) UpdateUser(input *user.UpdateUserInput) error {
+func (m *MockUserService) UpdateUser(ctx context.Context, input *user.UpdateUserInput) error {
 	args := m.Called(input)
 	return args.Error(0)
 }
 
-func (m *MockUserService) DeleteUser(id int) error {
+func (m *MockUserService) DeleteUser(ctx context.Context, id int, actor *user.Actor) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockUserService) ChangePassword(input *user.ChangePasswordInput) error {
+func (m *MockUserService) UnlockUser(ctx context.Context, id int, actor *user.Actor) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ChangePassword(ctx context.Context, input *user.ChangePasswordInput) error {
 	args := m.Called(input)
 	return args.Error(0)
 }
 
-func (m *MockUserService) Login(input *user.LoginInput) (*entity.User, error) {
+func (m *MockUserService) Login(ctx context.Context, input *user.LoginInput) (*entity.User, error) {
 	args := m.Called(input)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -75,17 +81,6 @@ func (m *MockUserService) Login(input *user.LoginInput) (*entity.User, error) {
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
-// ========== Mock JWT Service ==========
-
-type MockJWTService struct {
-	mock.Mock
-}
-
-func (m *MockJWTService) GenerateToken(userId int, role string) (string, error) {
-	args := m.Called(userId, role)
-	return args.String(0), args.Error(1)
-}
-
 // ========== Test Helpers ==========
 
 func setupTestRouter() *gin.Engine {
@@ -93,11 +88,10 @@ func setupTestRouter() *gin.Engine {
 	return gin.New()
 }
 
-func setupTestHandler(mockSvc *MockUserService, mockJWT *MockJWTService) *Handler {
+func setupTestHandler(mockSvc *MockUserService) *Handler {
 	return &Handler{
 		BaseHandler: handler.BaseHandler{},
 		userService: &user.Service{}, // We'll use mock directly in tests
-		jwtService:  mockJWT,
 	}
 }
 
@@ -105,24 +99,21 @@ func setupTestHandler(mockSvc *MockUserService, mockJWT *MockJWTService) *Handle
 type testHandler struct {
 	handler.BaseHandler
 	mockService *MockUserService
-	mockJWT     *MockJWTService
 }
 
-func newTestHandler() (*testHandler, *MockUserService, *MockJWTService) {
+func newTestHandler() (*testHandler, *MockUserService) {
 	mockSvc := new(MockUserService)
-	mockJWT := new(MockJWTService)
 	return &testHandler{
 		BaseHandler: handler.BaseHandler{},
 		mockService: mockSvc,
-		mockJWT:     mockJWT,
-	}, mockSvc, mockJWT
+	}, mockSvc
 }
 
 // ========== CreateUser Tests ==========
 
 func TestHandler_CreateUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
 	router.POST("/users", func(c *gin.Context) {
@@ -145,7 +136,7 @@ func TestHandler_CreateUser_Success(t *testing.T) {
 			Role:     req.Role,
 		}
 
-		userId, err := mockSvc.CreateUser(input)
+		userId, err := mockSvc.CreateUser(c.Request.Context(), input)
 		if err != nil {
 			h.HandleDomainError(c, err)
 			return
@@ -182,7 +173,7 @@ func TestHandler_CreateUser_Success(t *testing.T) {
 
 func TestHandler_CreateUser_InvalidJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, _, _ := newTestHandler()
+	h, _ := newTestHandler()
 
 	router := gin.New()
 	router.POST("/users", func(c *gin.Context) {
@@ -204,7 +195,7 @@ func TestHandler_CreateUser_InvalidJSON(t *testing.T) {
 
 func TestHandler_CreateUser_InvalidRole(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, _, _ := newTestHandler()
+	h, _ := newTestHandler()
 
 	router := gin.New()
 	router.POST("/users", func(c *gin.Context) {
@@ -240,7 +231,7 @@ func TestHandler_CreateUser_InvalidRole(t *testing.T) {
 
 func TestHandler_CreateUser_EmailAlreadyExists(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
 	router.POST("/users", func(c *gin.Context) {
@@ -258,7 +249,7 @@ func TestHandler_CreateUser_EmailAlreadyExists(t *testing.T) {
 			Role:     req.Role,
 		}
 
-		userId, err := mockSvc.CreateUser(input)
+		userId, err := mockSvc.CreateUser(c.Request.Context(), input)
 		if err != nil {
 			h.HandleDomainError(c, err)
 			return
@@ -293,13 +284,13 @@ func TestHandler_CreateUser_EmailAlreadyExists(t *testing.T) {
 
 func TestHandler_GetUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
 	router.GET("/users/:id", func(c *gin.Context) {
 		userId := 1 // simplified for test
 
-		gotUser, err := mockSvc.GetUserById(userId)
+		gotUser, err := mockSvc.GetUserById(c.Request.Context(), userId)
 		if err != nil {
 			h.HandleDomainError(c, err)
 			return
@@ -339,13 +330,13 @@ func TestHandler_GetUser_Success(t *testing.T) {
 
 func TestHandler_GetUser_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
 	router.GET("/users/:id", func(c *gin.Context) {
 		userId := 999
 
-		gotUser, err := mockSvc.GetUserById(userId)
+		gotUser, err := mockSvc.GetUserById(c.Request.Context(), userId)
 		if err != nil {
 			h.HandleDomainError(c, err)
 			return
@@ -369,7 +360,7 @@ func TestHandler_GetUser_NotFound(t *testing.T) {
 
 func TestHandler_GetUsers_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
 	router.GET("/users", func(c *gin.Context) {
@@ -379,7 +370,7 @@ func TestHandler_GetUsers_Success(t *testing.T) {
 			OnlyActive: false,
 		}
 
-		result, err := mockSvc.GetUsers(input)
+		result, err := mockSvc.GetUsers(c.Request.Context(), input)
 		if err != nil {
 			h.HandleDomainError(c, err)
 			return
@@ -421,157 +412,66 @@ func TestHandler_GetUsers_Success(t *testing.T) {
 	mockSvc.AssertExpectations(t)
 }
 
-// ========== Login Tests ==========
+// ========== DeleteUser Tests ==========
 
-func TestHandler_Login_Success(t *testing.T) {
+func TestHandler_DeleteUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, mockJWT := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
-	router.POST("/auth/login", func(c *gin.Context) {
-		var req LoginRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			h.HandleBindingError(c, err)
-			return
-		}
-
-		input := &user.LoginInput{
-			Email:    req.Email,
-			Password: req.Password,
-		}
-
-		loggedInUser, err := mockSvc.Login(input)
-		if err != nil {
-			h.HandleDomainError(c, err)
-			return
-		}
+	router.DELETE("/users/:id", func(c *gin.Context) {
+		userId := 1
 
-		token, err := mockJWT.GenerateToken(loggedInUser.Id, loggedInUser.Role)
-		if err != nil {
+		if err := mockSvc.DeleteUser(c.Request.Context(), userId, nil); err != nil {
 			h.HandleDomainError(c, err)
 			return
 		}
 
-		resp := &LoginResponse{
-			Token: token,
-			User:  ToUserResponse(loggedInUser),
-		}
-
-		h.HandleSuccess(c, http.StatusOK, resp)
+		h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "user deleted successfully"})
 	})
 
-	now := time.Now()
-	mockUser := &entity.User{
-		Id:        1,
-		Email:     "test@example.com",
-		Username:  "testuser",
-		Name:      "Test User",
-		Role:      "user",
-		IsActive:  true,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-
-	mockSvc.On("Login", mock.AnythingOfType("*user.LoginInput")).Return(mockUser, nil)
-	mockJWT.On("GenerateToken", 1, "user").Return("mock_jwt_token", nil)
-
-	reqBody := LoginRequest{
-		Email:    "test@example.com",
-		Password: "password123",
-	}
-	jsonBody, _ := json.Marshal(reqBody)
+	mockSvc.On("DeleteUser", 1).Return(nil)
 
-	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
-	req.Header.Set("Content-Type", "application/json")
+	req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp LoginResponse
+	var resp MessageResponse
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "mock_jwt_token", resp.Token)
-	assert.Equal(t, 1, resp.User.Id)
-	mockSvc.AssertExpectations(t)
-	mockJWT.AssertExpectations(t)
-}
-
-func TestHandler_Login_InvalidCredentials(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
-
-	router := gin.New()
-	router.POST("/auth/login", func(c *gin.Context) {
-		var req LoginRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			h.HandleBindingError(c, err)
-			return
-		}
-
-		input := &user.LoginInput{
-			Email:    req.Email,
-			Password: req.Password,
-		}
-
-		_, err := mockSvc.Login(input)
-		if err != nil {
-			h.HandleDomainError(c, err)
-			return
-		}
-	})
-
-	mockSvc.On("Login", mock.AnythingOfType("*user.LoginInput")).
-		Return(nil, domain.InvalidCredentialsError{})
-
-	reqBody := LoginRequest{
-		Email:    "test@example.com",
-		Password: "wrong_password",
-	}
-	jsonBody, _ := json.Marshal(reqBody)
-
-	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	router.ServeHTTP(w, req)
-
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "user deleted successfully", resp.Message)
 	mockSvc.AssertExpectations(t)
 }
 
-// ========== DeleteUser Tests ==========
+// ========== UnlockUser Tests ==========
 
-func TestHandler_DeleteUser_Success(t *testing.T) {
+func TestHandler_UnlockUser_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	h, mockSvc, _ := newTestHandler()
+	h, mockSvc := newTestHandler()
 
 	router := gin.New()
-	router.DELETE("/users/:id", func(c *gin.Context) {
-		userId := 1
+	router.POST("/users/:id/unlock", func(c *gin.Context) {
+		userId := 999
 
-		if err := mockSvc.DeleteUser(userId); err != nil {
+		if err := mockSvc.UnlockUser(c.Request.Context(), userId, nil); err != nil {
 			h.HandleDomainError(c, err)
 			return
 		}
 
-		h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "user deleted successfully"})
+		h.HandleSuccess(c, http.StatusOK, &MessageResponse{Message: "user unlocked successfully"})
 	})
 
-	mockSvc.On("DeleteUser", 1).Return(nil)
+	mockSvc.On("UnlockUser", 999).Return(domain.UserNotFoundError{Id: 999})
 
-	req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	req := httptest.NewRequest(http.MethodPost, "/users/999/unlock", nil)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
-
-	var resp MessageResponse
-	err := json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.NoError(t, err)
-	assert.Equal(t, "user deleted successfully", resp.Message)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 	mockSvc.AssertExpectations(t)
 }
 
@@ -596,7 +496,7 @@ func TestCreateUserRequest_Validate_InvalidRole(t *testing.T) {
 		Username: "testuser",
 		Password: "password123",
 		Name:     "Test User",
-		Role:     "superadmin",
+		Role:     "Super Admin",
 	}
 
 	err := req.Validate()
@@ -614,7 +514,7 @@ func TestUpdateUserRequest_Validate_ValidRole(t *testing.T) {
 }
 
 func TestUpdateUserRequest_Validate_InvalidRole(t *testing.T) {
-	role := "invalid"
+	role := "invalid role"
 	req := &UpdateUserRequest{
 		Role: &role,
 	}
@@ -623,6 +523,17 @@ func TestUpdateUserRequest_Validate_InvalidRole(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestInviteUserRequest_Validate_Role(t *testing.T) {
+	for _, role := range []string{"", "user", "viewer"} {
+		req := &InviteUserRequest{Email: "new@example.com", Role: role}
+		assert.NoError(t, req.Validate(), role)
+	}
+
+	// Invitations never grant admin access
+	req := &InviteUserRequest{Email: "new@example.com", Role: "admin"}
+	assert.Error(t, req.Validate())
+}
+
 func TestChangePasswordRequest_Validate_SamePassword(t *testing.T) {
 	req := &ChangePasswordRequest{
 		CurrentPassword: "password123",
@@ -643,6 +554,14 @@ func TestChangePasswordRequest_Validate_DifferentPassword(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMFACodeRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&MFACodeRequest{Code: "123456"}).Validate())
+	assert.NoError(t, (&MFACodeRequest{RecoveryCode: "abcdefgh-ijklmnop"}).Validate())
+
+	assert.Error(t, (&MFACodeRequest{}).Validate())
+	assert.Error(t, (&MFACodeRequest{Code: "123456", RecoveryCode: "abcdefgh-ijklmnop"}).Validate())
+}
+
 func TestGetUsersQuery_GetPage(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -684,6 +603,101 @@ func TestGetUsersQuery_GetSize(t *testing.T) {
 }
 
 // Helper function
+func TestToUserResponse_LockedUntil(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	locked := time.Now().Add(time.Minute)
+
+	assert.Nil(t, ToUserResponse(&entity.User{}).LockedUntil)
+	assert.Nil(t, ToUserResponse(&entity.User{LockedUntil: &expired}).LockedUntil, "an expired lock is not reported")
+	if resp := ToUserResponse(&entity.User{LockedUntil: &locked}); assert.NotNil(t, resp.LockedUntil) {
+		assert.Equal(t, locked.Unix(), *resp.LockedUntil)
+	}
+}
+
+func TestToPasskeyResponse(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour)
+	lastUsedAt := time.Now()
+
+	resp := ToPasskeyResponse(&entity.Passkey{Id: 1, Name: "Laptop", CreatedAt: createdAt, CredentialId: []byte{1}})
+	assert.Equal(t, 1, resp.Id)
+	assert.Equal(t, "Laptop", resp.Name)
+	assert.Equal(t, createdAt.Unix(), resp.CreatedAt)
+	assert.Nil(t, resp.LastUsedAt)
+
+	if resp := ToPasskeyResponse(&entity.Passkey{LastUsedAt: &lastUsedAt}); assert.NotNil(t, resp.LastUsedAt) {
+		assert.Equal(t, lastUsedAt.Unix(), *resp.LastUsedAt)
+	}
+}
+
+func TestToAPIKeyResponse(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour)
+	expiresAt := time.Now().Add(time.Hour)
+
+	resp := ToAPIKeyResponse(&entity.APIKey{
+		Id:        1,
+		Name:      "CI",
+		Prefix:    "gbt_0123456789ab",
+		KeyHash:   "hash",
+		Scopes:    []string{entity.APIKeyScopeUsersRead},
+		ExpiresAt: &expiresAt,
+		CreatedAt: createdAt,
+	})
+	assert.Equal(t, 1, resp.Id)
+	assert.Equal(t, "CI", resp.Name)
+	assert.Equal(t, "gbt_0123456789ab", resp.Prefix)
+	assert.Equal(t, []string{entity.APIKeyScopeUsersRead}, resp.Scopes)
+	assert.Equal(t, createdAt.Unix(), resp.CreatedAt)
+	if assert.NotNil(t, resp.ExpiresAt) {
+		assert.Equal(t, expiresAt.Unix(), *resp.ExpiresAt)
+	}
+	assert.Nil(t, resp.LastUsedAt)
+	assert.Nil(t, resp.RevokedAt)
+
+	body, err := json.Marshal(&CreateAPIKeyResponse{APIKeyResponse: resp, Key: "gbt_0123456789ab_secret"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"key":"gbt_0123456789ab_secret"`)
+	assert.Contains(t, string(body), `"prefix":"gbt_0123456789ab"`)
+	assert.NotContains(t, string(body), "hash")
+}
+
+func TestHandler_CreateMyAPIKey_Binding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, _ := newTestHandler()
+
+	router := gin.New()
+	router.POST("/me/api-keys", func(c *gin.Context) {
+		var req CreateAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.HandleBindingError(c, err)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{"valid", `{"name":"CI","scopes":["users:read"]}`, http.StatusCreated},
+		{"missing name", `{"scopes":["users:read"]}`, http.StatusBadRequest},
+		{"missing scopes", `{"name":"CI"}`, http.StatusBadRequest},
+		{"empty scopes", `{"name":"CI","scopes":[]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/me/api-keys", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -1,12 +1,18 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/pkg/cache"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
 )
 
@@ -20,26 +26,119 @@ type IJWTValidator interface {
 	ValidateToken(tokenString string) (*Claims, error)
 }
 
+// IRevocationChecker defines the interface for checking whether a valid token has been revoked.
+type IRevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, userId int, tokenId string, tokenVersion int) (bool, error)
+}
+
+// IAPIKeyAuthenticator defines the interface for authenticating API keys.
+// AuthenticateAPIKey returns domain.InvalidAPIKeyError for keys that must be rejected.
+type IAPIKeyAuthenticator interface {
+	IsAPIKey(token string) bool
+	AuthenticateAPIKey(ctx context.Context, key string) (*entity.User, *entity.APIKey, error)
+}
+
+// IPermissionResolver defines the interface for resolving the permissions granted by a role.
+type IPermissionResolver interface {
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+}
+
+// ITokenFailureRecorder defines the interface for recording why access tokens were rejected, e.g. as metrics.
+type ITokenFailureRecorder interface {
+	RecordTokenFailure(err error)
+}
+
 // Claims represents JWT claims.
 type Claims struct {
-	UserId int
-	Role   string
+	UserId       int
+	Role         string
+	TokenId      string
+	TokenVersion int
+	ExpiresAt    time.Time
+}
+
+// PermissionCache caches the permissions granted by roles, so that RequirePermission does not
+// resolve them on every request. Changes to a role made by another instance take at most the
+// cache TTL to be noticed.
+type PermissionCache struct {
+	roles *cache.TTLCache[string, []string]
+}
+
+// NewPermissionCache creates a permission cache whose entries live for the given duration.
+func NewPermissionCache(ttl time.Duration) *PermissionCache {
+	return &PermissionCache{roles: cache.NewTTLCache[string, []string](ttl)}
+}
+
+// ForgetRolePermissions drops the cached permissions of the role, so that a change to the role
+// applies on this instance with the next request.
+func (c *PermissionCache) ForgetRolePermissions(role string) {
+	c.roles.Delete(role)
 }
 
 // Middleware provides authentication middleware.
 type Middleware struct {
-	jwtValidator IJWTValidator
+	jwtValidator        IJWTValidator
+	revocationChecker   IRevocationChecker
+	apiKeyAuthenticator IAPIKeyAuthenticator
+	permissionResolver  IPermissionResolver
+	permissionCache     *PermissionCache
+	tokenFailures       ITokenFailureRecorder
+}
+
+// Option configures optional middleware dependencies.
+type Option func(*Middleware)
+
+// WithRevocationChecker makes RequireAuth reject tokens that have been revoked.
+func WithRevocationChecker(checker IRevocationChecker) Option {
+	return func(m *Middleware) {
+		m.revocationChecker = checker
+	}
+}
+
+// WithAPIKeyAuthenticator makes RequireAuth accept API keys as bearer tokens besides JWTs.
+func WithAPIKeyAuthenticator(authenticator IAPIKeyAuthenticator) Option {
+	return func(m *Middleware) {
+		m.apiKeyAuthenticator = authenticator
+	}
+}
+
+// WithPermissionResolver lets RequirePermission resolve the permissions of the user role.
+// Without it, RequirePermission rejects every request.
+func WithPermissionResolver(resolver IPermissionResolver) Option {
+	return func(m *Middleware) {
+		m.permissionResolver = resolver
+	}
+}
+
+// WithPermissionCache makes RequirePermission cache the permissions resolved for a role.
+func WithPermissionCache(permissionCache *PermissionCache) Option {
+	return func(m *Middleware) {
+		m.permissionCache = permissionCache
+	}
+}
+
+// WithTokenFailureRecorder makes RequireAuth report the validation error of every rejected JWT.
+func WithTokenFailureRecorder(recorder ITokenFailureRecorder) Option {
+	return func(m *Middleware) {
+		m.tokenFailures = recorder
+	}
 }
 
 // New creates a new auth middleware.
-func New(jwtValidator IJWTValidator) (*Middleware, error) {
+func New(jwtValidator IJWTValidator, opts ...Option) (*Middleware, error) {
 	if jwtValidator == nil {
 		return nil, errors.New("jwt validator is required")
 	}
-	return &Middleware{jwtValidator: jwtValidator}, nil
+
+	m := &Middleware{jwtValidator: jwtValidator}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
 }
 
-// RequireAuth returns a middleware that requires a valid JWT token.
+// RequireAuth returns a middleware that requires a valid JWT token, or a valid API key
+// if an API key authenticator is configured.
 func (m *Middleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Set Vary header for caching
@@ -48,47 +147,154 @@ func (m *Middleware) RequireAuth() gin.HandlerFunc {
 		// Get Authorization header
 		authHeader := c.GetHeader(authorizationHeader)
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "authorization header is required",
-			})
+			handler.AbortWithProblem(c, http.StatusUnauthorized, domain.CodeUnauthorized, "authorization header is required")
 			return
 		}
 
 		// Check Bearer prefix
 		if !strings.HasPrefix(authHeader, bearerPrefix) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "invalid authorization header format",
-			})
+			handler.AbortWithProblem(c, http.StatusUnauthorized, domain.CodeUnauthorized, "invalid authorization header format")
 			return
 		}
 
 		// Extract token
 		tokenString := strings.TrimPrefix(authHeader, bearerPrefix)
 		if tokenString == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "token is required",
-			})
+			handler.AbortWithProblem(c, http.StatusUnauthorized, domain.CodeUnauthorized, "token is required")
+			return
+		}
+
+		if m.apiKeyAuthenticator != nil && m.apiKeyAuthenticator.IsAPIKey(tokenString) {
+			m.authenticateAPIKey(c, tokenString)
 			return
 		}
 
 		// Validate token
 		claims, err := m.jwtValidator.ValidateToken(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"message": "invalid token",
-				"data":    err.Error(),
-			})
+			if m.tokenFailures != nil {
+				m.tokenFailures.RecordTokenFailure(err)
+			}
+			handler.AbortWithProblem(c, http.StatusUnauthorized, handler.CodeInvalidAccessToken, err.Error())
 			return
 		}
 
+		// Check revocation (logout, logout everywhere, deactivated or deleted user)
+		if m.revocationChecker != nil {
+			revoked, err := m.revocationChecker.IsTokenRevoked(c.Request.Context(), claims.UserId, claims.TokenId, claims.TokenVersion)
+			if err != nil {
+				handler.AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, "failed to verify token")
+				return
+			}
+			if revoked {
+				handler.AbortWithProblem(c, http.StatusUnauthorized, handler.CodeTokenRevoked, "token has been revoked")
+				return
+			}
+		}
+
 		// Set user info in context
 		handler.SetUserId(c, claims.UserId)
 		handler.SetUserRole(c, claims.Role)
+		handler.SetTokenId(c, claims.TokenId)
+		handler.SetTokenExpiresAt(c, claims.ExpiresAt)
 
 		c.Next()
 	}
 }
 
+// authenticateAPIKey authenticates a request that presents an API key. The key acts as its owner,
+// so the owner's ID and current role are set in the context like for a JWT.
+func (m *Middleware) authenticateAPIKey(c *gin.Context, key string) {
+	user, apiKey, err := m.apiKeyAuthenticator.AuthenticateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		var invalidErr domain.InvalidAPIKeyError
+		if errors.As(err, &invalidErr) {
+			handler.AbortWithProblem(c, http.StatusUnauthorized, domain.CodeInvalidAPIKey, "invalid api key")
+			return
+		}
+		handler.AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, "failed to verify api key")
+		return
+	}
+
+	handler.SetUserId(c, user.Id)
+	handler.SetUserRole(c, user.Role)
+	handler.SetAPIKeyId(c, apiKey.Id)
+	handler.SetAPIKeyScopes(c, apiKey.Scopes)
+
+	c.Next()
+}
+
+// RequireScope returns a middleware that requires requests authenticated with an API key to hold
+// the given scope. Requests authenticated with a JWT are not limited by scopes.
+func (m *Middleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if handler.GetAPIKeyId(c) != 0 && !slices.Contains(handler.GetAPIKeyScopes(c), scope) {
+			handler.AbortWithProblem(c, http.StatusForbidden, handler.CodeInsufficientScope, "api key is missing scope "+scope)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession returns a middleware that rejects requests authenticated with an API key,
+// for endpoints that manage credentials and must be used by the user themselves.
+func (m *Middleware) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if handler.GetAPIKeyId(c) != 0 {
+			handler.AbortWithProblem(c, http.StatusForbidden, handler.CodeSessionRequired, "api keys cannot be used for this endpoint")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission returns a middleware that requires the role of the user to grant the given permission.
+// Permissions are resolved from the current role rather than the token, so changes to a role
+// apply to existing tokens and API keys, at the latest once the permission cache has expired.
+func (m *Middleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, err := m.permissions(c)
+		if err != nil {
+			handler.AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, "failed to verify permissions")
+			return
+		}
+
+		if !slices.Contains(permissions, permission) {
+			handler.AbortWithProblem(c, http.StatusForbidden, handler.CodeInsufficientPermissions, "insufficient permissions")
+			return
+		}
+		c.Next()
+	}
+}
+
+// permissions returns the permissions of the user role, resolving them at most once per request.
+func (m *Middleware) permissions(c *gin.Context) ([]string, error) {
+	if _, ok := c.Get(handler.ContextKeyPermissions); ok {
+		return handler.GetPermissions(c), nil
+	}
+	if m.permissionResolver == nil {
+		return nil, nil
+	}
+
+	role := handler.GetUserRole(c)
+	if m.permissionCache != nil {
+		if permissions, ok := m.permissionCache.roles.Get(role); ok {
+			handler.SetPermissions(c, permissions)
+			return permissions, nil
+		}
+	}
+
+	permissions, err := m.permissionResolver.GetRolePermissions(c.Request.Context(), role)
+	if err != nil {
+		return nil, err
+	}
+	if m.permissionCache != nil {
+		m.permissionCache.roles.Set(role, permissions)
+	}
+	handler.SetPermissions(c, permissions)
+	return permissions, nil
+}
+
 // RequireRole returns a middleware that requires a specific role.
 func (m *Middleware) RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -101,9 +307,7 @@ func (m *Middleware) RequireRole(allowedRoles ...string) gin.HandlerFunc {
 			}
 		}
 
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-			"message": "insufficient permissions",
-		})
+		handler.AbortWithProblem(c, http.StatusForbidden, handler.CodeInsufficientPermissions, "insufficient permissions")
 	}
 }
 
@@ -112,31 +316,73 @@ func (m *Middleware) RequireAdmin() gin.HandlerFunc {
 	return m.RequireRole(entity.RoleAdmin)
 }
 
-// RequireAdminOrSelf returns a middleware that allows admin or the user themselves.
+// OwnerFunc resolves the ID of the user that owns the resource a request targets.
+// It returns 0 if the resource has no owner or the request names no valid resource, and an
+// error only if ownership could not be checked (e.g. a failed database query).
+type OwnerFunc func(c *gin.Context) (int, error)
+
+// ParamOwner returns an OwnerFunc for resources that are users themselves, identified by the
+// user ID in the given path parameter.
+func ParamOwner(paramName string) OwnerFunc {
+	return func(c *gin.Context) (int, error) {
+		id, err := strconv.Atoi(c.Param(paramName))
+		if err != nil || id <= 0 {
+			return 0, nil
+		}
+		return id, nil
+	}
+}
+
+// RequireOwnerOrPermission returns a middleware that allows the owner of the resource, resolved
+// by owner, and users whose role grants the given permission.
+func (m *Middleware) RequireOwnerOrPermission(permission string, owner OwnerFunc) gin.HandlerFunc {
+	return m.requireOwnerOr(owner, func(c *gin.Context) (bool, error) {
+		permissions, err := m.permissions(c)
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(permissions, permission), nil
+	})
+}
+
+// RequireSelfOrPermission returns a middleware that allows the user identified by the user ID in the
+// given path parameter, and users whose role grants the given permission.
+func (m *Middleware) RequireSelfOrPermission(permission, paramName string) gin.HandlerFunc {
+	return m.RequireOwnerOrPermission(permission, ParamOwner(paramName))
+}
+
+// RequireAdminOrSelf returns a middleware that allows admin or the user themselves,
+// identified by the user ID in the given path parameter.
 func (m *Middleware) RequireAdminOrSelf(paramName string) gin.HandlerFunc {
+	return m.requireOwnerOr(ParamOwner(paramName), func(c *gin.Context) (bool, error) {
+		return handler.GetUserRole(c) == entity.RoleAdmin, nil
+	})
+}
+
+// requireOwnerOr returns a middleware that allows the owner of the resource and requests for which allowed reports true.
+func (m *Middleware) requireOwnerOr(owner OwnerFunc, allowed func(c *gin.Context) (bool, error)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole := handler.GetUserRole(c)
-		userId := handler.GetUserId(c)
+		ownerId, err := owner(c)
+		if err != nil {
+			handler.AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, "failed to verify permissions")
+			return
+		}
 
-		// Admin can access any resource
-		if userRole == entity.RoleAdmin {
+		// An ID of 0 is neither a signed-in user nor an owner
+		if userId := handler.GetUserId(c); userId != 0 && userId == ownerId {
 			c.Next()
 			return
 		}
 
-		// Check if accessing own resource
-		paramId := c.Param(paramName)
-		if paramId != "" {
-			// Simple string comparison since we set it as int in context
-			if paramId == string(rune(userId)) {
-				c.Next()
-				return
-			}
+		ok, err := allowed(c)
+		if err != nil {
+			handler.AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, "failed to verify permissions")
+			return
 		}
-
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-			"message": "insufficient permissions",
-		})
+		if !ok {
+			handler.AbortWithProblem(c, http.StatusForbidden, handler.CodeInsufficientPermissions, "insufficient permissions")
+			return
+		}
+		c.Next()
 	}
 }
-
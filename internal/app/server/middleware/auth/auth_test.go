@@ -1,15 +1,20 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
 )
 
@@ -27,6 +32,59 @@ func (m *MockJWTValidator) ValidateToken(tokenString string) (*Claims, error) {
 	return args.Get(0).(*Claims), args.Error(1)
 }
 
+// ========== Mock Revocation Checker ==========
+
+type MockRevocationChecker struct {
+	mock.Mock
+}
+
+func (m *MockRevocationChecker) IsTokenRevoked(ctx context.Context, userId int, tokenId string, tokenVersion int) (bool, error) {
+	args := m.Called(userId, tokenId, tokenVersion)
+	return args.Bool(0), args.Error(1)
+}
+
+// ========== Mock API Key Authenticator ==========
+
+type MockAPIKeyAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyAuthenticator) IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, "gbt_")
+}
+
+func (m *MockAPIKeyAuthenticator) AuthenticateAPIKey(ctx context.Context, key string) (*entity.User, *entity.APIKey, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.User), args.Get(1).(*entity.APIKey), args.Error(2)
+}
+
+// ========== Mock Permission Resolver ==========
+
+type MockPermissionResolver struct {
+	mock.Mock
+}
+
+func (m *MockPermissionResolver) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	args := m.Called(role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// ========== Mock Token Failure Recorder ==========
+
+type MockTokenFailureRecorder struct {
+	mock.Mock
+}
+
+func (m *MockTokenFailureRecorder) RecordTokenFailure(err error) {
+	m.Called(err)
+}
+
 // ========== Test Helpers ==========
 
 func setupTestRouter() *gin.Engine {
@@ -101,7 +159,16 @@ func TestRequireAuth_NoAuthorizationHeader(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
-	assert.Contains(t, w.Body.String(), "authorization header is required")
+	assert.Equal(t, handler.ContentTypeProblem, w.Header().Get("Content-Type"))
+
+	var problem handler.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	assert.Equal(t, domain.CodeUnauthorized, problem.Code)
+	assert.Equal(t, http.StatusUnauthorized, problem.Status)
+	assert.Equal(t, "authorization header is required", problem.Detail)
+	assert.Equal(t, "/protected", problem.Instance)
 }
 
 func TestRequireAuth_InvalidHeaderFormat(t *testing.T) {
@@ -184,10 +251,36 @@ func TestRequireAuth_InvalidToken(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
-	assert.Contains(t, w.Body.String(), "invalid token")
+	assert.Contains(t, w.Body.String(), handler.CodeInvalidAccessToken)
 	mockValidator.AssertExpectations(t)
 }
 
+func TestRequireAuth_RecordsTokenFailure(t *testing.T) {
+	mockValidator := new(MockJWTValidator)
+	mockRecorder := new(MockTokenFailureRecorder)
+	middleware, _ := New(mockValidator, WithTokenFailureRecorder(mockRecorder))
+
+	validationErr := errors.New("token expired")
+	mockValidator.On("ValidateToken", "invalid-token").Return(nil, validationErr)
+	mockValidator.On("ValidateToken", "valid-token").Return(&Claims{UserId: 1, Role: "user"}, nil)
+	mockRecorder.On("RecordTokenFailure", validationErr).Return()
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireAuth())
+	router.GET("/protected", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, token := range []string{"invalid-token", "valid-token"} {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	mockRecorder.AssertNumberOfCalls(t, "RecordTokenFailure", 1)
+	mockRecorder.AssertExpectations(t)
+}
+
 func TestRequireAuth_SetsVaryHeader(t *testing.T) {
 	mockValidator := new(MockJWTValidator)
 	middleware, _ := New(mockValidator)
@@ -209,6 +302,320 @@ func TestRequireAuth_SetsVaryHeader(t *testing.T) {
 	assert.Equal(t, "Authorization", w.Header().Get("Vary"))
 }
 
+// ========== Revocation Tests ==========
+
+func TestRequireAuth_RevocationChecker(t *testing.T) {
+	testCases := []struct {
+		name         string
+		revoked      bool
+		checkErr     error
+		expectedCode int
+		expected     string
+	}{
+		{"not revoked", false, nil, http.StatusOK, ""},
+		{"revoked", true, nil, http.StatusUnauthorized, "token has been revoked"},
+		{"checker error", false, errors.New("db down"), http.StatusInternalServerError, "failed to verify token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockJWTValidator)
+			mockChecker := new(MockRevocationChecker)
+			middleware, _ := New(mockValidator, WithRevocationChecker(mockChecker))
+
+			mockValidator.On("ValidateToken", "token").
+				Return(&Claims{UserId: 1, Role: "user", TokenId: "jti-1", TokenVersion: 2}, nil)
+			mockChecker.On("IsTokenRevoked", 1, "jti-1", 2).Return(tc.revoked, tc.checkErr)
+
+			router := setupTestRouter()
+			router.Use(middleware.RequireAuth())
+			router.GET("/protected", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"token_id": handler.GetTokenId(c)})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expected != "" {
+				assert.Contains(t, w.Body.String(), tc.expected)
+			} else {
+				assert.Contains(t, w.Body.String(), "jti-1")
+			}
+			mockChecker.AssertExpectations(t)
+		})
+	}
+}
+
+// ========== API Key Tests ==========
+
+func TestRequireAuth_APIKey(t *testing.T) {
+	testCases := []struct {
+		name         string
+		authErr      error
+		expectedCode int
+		expected     string
+	}{
+		{"valid key", nil, http.StatusOK, `"api_key_id":7`},
+		{"invalid key", domain.InvalidAPIKeyError{}, http.StatusUnauthorized, "invalid api key"},
+		{"authenticator error", domain.InternalServerError{Msg: "db down"}, http.StatusInternalServerError, "failed to verify api key"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockJWTValidator)
+			mockAuthenticator := new(MockAPIKeyAuthenticator)
+			middleware, _ := New(mockValidator, WithAPIKeyAuthenticator(mockAuthenticator))
+
+			if tc.authErr != nil {
+				mockAuthenticator.On("AuthenticateAPIKey", "gbt_key").Return(nil, nil, tc.authErr)
+			} else {
+				mockAuthenticator.On("AuthenticateAPIKey", "gbt_key").Return(
+					&entity.User{Id: 5, Role: entity.RoleUser},
+					&entity.APIKey{Id: 7, Scopes: []string{entity.APIKeyScopeUsersRead}},
+					nil,
+				)
+			}
+
+			router := setupTestRouter()
+			router.Use(middleware.RequireAuth())
+			router.GET("/protected", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{
+					"user_id":    handler.GetUserId(c),
+					"role":       handler.GetUserRole(c),
+					"api_key_id": handler.GetAPIKeyId(c),
+				})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer gbt_key")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expected)
+			if tc.authErr == nil {
+				assert.Contains(t, w.Body.String(), `"user_id":5`)
+				assert.Contains(t, w.Body.String(), `"role":"user"`)
+			}
+			mockValidator.AssertNotCalled(t, "ValidateToken", mock.Anything)
+		})
+	}
+}
+
+func TestRequireAuth_APIKeyWithoutAuthenticator(t *testing.T) {
+	mockValidator := new(MockJWTValidator)
+	middleware, _ := New(mockValidator)
+
+	mockValidator.On("ValidateToken", "gbt_key").Return(nil, errors.New("malformed token"))
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireAuth())
+	router.GET("/protected", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer gbt_key")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), handler.CodeInvalidAccessToken)
+}
+
+func TestRequireScope(t *testing.T) {
+	testCases := []struct {
+		name         string
+		token        string
+		expectedCode int
+	}{
+		{"jwt is not limited by scopes", "jwt", http.StatusOK},
+		{"api key with scope", "gbt_read", http.StatusOK},
+		{"api key without scope", "gbt_write", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockJWTValidator)
+			mockAuthenticator := new(MockAPIKeyAuthenticator)
+			middleware, _ := New(mockValidator, WithAPIKeyAuthenticator(mockAuthenticator))
+
+			mockValidator.On("ValidateToken", "jwt").Return(&Claims{UserId: 1, Role: entity.RoleUser}, nil)
+			mockAuthenticator.On("AuthenticateAPIKey", "gbt_read").Return(
+				&entity.User{Id: 1, Role: entity.RoleUser},
+				&entity.APIKey{Id: 1, Scopes: []string{entity.APIKeyScopeUsersRead}},
+				nil,
+			)
+			mockAuthenticator.On("AuthenticateAPIKey", "gbt_write").Return(
+				&entity.User{Id: 1, Role: entity.RoleUser},
+				&entity.APIKey{Id: 2, Scopes: []string{entity.APIKeyScopeUsersWrite}},
+				nil,
+			)
+
+			router := setupTestRouter()
+			router.Use(middleware.RequireAuth())
+			router.GET("/users", middleware.RequireScope(entity.APIKeyScopeUsersRead), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	mockValidator := new(MockJWTValidator)
+	mockAuthenticator := new(MockAPIKeyAuthenticator)
+	middleware, _ := New(mockValidator, WithAPIKeyAuthenticator(mockAuthenticator))
+
+	mockValidator.On("ValidateToken", "jwt").Return(&Claims{UserId: 1, Role: entity.RoleUser}, nil)
+	mockAuthenticator.On("AuthenticateAPIKey", "gbt_key").Return(
+		&entity.User{Id: 1, Role: entity.RoleUser},
+		&entity.APIKey{Id: 1, Scopes: []string{entity.APIKeyScopeUsersWrite}},
+		nil,
+	)
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireAuth())
+	router.POST("/me/api-keys", middleware.RequireSession(), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	for token, expectedCode := range map[string]int{"jwt": http.StatusCreated, "gbt_key": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodPost, "/me/api-keys", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedCode, w.Code, token)
+	}
+}
+
+// ========== RequirePermission Tests ==========
+
+func TestRequirePermission(t *testing.T) {
+	testCases := []struct {
+		name         string
+		token        string
+		expectedCode int
+	}{
+		{"role grants permission", "admin-token", http.StatusOK},
+		{"role lacks permission", "viewer-token", http.StatusForbidden},
+		{"api key acts with owner role", "gbt_key", http.StatusOK},
+		{"resolver failure", "broken-token", http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockJWTValidator)
+			mockAuthenticator := new(MockAPIKeyAuthenticator)
+			mockResolver := new(MockPermissionResolver)
+			middleware, _ := New(mockValidator, WithAPIKeyAuthenticator(mockAuthenticator), WithPermissionResolver(mockResolver))
+
+			mockValidator.On("ValidateToken", "admin-token").Return(&Claims{UserId: 1, Role: entity.RoleAdmin}, nil)
+			mockValidator.On("ValidateToken", "viewer-token").Return(&Claims{UserId: 2, Role: entity.RoleViewer}, nil)
+			mockValidator.On("ValidateToken", "broken-token").Return(&Claims{UserId: 3, Role: "broken"}, nil)
+			mockAuthenticator.On("AuthenticateAPIKey", "gbt_key").Return(
+				&entity.User{Id: 1, Role: entity.RoleAdmin},
+				&entity.APIKey{Id: 1, Scopes: []string{entity.APIKeyScopeUsersWrite}},
+				nil,
+			)
+			mockResolver.On("GetRolePermissions", entity.RoleAdmin).Return(entity.AllPermissions(), nil)
+			mockResolver.On("GetRolePermissions", entity.RoleViewer).Return([]string{}, nil)
+			mockResolver.On("GetRolePermissions", "broken").Return(nil, errors.New("db error"))
+
+			router := setupTestRouter()
+			router.Use(middleware.RequireAuth())
+			// The second check reuses the permissions resolved by the first
+			router.DELETE("/users/1",
+				middleware.RequirePermission(entity.PermissionUsersRead),
+				middleware.RequirePermission(entity.PermissionUsersWrite),
+				func(c *gin.Context) {
+					c.Status(http.StatusOK)
+				},
+			)
+
+			req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			mockResolver.AssertNumberOfCalls(t, "GetRolePermissions", 1)
+		})
+	}
+}
+
+func TestRequirePermission_NoResolver(t *testing.T) {
+	mockValidator := new(MockJWTValidator)
+	middleware, _ := New(mockValidator)
+
+	mockValidator.On("ValidateToken", "admin-token").Return(&Claims{UserId: 1, Role: entity.RoleAdmin}, nil)
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireAuth())
+	router.GET("/users", middleware.RequirePermission(entity.PermissionUsersRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequirePermission_Cache(t *testing.T) {
+	mockValidator := new(MockJWTValidator)
+	mockResolver := new(MockPermissionResolver)
+	permissionCache := NewPermissionCache(time.Minute)
+	middleware, _ := New(mockValidator, WithPermissionResolver(mockResolver), WithPermissionCache(permissionCache))
+
+	mockValidator.On("ValidateToken", "viewer-token").Return(&Claims{UserId: 2, Role: entity.RoleViewer}, nil)
+	mockResolver.On("GetRolePermissions", entity.RoleViewer).Return([]string{}, nil).Once()
+	mockResolver.On("GetRolePermissions", entity.RoleViewer).Return([]string{entity.PermissionUsersRead}, nil).Once()
+
+	router := setupTestRouter()
+	router.Use(middleware.RequireAuth())
+	router.GET("/users", middleware.RequirePermission(entity.PermissionUsersRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Authorization", "Bearer viewer-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, request())
+	assert.Equal(t, http.StatusForbidden, request(), "cached permissions are reused")
+	mockResolver.AssertNumberOfCalls(t, "GetRolePermissions", 1)
+
+	// The role service drops the permissions of a changed role
+	permissionCache.ForgetRolePermissions(entity.RoleViewer)
+
+	assert.Equal(t, http.StatusOK, request())
+	mockResolver.AssertNumberOfCalls(t, "GetRolePermissions", 2)
+}
+
 // ========== RequireRole Tests ==========
 
 func TestRequireRole_AllowedRole(t *testing.T) {
@@ -333,6 +740,120 @@ func TestRequireAdmin_NonAdminUser(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
 
+// ========== Resource Ownership Tests ==========
+
+func TestRequireAdminOrSelf(t *testing.T) {
+	testCases := []struct {
+		name         string
+		token        string
+		path         string
+		expectedCode int
+	}{
+		{"admin accesses other user", "admin-token", "/users/2", http.StatusOK},
+		{"user accesses self", "user-token", "/users/2", http.StatusOK},
+		{"user accesses other user", "user-token", "/users/3", http.StatusForbidden},
+		{"user with invalid id", "user-token", "/users/abc", http.StatusForbidden},
+		// User 65 used to match "A", the rune with that code point
+		{"user id is not compared as a rune", "user-65-token", "/users/A", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockJWTValidator)
+			middleware, _ := New(mockValidator)
+
+			mockValidator.On("ValidateToken", "admin-token").Return(&Claims{UserId: 1, Role: entity.RoleAdmin}, nil)
+			mockValidator.On("ValidateToken", "user-token").Return(&Claims{UserId: 2, Role: entity.RoleUser}, nil)
+			mockValidator.On("ValidateToken", "user-65-token").Return(&Claims{UserId: 65, Role: entity.RoleUser}, nil)
+
+			router := setupTestRouter()
+			router.Use(middleware.RequireAuth())
+			router.GET("/users/:id", middleware.RequireAdminOrSelf("id"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestRequireOwnerOrPermission(t *testing.T) {
+	testCases := []struct {
+		name         string
+		token        string
+		path         string
+		expectedCode int
+	}{
+		{"admin accesses other user", "admin-token", "/users/2", http.StatusOK},
+		{"user accesses self", "user-token", "/users/2", http.StatusOK},
+		{"user accesses other user", "user-token", "/users/3", http.StatusForbidden},
+		{"admin accesses other user's post", "admin-token", "/posts/10", http.StatusOK},
+		{"user accesses own post", "user-token", "/posts/10", http.StatusOK},
+		{"user accesses other user's post", "user-token", "/posts/11", http.StatusForbidden},
+		{"owner lookup failure", "user-token", "/posts/12", http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockJWTValidator)
+			mockResolver := new(MockPermissionResolver)
+			middleware, _ := New(mockValidator, WithPermissionResolver(mockResolver))
+
+			mockValidator.On("ValidateToken", "admin-token").Return(&Claims{UserId: 1, Role: entity.RoleAdmin}, nil)
+			mockValidator.On("ValidateToken", "user-token").Return(&Claims{UserId: 2, Role: entity.RoleUser}, nil)
+			mockResolver.On("GetRolePermissions", entity.RoleAdmin).Return(entity.AllPermissions(), nil)
+			mockResolver.On("GetRolePermissions", entity.RoleUser).Return([]string{}, nil)
+
+			// Posts stand in for another domain whose resources have an owner
+			postOwners := map[string]int{"10": 2, "11": 3}
+			postOwner := func(c *gin.Context) (int, error) {
+				ownerId, ok := postOwners[c.Param("id")]
+				if !ok {
+					return 0, errors.New("db error")
+				}
+				return ownerId, nil
+			}
+
+			router := setupTestRouter()
+			router.Use(middleware.RequireAuth())
+			router.GET("/users/:id", middleware.RequireSelfOrPermission(entity.PermissionUsersRead, "id"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			router.GET("/posts/:id", middleware.RequireOwnerOrPermission(entity.PermissionUsersWrite, postOwner), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestParamOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for param, expected := range map[string]int{"42": 42, "0": 0, "-1": 0, "abc": 0, "": 0} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Params = gin.Params{{Key: "id", Value: param}}
+
+		ownerId, err := ParamOwner("id")(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ownerId, param)
+	}
+}
+
 // ========== Context Helpers Tests ==========
 
 func TestContextHelpers_SetAndGet(t *testing.T) {
@@ -0,0 +1,44 @@
+package httpmetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/your-org/go-backend-template/internal/pkg/metrics"
+)
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary paths do not create series.
+const unmatchedRoute = "unmatched"
+
+// New returns a middleware that counts requests and records their latency in registry,
+// by method, route template (e.g. /api/users/:id) and status.
+func New(registry prometheus.Registerer) gin.HandlerFunc {
+	factory := promauto.With(registry)
+	requests := factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests handled.",
+	}, []string{"method", "route", "status"})
+	duration := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"method", "route", "status"})
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		duration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
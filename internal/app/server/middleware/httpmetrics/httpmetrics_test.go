@@ -0,0 +1,66 @@
+package httpmetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/metrics"
+)
+
+// ========== Test Helpers ==========
+
+func setupTestRouter(registry *prometheus.Registry) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(New(registry))
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/users", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusBadRequest)
+	})
+	return router
+}
+
+func scrape(t *testing.T, registry *prometheus.Registry) string {
+	w := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to scrape metrics: status %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+// ========== Tests ==========
+
+func TestNew_RecordsByRouteTemplate(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	router := setupTestRouter(registry)
+
+	for _, path := range []string{"/users/1", "/users/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	out := scrape(t, registry)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, out, `http_requests_total{method="POST",route="/users",status="400"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 2`)
+	assert.NotContains(t, out, "/users/1")
+}
+
+func TestNew_UnmatchedRoute(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	router := setupTestRouter(registry)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/random/path", nil))
+
+	out := scrape(t, registry)
+	assert.Contains(t, out, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, out, "/random/path")
+}
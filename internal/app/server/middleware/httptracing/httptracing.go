@@ -0,0 +1,46 @@
+package httptracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns the middlewares that trace every request with a server span of provider.
+//
+// The span is started by otelgin, continues the caller's trace when the request carries a
+// W3C traceparent header, and fails on server errors. It is carried in the request context,
+// so that spans started with telemetry.Start while handling the request become its children,
+// and its trace and span IDs are added to the context logger so that log records can be
+// matched with traces. It must come after requestlog.New.
+func New(provider trace.TracerProvider) gin.HandlersChain {
+	return gin.HandlersChain{
+		otelgin.Middleware("",
+			otelgin.WithTracerProvider(provider),
+			otelgin.WithPropagators(propagation.TraceContext{}),
+		),
+		annotate,
+	}
+}
+
+// annotate adds the request and user IDs to the server span and its IDs to the context logger.
+func annotate(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+
+	if sc := span.SpanContext(); sc.IsValid() {
+		log := logger.FromContext(ctx).With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, log))
+	}
+	span.SetAttributes(attribute.String("request.id", handler.GetRequestId(c)))
+
+	c.Next()
+
+	if userId := handler.GetUserId(c); userId != 0 {
+		span.SetAttributes(attribute.Int("user.id", userId))
+	}
+}
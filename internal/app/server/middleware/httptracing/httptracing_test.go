@@ -0,0 +1,172 @@
+package httptracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ========== Test Helpers ==========
+
+func setupTestRouter(recorder *tracetest.SpanRecorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))...)
+	return router
+}
+
+// findSpan returns the first ended span with the given name, or nil.
+func findSpan(recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// attributeValue returns the value of the attribute with the given key, or nil.
+func attributeValue(span sdktrace.ReadOnlySpan, key string) any {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+// ========== Tests ==========
+
+func TestNew_StartsServerSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	router := setupTestRouter(recorder)
+
+	router.GET("/users/:id", func(c *gin.Context) {
+		handler.SetUserId(c, 7)
+		_, span := telemetry.Start(c.Request.Context(), "user.Service.GetUserById")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	server := findSpan(recorder, "/users/:id")
+	if !assert.NotNil(t, server) {
+		return
+	}
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, codes.Unset, server.Status().Code)
+	assert.Equal(t, "/users/:id", attributeValue(server, "http.route"))
+	assert.Equal(t, int64(http.StatusOK), attributeValue(server, "http.status_code"))
+	assert.Equal(t, int64(7), attributeValue(server, "user.id"))
+
+	child := findSpan(recorder, "user.Service.GetUserById")
+	if assert.NotNil(t, child) {
+		assert.Equal(t, server.SpanContext().TraceID(), child.SpanContext().TraceID())
+		assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	}
+}
+
+func TestNew_ContinuesIncomingTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	router := setupTestRouter(recorder)
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	server := findSpan(recorder, "/test")
+	if assert.NotNil(t, server) {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	}
+}
+
+func TestNew_ServerErrorFailsSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	router := setupTestRouter(recorder)
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	assert.Equal(t, codes.Error, findSpan(recorder, "/fail").Status().Code)
+	assert.Equal(t, codes.Unset, findSpan(recorder, "/missing").Status().Code)
+}
+
+func TestNew_RecordsDomainError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	router := setupTestRouter(recorder)
+	router.GET("/users/:id", func(c *gin.Context) {
+		var base handler.BaseHandler
+		base.HandleDomainError(c, domain.UserNotFoundError{})
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	server := findSpan(recorder, "/users/:id")
+	if !assert.NotNil(t, server) {
+		return
+	}
+	assert.Equal(t, "domain.UserNotFoundError", attributeValue(server, "error.type"))
+	assert.Equal(t, int64(http.StatusNotFound), attributeValue(server, "error.http_status"))
+	assert.Equal(t, codes.Unset, server.Status().Code)
+}
+
+func TestNew_UnmatchedRoute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	router := setupTestRouter(recorder)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/random/path", nil))
+
+	assert.NotNil(t, findSpan(recorder, "HTTP GET route not found"))
+}
+
+func TestNew_AddsTraceIdToLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base, _ := logger.New(&buf, logger.Config{})
+
+	recorder := tracetest.NewSpanRecorder()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), base))
+	})
+	router.Use(New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))...)
+	router.GET("/test", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("handled")
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode log record: %v", err)
+	}
+	server := findSpan(recorder, "/test")
+	assert.Equal(t, server.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, server.SpanContext().SpanID().String(), record["span_id"])
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+)
+
+// sweepInterval is the number of requests between sweeps of finished windows.
+const sweepInterval = 1024
+
+// Limiter allows each key at most limit requests per fixed time window.
+// State is kept in memory, so every server instance counts separately.
+type Limiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	windows  map[string]*window
+	requests int
+	now      func() time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter creates a limiter that allows limit requests per key and window.
+func NewLimiter(limit int, d time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  d,
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+}
+
+// Allow records a request for key. If the key is over its limit, it returns false
+// and the time until the current window ends.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.start.Add(l.window)) {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// sweep periodically drops finished windows so that the map does not grow unbounded.
+func (l *Limiter) sweep(now time.Time) {
+	l.requests++
+	if l.requests < sweepInterval {
+		return
+	}
+	l.requests = 0
+	for key, w := range l.windows {
+		if !now.Before(w.start.Add(l.window)) {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// New returns a middleware that allows each client IP at most limit requests per window
+// and rejects the rest with 429 Too Many Requests and a Retry-After header.
+// The client IP is gin's Context.ClientIP: forwarding headers only count if the router trusts
+// the proxy that set them (see gin.Engine.SetTrustedProxies), which gin.New does for every peer.
+// A non-positive limit or window disables the middleware.
+func New(limit int, d time.Duration) gin.HandlerFunc {
+	if limit <= 0 || d <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	limiter := NewLimiter(limit, d)
+	return func(c *gin.Context) {
+		ok, retryAfter := limiter.Allow(c.ClientIP())
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			handler.AbortWithProblem(c, http.StatusTooManyRequests, handler.CodeRateLimited, "too many requests, please try again later")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package requestlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+)
+
+// HeaderRequestId is the header that carries the correlation ID of a request.
+const HeaderRequestId = "X-Request-ID"
+
+const maxRequestIdLength = 128
+
+// New returns a middleware that assigns every request a correlation ID and logs it once handled.
+//
+// The ID is taken from the X-Request-ID header set by a proxy or client when it is well-formed,
+// and generated otherwise. It is echoed in the response header, and added to a child of base
+// that is carried in the request context, so that everything logged with logger.FromContext
+// while handling the request can be correlated. It must be the first middleware, so that
+// panics and other middlewares' rejections are logged too.
+func New(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestId := c.GetHeader(HeaderRequestId)
+		if !isValidRequestId(requestId) {
+			requestId = newRequestId()
+		}
+		handler.SetRequestId(c, requestId)
+		c.Header(HeaderRequestId, requestId)
+
+		log := base.With("request_id", requestId)
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), log))
+
+		c.Next()
+
+		// The query string is left out: it may carry tokens (e.g. email verification links)
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if userId := handler.GetUserId(c); userId != 0 {
+			attrs = append(attrs, slog.Int("user_id", userId))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch status := c.Writer.Status(); {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		log.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery returns a middleware that turns panics into 500 responses and logs them with the
+// request's logger. It must come after New.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		logger.FromContext(c.Request.Context()).Error("panic while handling request",
+			"panic", err,
+			"stack", string(debug.Stack()),
+		)
+		handler.AbortWithProblem(c, http.StatusInternalServerError, domain.CodeInternal, "internal server error")
+	})
+}
+
+// isValidRequestId reports whether a request ID received from a client can be used as is.
+// Only short IDs of URL-safe characters are accepted, so that they cannot forge log lines.
+func isValidRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestId returns a random 128-bit ID in hex.
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
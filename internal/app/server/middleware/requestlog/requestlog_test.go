@@ -0,0 +1,157 @@
+package requestlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/app/server/handler"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+)
+
+// ========== Test Helpers ==========
+
+func setupTestRouter(buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	base, _ := logger.New(buf, logger.Config{Level: "debug"})
+
+	router := gin.New()
+	router.Use(New(base), Recovery())
+	return router
+}
+
+// records decodes the JSON log records written to buf.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var result []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log record %q: %v", line, err)
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// ========== Request ID Tests ==========
+
+func TestNew_GeneratesRequestId(t *testing.T) {
+	var buf bytes.Buffer
+	router := setupTestRouter(&buf)
+
+	var contextId string
+	router.GET("/test", func(c *gin.Context) {
+		contextId = handler.GetRequestId(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	requestId := w.Header().Get(HeaderRequestId)
+	assert.Len(t, requestId, 32)
+	assert.Equal(t, requestId, contextId)
+}
+
+func TestNew_PropagatesRequestId(t *testing.T) {
+	testCases := []struct {
+		name      string
+		header    string
+		propagate bool
+	}{
+		{"well-formed id", "req-123_abc.def:1", true},
+		{"id with spaces", "req 123", false},
+		{"id with line break", "req\nlevel=ERROR", false},
+		{"too long id", strings.Repeat("a", 129), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := setupTestRouter(&buf)
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set(HeaderRequestId, tc.header)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tc.propagate {
+				assert.Equal(t, tc.header, w.Header().Get(HeaderRequestId))
+			} else {
+				assert.Len(t, w.Header().Get(HeaderRequestId), 32, "a new id replaces a malformed one")
+			}
+		})
+	}
+}
+
+// ========== Logging Tests ==========
+
+func TestNew_LoggerInContext(t *testing.T) {
+	var buf bytes.Buffer
+	router := setupTestRouter(&buf)
+	router.GET("/users/:id", func(c *gin.Context) {
+		handler.SetUserId(c, 7)
+		logger.FromContext(c.Request.Context()).Info("handling", "password", "hunter22")
+		c.Status(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?token=secret-token", nil)
+	req.Header.Set(HeaderRequestId, "req-1")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	logged := records(t, &buf)
+	if assert.Len(t, logged, 2) {
+		assert.Equal(t, "handling", logged[0]["msg"])
+		assert.Equal(t, "req-1", logged[0]["request_id"], "records logged while handling carry the request id")
+		assert.Equal(t, logger.Redacted, logged[0]["password"])
+
+		access := logged[1]
+		assert.Equal(t, "request", access["msg"])
+		assert.Equal(t, "WARN", access["level"])
+		assert.Equal(t, "req-1", access["request_id"])
+		assert.Equal(t, "GET", access["method"])
+		assert.Equal(t, "/users/1", access["path"])
+		assert.Equal(t, "/users/:id", access["route"])
+		assert.Equal(t, float64(http.StatusNotFound), access["status"])
+		assert.Equal(t, float64(7), access["user_id"])
+	}
+	assert.NotContains(t, buf.String(), "secret-token", "query strings are not logged")
+	assert.NotContains(t, buf.String(), "hunter22")
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	router := setupTestRouter(&buf)
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	logged := records(t, &buf)
+	if assert.Len(t, logged, 2) {
+		assert.Equal(t, "panic while handling request", logged[0]["msg"])
+		assert.Equal(t, "boom", logged[0]["panic"])
+		assert.Equal(t, logged[0]["request_id"], logged[1]["request_id"])
+		assert.Equal(t, slog.LevelError.String(), logged[1]["level"])
+	}
+}
@@ -0,0 +1,28 @@
+package timeout
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// New returns a middleware that bounds the request context by d.
+// Handlers pass c.Request.Context() down to the repositories, so queries still running
+// when the deadline passes (or the client disconnects) are cancelled.
+// Deadlines only ever shorten: a route-level timeout cannot extend a server-wide one.
+// A non-positive d disables the deadline.
+func New(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
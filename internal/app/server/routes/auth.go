@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	authHandler "github.com/your-org/go-backend-template/internal/app/server/handler/auth"
+)
+
+// SetupAuthRoutes sets up authentication routes.
+// Registration, login (including its second factor and passkey login), refresh, password reset and email verification are public;
+// logout requires a valid access token.
+func SetupAuthRoutes(r *gin.RouterGroup, h *authHandler.Handler, auth AuthMiddleware, limits RateLimits) {
+	authRoutes := r.Group("/auth")
+	{
+		// Self-registration, governed by the registration mode
+		authRoutes.POST("/register", limits.Register, h.Register)
+
+		// Throttled per client IP; accounts are additionally locked after repeated failures
+		authRoutes.POST("/login", limits.Login, h.Login)
+		authRoutes.POST("/refresh", h.Refresh)
+
+		// Second step of a login that needs a second factor, throttled tighter than the login
+		// against code guessing
+		authRoutes.POST("/mfa/verify", limits.MFA, h.VerifyMFA)
+		authRoutes.POST("/mfa/enroll", limits.MFA, h.BeginMFAEnrollment)
+		authRoutes.POST("/mfa/enroll/confirm", limits.MFA, h.ConfirmMFAEnrollment)
+
+		// Usernameless login with a passkey, throttled separately as every attempt takes two requests
+		authRoutes.POST("/passkey/login/begin", limits.Passkey, h.BeginPasskeyLogin)
+		authRoutes.POST("/passkey/login/finish", limits.Passkey, h.FinishPasskeyLogin)
+
+		// Self-service password reset, throttled per client IP against mail flooding and token guessing
+		authRoutes.POST("/password/forgot", limits.Email, h.ForgotPassword)
+		authRoutes.POST("/password/reset", limits.Email, h.ResetPassword)
+
+		// Email verification, throttled like the password reset
+		authRoutes.POST("/verify-email", limits.Email, h.VerifyEmail)
+		authRoutes.POST("/verify-email/resend", limits.Email, h.ResendEmailVerification)
+
+		// Logout the current session / all sessions; API keys are revoked instead
+		authRoutes.POST("/logout", auth.RequireAuth(), auth.RequireSession(), h.Logout)
+		authRoutes.POST("/logout-all", auth.RequireAuth(), auth.RequireSession(), h.LogoutAll)
+	}
+}
+
+// SetupWellKnownRoutes sets up public discovery routes served from the server root.
+func SetupWellKnownRoutes(r gin.IRouter, h *authHandler.Handler) {
+	r.GET("/.well-known/jwks.json", h.JWKS)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	roleHandler "github.com/your-org/go-backend-template/internal/app/server/handler/role"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// SetupRoleRoutes sets up role management routes (protected).
+// API keys have no scope for roles, so these routes require a session.
+func SetupRoleRoutes(r *gin.RouterGroup, h *roleHandler.Handler, auth AuthMiddleware) {
+	canRead := auth.RequirePermission(entity.PermissionRolesRead)
+	canWrite := auth.RequirePermission(entity.PermissionRolesWrite)
+
+	// Permissions that roles can grant - requires roles:read
+	r.GET("/permissions", auth.RequireSession(), canRead, h.GetPermissions)
+
+	roles := r.Group("/roles")
+	roles.Use(auth.RequireSession())
+	{
+		// List and get roles - requires roles:read
+		roles.GET("", canRead, h.GetRoles)
+		roles.GET("/:name", canRead, h.GetRole)
+
+		// Create, update and delete roles - requires roles:write
+		roles.POST("", canWrite, h.CreateRole)
+		roles.PATCH("/:name", canWrite, h.UpdateRole)
+		roles.DELETE("/:name", canWrite, h.DeleteRole)
+	}
+}
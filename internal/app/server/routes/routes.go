@@ -2,12 +2,26 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	authHandler "github.com/your-org/go-backend-template/internal/app/server/handler/auth"
+	roleHandler "github.com/your-org/go-backend-template/internal/app/server/handler/role"
 	userHandler "github.com/your-org/go-backend-template/internal/app/server/handler/user"
+	authMiddleware "github.com/your-org/go-backend-template/internal/app/server/middleware/auth"
 )
 
 // Handlers holds all domain-specific handlers.
 type Handlers struct {
+	Auth *authHandler.Handler
 	User *userHandler.Handler
+	Role *roleHandler.Handler
+}
+
+// RateLimits holds the rate limiting middleware of abuse-prone public routes.
+type RateLimits struct {
+	Register gin.HandlerFunc
+	Login    gin.HandlerFunc
+	MFA      gin.HandlerFunc // second step of a login, which guesses a short code
+	Passkey  gin.HandlerFunc // passkey login, which takes two requests per attempt
+	Email    gin.HandlerFunc // password reset and email verification, which send mail or redeem mailed tokens
 }
 
 // AuthMiddleware defines the auth middleware interface.
@@ -15,18 +29,24 @@ type AuthMiddleware interface {
 	RequireAuth() gin.HandlerFunc
 	RequireAdmin() gin.HandlerFunc
 	RequireRole(roles ...string) gin.HandlerFunc
+	RequirePermission(permission string) gin.HandlerFunc
+	RequireOwnerOrPermission(permission string, owner authMiddleware.OwnerFunc) gin.HandlerFunc
+	RequireSelfOrPermission(permission, paramName string) gin.HandlerFunc
+	RequireScope(scope string) gin.HandlerFunc
+	RequireSession() gin.HandlerFunc
 }
 
 // SetupRoutes configures all API routes.
-func SetupRoutes(r *gin.RouterGroup, h *Handlers, auth AuthMiddleware) {
-	// Public routes (no authentication required)
-	SetupAuthRoutes(r, h.User)
+func SetupRoutes(r *gin.RouterGroup, h *Handlers, auth AuthMiddleware, limits RateLimits) {
+	// Auth routes (mostly public)
+	SetupAuthRoutes(r, h.Auth, auth, limits)
 
 	// Protected routes (authentication required)
 	protected := r.Group("")
 	protected.Use(auth.RequireAuth())
 	{
 		SetupUserRoutes(protected, h.User, auth)
+		SetupRoleRoutes(protected, h.Role, auth)
 	}
 }
 
@@ -3,41 +3,79 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	userHandler "github.com/your-org/go-backend-template/internal/app/server/handler/user"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
 )
 
-// SetupAuthRoutes sets up authentication routes (public).
-func SetupAuthRoutes(r *gin.RouterGroup, h *userHandler.Handler) {
-	auth := r.Group("/auth")
-	{
-		auth.POST("/login", h.Login)
-	}
-}
-
 // SetupUserRoutes sets up user routes (protected).
+// Requests authenticated with an API key need the matching scope, and cannot manage credentials.
 func SetupUserRoutes(r *gin.RouterGroup, h *userHandler.Handler, auth AuthMiddleware) {
+	read := auth.RequireScope(entity.APIKeyScopeUsersRead)
+	write := auth.RequireScope(entity.APIKeyScopeUsersWrite)
+	canRead := auth.RequirePermission(entity.PermissionUsersRead)
+	canWrite := auth.RequirePermission(entity.PermissionUsersWrite)
+
 	// Current user endpoints
-	r.GET("/me", h.GetMe)
+	r.GET("/me", read, h.GetMe)
+
+	// Two-factor authentication of the current user
+	mfa := r.Group("/me/mfa")
+	mfa.Use(auth.RequireSession())
+	{
+		mfa.GET("", h.GetMyMFA)
+		mfa.POST("/totp", h.BeginMFAEnrollment)
+		mfa.POST("/totp/confirm", h.ConfirmMFAEnrollment)
+		mfa.POST("/disable", h.DisableMFA)
+		mfa.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+	}
+
+	// Passkeys of the current user
+	passkeys := r.Group("/me/passkeys")
+	passkeys.Use(auth.RequireSession())
+	{
+		passkeys.GET("", h.GetMyPasskeys)
+		passkeys.POST("/register/begin", h.BeginPasskeyRegistration)
+		passkeys.POST("/register/finish", h.FinishPasskeyRegistration)
+		passkeys.DELETE("/:id", h.DeleteMyPasskey)
+	}
+
+	// API keys of the current user
+	apiKeys := r.Group("/me/api-keys")
+	apiKeys.Use(auth.RequireSession())
+	{
+		apiKeys.GET("", h.GetMyAPIKeys)
+		apiKeys.POST("", h.CreateMyAPIKey)
+		apiKeys.DELETE("/:id", h.RevokeMyAPIKey)
+	}
 
 	// User CRUD endpoints
 	users := r.Group("/users")
 	{
-		// List users - requires admin role
-		users.GET("", auth.RequireAdmin(), h.GetUsers)
+		// List users - requires users:read
+		users.GET("", read, canRead, h.GetUsers)
+
+		// Create user - requires users:write
+		users.POST("", write, canWrite, h.CreateUser)
+
+		// Invite user to self-register - requires users:write
+		users.POST("/invitations", write, canWrite, h.InviteUser)
+
+		// Get user by ID - the user themselves, or requires users:read
+		users.GET("/:id", read, auth.RequireSelfOrPermission(entity.PermissionUsersRead, "id"), h.GetUser)
 
-		// Create user - requires admin role
-		users.POST("", auth.RequireAdmin(), h.CreateUser)
+		// Update user - requires users:write
+		users.PATCH("/:id", write, canWrite, h.UpdateUser)
 
-		// Get user by ID - authenticated users can access
-		users.GET("/:id", h.GetUser)
+		// Delete user - requires users:write
+		users.DELETE("/:id", write, canWrite, h.DeleteUser)
 
-		// Update user - requires admin role
-		users.PATCH("/:id", auth.RequireAdmin(), h.UpdateUser)
+		// Unlock a user locked out by failed logins - requires users:write
+		users.POST("/:id/unlock", write, canWrite, h.UnlockUser)
 
-		// Delete user - requires admin role
-		users.DELETE("/:id", auth.RequireAdmin(), h.DeleteUser)
+		// Remove the second factor of a user who lost it - requires users:write
+		users.DELETE("/:id/mfa", write, canWrite, h.ResetMFA)
 
-		// Change password - user can change their own password
-		users.POST("/:id/change-password", h.ChangePassword)
+		// Change password - the user themselves, or requires users:write; not with an API key
+		users.POST("/:id/change-password", auth.RequireSession(), auth.RequireSelfOrPermission(entity.PermissionUsersWrite, "id"), h.ChangePassword)
 	}
 }
 
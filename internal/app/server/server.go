@@ -1,22 +1,50 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	authHandler "github.com/your-org/go-backend-template/internal/app/server/handler/auth"
+	roleHandler "github.com/your-org/go-backend-template/internal/app/server/handler/role"
 	userHandler "github.com/your-org/go-backend-template/internal/app/server/handler/user"
 	"github.com/your-org/go-backend-template/internal/app/server/middleware/auth"
+	"github.com/your-org/go-backend-template/internal/app/server/middleware/httpmetrics"
+	"github.com/your-org/go-backend-template/internal/app/server/middleware/httptracing"
+	"github.com/your-org/go-backend-template/internal/app/server/middleware/ratelimit"
+	"github.com/your-org/go-backend-template/internal/app/server/middleware/requestlog"
+	"github.com/your-org/go-backend-template/internal/app/server/middleware/timeout"
 	"github.com/your-org/go-backend-template/internal/app/server/routes"
+	accountService "github.com/your-org/go-backend-template/internal/app/server/service/account"
+	apiKeyService "github.com/your-org/go-backend-template/internal/app/server/service/apikey"
+	authService "github.com/your-org/go-backend-template/internal/app/server/service/auth"
+	mfaService "github.com/your-org/go-backend-template/internal/app/server/service/mfa"
+	passkeyService "github.com/your-org/go-backend-template/internal/app/server/service/passkey"
+	roleService "github.com/your-org/go-backend-template/internal/app/server/service/role"
 	userService "github.com/your-org/go-backend-template/internal/app/server/service/user"
 	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
-	"github.com/your-org/go-backend-template/internal/pkg/repository/postgres"
+	"github.com/your-org/go-backend-template/internal/pkg/health"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/metrics"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 const (
-	prefixAPI = "/api"
+	prefixAPI   = "/api"
+	pathMetrics = "/metrics"
+	pathLivez   = "/livez"
+	pathReadyz  = "/readyz"
+	pathHealth  = "/health"
 )
 
 // Config holds server configuration.
@@ -25,6 +53,56 @@ type Config struct {
 	Port             int
 	Mode             string   // debug, release, test
 	CORSAllowOrigins []string // allowed CORS origins
+	TrustedProxies   []string // IPs or CIDRs of proxies whose X-Forwarded-For is trusted; none by default
+
+	MetricsEnabled bool // serve Prometheus metrics on /metrics
+	MetricsPort    int  // serve /metrics on this admin port instead of Port; 0 uses Port
+
+	RefreshTokenDuration time.Duration // lifetime of refresh tokens
+	RevocationCacheTTL   time.Duration // how long token revocation state is cached
+	PermissionCacheTTL   time.Duration // how long the permissions of roles are cached; 0 disables the cache
+
+	PasswordResetTokenDuration     time.Duration // lifetime of password reset tokens
+	PasswordResetURL               string        // page that completes a password reset
+	EmailVerificationTokenDuration time.Duration // lifetime of email verification tokens
+	EmailVerificationURL           string        // page that completes an email verification
+	RequireVerifiedEmail           bool          // reject logins of users with an unverified email
+	EmailRateLimit                 int           // password reset and email verification requests per client IP and window; 0 disables the limit
+	EmailRateWindow                time.Duration // window of EmailRateLimit
+
+	RegistrationMode           string        // disabled, open, invite or domain
+	RegistrationAllowedDomains []string      // email domains that may register in domain mode
+	RegistrationRole           string        // role of self-registered users: user or viewer
+	RegistrationRateLimit      int           // registrations allowed per client IP and window; 0 disables the limit
+	RegistrationRateWindow     time.Duration // window of RegistrationRateLimit
+	InvitationTokenDuration    time.Duration // lifetime of invitations
+	InvitationURL              string        // page that completes an invited registration
+
+	LoginRateLimit         int           // login attempts allowed per client IP and window; 0 disables the limit
+	LoginRateWindow        time.Duration // window of LoginRateLimit
+	MaxFailedLoginAttempts int           // consecutive failed logins before a user is locked; 0 disables lockout
+	LockoutDuration        time.Duration // first lockout, doubled for every further failure
+	MaxLockoutDuration     time.Duration // upper bound of the lockout
+
+	MFAIssuer            string        // issuer shown by authenticator apps
+	MFARequiredRoles     []string      // roles that must use a second factor
+	MFAChallengeDuration time.Duration // time to complete the second step of a login
+	MFARateLimit         int           // second-step requests allowed per client IP and window; 0 disables the limit
+	MFARateWindow        time.Duration // window of MFARateLimit
+
+	WebAuthnRPID              string        // domain that scopes passkeys, e.g. example.com
+	WebAuthnRPName            string        // service name shown by authenticators
+	WebAuthnOrigins           []string      // origins of the web apps that use passkeys
+	WebAuthnChallengeDuration time.Duration // time to complete a passkey registration or login
+	PasskeyRateLimit          int           // passkey login requests allowed per client IP and window; 0 disables the limit
+	PasskeyRateWindow         time.Duration // window of PasskeyRateLimit
+
+	APIKeyMaxPerUser int // active API keys a user may have
+
+	RequestTimeout time.Duration // default deadline for every request; 0 disables it
+
+	ShutdownTimeout time.Duration // max time to drain in-flight requests and run shutdown hooks
+	ShutdownDelay   time.Duration // time between failing readiness and closing listeners
 }
 
 // Validate checks if the configuration is valid.
@@ -32,14 +110,42 @@ func (c *Config) Validate() error {
 	if c.Port <= 0 || c.Port > 65535 {
 		return errors.New("invalid port")
 	}
+	if c.MetricsPort < 0 || c.MetricsPort > 65535 {
+		return errors.New("invalid metrics port")
+	}
+	if c.MetricsEnabled && c.MetricsPort == c.Port {
+		return errors.New("metrics port must differ from the server port")
+	}
 	return nil
 }
 
+// Repository is the data access the server needs from a storage backend.
+// It is implemented by postgres.Repository, sqlite.Repository and memory.Repository.
+type Repository interface {
+	userService.IUserRepository
+	authService.IUserRepository
+	authService.IRefreshTokenRepository
+	authService.IRevokedTokenRepository
+	accountService.IUserTokenRepository
+	accountService.IInvitationRepository
+	mfaService.IMFARepository
+	passkeyService.IPasskeyRepository
+	apiKeyService.IAPIKeyRepository
+	roleService.IRoleRepository
+	Close() error
+}
+
 // Dependencies holds all external dependencies for the server.
 type Dependencies struct {
-	Repository     *postgres.Repository
+	Repository     Repository
+	TxManager      userService.ITxManager
 	JWTService     *pkgAuth.JWTService
 	PasswordHasher *pkgAuth.PasswordHasher
+	Mailer         mailer.Mailer
+	Logger         *slog.Logger             // optional; defaults to slog.Default()
+	Metrics        *prometheus.Registry     // optional; defaults to metrics.NewRegistry()
+	TracerProvider *sdktrace.TracerProvider // optional; requests are not traced when nil
+	Health         *health.Registry         // optional readiness checks reported by /readyz
 }
 
 // Validate checks if all required dependencies are provided.
@@ -47,21 +153,45 @@ func (d *Dependencies) Validate() error {
 	if d.Repository == nil {
 		return errors.New("repository is nil")
 	}
+	if d.TxManager == nil {
+		return errors.New("tx manager is nil")
+	}
 	if d.JWTService == nil {
 		return errors.New("jwt service is nil")
 	}
 	if d.PasswordHasher == nil {
 		return errors.New("password hasher is nil")
 	}
+	if d.Mailer == nil {
+		return errors.New("mailer is nil")
+	}
 	return nil
 }
 
+// ShutdownHook releases a component when the server shuts down.
+type ShutdownHook struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
 // Server represents the HTTP server.
 type Server struct {
 	config         *Config
 	router         *gin.Engine
+	httpServer     *http.Server
+	adminServer    *http.Server // serves /metrics on MetricsPort; nil when served on the main port
+	metrics        *prometheus.Registry
+	health         *health.Registry // readiness checks; nil has none
 	handlers       *routes.Handlers
 	authMiddleware *auth.Middleware
+	rateLimits     routes.RateLimits
+	logger         *slog.Logger
+
+	ready         atomic.Bool // reported by /readyz; false before Run and while draining
+	hooksMu       sync.Mutex
+	shutdownHooks []ShutdownHook
+	shutdownOnce  sync.Once
+	shutdownErr   error
 }
 
 // New creates a new Server with the given configuration and dependencies.
@@ -79,6 +209,17 @@ func New(config *Config, deps *Dependencies) (*Server, error) {
 		return nil, fmt.Errorf("invalid dependencies: %w", err)
 	}
 
+	logger := deps.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	registry := deps.Metrics
+	if registry == nil {
+		registry = metrics.NewRegistry()
+	}
+	authMetrics := pkgAuth.NewMetrics(registry)
+
 	// Set Gin mode
 	switch config.Mode {
 	case "debug":
@@ -88,31 +229,119 @@ func New(config *Config, deps *Dependencies) (*Server, error) {
 	case "test":
 		gin.SetMode(gin.TestMode)
 	default:
-		log.Printf("unknown server mode: %s, using debug mode\n", config.Mode)
+		logger.Warn("unknown server mode, using debug mode", "mode", config.Mode)
 		gin.SetMode(gin.DebugMode)
 	}
 
-	// Initialize auth middleware
-	authMiddleware, err := auth.New(deps.JWTService)
+	// Initialize role service; it drops the permissions the auth middleware cached for a changed role
+	permissionCache := auth.NewPermissionCache(config.PermissionCacheTTL)
+	roleSvc, err := roleService.NewService(deps.Repository, permissionCache, deps.TxManager)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init auth middleware: %w", err)
+		return nil, fmt.Errorf("failed to init role service: %w", err)
 	}
 
 	// Initialize user service
-	userSvc, err := userService.NewService(deps.Repository, deps.PasswordHasher)
+	userSvc, err := userService.NewService(deps.Repository, deps.Repository, deps.Repository, deps.TxManager, deps.PasswordHasher, userService.Config{
+		RequireVerifiedEmail:   config.RequireVerifiedEmail,
+		MaxFailedLoginAttempts: config.MaxFailedLoginAttempts,
+		LockoutDuration:        config.LockoutDuration,
+		MaxLockoutDuration:     config.MaxLockoutDuration,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to init user service: %w", err)
 	}
 
+	// Initialize auth service
+	authSvc, err := authService.NewService(deps.Repository, deps.Repository, deps.Repository, deps.TxManager, deps.JWTService, authService.Config{
+		RefreshTokenDuration: config.RefreshTokenDuration,
+		RevocationCacheTTL:   config.RevocationCacheTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init auth service: %w", err)
+	}
+
+	// Initialize account service
+	accountSvc, err := accountService.NewService(deps.Repository, deps.Repository, deps.Repository, deps.Repository, deps.TxManager, deps.PasswordHasher, deps.Mailer, accountService.Config{
+		PasswordResetTokenDuration:     config.PasswordResetTokenDuration,
+		PasswordResetURL:               config.PasswordResetURL,
+		EmailVerificationTokenDuration: config.EmailVerificationTokenDuration,
+		EmailVerificationURL:           config.EmailVerificationURL,
+		RegistrationMode:               config.RegistrationMode,
+		RegistrationAllowedDomains:     config.RegistrationAllowedDomains,
+		RegistrationRole:               config.RegistrationRole,
+		InvitationTokenDuration:        config.InvitationTokenDuration,
+		InvitationURL:                  config.InvitationURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init account service: %w", err)
+	}
+
+	// Initialize MFA service
+	mfaSvc, err := mfaService.NewService(deps.Repository, deps.Repository, deps.Repository, deps.TxManager, mfaService.Config{
+		Issuer:            config.MFAIssuer,
+		RequiredRoles:     config.MFARequiredRoles,
+		ChallengeDuration: config.MFAChallengeDuration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init mfa service: %w", err)
+	}
+
+	// Initialize passkey service
+	passkeySvc, err := passkeyService.NewService(deps.Repository, deps.Repository, deps.TxManager, passkeyService.Config{
+		RPID:                 config.WebAuthnRPID,
+		RPName:               config.WebAuthnRPName,
+		Origins:              config.WebAuthnOrigins,
+		ChallengeDuration:    config.WebAuthnChallengeDuration,
+		RequireVerifiedEmail: config.RequireVerifiedEmail,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init passkey service: %w", err)
+	}
+
+	// Initialize API key service
+	apiKeySvc, err := apiKeyService.NewService(deps.Repository, deps.Repository, deps.TxManager, apiKeyService.Config{
+		MaxKeysPerUser: config.APIKeyMaxPerUser,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init api key service: %w", err)
+	}
+
+	// Initialize auth middleware
+	authMiddleware, err := auth.New(deps.JWTService,
+		auth.WithRevocationChecker(authSvc),
+		auth.WithAPIKeyAuthenticator(apiKeySvc),
+		auth.WithPermissionResolver(roleSvc),
+		auth.WithPermissionCache(permissionCache),
+		auth.WithTokenFailureRecorder(authMetrics),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init auth middleware: %w", err)
+	}
+
 	// Initialize handlers
-	userH := userHandler.NewHandler(userSvc, deps.JWTService)
+	authH := authHandler.NewHandler(userSvc, authSvc, accountSvc, mfaSvc, passkeySvc, deps.JWTService, authMetrics)
+	userH := userHandler.NewHandler(userSvc, authSvc, accountSvc, mfaSvc, passkeySvc, apiKeySvc)
+	roleH := roleHandler.NewHandler(roleSvc)
 
 	handlers := &routes.Handlers{
+		Auth: authH,
 		User: userH,
+		Role: roleH,
 	}
 
-	// Setup Gin router
-	router := gin.Default()
+	// Setup Gin router; requests are logged with their correlation ID instead of by gin's text logger.
+	// Metrics and traces are recorded outside of Recovery so that panics count as 500s.
+	router := gin.New()
+	// The client IP, which rate limits are keyed by, comes from X-Forwarded-For only behind a
+	// trusted proxy; otherwise any client could pick a fresh IP per request.
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.Use(requestlog.New(logger))
+	if deps.TracerProvider != nil {
+		router.Use(httptracing.New(deps.TracerProvider)...)
+	}
+	router.Use(httpmetrics.New(registry), requestlog.Recovery())
 
 	// Configure CORS
 	allowOrigins := config.CORSAllowOrigins
@@ -120,27 +349,80 @@ func New(config *Config, deps *Dependencies) (*Server, error) {
 		allowOrigins = []string{"*"} // default to allow all (change in production)
 	}
 
+	// Browsers may send the W3C trace context headers to continue their trace
+	allowHeaders := []string{"Origin", "Content-Type", "Authorization", requestlog.HeaderRequestId}
+	allowHeaders = append(allowHeaders, propagation.TraceContext{}.Fields()...)
+
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     allowOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     allowHeaders,
+		ExposeHeaders:    []string{requestlog.HeaderRequestId},
 		AllowCredentials: true,
 	}))
 
-	return &Server{
-		config:         config,
-		router:         router,
+	// Bound every request; routes may set a shorter deadline with timeout.New
+	router.Use(timeout.New(config.RequestTimeout))
+
+	s := &Server{
+		config: config,
+		router: router,
+		httpServer: &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", config.Host, config.Port),
+			Handler: router,
+		},
 		handlers:       handlers,
 		authMiddleware: authMiddleware,
-	}, nil
+		metrics:        registry,
+		health:         deps.Health,
+		logger:         logger,
+		rateLimits: routes.RateLimits{
+			Register: ratelimit.New(config.RegistrationRateLimit, config.RegistrationRateWindow),
+			Login:    ratelimit.New(config.LoginRateLimit, config.LoginRateWindow),
+			MFA:      ratelimit.New(config.MFARateLimit, config.MFARateWindow),
+			Passkey:  ratelimit.New(config.PasskeyRateLimit, config.PasskeyRateWindow),
+			Email:    ratelimit.New(config.EmailRateLimit, config.EmailRateWindow),
+		},
+	}
+
+	// A separate admin port keeps metrics off the public listener
+	if config.MetricsEnabled && config.MetricsPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle(pathMetrics, metrics.Handler(registry))
+		s.adminServer = &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", config.Host, config.MetricsPort),
+			Handler: mux,
+		}
+	}
+
+	// Registered first so it is closed last
+	s.OnShutdown("database", func(ctx context.Context) error {
+		return deps.Repository.Close()
+	})
+	// Finish sending emails while the database is still open
+	s.OnShutdown("account", accountSvc.Wait)
+	// Flush buffered spans
+	if deps.TracerProvider != nil {
+		s.OnShutdown("tracing", deps.TracerProvider.Shutdown)
+	}
+
+	return s, nil
 }
 
 // SetupRoutes configures all routes.
 func (s *Server) SetupRoutes() {
-	// Health check endpoint
-	s.router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Health probes; /health is kept as an alias of /readyz for existing probes
+	s.router.GET(pathLivez, s.livez)
+	s.router.GET(pathReadyz, s.readyz)
+	s.router.GET(pathHealth, s.readyz)
+
+	// Prometheus metrics, unless served on the admin port
+	if s.config.MetricsEnabled && s.adminServer == nil {
+		s.router.GET(pathMetrics, gin.WrapH(metrics.Handler(s.metrics)))
+	}
+
+	// Public key set for verifying access tokens
+	routes.SetupWellKnownRoutes(s.router, s.handlers.Auth)
 
 	// API routes
 	apiRoutes := s.router.Group(prefixAPI)
@@ -151,14 +433,137 @@ func (s *Server) SetupRoutes() {
 		})
 	})
 
-	routes.SetupRoutes(apiRoutes, s.handlers, s.authMiddleware)
+	routes.SetupRoutes(apiRoutes, s.handlers, s.authMiddleware, s.rateLimits)
+}
+
+// livez reports that the process is up and serving requests. It runs no dependency checks:
+// restarting the process does not fix an unavailable database, so those only fail readiness.
+func (s *Server) livez(c *gin.Context) {
+	s.writeHealthReport(c, &health.Report{Status: health.StatusOK})
+}
+
+// readyz reports whether the server should receive traffic. It fails while the server
+// is not running or is draining, and when a readiness check fails.
+func (s *Server) readyz(c *gin.Context) {
+	if !s.ready.Load() {
+		s.writeHealthReport(c, &health.Report{
+			Status: health.StatusFail,
+			Checks: []health.Result{{Name: "shutdown", Status: health.StatusFail, Error: "shutting down"}},
+		})
+		return
+	}
+	s.writeHealthReport(c, s.health.Run(c.Request.Context()))
 }
 
-// Run starts the HTTP server.
+// writeHealthReport responds 200 or 503. Per-check details may contain internal
+// error messages, so they are only included in debug mode.
+func (s *Server) writeHealthReport(c *gin.Context, report *health.Report) {
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	if s.config.Mode != gin.DebugMode {
+		report = &health.Report{Status: report.Status}
+	}
+	c.JSON(status, report)
+}
+
+// Run starts the HTTP server and blocks until it stops.
+// It returns nil once Shutdown has been called.
 func (s *Server) Run() error {
-	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
-	log.Printf("Starting server on %s\n", addr)
-	return s.router.Run(addr)
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	if s.adminServer != nil {
+		adminListener, err := net.Listen("tcp", s.adminServer.Addr)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+
+		s.logger.Info("starting admin server", "addr", s.adminServer.Addr)
+		go func() {
+			if err := s.adminServer.Serve(adminListener); !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("admin server failed", "error", err)
+			}
+		}()
+	}
+
+	s.logger.Info("starting server", "addr", s.httpServer.Addr)
+	return s.serve(listener)
+}
+
+func (s *Server) serve(listener net.Listener) error {
+	s.ready.Store(true)
+	defer s.ready.Store(false)
+
+	if err := s.httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// OnShutdown registers a hook to run after in-flight requests have drained.
+// Hooks run in reverse registration order, so components should be registered
+// after the components they depend on.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, ShutdownHook{Name: name, Fn: fn})
+}
+
+// Shutdown gracefully stops the server: it fails readiness, waits ShutdownDelay,
+// drains in-flight requests and then runs the shutdown hooks.
+// The whole sequence is bounded by ShutdownTimeout. Later calls return the first result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		s.shutdownErr = s.shutdown(ctx)
+	})
+	return s.shutdownErr
+}
+
+func (s *Server) shutdown(ctx context.Context) error {
+	if s.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ShutdownTimeout)
+		defer cancel()
+	}
+
+	// 1. Stop receiving new traffic from load balancers
+	s.ready.Store(false)
+	if s.config.ShutdownDelay > 0 {
+		select {
+		case <-time.After(s.config.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	// 2. Close listeners and wait for in-flight requests
+	var errs []error
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain http server: %w", err))
+	}
+	// Metrics stay available while draining
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drain admin server: %w", err))
+		}
+	}
+
+	// 3. Release components, newest first
+	s.hooksMu.Lock()
+	hooks := append([]ShutdownHook(nil), s.shutdownHooks...)
+	s.hooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].Fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %s: %w", hooks[i].Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 // Router returns the underlying gin router (useful for testing).
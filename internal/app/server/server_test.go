@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/health"
+)
+
+// ========== Test Helpers ==========
+
+// newTestServer starts a server with only the health probes and the given extra routes.
+func newTestServer(t *testing.T, config *Config, setup func(r *gin.Engine)) (*Server, string, <-chan error) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	s := &Server{
+		config:     config,
+		router:     router,
+		httpServer: &http.Server{Handler: router},
+	}
+	router.GET(pathLivez, s.livez)
+	router.GET(pathReadyz, s.readyz)
+	if setup != nil {
+		setup(router)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.serve(listener) }()
+
+	return s, "http://" + listener.Addr().String(), done
+}
+
+// ========== Health ==========
+
+func TestServer_Readyz_FailingCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		wantDetails bool
+	}{
+		{name: "debug mode shows details", mode: gin.DebugMode, wantDetails: true},
+		{name: "release mode hides details", mode: gin.ReleaseMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, baseURL, _ := newTestServer(t, &Config{Mode: tt.mode}, nil)
+			defer s.Shutdown(context.Background())
+			s.health = health.NewRegistry(time.Second)
+			s.health.Register("database", func(ctx context.Context) error { return errors.New("connection refused") })
+
+			resp, err := http.Get(baseURL + pathReadyz)
+			if err != nil {
+				t.Fatalf("Failed to get %s: %v", pathReadyz, err)
+			}
+			defer resp.Body.Close()
+
+			var report health.Report
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				t.Fatalf("Failed to decode report: %v", err)
+			}
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			assert.Equal(t, health.StatusFail, report.Status)
+			if tt.wantDetails {
+				if assert.Len(t, report.Checks, 1) {
+					assert.Equal(t, "database", report.Checks[0].Name)
+					assert.Equal(t, "connection refused", report.Checks[0].Error)
+				}
+			} else {
+				assert.Empty(t, report.Checks)
+			}
+
+			// Liveness does not depend on the database
+			live, err := http.Get(baseURL + pathLivez)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, live.StatusCode)
+			live.Body.Close()
+		})
+	}
+}
+
+// ========== Shutdown ==========
+
+func TestServer_Shutdown_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	s, baseURL, done := newTestServer(t, &Config{ShutdownTimeout: 5 * time.Second}, func(r *gin.Engine) {
+		r.GET("/slow", func(c *gin.Context) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			c.String(http.StatusOK, "finished")
+		})
+	})
+
+	type result struct {
+		body string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			resultCh <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		resultCh <- result{body: string(body)}
+	}()
+
+	<-started
+	err := s.Shutdown(context.Background())
+
+	assert.NoError(t, err)
+	res := <-resultCh
+	assert.NoError(t, res.err)
+	assert.Equal(t, "finished", res.body)
+	assert.NoError(t, <-done)
+}
+
+func TestServer_Shutdown_FailsReadinessFirst(t *testing.T) {
+	s, baseURL, _ := newTestServer(t, &Config{ShutdownDelay: 300 * time.Millisecond}, nil)
+
+	resp, err := http.Get(baseURL + pathReadyz)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- s.Shutdown(context.Background()) }()
+
+	// During the delay the listener is still open but readiness fails
+	assert.Eventually(t, func() bool { return !s.ready.Load() }, time.Second, 10*time.Millisecond)
+	resp, err = http.Get(baseURL + pathReadyz)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+
+	// The process is still alive
+	resp, err = http.Get(baseURL + pathLivez)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	assert.NoError(t, <-shutdownDone)
+}
+
+func TestServer_Shutdown_RunsHooksInReverseOrder(t *testing.T) {
+	s, _, _ := newTestServer(t, &Config{}, nil)
+
+	var order []string
+	s.OnShutdown("database", func(ctx context.Context) error {
+		order = append(order, "database")
+		return nil
+	})
+	s.OnShutdown("cache", func(ctx context.Context) error {
+		order = append(order, "cache")
+		return errors.New("flush failed")
+	})
+	s.OnShutdown("worker", func(ctx context.Context) error {
+		order = append(order, "worker")
+		return nil
+	})
+
+	err := s.Shutdown(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "shutdown hook cache: flush failed")
+	assert.Equal(t, []string{"worker", "cache", "database"}, order)
+
+	// Shutdown is idempotent
+	assert.Equal(t, err, s.Shutdown(context.Background()))
+	assert.Len(t, order, 3)
+}
+
+func TestServer_Shutdown_Timeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	s, baseURL, _ := newTestServer(t, &Config{ShutdownTimeout: 100 * time.Millisecond}, func(r *gin.Engine) {
+		r.GET("/stuck", func(c *gin.Context) {
+			close(started)
+			<-release
+		})
+	})
+
+	go http.Get(baseURL + "/stuck")
+	<-started
+
+	err := s.Shutdown(context.Background())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,61 @@
+package account
+
+import (
+	"context"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+)
+
+// ========== Service Dependencies ==========
+// Interfaces that the account service depends on (injected from outside)
+
+// IUserRepository defines the user data access needed by the account service.
+type IUserRepository interface {
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	ExistsUserByEmail(ctx context.Context, email string) (bool, error)
+	InsertUser(ctx context.Context, user *entity.User) (int, error)
+	UpdateUser(ctx context.Context, user *entity.User) error
+	UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error
+	IncrementUserTokenVersion(ctx context.Context, id int) error
+}
+
+// ITokenRepository defines the interface for refresh token access.
+type ITokenRepository interface {
+	RevokeUserRefreshTokens(ctx context.Context, userId int) error
+}
+
+// IUserTokenRepository defines the interface for single-use user token data access.
+type IUserTokenRepository interface {
+	InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error)
+	GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error)
+	ConsumeUserToken(ctx context.Context, id int) error
+	DeleteUserTokens(ctx context.Context, userId int, purpose string) error
+	DeleteExpiredUserTokens(ctx context.Context) error
+}
+
+// IInvitationRepository defines the interface for invitation data access.
+type IInvitationRepository interface {
+	InsertInvitation(ctx context.Context, invitation *entity.Invitation) (int, error)
+	GetInvitationByHash(ctx context.Context, tokenHash string) (*entity.Invitation, error)
+	ConsumeInvitation(ctx context.Context, id int) error
+	DeleteInvitations(ctx context.Context, email string) error
+	DeleteExpiredInvitations(ctx context.Context) error
+}
+
+// ITxManager runs a function inside a database transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// IPasswordHasher defines the interface for password hashing.
+type IPasswordHasher interface {
+	Hash(password string) (string, error)
+}
+
+// IMailer defines the interface for sending email.
+type IMailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
+}
@@ -0,0 +1,25 @@
+package account
+
+// ========== Reset Password ==========
+
+type ResetPasswordInput struct {
+	Token       string
+	NewPassword string
+}
+
+// ========== Register ==========
+
+type RegisterInput struct {
+	Email           string
+	Username        string
+	Password        string
+	Name            string
+	InvitationToken string // optional; required in invite mode
+}
+
+// ========== Invite User ==========
+
+type InviteUserInput struct {
+	Email string
+	Role  string // user or viewer; defaults to the registration role
+}
@@ -0,0 +1,224 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Register ==========
+
+// Register creates a self-registered user and returns the user ID. The registration policy
+// picks the role; self-registered users are never admins.
+//
+// An invitation is consumed in the same transaction that creates the user, so that it admits
+// exactly one user, and marks the email verified since the invitation was delivered to it.
+// Without an invitation, callers should send a verification email (see SendEmailVerification).
+func (s *Service) Register(ctx context.Context, input *RegisterInput) (int, error) {
+	role, invitation, err := s.checkRegistration(ctx, input.Email, input.InvitationToken)
+	if err != nil {
+		return 0, err
+	}
+
+	// Hash password outside the transaction; hashing is slow and would hold it open
+	hashedPassword, err := s.passwordHasher.Hash(input.Password)
+	if err != nil {
+		return 0, domain.InternalServerError{Msg: "failed to hash password", Err: err}
+	}
+
+	var userId int
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		user := &entity.User{
+			Email:    input.Email,
+			Username: input.Username,
+			Password: hashedPassword,
+			Name:     input.Name,
+			Role:     role,
+			IsActive: true,
+		}
+
+		if invitation != nil {
+			// Losing this race means a concurrent request accepted the invitation first
+			if err := s.invitationRepo.ConsumeInvitation(ctx, invitation.Id); err != nil {
+				if errors.Is(err, repository.ErrInvitationNotFound) {
+					return domain.InvalidTokenError{}
+				}
+				return domain.InternalServerError{Msg: "failed to consume invitation", Err: err}
+			}
+			verifiedAt := s.now()
+			user.EmailVerifiedAt = &verifiedAt
+		}
+
+		exists, err := s.userRepo.ExistsUserByEmail(ctx, input.Email)
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to check email existence", Err: err}
+		}
+		if exists {
+			return domain.UserAlreadyExistsError{Email: input.Email}
+		}
+
+		userId, err = s.userRepo.InsertUser(ctx, user)
+		if err != nil {
+			if errors.Is(err, repository.ErrDuplicateEmail) {
+				return domain.UserAlreadyExistsError{Email: input.Email}
+			}
+			return domain.InternalServerError{Msg: "failed to create user", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userId, nil
+}
+
+// checkRegistration reports whether the given email may self-register and returns the role
+// the new user gets, together with the invitation it registers with, if any. Invite mode
+// requires an invitation; in the other enabled modes an invitation is optional and admits
+// its email even if the domain is not allowed.
+func (s *Service) checkRegistration(ctx context.Context, email, invitationToken string) (string, *entity.Invitation, error) {
+	if s.registrationMode == RegistrationModeDisabled {
+		return "", nil, domain.ForbiddenError{Reason: "self-registration is disabled"}
+	}
+
+	if invitationToken != "" {
+		invitation, err := s.findInvitation(ctx, invitationToken)
+		if err != nil {
+			return "", nil, err
+		}
+		// An invitation is bound to the email it was sent to
+		if !strings.EqualFold(invitation.Email, email) {
+			return "", nil, domain.InvalidTokenError{}
+		}
+		return invitation.Role, invitation, nil
+	}
+
+	switch s.registrationMode {
+	case RegistrationModeInvite:
+		return "", nil, domain.ForbiddenError{Reason: "an invitation is required to register"}
+	case RegistrationModeDomain:
+		if !s.registrationAllowedDomains[emailDomain(email)] {
+			return "", nil, domain.ForbiddenError{Reason: "email domain is not allowed to register"}
+		}
+	}
+
+	return s.registrationRole, nil, nil
+}
+
+// ========== Invitations ==========
+
+// InviteUser emails an invitation to register. Inviting the same email again
+// invalidates the previous invitations.
+func (s *Service) InviteUser(ctx context.Context, input *InviteUserInput) error {
+	if s.registrationMode == RegistrationModeDisabled {
+		return domain.ForbiddenError{Reason: "self-registration is disabled"}
+	}
+
+	role := input.Role
+	if role == "" {
+		role = s.registrationRole
+	}
+	if !isRegistrationRole(role) {
+		return domain.InvalidRoleError{Role: role}
+	}
+
+	_, err := s.userRepo.GetUserByEmail(ctx, input.Email)
+	if err == nil {
+		return domain.UserAlreadyExistsError{Email: input.Email}
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+
+	token, err := pkgAuth.GenerateOpaqueToken()
+	if err != nil {
+		return domain.InternalServerError{Msg: "failed to generate token", Err: err}
+	}
+
+	expiresAt := s.now().Add(s.invitationTokenDuration)
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		// Opportunistically drop invitations that can no longer be accepted
+		if err := s.invitationRepo.DeleteExpiredInvitations(ctx); err != nil {
+			return domain.InternalServerError{Msg: "failed to clean up invitations", Err: err}
+		}
+
+		if err := s.invitationRepo.DeleteInvitations(ctx, input.Email); err != nil {
+			return domain.InternalServerError{Msg: "failed to delete invitations", Err: err}
+		}
+
+		_, err := s.invitationRepo.InsertInvitation(ctx, &entity.Invitation{
+			Email:     input.Email,
+			Role:      role,
+			TokenHash: pkgAuth.HashOpaqueToken(token),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to store invitation", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      input.Email,
+		Subject: "You are invited to create an account",
+		Body: fmt.Sprintf(
+			"You have been invited to create an account with this email address.\n\n"+
+				"Use the following link to register. It expires in %s and can be used once:\n\n"+
+				"%s\n\n"+
+				"If you were not expecting this, you can ignore this email.\n",
+			formatDuration(s.invitationTokenDuration), tokenLink(s.invitationURL, token),
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return domain.InternalServerError{Msg: "failed to send invitation email", Err: err}
+	}
+
+	return nil
+}
+
+// findInvitation returns the unused, unexpired invitation for the given token.
+func (s *Service) findInvitation(ctx context.Context, plainToken string) (*entity.Invitation, error) {
+	invitation, err := s.invitationRepo.GetInvitationByHash(ctx, pkgAuth.HashOpaqueToken(plainToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			return nil, domain.InvalidTokenError{}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get invitation", Err: err}
+	}
+	if invitation.IsUsed() || invitation.IsExpired(s.now()) {
+		return nil, domain.InvalidTokenError{}
+	}
+	return invitation, nil
+}
+
+// ========== Helpers ==========
+
+// isRegistrationRole reports whether self-registered users may get the role. Never admin.
+func isRegistrationRole(role string) bool {
+	return role == entity.RoleUser || role == entity.RoleViewer
+}
+
+// emailDomain returns the lowercased domain of an email address.
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return normalizeDomain(email[at+1:])
+}
+
+// normalizeDomain lowercases an allowlist entry and strips an optional leading "@".
+func normalizeDomain(d string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+}
@@ -0,0 +1,281 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+func invitation(email, role string) *entity.Invitation {
+	return &entity.Invitation{Id: 7, Email: email, Role: role, ExpiresAt: testNow.Add(time.Hour)}
+}
+
+// ========== Registration Policy Tests ==========
+
+func TestRegistrationPolicy_Policy(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		email    string
+		wantRole string
+		wantErr  error
+	}{
+		{
+			name:    "disabled by default",
+			config:  Config{},
+			email:   "new@example.com",
+			wantErr: domain.ForbiddenError{Reason: "self-registration is disabled"},
+		},
+		{
+			name:     "open",
+			config:   Config{RegistrationMode: RegistrationModeOpen},
+			email:    "new@example.com",
+			wantRole: entity.RoleUser,
+		},
+		{
+			name:     "open with viewer role",
+			config:   Config{RegistrationMode: RegistrationModeOpen, RegistrationRole: entity.RoleViewer},
+			email:    "new@example.com",
+			wantRole: entity.RoleViewer,
+		},
+		{
+			name:    "invite without invitation",
+			config:  Config{RegistrationMode: RegistrationModeInvite},
+			email:   "new@example.com",
+			wantErr: domain.ForbiddenError{Reason: "an invitation is required to register"},
+		},
+		{
+			name:     "allowed domain",
+			config:   Config{RegistrationMode: RegistrationModeDomain, RegistrationAllowedDomains: []string{"@Example.com"}},
+			email:    "new@EXAMPLE.com",
+			wantRole: entity.RoleUser,
+		},
+		{
+			name:    "subdomain is not allowed",
+			config:  Config{RegistrationMode: RegistrationModeDomain, RegistrationAllowedDomains: []string{"example.com"}},
+			email:   "new@mail.example.com",
+			wantErr: domain.ForbiddenError{Reason: "email domain is not allowed to register"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := setupTestService(tt.config)
+
+			role, _, err := svc.checkRegistration(context.Background(), tt.email, "")
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantRole, role)
+		})
+	}
+}
+
+func TestRegistrationPolicy_Invitation(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeDomain, RegistrationAllowedDomains: []string{"example.com"}})
+	deps.invitationRepo.On("GetInvitationByHash", pkgAuth.HashOpaqueToken("invite-token")).
+		Return(invitation("guest@partner.org", entity.RoleViewer), nil)
+
+	// The invitation admits its email outside the allowed domains, with the invited role
+	role, invited, err := svc.checkRegistration(context.Background(), "Guest@partner.org", "invite-token")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.RoleViewer, role)
+	assert.Equal(t, 7, invited.Id)
+
+	// ...but nobody else
+	_, _, err = svc.checkRegistration(context.Background(), "someone@partner.org", "invite-token")
+	assert.IsType(t, domain.InvalidTokenError{}, err)
+
+	deps.invitationRepo.AssertNotCalled(t, "ConsumeInvitation", mock.Anything)
+}
+
+func TestRegistrationPolicy_InvalidInvitation(t *testing.T) {
+	usedAt := testNow.Add(-time.Minute)
+	used := invitation("new@example.com", entity.RoleUser)
+	used.UsedAt = &usedAt
+	expired := invitation("new@example.com", entity.RoleUser)
+	expired.ExpiresAt = testNow
+
+	tests := []struct {
+		name       string
+		invitation *entity.Invitation
+		err        error
+	}{
+		{"unknown", nil, repository.ErrInvitationNotFound},
+		{"used", used, nil},
+		{"expired", expired, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeInvite})
+			deps.invitationRepo.On("GetInvitationByHash", mock.Anything).Return(tt.invitation, tt.err)
+
+			_, _, err := svc.checkRegistration(context.Background(), "new@example.com", "invite-token")
+
+			assert.IsType(t, domain.InvalidTokenError{}, err)
+		})
+	}
+}
+
+// ========== InviteUser Tests ==========
+
+func TestInviteUser_SendsInvitation(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeInvite, InvitationURL: "https://app.example.com/register"})
+
+	var stored *entity.Invitation
+	deps.userRepo.On("GetUserByEmail", "new@example.com").Return(nil, repository.ErrUserNotFound)
+	deps.invitationRepo.On("DeleteExpiredInvitations").Return(nil)
+	deps.invitationRepo.On("DeleteInvitations", "new@example.com").Return(nil)
+	deps.invitationRepo.On("InsertInvitation", mock.AnythingOfType("*entity.Invitation")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*entity.Invitation) }).
+		Return(1, nil)
+
+	err := svc.InviteUser(context.Background(), &InviteUserInput{Email: "new@example.com"})
+
+	assert.NoError(t, err)
+	deps.invitationRepo.AssertExpectations(t)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, entity.RoleUser, stored.Role)
+		assert.Equal(t, testNow.Add(defaultInvitationTokenDuration), stored.ExpiresAt)
+	}
+	if assert.Len(t, deps.mailer.sent, 1) {
+		assert.Equal(t, "new@example.com", deps.mailer.sent[0].To)
+		assert.Contains(t, deps.mailer.sent[0].Body, "https://app.example.com/register?token=")
+		assert.Contains(t, deps.mailer.sent[0].Body, "expires in 168 hours ")
+	}
+}
+
+func TestInviteUser_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		input   *InviteUserInput
+		wantErr error
+	}{
+		{
+			name:    "registration disabled",
+			config:  Config{},
+			input:   &InviteUserInput{Email: "new@example.com"},
+			wantErr: domain.ForbiddenError{Reason: "self-registration is disabled"},
+		},
+		{
+			name:    "admin role",
+			config:  Config{RegistrationMode: RegistrationModeInvite},
+			input:   &InviteUserInput{Email: "new@example.com", Role: entity.RoleAdmin},
+			wantErr: domain.InvalidRoleError{Role: entity.RoleAdmin},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(tt.config)
+
+			err := svc.InviteUser(context.Background(), tt.input)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Empty(t, deps.mailer.sent)
+		})
+	}
+}
+
+func TestInviteUser_ExistingUser(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeInvite})
+	deps.userRepo.On("GetUserByEmail", "test@example.com").Return(activeUser(), nil)
+
+	err := svc.InviteUser(context.Background(), &InviteUserInput{Email: "test@example.com"})
+
+	assert.IsType(t, domain.UserAlreadyExistsError{}, err)
+	deps.invitationRepo.AssertNotCalled(t, "InsertInvitation", mock.Anything)
+}
+
+// ========== Register Tests ==========
+
+func registerInput(invitationToken string) *RegisterInput {
+	return &RegisterInput{
+		Email:           "new@example.com",
+		Username:        "newuser",
+		Password:        "password123",
+		Name:            "New User",
+		InvitationToken: invitationToken,
+	}
+}
+
+func TestRegister_Open(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeOpen, RegistrationRole: entity.RoleViewer})
+	deps.hasher.On("Hash", "password123").Return("hashed", nil)
+	deps.userRepo.On("ExistsUserByEmail", "new@example.com").Return(false, nil)
+	deps.userRepo.On("InsertUser", mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == "new@example.com" && u.Password == "hashed" && u.Role == entity.RoleViewer &&
+			u.IsActive && u.EmailVerifiedAt == nil
+	})).Return(5, nil)
+
+	userId, err := svc.Register(context.Background(), registerInput(""))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, userId)
+	deps.userRepo.AssertExpectations(t)
+}
+
+func TestRegister_Invitation(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeInvite})
+	deps.invitationRepo.On("GetInvitationByHash", pkgAuth.HashOpaqueToken("invite-token")).
+		Return(invitation("new@example.com", entity.RoleViewer), nil)
+	deps.hasher.On("Hash", "password123").Return("hashed", nil)
+	deps.invitationRepo.On("ConsumeInvitation", 7).Return(nil)
+	deps.userRepo.On("ExistsUserByEmail", "new@example.com").Return(false, nil)
+	// The invitation was delivered to the email, which proves it
+	deps.userRepo.On("InsertUser", mock.MatchedBy(func(u *entity.User) bool {
+		return u.Role == entity.RoleViewer && u.EmailVerifiedAt != nil && u.EmailVerifiedAt.Equal(testNow)
+	})).Return(5, nil)
+
+	userId, err := svc.Register(context.Background(), registerInput("invite-token"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, userId)
+	assert.Equal(t, 1, deps.txManager.calls)
+	deps.invitationRepo.AssertExpectations(t)
+	deps.userRepo.AssertExpectations(t)
+}
+
+func TestRegister_InvitationConcurrentlyAccepted(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeInvite})
+	deps.invitationRepo.On("GetInvitationByHash", mock.Anything).Return(invitation("new@example.com", entity.RoleUser), nil)
+	deps.hasher.On("Hash", "password123").Return("hashed", nil)
+	deps.invitationRepo.On("ConsumeInvitation", 7).Return(repository.ErrInvitationNotFound)
+
+	_, err := svc.Register(context.Background(), registerInput("invite-token"))
+
+	assert.IsType(t, domain.InvalidTokenError{}, err)
+	deps.userRepo.AssertNotCalled(t, "InsertUser", mock.Anything)
+}
+
+func TestRegister_EmailTaken(t *testing.T) {
+	svc, deps := setupTestService(Config{RegistrationMode: RegistrationModeInvite})
+	deps.invitationRepo.On("GetInvitationByHash", mock.Anything).Return(invitation("new@example.com", entity.RoleUser), nil)
+	deps.hasher.On("Hash", "password123").Return("hashed", nil)
+	deps.invitationRepo.On("ConsumeInvitation", 7).Return(nil)
+	deps.userRepo.On("ExistsUserByEmail", "new@example.com").Return(true, nil)
+
+	// The error rolls back the transaction, so the invitation stays usable
+	_, err := svc.Register(context.Background(), registerInput("invite-token"))
+
+	assert.Equal(t, domain.UserAlreadyExistsError{Email: "new@example.com"}, err)
+	deps.userRepo.AssertNotCalled(t, "InsertUser", mock.Anything)
+}
+
+func TestRegister_Rejected(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+
+	_, err := svc.Register(context.Background(), registerInput(""))
+
+	assert.Equal(t, domain.ForbiddenError{Reason: "self-registration is disabled"}, err)
+	deps.hasher.AssertNotCalled(t, "Hash", mock.Anything)
+	assert.Equal(t, 0, deps.txManager.calls)
+}
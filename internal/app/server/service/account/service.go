@@ -0,0 +1,421 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+var (
+	errNilUserRepository       = errors.New("user repository is nil")
+	errNilUserTokenRepository  = errors.New("user token repository is nil")
+	errNilInvitationRepository = errors.New("invitation repository is nil")
+	errNilTokenRepository      = errors.New("token repository is nil")
+	errNilTxManager            = errors.New("transaction manager is nil")
+	errNilPasswordHasher       = errors.New("password hasher is nil")
+	errNilMailer               = errors.New("mailer is nil")
+)
+
+const (
+	defaultPasswordResetTokenDuration     = time.Hour
+	defaultEmailVerificationTokenDuration = 24 * time.Hour
+	defaultInvitationTokenDuration        = 7 * 24 * time.Hour
+)
+
+// Self-registration modes
+const (
+	RegistrationModeDisabled = "disabled" // only admins create users
+	RegistrationModeOpen     = "open"     // anyone can register
+	RegistrationModeInvite   = "invite"   // an invitation sent by an admin is required
+	RegistrationModeDomain   = "domain"   // the email domain must be in the allowlist
+)
+
+// Config holds account service configuration.
+//
+// The URLs are the pages that complete each workflow; the token is added as the "token"
+// query parameter. If a URL is empty, the email contains the bare token.
+type Config struct {
+	PasswordResetTokenDuration     time.Duration
+	PasswordResetURL               string
+	EmailVerificationTokenDuration time.Duration
+	EmailVerificationURL           string
+
+	// RegistrationMode is one of the RegistrationMode constants; defaults to disabled.
+	RegistrationMode string
+	// RegistrationAllowedDomains lists the email domains that may register in domain mode.
+	RegistrationAllowedDomains []string
+	// RegistrationRole is the role of self-registered users: user (default) or viewer.
+	RegistrationRole string
+
+	InvitationTokenDuration time.Duration
+	InvitationURL           string
+}
+
+// Service handles self-service account workflows that are driven by emailed tokens.
+type Service struct {
+	userRepo                       IUserRepository
+	userTokenRepo                  IUserTokenRepository
+	invitationRepo                 IInvitationRepository
+	tokenRepo                      ITokenRepository
+	txManager                      ITxManager
+	passwordHasher                 IPasswordHasher
+	mailer                         IMailer
+	passwordResetTokenDuration     time.Duration
+	passwordResetURL               string
+	emailVerificationTokenDuration time.Duration
+	emailVerificationURL           string
+	registrationMode               string
+	registrationAllowedDomains     map[string]bool
+	registrationRole               string
+	invitationTokenDuration        time.Duration
+	invitationURL                  string
+	now                            func() time.Time
+
+	// background tracks the work started by runInBackground
+	background sync.WaitGroup
+}
+
+// NewService creates a new account service.
+func NewService(
+	userRepo IUserRepository,
+	userTokenRepo IUserTokenRepository,
+	invitationRepo IInvitationRepository,
+	tokenRepo ITokenRepository,
+	txManager ITxManager,
+	passwordHasher IPasswordHasher,
+	mailer IMailer,
+	config Config,
+) (*Service, error) {
+	if userRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilUserRepository}
+	}
+	if userTokenRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilUserTokenRepository}
+	}
+	if invitationRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilInvitationRepository}
+	}
+	if tokenRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilTokenRepository}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilTxManager}
+	}
+	if passwordHasher == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilPasswordHasher}
+	}
+	if mailer == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create account service", Err: errNilMailer}
+	}
+
+	passwordResetTokenDuration := config.PasswordResetTokenDuration
+	if passwordResetTokenDuration <= 0 {
+		passwordResetTokenDuration = defaultPasswordResetTokenDuration
+	}
+
+	emailVerificationTokenDuration := config.EmailVerificationTokenDuration
+	if emailVerificationTokenDuration <= 0 {
+		emailVerificationTokenDuration = defaultEmailVerificationTokenDuration
+	}
+
+	registrationMode := config.RegistrationMode
+	switch registrationMode {
+	case "":
+		registrationMode = RegistrationModeDisabled
+	case RegistrationModeDisabled, RegistrationModeOpen, RegistrationModeInvite, RegistrationModeDomain:
+	default:
+		return nil, domain.InternalServerError{
+			Msg: "failed to create account service",
+			Err: fmt.Errorf("unknown registration mode %q", registrationMode),
+		}
+	}
+
+	registrationRole := config.RegistrationRole
+	if registrationRole == "" {
+		registrationRole = entity.RoleUser
+	}
+	if !isRegistrationRole(registrationRole) {
+		return nil, domain.InternalServerError{
+			Msg: "failed to create account service",
+			Err: fmt.Errorf("self-registered users cannot have role %q", registrationRole),
+		}
+	}
+
+	allowedDomains := make(map[string]bool, len(config.RegistrationAllowedDomains))
+	for _, d := range config.RegistrationAllowedDomains {
+		if d = normalizeDomain(d); d != "" {
+			allowedDomains[d] = true
+		}
+	}
+
+	invitationTokenDuration := config.InvitationTokenDuration
+	if invitationTokenDuration <= 0 {
+		invitationTokenDuration = defaultInvitationTokenDuration
+	}
+
+	return &Service{
+		userRepo:                       userRepo,
+		userTokenRepo:                  userTokenRepo,
+		invitationRepo:                 invitationRepo,
+		tokenRepo:                      tokenRepo,
+		txManager:                      txManager,
+		passwordHasher:                 passwordHasher,
+		mailer:                         mailer,
+		passwordResetTokenDuration:     passwordResetTokenDuration,
+		passwordResetURL:               config.PasswordResetURL,
+		emailVerificationTokenDuration: emailVerificationTokenDuration,
+		emailVerificationURL:           config.EmailVerificationURL,
+		registrationMode:               registrationMode,
+		registrationAllowedDomains:     allowedDomains,
+		registrationRole:               registrationRole,
+		invitationTokenDuration:        invitationTokenDuration,
+		invitationURL:                  config.InvitationURL,
+		now:                            time.Now,
+	}, nil
+}
+
+// ========== Forgot Password ==========
+
+// ForgotPassword emails a password reset token to the user with the given email.
+// Unknown and inactive accounts are silently ignored, and the work runs in the background,
+// so that neither errors nor the response time reveal which emails are registered.
+// Requesting a new token invalidates the previous ones.
+func (s *Service) ForgotPassword(ctx context.Context, email string) {
+	s.runInBackground(ctx, "failed to send password reset email", func(ctx context.Context) error {
+		return s.sendPasswordReset(ctx, email)
+	})
+}
+
+func (s *Service) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.Id, entity.TokenPurposePasswordReset, s.passwordResetTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your account.\n\n"+
+				"Use the following link to choose a new password. It expires in %s and can be used once:\n\n"+
+				"%s\n\n"+
+				"If you did not ask for this, you can ignore this email.\n",
+			formatDuration(s.passwordResetTokenDuration), tokenLink(s.passwordResetURL, token),
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return domain.InternalServerError{Msg: "failed to send reset email", Err: err}
+	}
+
+	return nil
+}
+
+// ========== Reset Password ==========
+
+// ResetPassword sets a new password using a token sent by ForgotPassword and returns
+// the user ID. The token is consumed, any other reset token of the user is deleted, and
+// the user's sessions are revoked in the same transaction: whoever triggered the reset
+// may not be the only one who knew the old password.
+func (s *Service) ResetPassword(ctx context.Context, input *ResetPasswordInput) (int, error) {
+	// Hash password outside the transaction; hashing is slow and would hold it open
+	hashedPassword, err := s.passwordHasher.Hash(input.NewPassword)
+	if err != nil {
+		return 0, domain.InternalServerError{Msg: "failed to hash password", Err: err}
+	}
+
+	var userId int
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		user, err := s.redeemToken(ctx, input.Token, entity.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+
+		if err := s.userRepo.UpdateUserPassword(ctx, user.Id, hashedPassword); err != nil {
+			return domain.InternalServerError{Msg: "failed to update password", Err: err}
+		}
+		if err := s.revokeSessions(ctx, user.Id); err != nil {
+			return err
+		}
+
+		userId = user.Id
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userId, nil
+}
+
+// ========== Helpers ==========
+
+// runInBackground runs fn after the request returns, logging its error with msg.
+// fn gets a context with the values of ctx but not its cancellation.
+func (s *Service) runInBackground(ctx context.Context, msg string, fn func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		if err := fn(ctx); err != nil {
+			logger.FromContext(ctx).Error(msg, "error", err)
+		}
+	}()
+}
+
+// Wait waits for the background work started by ForgotPassword and ResendEmailVerification,
+// or until ctx is done. It is meant to run on shutdown, before the database is closed.
+func (s *Service) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// revokeSessions revokes every access and refresh token of the user, like auth.Service.LogoutAll.
+// Access tokens may still pass for up to the revocation cache TTL of the auth service.
+func (s *Service) revokeSessions(ctx context.Context, userId int) error {
+	if err := s.userRepo.IncrementUserTokenVersion(ctx, userId); err != nil {
+		return domain.InternalServerError{Msg: "failed to increment token version", Err: err}
+	}
+	if err := s.tokenRepo.RevokeUserRefreshTokens(ctx, userId); err != nil {
+		return domain.InternalServerError{Msg: "failed to revoke refresh tokens", Err: err}
+	}
+	return nil
+}
+
+// issueToken stores a new single-use token for the user and returns it. Tokens previously
+// issued to the user for the same purpose are deleted. Callers send the token after this
+// returns: the transaction may be retried and must not send mail twice.
+func (s *Service) issueToken(ctx context.Context, userId int, purpose string, duration time.Duration) (string, error) {
+	token, err := pkgAuth.GenerateOpaqueToken()
+	if err != nil {
+		return "", domain.InternalServerError{Msg: "failed to generate token", Err: err}
+	}
+
+	expiresAt := s.now().Add(duration)
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		// Opportunistically drop tokens that can no longer be redeemed
+		if err := s.userTokenRepo.DeleteExpiredUserTokens(ctx); err != nil {
+			return domain.InternalServerError{Msg: "failed to clean up user tokens", Err: err}
+		}
+
+		if err := s.userTokenRepo.DeleteUserTokens(ctx, userId, purpose); err != nil {
+			return domain.InternalServerError{Msg: "failed to delete user tokens", Err: err}
+		}
+
+		_, err := s.userTokenRepo.InsertUserToken(ctx, &entity.UserToken{
+			UserId:    userId,
+			Purpose:   purpose,
+			TokenHash: pkgAuth.HashOpaqueToken(token),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to store user token", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// redeemToken consumes a token issued for purpose and returns its active user.
+// Every other token of the user for the same purpose is deleted. Must run inside a transaction,
+// so that the token is not consumed if the caller fails afterwards.
+func (s *Service) redeemToken(ctx context.Context, plainToken, purpose string) (*entity.User, error) {
+	token, err := s.userTokenRepo.GetUserTokenByHash(ctx, pkgAuth.HashOpaqueToken(plainToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, domain.InvalidTokenError{}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get user token", Err: err}
+	}
+	if token.Purpose != purpose || token.IsUsed() || token.IsExpired(s.now()) {
+		return nil, domain.InvalidTokenError{}
+	}
+
+	// Losing this race means a concurrent request redeemed the token first
+	if err := s.userTokenRepo.ConsumeUserToken(ctx, token.Id); err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, domain.InvalidTokenError{}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to consume user token", Err: err}
+	}
+
+	// The user may have been deactivated since the token was issued
+	user, err := s.userRepo.GetUserById(ctx, token.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, domain.InvalidTokenError{}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+	if !user.IsActive {
+		return nil, domain.InvalidTokenError{}
+	}
+
+	if err := s.userTokenRepo.DeleteUserTokens(ctx, user.Id, purpose); err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to delete user tokens", Err: err}
+	}
+
+	return user, nil
+}
+
+// tokenLink returns the link sent to the user, or the bare token without a base URL.
+func tokenLink(baseURL, token string) string {
+	if baseURL == "" {
+		return token
+	}
+
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return token
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// formatDuration renders a token lifetime for an email, e.g. "24 hours" or "30 minutes".
+func formatDuration(d time.Duration) string {
+	n, unit := int(d.Minutes()), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		n, unit = int(d.Hours()), "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
@@ -0,0 +1,516 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Mock Repositories ==========
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ExistsUserByEmail(ctx context.Context, email string) (bool, error) {
+	args := m.Called(email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) InsertUser(ctx context.Context, user *entity.User) (int, error) {
+	args := m.Called(user)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user *entity.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error {
+	args := m.Called(id, hashedPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) IncrementUserTokenVersion(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepository) InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error) {
+	args := m.Called(token)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserToken), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) ConsumeUserToken(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) DeleteUserTokens(ctx context.Context, userId int, purpose string) error {
+	args := m.Called(userId, purpose)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) DeleteExpiredUserTokens(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) InsertInvitation(ctx context.Context, invitation *entity.Invitation) (int, error) {
+	args := m.Called(invitation)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetInvitationByHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) ConsumeInvitation(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) DeleteInvitations(ctx context.Context, email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) DeleteExpiredInvitations(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+// ========== Mock Password Hasher ==========
+
+type MockPasswordHasher struct {
+	mock.Mock
+}
+
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	args := m.Called(password)
+	return args.String(0), args.Error(1)
+}
+
+// ========== Fake Transaction Manager and Mailer ==========
+
+// fakeTxManager runs fn directly, like a transaction that always commits.
+type fakeTxManager struct {
+	calls int
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+// fakeMailer records sent messages.
+type fakeMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (f *fakeMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+// ========== Test Helper ==========
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type testDeps struct {
+	userRepo       *MockUserRepository
+	userTokenRepo  *MockUserTokenRepository
+	invitationRepo *MockInvitationRepository
+	tokenRepo      *MockTokenRepository
+	hasher         *MockPasswordHasher
+	txManager      *fakeTxManager
+	mailer         *fakeMailer
+}
+
+func setupTestService(config Config) (*Service, *testDeps) {
+	deps := &testDeps{
+		userRepo:       new(MockUserRepository),
+		userTokenRepo:  new(MockUserTokenRepository),
+		invitationRepo: new(MockInvitationRepository),
+		tokenRepo:      new(MockTokenRepository),
+		hasher:         new(MockPasswordHasher),
+		txManager:      &fakeTxManager{},
+		mailer:         &fakeMailer{},
+	}
+	svc, _ := NewService(deps.userRepo, deps.userTokenRepo, deps.invitationRepo, deps.tokenRepo, deps.txManager, deps.hasher, deps.mailer, config)
+	svc.now = func() time.Time { return testNow }
+	return svc, deps
+}
+
+func activeUser() *entity.User {
+	return &entity.User{Id: 1, Email: "test@example.com", IsActive: true}
+}
+
+// ========== ForgotPassword Tests ==========
+
+func TestForgotPassword_SendsResetLink(t *testing.T) {
+	svc, deps := setupTestService(Config{PasswordResetURL: "https://app.example.com/reset?lang=en"})
+
+	var stored *entity.UserToken
+	deps.userRepo.On("GetUserByEmail", "test@example.com").Return(activeUser(), nil)
+	deps.userTokenRepo.On("DeleteExpiredUserTokens").Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposePasswordReset).Return(nil)
+	deps.userTokenRepo.On("InsertUserToken", mock.AnythingOfType("*entity.UserToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*entity.UserToken) }).
+		Return(1, nil)
+
+	svc.ForgotPassword(context.Background(), "test@example.com")
+
+	assert.NoError(t, svc.Wait(context.Background()))
+	assert.Equal(t, 1, deps.txManager.calls)
+	deps.userTokenRepo.AssertExpectations(t)
+
+	if !assert.Len(t, deps.mailer.sent, 1) || !assert.NotNil(t, stored) {
+		return
+	}
+	msg := deps.mailer.sent[0]
+	assert.Equal(t, "test@example.com", msg.To)
+	assert.Contains(t, msg.Body, "expires in 1 hour ")
+
+	// The link carries the plain token; only its hash is stored
+	start := strings.Index(msg.Body, "https://")
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "en", link.Query().Get("lang"))
+	token := link.Query().Get("token")
+	assert.NotEmpty(t, token)
+	assert.Equal(t, pkgAuth.HashOpaqueToken(token), stored.TokenHash)
+	assert.Equal(t, entity.TokenPurposePasswordReset, stored.Purpose)
+	assert.Equal(t, testNow.Add(defaultPasswordResetTokenDuration), stored.ExpiresAt)
+}
+
+func TestForgotPassword_WithoutResetURL(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+
+	var stored *entity.UserToken
+	deps.userRepo.On("GetUserByEmail", "test@example.com").Return(activeUser(), nil)
+	deps.userTokenRepo.On("DeleteExpiredUserTokens").Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposePasswordReset).Return(nil)
+	deps.userTokenRepo.On("InsertUserToken", mock.AnythingOfType("*entity.UserToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*entity.UserToken) }).
+		Return(1, nil)
+
+	svc.ForgotPassword(context.Background(), "test@example.com")
+
+	assert.NoError(t, svc.Wait(context.Background()))
+	if assert.Len(t, deps.mailer.sent, 1) && assert.NotNil(t, stored) {
+		found := false
+		for _, field := range strings.Fields(deps.mailer.sent[0].Body) {
+			if pkgAuth.HashOpaqueToken(field) == stored.TokenHash {
+				found = true
+			}
+		}
+		assert.True(t, found, "email must contain the bare token")
+	}
+}
+
+func TestForgotPassword_UnknownOrInactiveUser(t *testing.T) {
+	tests := []struct {
+		name string
+		user *entity.User
+		err  error
+	}{
+		{name: "unknown email", err: repository.ErrUserNotFound},
+		{name: "inactive user", user: &entity.User{Id: 1, Email: "test@example.com", IsActive: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(Config{})
+			if tt.user != nil {
+				deps.userRepo.On("GetUserByEmail", "test@example.com").Return(tt.user, nil)
+			} else {
+				deps.userRepo.On("GetUserByEmail", "test@example.com").Return(nil, tt.err)
+			}
+
+			svc.ForgotPassword(context.Background(), "test@example.com")
+
+			assert.NoError(t, svc.Wait(context.Background()))
+			assert.Empty(t, deps.mailer.sent)
+			deps.userRepo.AssertExpectations(t)
+			deps.userTokenRepo.AssertNotCalled(t, "InsertUserToken", mock.Anything)
+		})
+	}
+}
+
+func TestForgotPassword_MailerError(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.mailer.err = errors.New("smtp unavailable")
+
+	deps.userRepo.On("GetUserByEmail", "test@example.com").Return(activeUser(), nil)
+	deps.userTokenRepo.On("DeleteExpiredUserTokens").Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposePasswordReset).Return(nil)
+	deps.userTokenRepo.On("InsertUserToken", mock.AnythingOfType("*entity.UserToken")).Return(1, nil)
+
+	// The failure is logged rather than returned, which would reveal that the account exists
+	svc.ForgotPassword(context.Background(), "test@example.com")
+
+	assert.NoError(t, svc.Wait(context.Background()))
+	assert.Empty(t, deps.mailer.sent)
+	deps.userTokenRepo.AssertExpectations(t)
+}
+
+func TestForgotPassword_OutlivesRequest(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+
+	deps.userRepo.On("GetUserByEmail", "test@example.com").Return(activeUser(), nil)
+	deps.userTokenRepo.On("DeleteExpiredUserTokens").Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposePasswordReset).Return(nil)
+	deps.userTokenRepo.On("InsertUserToken", mock.AnythingOfType("*entity.UserToken")).Return(1, nil)
+
+	// The request context is canceled as soon as the response is written
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.ForgotPassword(ctx, "test@example.com")
+	cancel()
+
+	assert.NoError(t, svc.Wait(context.Background()))
+	assert.Len(t, deps.mailer.sent, 1)
+}
+
+// ========== ResetPassword Tests ==========
+
+func TestResetPassword_Success(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	token := &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow.Add(time.Minute)}
+
+	deps.hasher.On("Hash", "new_password").Return("new_hash", nil)
+	deps.userTokenRepo.On("GetUserTokenByHash", pkgAuth.HashOpaqueToken("token")).Return(token, nil)
+	deps.userTokenRepo.On("ConsumeUserToken", 7).Return(nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.userRepo.On("UpdateUserPassword", 1, "new_hash").Return(nil)
+	deps.userRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	deps.tokenRepo.On("RevokeUserRefreshTokens", 1).Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposePasswordReset).Return(nil)
+
+	userId, err := svc.ResetPassword(context.Background(), &ResetPasswordInput{Token: "token", NewPassword: "new_password"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userId)
+	assert.Equal(t, 1, deps.txManager.calls)
+	deps.userRepo.AssertExpectations(t)
+	deps.userTokenRepo.AssertExpectations(t)
+	deps.tokenRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_RevocationFails(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	token := &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow.Add(time.Minute)}
+
+	deps.hasher.On("Hash", "new_password").Return("new_hash", nil)
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(token, nil)
+	deps.userTokenRepo.On("ConsumeUserToken", 7).Return(nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.userRepo.On("UpdateUserPassword", 1, "new_hash").Return(nil)
+	deps.userRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	deps.tokenRepo.On("RevokeUserRefreshTokens", 1).Return(errors.New("db error"))
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposePasswordReset).Return(nil)
+
+	// The error rolls back the transaction, so the password is not changed without revoking sessions
+	_, err := svc.ResetPassword(context.Background(), &ResetPasswordInput{Token: "token", NewPassword: "new_password"})
+
+	assert.IsType(t, domain.InternalServerError{}, err)
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	usedAt := testNow.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		token *entity.UserToken
+		err   error
+	}{
+		{name: "unknown", err: repository.ErrUserTokenNotFound},
+		{name: "expired", token: &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow}},
+		{name: "used", token: &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow.Add(time.Minute), UsedAt: &usedAt}},
+		{name: "other purpose", token: &entity.UserToken{Id: 7, UserId: 1, Purpose: "other", ExpiresAt: testNow.Add(time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(Config{})
+			deps.hasher.On("Hash", "new_password").Return("new_hash", nil)
+			if tt.token != nil {
+				deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(tt.token, nil)
+			} else {
+				deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(nil, tt.err)
+			}
+
+			_, err := svc.ResetPassword(context.Background(), &ResetPasswordInput{Token: "token", NewPassword: "new_password"})
+
+			assert.IsType(t, domain.InvalidTokenError{}, err)
+			deps.userRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestResetPassword_ConcurrentlyConsumed(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	token := &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow.Add(time.Minute)}
+
+	deps.hasher.On("Hash", "new_password").Return("new_hash", nil)
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(token, nil)
+	deps.userTokenRepo.On("ConsumeUserToken", 7).Return(repository.ErrUserTokenNotFound)
+
+	_, err := svc.ResetPassword(context.Background(), &ResetPasswordInput{Token: "token", NewPassword: "new_password"})
+
+	assert.IsType(t, domain.InvalidTokenError{}, err)
+	deps.userRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword_InactiveUser(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	token := &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow.Add(time.Minute)}
+
+	deps.hasher.On("Hash", "new_password").Return("new_hash", nil)
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(token, nil)
+	deps.userTokenRepo.On("ConsumeUserToken", 7).Return(nil)
+	deps.userRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: false}, nil)
+
+	_, err := svc.ResetPassword(context.Background(), &ResetPasswordInput{Token: "token", NewPassword: "new_password"})
+
+	assert.IsType(t, domain.InvalidTokenError{}, err)
+	deps.userRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+}
+
+// ========== Helper Tests ==========
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "1 hour", formatDuration(time.Hour))
+	assert.Equal(t, "24 hours", formatDuration(24*time.Hour))
+	assert.Equal(t, "30 minutes", formatDuration(30*time.Minute))
+	assert.Equal(t, "90 minutes", formatDuration(90*time.Minute))
+	assert.Equal(t, "1 minute", formatDuration(time.Minute))
+}
+
+// ========== NewService Tests ==========
+
+func TestNewService_NilDependencies(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userTokenRepo := new(MockUserTokenRepository)
+	invitationRepo := new(MockInvitationRepository)
+	tokenRepo := new(MockTokenRepository)
+	txManager := &fakeTxManager{}
+	hasher := new(MockPasswordHasher)
+	m := &fakeMailer{}
+
+	tests := []struct {
+		name    string
+		svc     func() (*Service, error)
+		wantErr error
+	}{
+		{"user repository", func() (*Service, error) {
+			return NewService(nil, userTokenRepo, invitationRepo, tokenRepo, txManager, hasher, m, Config{})
+		}, errNilUserRepository},
+		{"user token repository", func() (*Service, error) {
+			return NewService(userRepo, nil, invitationRepo, tokenRepo, txManager, hasher, m, Config{})
+		}, errNilUserTokenRepository},
+		{"invitation repository", func() (*Service, error) {
+			return NewService(userRepo, userTokenRepo, nil, tokenRepo, txManager, hasher, m, Config{})
+		}, errNilInvitationRepository},
+		{"token repository", func() (*Service, error) {
+			return NewService(userRepo, userTokenRepo, invitationRepo, nil, txManager, hasher, m, Config{})
+		}, errNilTokenRepository},
+		{"transaction manager", func() (*Service, error) {
+			return NewService(userRepo, userTokenRepo, invitationRepo, tokenRepo, nil, hasher, m, Config{})
+		}, errNilTxManager},
+		{"password hasher", func() (*Service, error) {
+			return NewService(userRepo, userTokenRepo, invitationRepo, tokenRepo, txManager, nil, m, Config{})
+		}, errNilPasswordHasher},
+		{"mailer", func() (*Service, error) {
+			return NewService(userRepo, userTokenRepo, invitationRepo, tokenRepo, txManager, hasher, nil, Config{})
+		}, errNilMailer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := tt.svc()
+
+			assert.Nil(t, svc)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNewService_InvalidRegistrationConfig(t *testing.T) {
+	newService := func(config Config) (*Service, error) {
+		return NewService(new(MockUserRepository), new(MockUserTokenRepository), new(MockInvitationRepository), new(MockTokenRepository),
+			&fakeTxManager{}, new(MockPasswordHasher), &fakeMailer{}, config)
+	}
+
+	_, err := newService(Config{RegistrationMode: "everyone"})
+	assert.Error(t, err)
+
+	_, err = newService(Config{RegistrationMode: RegistrationModeOpen, RegistrationRole: entity.RoleAdmin})
+	assert.Error(t, err, "self-registration must never create admins")
+
+	svc, err := newService(Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, RegistrationModeDisabled, svc.registrationMode)
+	assert.Equal(t, entity.RoleUser, svc.registrationRole)
+}
@@ -0,0 +1,135 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/mailer"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Send Verification ==========
+
+// SendEmailVerification emails a verification token to the address the user still has to
+// verify: the pending email if an email change was requested, otherwise the current email
+// if it is unverified. Requesting a new token invalidates the previous ones; if there is
+// nothing left to verify (e.g. a pending change was cancelled), outstanding tokens are deleted.
+func (s *Service) SendEmailVerification(ctx context.Context, userId int) error {
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.UserNotFoundError{Id: userId}
+		}
+		return domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+
+	return s.sendEmailVerification(ctx, user)
+}
+
+// ResendEmailVerification sends a new verification token to the user with the given email.
+// Like ForgotPassword, it ignores unknown accounts and runs in the background, so that callers
+// cannot find out which emails are registered.
+func (s *Service) ResendEmailVerification(ctx context.Context, email string) {
+	s.runInBackground(ctx, "failed to resend verification email", func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil
+			}
+			return domain.InternalServerError{Msg: "failed to get user", Err: err}
+		}
+
+		return s.sendEmailVerification(ctx, user)
+	})
+}
+
+func (s *Service) sendEmailVerification(ctx context.Context, user *entity.User) error {
+	to := verificationTarget(user)
+	if to == "" || !user.IsActive {
+		if err := s.userTokenRepo.DeleteUserTokens(ctx, user.Id, entity.TokenPurposeEmailVerification); err != nil {
+			return domain.InternalServerError{Msg: "failed to delete verification tokens", Err: err}
+		}
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.Id, entity.TokenPurposeEmailVerification, s.emailVerificationTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Use the following link to confirm that %s is your email address. "+
+				"It expires in %s and can be used once:\n\n"+
+				"%s\n\n"+
+				"If you did not ask for this, you can ignore this email.\n",
+			to, formatDuration(s.emailVerificationTokenDuration), tokenLink(s.emailVerificationURL, token),
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return domain.InternalServerError{Msg: "failed to send verification email", Err: err}
+	}
+
+	return nil
+}
+
+// verificationTarget returns the email the user has yet to verify, or "" if there is none.
+func verificationTarget(user *entity.User) string {
+	if user.PendingEmail != nil {
+		return *user.PendingEmail
+	}
+	if !user.IsEmailVerified() {
+		return user.Email
+	}
+	return ""
+}
+
+// ========== Verify Email ==========
+
+// VerifyEmail redeems a token sent by SendEmailVerification and returns the user ID.
+// A pending email replaces the current one and revokes the user's sessions in the same
+// transaction, since whoever is behind the new email must not share them; otherwise the
+// current email is marked verified.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (int, error) {
+	var userId int
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		user, err := s.redeemToken(ctx, token, entity.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		emailChanged := user.PendingEmail != nil
+		if emailChanged {
+			user.Email = *user.PendingEmail
+			user.PendingEmail = nil
+		}
+		verifiedAt := s.now()
+		user.EmailVerifiedAt = &verifiedAt
+
+		if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+			// Someone else registered the pending email in the meantime
+			if errors.Is(err, repository.ErrDuplicateEmail) {
+				return domain.UserAlreadyExistsError{Email: user.Email}
+			}
+			return domain.InternalServerError{Msg: "failed to update user", Err: err}
+		}
+		if emailChanged {
+			if err := s.revokeSessions(ctx, user.Id); err != nil {
+				return err
+			}
+		}
+
+		userId = user.Id
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userId, nil
+}
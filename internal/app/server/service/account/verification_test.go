@@ -0,0 +1,170 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== SendEmailVerification Tests ==========
+
+func expectIssueToken(deps *testDeps, userId int, purpose string) {
+	deps.userTokenRepo.On("DeleteExpiredUserTokens").Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", userId, purpose).Return(nil)
+	deps.userTokenRepo.On("InsertUserToken", mock.MatchedBy(func(t *entity.UserToken) bool {
+		return t.UserId == userId && t.Purpose == purpose
+	})).Return(1, nil)
+}
+
+func TestSendEmailVerification_Recipient(t *testing.T) {
+	verifiedAt := testNow
+	pendingEmail := "new@example.com"
+
+	tests := []struct {
+		name   string
+		user   *entity.User
+		wantTo string
+	}{
+		{
+			name:   "unverified email",
+			user:   &entity.User{Id: 1, Email: "test@example.com", IsActive: true},
+			wantTo: "test@example.com",
+		},
+		{
+			name:   "pending email change",
+			user:   &entity.User{Id: 1, Email: "test@example.com", EmailVerifiedAt: &verifiedAt, PendingEmail: &pendingEmail, IsActive: true},
+			wantTo: pendingEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(Config{EmailVerificationURL: "https://app.example.com/verify"})
+			deps.userRepo.On("GetUserById", 1).Return(tt.user, nil)
+			expectIssueToken(deps, 1, entity.TokenPurposeEmailVerification)
+
+			err := svc.SendEmailVerification(context.Background(), 1)
+
+			assert.NoError(t, err)
+			if assert.Len(t, deps.mailer.sent, 1) {
+				assert.Equal(t, tt.wantTo, deps.mailer.sent[0].To)
+				assert.Contains(t, deps.mailer.sent[0].Body, "https://app.example.com/verify?token=")
+				assert.Contains(t, deps.mailer.sent[0].Body, "expires in 24 hours ")
+			}
+			deps.userTokenRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSendEmailVerification_NothingToVerify(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	verifiedAt := testNow
+	deps.userRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Email: "test@example.com", EmailVerifiedAt: &verifiedAt, IsActive: true}, nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposeEmailVerification).Return(nil)
+
+	err := svc.SendEmailVerification(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Empty(t, deps.mailer.sent)
+	deps.userTokenRepo.AssertExpectations(t)
+	deps.userTokenRepo.AssertNotCalled(t, "InsertUserToken", mock.Anything)
+}
+
+func TestSendEmailVerification_UserNotFound(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userRepo.On("GetUserById", 999).Return(nil, repository.ErrUserNotFound)
+
+	err := svc.SendEmailVerification(context.Background(), 999)
+
+	assert.IsType(t, domain.UserNotFoundError{}, err)
+}
+
+func TestResendEmailVerification_UnknownEmail(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userRepo.On("GetUserByEmail", "unknown@example.com").Return(nil, repository.ErrUserNotFound)
+
+	svc.ResendEmailVerification(context.Background(), "unknown@example.com")
+
+	assert.NoError(t, svc.Wait(context.Background()))
+	assert.Empty(t, deps.mailer.sent)
+	deps.userRepo.AssertExpectations(t)
+}
+
+// ========== VerifyEmail Tests ==========
+
+func verificationToken() *entity.UserToken {
+	return &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposeEmailVerification, ExpiresAt: testNow.Add(time.Hour)}
+}
+
+func expectRedeemToken(deps *testDeps, token *entity.UserToken, user *entity.User) {
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(token, nil)
+	deps.userTokenRepo.On("ConsumeUserToken", token.Id).Return(nil)
+	deps.userRepo.On("GetUserById", user.Id).Return(user, nil)
+	deps.userTokenRepo.On("DeleteUserTokens", user.Id, token.Purpose).Return(nil)
+}
+
+func TestVerifyEmail_CurrentEmail(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	expectRedeemToken(deps, verificationToken(), &entity.User{Id: 1, Email: "test@example.com", IsActive: true})
+	deps.userRepo.On("UpdateUser", mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == "test@example.com" && u.EmailVerifiedAt != nil && u.EmailVerifiedAt.Equal(testNow)
+	})).Return(nil)
+
+	userId, err := svc.VerifyEmail(context.Background(), "token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userId)
+	deps.userRepo.AssertExpectations(t)
+	deps.userTokenRepo.AssertExpectations(t)
+	deps.userRepo.AssertNotCalled(t, "IncrementUserTokenVersion", mock.Anything)
+}
+
+func TestVerifyEmail_PendingEmail(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	pendingEmail := "new@example.com"
+	expectRedeemToken(deps, verificationToken(), &entity.User{Id: 1, Email: "old@example.com", PendingEmail: &pendingEmail, IsActive: true})
+	deps.userRepo.On("UpdateUser", mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == pendingEmail && u.PendingEmail == nil && u.EmailVerifiedAt != nil
+	})).Return(nil)
+	// Whoever is behind the new email must not share existing sessions
+	deps.userRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	deps.tokenRepo.On("RevokeUserRefreshTokens", 1).Return(nil)
+
+	_, err := svc.VerifyEmail(context.Background(), "token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deps.txManager.calls)
+	deps.userRepo.AssertExpectations(t)
+	deps.tokenRepo.AssertExpectations(t)
+}
+
+func TestVerifyEmail_PendingEmailTaken(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	pendingEmail := "taken@example.com"
+	expectRedeemToken(deps, verificationToken(), &entity.User{Id: 1, Email: "old@example.com", PendingEmail: &pendingEmail, IsActive: true})
+	deps.userRepo.On("UpdateUser", mock.Anything).Return(repository.ErrDuplicateEmail)
+
+	_, err := svc.VerifyEmail(context.Background(), "token")
+
+	assert.Equal(t, domain.UserAlreadyExistsError{Email: pendingEmail}, err)
+	deps.tokenRepo.AssertNotCalled(t, "RevokeUserRefreshTokens", mock.Anything)
+}
+
+func TestVerifyEmail_PasswordResetToken(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	token := verificationToken()
+	token.Purpose = entity.TokenPurposePasswordReset
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(token, nil)
+
+	_, err := svc.VerifyEmail(context.Background(), "token")
+
+	assert.IsType(t, domain.InvalidTokenError{}, err)
+	deps.userTokenRepo.AssertNotCalled(t, "ConsumeUserToken", mock.Anything)
+	deps.userRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
@@ -0,0 +1,31 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Service Dependencies ==========
+// Interfaces that the API key service depends on (injected from outside)
+
+// IUserRepository defines the user data access needed by the API key service.
+type IUserRepository interface {
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+}
+
+// IAPIKeyRepository defines the interface for API key data access.
+type IAPIKeyRepository interface {
+	InsertAPIKey(ctx context.Context, key *entity.APIKey) (int, error)
+	GetAPIKeysByUserId(ctx context.Context, userId int) ([]*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id int, usedAt time.Time) error
+	RevokeAPIKey(ctx context.Context, userId, id int) error
+}
+
+// ITxManager runs a function inside a database transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,11 @@
+package apikey
+
+import "time"
+
+// CreateAPIKeyInput represents input for creating an API key.
+type CreateAPIKeyInput struct {
+	UserId    int
+	Name      string     // label shown in the user's key list
+	Scopes    []string   // see the entity.APIKeyScope constants
+	ExpiresAt *time.Time // nil for a key that never expires
+}
@@ -0,0 +1,221 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+var (
+	errNilUserRepository   = errors.New("user repository is nil")
+	errNilAPIKeyRepository = errors.New("api key repository is nil")
+	errNilTxManager        = errors.New("transaction manager is nil")
+)
+
+const (
+	defaultMaxKeysPerUser = 25
+
+	// lastUsedInterval throttles last-used writes, so that a busy key does not write on every request.
+	lastUsedInterval = time.Minute
+)
+
+// Config holds API key service configuration.
+type Config struct {
+	// MaxKeysPerUser limits the number of active (unrevoked and unexpired) keys of a user.
+	MaxKeysPerUser int
+}
+
+// Service handles API keys: creation, listing, revocation and authentication of machine clients.
+type Service struct {
+	userRepo       IUserRepository
+	apiKeyRepo     IAPIKeyRepository
+	txManager      ITxManager
+	maxKeysPerUser int
+	now            func() time.Time
+}
+
+// NewService creates a new API key service.
+func NewService(
+	userRepo IUserRepository,
+	apiKeyRepo IAPIKeyRepository,
+	txManager ITxManager,
+	config Config,
+) (*Service, error) {
+	if userRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create api key service", Err: errNilUserRepository}
+	}
+	if apiKeyRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create api key service", Err: errNilAPIKeyRepository}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create api key service", Err: errNilTxManager}
+	}
+
+	maxKeysPerUser := config.MaxKeysPerUser
+	if maxKeysPerUser <= 0 {
+		maxKeysPerUser = defaultMaxKeysPerUser
+	}
+
+	return &Service{
+		userRepo:       userRepo,
+		apiKeyRepo:     apiKeyRepo,
+		txManager:      txManager,
+		maxKeysPerUser: maxKeysPerUser,
+		now:            time.Now,
+	}, nil
+}
+
+// ========== Management ==========
+
+// CreateAPIKey creates an API key for a user. The returned key is the only copy of the secret:
+// only its hash is stored, so it cannot be shown again.
+func (s *Service) CreateAPIKey(ctx context.Context, input *CreateAPIKeyInput) (string, *entity.APIKey, error) {
+	if len(input.Scopes) == 0 {
+		return "", nil, domain.ValidationError{Field: "scopes", Message: "at least one scope is required"}
+	}
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !entity.IsValidAPIKeyScope(scope) {
+			return "", nil, domain.ValidationError{Field: "scopes", Message: "unknown scope: " + scope}
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return "", nil, domain.ValidationError{Field: "expires_at", Message: "must be in the future"}
+	}
+
+	key, prefix, err := pkgAuth.GenerateAPIKey()
+	if err != nil {
+		return "", nil, domain.InternalServerError{Msg: "failed to generate api key", Err: err}
+	}
+
+	var created *entity.APIKey
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		existing, err := s.apiKeyRepo.GetAPIKeysByUserId(ctx, input.UserId)
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to get api keys", Err: err}
+		}
+		active := 0
+		for _, k := range existing {
+			if !k.IsRevoked() && !k.IsExpired(s.now()) {
+				active++
+			}
+		}
+		if active >= s.maxKeysPerUser {
+			return domain.APIKeyLimitExceededError{Max: s.maxKeysPerUser}
+		}
+
+		_, err = s.apiKeyRepo.InsertAPIKey(ctx, &entity.APIKey{
+			UserId:    input.UserId,
+			Name:      strings.TrimSpace(input.Name),
+			Prefix:    prefix,
+			KeyHash:   pkgAuth.HashOpaqueToken(key),
+			Scopes:    scopes,
+			ExpiresAt: input.ExpiresAt,
+		})
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return domain.UserNotFoundError{Id: input.UserId}
+			}
+			return domain.InternalServerError{Msg: "failed to store api key", Err: err}
+		}
+
+		created, err = s.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix)
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to get api key", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, created, nil
+}
+
+// ListAPIKeys returns the API keys of a user, revoked and expired ones included, oldest first.
+func (s *Service) ListAPIKeys(ctx context.Context, userId int) ([]*entity.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetAPIKeysByUserId(ctx, userId)
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to get api keys", Err: err}
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of a user. The key stops working immediately.
+func (s *Service) RevokeAPIKey(ctx context.Context, userId, id int) error {
+	if err := s.apiKeyRepo.RevokeAPIKey(ctx, userId, id); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return domain.APIKeyNotFoundError{Id: id}
+		}
+		return domain.InternalServerError{Msg: "failed to revoke api key", Err: err}
+	}
+	return nil
+}
+
+// ========== Authentication ==========
+
+// IsAPIKey reports whether a bearer token is an API key rather than a JWT.
+func (s *Service) IsAPIKey(token string) bool {
+	return pkgAuth.IsAPIKey(token)
+}
+
+// AuthenticateAPIKey verifies an API key presented by a client and returns the key and its owner.
+// The owner is loaded from the database, so role changes and deactivation apply immediately.
+// Every rejection is reported as domain.InvalidAPIKeyError.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (*entity.User, *entity.APIKey, error) {
+	prefix, ok := pkgAuth.ParseAPIKey(key)
+	if !ok {
+		return nil, nil, domain.InvalidAPIKeyError{}
+	}
+
+	apiKey, err := s.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, nil, domain.InvalidAPIKeyError{}
+		}
+		return nil, nil, domain.InternalServerError{Msg: "failed to get api key", Err: err}
+	}
+
+	now := s.now()
+	if subtle.ConstantTimeCompare([]byte(pkgAuth.HashOpaqueToken(key)), []byte(apiKey.KeyHash)) != 1 {
+		return nil, nil, domain.InvalidAPIKeyError{}
+	}
+	if apiKey.IsRevoked() || apiKey.IsExpired(now) {
+		return nil, nil, domain.InvalidAPIKeyError{}
+	}
+
+	user, err := s.userRepo.GetUserById(ctx, apiKey.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, nil, domain.InvalidAPIKeyError{}
+		}
+		return nil, nil, domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+	// Lockouts guard against password guessing and do not apply to keys
+	if !user.IsActive {
+		return nil, nil, domain.InvalidAPIKeyError{}
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		// Usage tracking is best effort and never fails a request
+		if err := s.apiKeyRepo.UpdateAPIKeyLastUsed(ctx, apiKey.Id, now); err != nil {
+			logger.FromContext(ctx).Warn("failed to record api key use", "api_key_id", apiKey.Id, "error", err)
+		} else {
+			apiKey.LastUsedAt = &now
+		}
+	}
+
+	return user, apiKey, nil
+}
@@ -0,0 +1,337 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Mock Repositories ==========
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) InsertAPIKey(ctx context.Context, key *entity.APIKey) (int, error) {
+	args := m.Called(key)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeysByUserId(ctx context.Context, userId int) ([]*entity.APIKey, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	args := m.Called(prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id int, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, userId, id int) error {
+	args := m.Called(userId, id)
+	return args.Error(0)
+}
+
+// ========== Fake Transaction Manager ==========
+
+// fakeTxManager runs fn directly, like a transaction that always commits.
+type fakeTxManager struct {
+	calls int
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+// ========== Test Helper ==========
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type testDeps struct {
+	userRepo   *MockUserRepository
+	apiKeyRepo *MockAPIKeyRepository
+	txManager  *fakeTxManager
+}
+
+func setupTestService(t *testing.T) (*Service, *testDeps) {
+	deps := &testDeps{
+		userRepo:   new(MockUserRepository),
+		apiKeyRepo: new(MockAPIKeyRepository),
+		txManager:  &fakeTxManager{},
+	}
+	svc, err := NewService(deps.userRepo, deps.apiKeyRepo, deps.txManager, Config{MaxKeysPerUser: 2})
+	if err != nil {
+		t.Fatalf("Failed to create api key service: %v", err)
+	}
+	svc.now = func() time.Time { return testNow }
+	return svc, deps
+}
+
+func activeUser() *entity.User {
+	return &entity.User{Id: 1, Email: "test@example.com", Name: "Test User", Role: entity.RoleUser, IsActive: true}
+}
+
+// storedKey generates a key and returns it together with its stored form.
+func storedKey(t *testing.T) (string, *entity.APIKey) {
+	key, prefix, err := pkgAuth.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Failed to generate api key: %v", err)
+	}
+	return key, &entity.APIKey{
+		Id:      7,
+		UserId:  1,
+		Name:    "CI",
+		Prefix:  prefix,
+		KeyHash: pkgAuth.HashOpaqueToken(key),
+		Scopes:  []string{entity.APIKeyScopeUsersRead},
+	}
+}
+
+// ========== NewService Tests ==========
+
+func TestNewService_NilDependencies(t *testing.T) {
+	_, err := NewService(nil, new(MockAPIKeyRepository), &fakeTxManager{}, Config{})
+	assert.ErrorIs(t, err, errNilUserRepository)
+
+	_, err = NewService(new(MockUserRepository), nil, &fakeTxManager{}, Config{})
+	assert.ErrorIs(t, err, errNilAPIKeyRepository)
+
+	_, err = NewService(new(MockUserRepository), new(MockAPIKeyRepository), nil, Config{})
+	assert.ErrorIs(t, err, errNilTxManager)
+}
+
+func TestNewService_Defaults(t *testing.T) {
+	svc, err := NewService(new(MockUserRepository), new(MockAPIKeyRepository), &fakeTxManager{}, Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMaxKeysPerUser, svc.maxKeysPerUser)
+}
+
+// ========== CreateAPIKey Tests ==========
+
+func TestCreateAPIKey_Success(t *testing.T) {
+	svc, deps := setupTestService(t)
+	expiresAt := testNow.Add(24 * time.Hour)
+
+	deps.apiKeyRepo.On("GetAPIKeysByUserId", 1).Return([]*entity.APIKey{}, nil)
+	var inserted *entity.APIKey
+	deps.apiKeyRepo.On("InsertAPIKey", mock.Anything).Run(func(args mock.Arguments) {
+		inserted = args.Get(0).(*entity.APIKey)
+	}).Return(3, nil)
+	deps.apiKeyRepo.On("GetAPIKeyByPrefix", mock.Anything).Return(&entity.APIKey{Id: 3, UserId: 1}, nil)
+
+	key, created, err := svc.CreateAPIKey(context.Background(), &CreateAPIKeyInput{
+		UserId:    1,
+		Name:      " Deploy ",
+		Scopes:    []string{entity.APIKeyScopeUsersRead, entity.APIKeyScopeUsersRead, entity.APIKeyScopeUsersWrite},
+		ExpiresAt: &expiresAt,
+	})
+
+	assert.NoError(t, err)
+	prefix, ok := pkgAuth.ParseAPIKey(key)
+	assert.True(t, ok)
+	if assert.NotNil(t, inserted) {
+		assert.Equal(t, 1, inserted.UserId)
+		assert.Equal(t, "Deploy", inserted.Name)
+		assert.Equal(t, prefix, inserted.Prefix)
+		assert.Equal(t, pkgAuth.HashOpaqueToken(key), inserted.KeyHash, "only the hash is stored")
+		assert.Equal(t, []string{entity.APIKeyScopeUsersRead, entity.APIKeyScopeUsersWrite}, inserted.Scopes)
+		assert.Equal(t, &expiresAt, inserted.ExpiresAt)
+	}
+	if assert.NotNil(t, created) {
+		assert.Equal(t, 3, created.Id)
+	}
+	assert.Equal(t, 1, deps.txManager.calls)
+}
+
+func TestCreateAPIKey_Validation(t *testing.T) {
+	past := testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		input CreateAPIKeyInput
+		field string
+	}{
+		{"no scopes", CreateAPIKeyInput{UserId: 1, Name: "CI"}, "scopes"},
+		{"unknown scope", CreateAPIKeyInput{UserId: 1, Name: "CI", Scopes: []string{"admin"}}, "scopes"},
+		{"expiry in the past", CreateAPIKeyInput{UserId: 1, Name: "CI", Scopes: []string{entity.APIKeyScopeUsersRead}, ExpiresAt: &past}, "expires_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(t)
+
+			_, _, err := svc.CreateAPIKey(context.Background(), &tt.input)
+
+			var validationErr domain.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+			deps.apiKeyRepo.AssertNotCalled(t, "InsertAPIKey", mock.Anything)
+		})
+	}
+}
+
+func TestCreateAPIKey_LimitExceeded(t *testing.T) {
+	svc, deps := setupTestService(t)
+	revokedAt := testNow.Add(-time.Hour)
+	expiredAt := testNow.Add(-time.Minute)
+
+	deps.apiKeyRepo.On("GetAPIKeysByUserId", 1).Return([]*entity.APIKey{
+		{Id: 1},
+		{Id: 2, RevokedAt: &revokedAt},
+		{Id: 3, ExpiresAt: &expiredAt},
+		{Id: 4},
+	}, nil)
+
+	_, _, err := svc.CreateAPIKey(context.Background(), &CreateAPIKeyInput{
+		UserId: 1,
+		Name:   "CI",
+		Scopes: []string{entity.APIKeyScopeUsersRead},
+	})
+
+	assert.Equal(t, domain.APIKeyLimitExceededError{Max: 2}, err)
+	deps.apiKeyRepo.AssertNotCalled(t, "InsertAPIKey", mock.Anything)
+}
+
+// ========== RevokeAPIKey Tests ==========
+
+func TestRevokeAPIKey(t *testing.T) {
+	svc, deps := setupTestService(t)
+
+	deps.apiKeyRepo.On("RevokeAPIKey", 1, 7).Return(nil)
+	deps.apiKeyRepo.On("RevokeAPIKey", 1, 8).Return(repository.ErrAPIKeyNotFound)
+
+	assert.NoError(t, svc.RevokeAPIKey(context.Background(), 1, 7))
+	assert.Equal(t, domain.APIKeyNotFoundError{Id: 8}, svc.RevokeAPIKey(context.Background(), 1, 8))
+}
+
+// ========== AuthenticateAPIKey Tests ==========
+
+func TestAuthenticateAPIKey_Success(t *testing.T) {
+	svc, deps := setupTestService(t)
+	key, stored := storedKey(t)
+
+	deps.apiKeyRepo.On("GetAPIKeyByPrefix", stored.Prefix).Return(stored, nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.apiKeyRepo.On("UpdateAPIKeyLastUsed", 7, testNow).Return(nil)
+
+	user, apiKey, err := svc.AuthenticateAPIKey(context.Background(), key)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.Id)
+	assert.Equal(t, 7, apiKey.Id)
+	deps.apiKeyRepo.AssertExpectations(t)
+}
+
+func TestAuthenticateAPIKey_ThrottlesLastUsed(t *testing.T) {
+	svc, deps := setupTestService(t)
+	key, stored := storedKey(t)
+	recently := testNow.Add(-lastUsedInterval / 2)
+	stored.LastUsedAt = &recently
+
+	deps.apiKeyRepo.On("GetAPIKeyByPrefix", stored.Prefix).Return(stored, nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+
+	_, _, err := svc.AuthenticateAPIKey(context.Background(), key)
+
+	assert.NoError(t, err)
+	deps.apiKeyRepo.AssertNotCalled(t, "UpdateAPIKeyLastUsed", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateAPIKey_LastUsedFailureIgnored(t *testing.T) {
+	svc, deps := setupTestService(t)
+	key, stored := storedKey(t)
+
+	deps.apiKeyRepo.On("GetAPIKeyByPrefix", stored.Prefix).Return(stored, nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.apiKeyRepo.On("UpdateAPIKeyLastUsed", 7, testNow).Return(errors.New("database is locked"))
+
+	_, _, err := svc.AuthenticateAPIKey(context.Background(), key)
+
+	assert.NoError(t, err)
+}
+
+func TestAuthenticateAPIKey_Rejected(t *testing.T) {
+	past := testNow.Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		key    func(key string) string
+		stored func(stored *entity.APIKey)
+		user   *entity.User
+	}{
+		{name: "malformed", key: func(string) string { return "gbt_nope" }},
+		{name: "wrong secret", key: func(key string) string { return key[:len(key)-4] + "AAAA" }},
+		{name: "revoked", stored: func(k *entity.APIKey) { k.RevokedAt = &past }},
+		{name: "expired", stored: func(k *entity.APIKey) { k.ExpiresAt = &past }},
+		{name: "inactive owner", user: &entity.User{Id: 1, Role: entity.RoleUser, IsActive: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(t)
+			key, stored := storedKey(t)
+			if tt.key != nil {
+				key = tt.key(key)
+			}
+			if tt.stored != nil {
+				tt.stored(stored)
+			}
+			user := tt.user
+			if user == nil {
+				user = activeUser()
+			}
+
+			deps.apiKeyRepo.On("GetAPIKeyByPrefix", stored.Prefix).Return(stored, nil)
+			deps.userRepo.On("GetUserById", 1).Return(user, nil)
+
+			_, _, err := svc.AuthenticateAPIKey(context.Background(), key)
+
+			assert.Equal(t, domain.InvalidAPIKeyError{}, err)
+			deps.apiKeyRepo.AssertNotCalled(t, "UpdateAPIKeyLastUsed", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticateAPIKey_UnknownPrefix(t *testing.T) {
+	svc, deps := setupTestService(t)
+	key, stored := storedKey(t)
+
+	deps.apiKeyRepo.On("GetAPIKeyByPrefix", stored.Prefix).Return(nil, repository.ErrAPIKeyNotFound)
+
+	_, _, err := svc.AuthenticateAPIKey(context.Background(), key)
+
+	assert.Equal(t, domain.InvalidAPIKeyError{}, err)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Service Dependencies ==========
+// Interfaces that the auth service depends on (injected from outside)
+
+// IUserRepository defines the user data access needed by the auth service.
+type IUserRepository interface {
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+	IncrementUserTokenVersion(ctx context.Context, id int) error
+}
+
+// IRefreshTokenRepository defines the interface for refresh token data access.
+type IRefreshTokenRepository interface {
+	InsertRefreshToken(ctx context.Context, token *entity.RefreshToken) (int, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id int) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyId string) error
+	RevokeUserRefreshTokens(ctx context.Context, userId int) error
+}
+
+// IRevokedTokenRepository defines the interface for the access token denylist.
+type IRevokedTokenRepository interface {
+	InsertRevokedToken(ctx context.Context, tokenId string, userId int, expiresAt time.Time) error
+	ExistsRevokedToken(ctx context.Context, tokenId string) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+}
+
+// ITxManager runs a function inside a database transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ITokenGenerator defines the interface for access token generation.
+type ITokenGenerator interface {
+	GenerateToken(userId int, role string, tokenVersion int) (string, error)
+}
@@ -0,0 +1,18 @@
+package auth
+
+import "time"
+
+// ========== Refresh ==========
+
+type RefreshInput struct {
+	RefreshToken string
+}
+
+// ========== Logout ==========
+
+type LogoutInput struct {
+	UserId         int
+	TokenId        string
+	TokenExpiresAt time.Time
+	RefreshToken   string // optional; its token family is revoked too
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// userTokenState is the cached part of a user that decides whether their tokens are still valid.
+type userTokenState struct {
+	exists       bool
+	isActive     bool
+	tokenVersion int
+}
+
+// ========== Logout ==========
+
+// Logout revokes the current access token and, if given, the refresh token family it belongs to.
+func (s *Service) Logout(ctx context.Context, input *LogoutInput) error {
+	// Opportunistically drop denylist entries that no longer matter
+	if err := s.revokedTokenRepo.DeleteExpiredRevokedTokens(ctx); err != nil {
+		return domain.InternalServerError{Msg: "failed to clean up revoked tokens", Err: err}
+	}
+
+	if err := s.revokedTokenRepo.InsertRevokedToken(ctx, input.TokenId, input.UserId, input.TokenExpiresAt); err != nil {
+		return domain.InternalServerError{Msg: "failed to revoke access token", Err: err}
+	}
+	s.revokedTokenIds.Set(input.TokenId, true)
+
+	if input.RefreshToken == "" {
+		return nil
+	}
+
+	token, err := s.tokenRepo.GetRefreshTokenByHash(ctx, pkgAuth.HashOpaqueToken(input.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil // nothing to revoke
+		}
+		return domain.InternalServerError{Msg: "failed to get refresh token", Err: err}
+	}
+
+	// Never let a user revoke someone else's session
+	if token.UserId != input.UserId {
+		return nil
+	}
+
+	if err := s.tokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyId); err != nil {
+		return domain.InternalServerError{Msg: "failed to revoke refresh token family", Err: err}
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every access and refresh token of the user ("logout everywhere").
+func (s *Service) LogoutAll(ctx context.Context, userId int) error {
+	if err := s.userRepo.IncrementUserTokenVersion(ctx, userId); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.UserNotFoundError{Id: userId}
+		}
+		return domain.InternalServerError{Msg: "failed to increment token version", Err: err}
+	}
+	s.userTokenStates.Delete(userId)
+
+	if err := s.tokenRepo.RevokeUserRefreshTokens(ctx, userId); err != nil {
+		return domain.InternalServerError{Msg: "failed to revoke refresh tokens", Err: err}
+	}
+
+	return nil
+}
+
+// ForgetUserTokenState drops the cached token state of the user, so that changes made by other
+// services (deactivation, deletion, a new token version) apply on this instance right away
+// rather than after the revocation cache TTL.
+func (s *Service) ForgetUserTokenState(userId int) {
+	s.userTokenStates.Delete(userId)
+}
+
+// ========== Revocation Check ==========
+
+// IsTokenRevoked reports whether a validated access token has been revoked, either individually
+// (logout) or through its user (logout everywhere, deactivation, deletion).
+// Results are cached in memory for the configured revocation cache TTL.
+func (s *Service) IsTokenRevoked(ctx context.Context, userId int, tokenId string, tokenVersion int) (bool, error) {
+	state, err := s.getUserTokenState(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	if !state.exists || !state.isActive || state.tokenVersion != tokenVersion {
+		return true, nil
+	}
+
+	revoked, ok := s.revokedTokenIds.Get(tokenId)
+	if !ok {
+		revoked, err = s.revokedTokenRepo.ExistsRevokedToken(ctx, tokenId)
+		if err != nil {
+			return false, domain.InternalServerError{Msg: "failed to check revoked token", Err: err}
+		}
+		s.revokedTokenIds.Set(tokenId, revoked)
+	}
+
+	return revoked, nil
+}
+
+func (s *Service) getUserTokenState(ctx context.Context, userId int) (userTokenState, error) {
+	if state, ok := s.userTokenStates.Get(userId); ok {
+		return state, nil
+	}
+
+	var state userTokenState
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		state = userTokenState{exists: false}
+	case err != nil:
+		return userTokenState{}, domain.InternalServerError{Msg: "failed to get user", Err: err}
+	default:
+		state = userTokenState{exists: true, isActive: user.IsActive, tokenVersion: user.TokenVersion}
+	}
+
+	s.userTokenStates.Set(userId, state)
+	return state, nil
+}
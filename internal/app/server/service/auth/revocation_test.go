@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Logout Tests ==========
+
+func TestLogout_AccessTokenOnly(t *testing.T) {
+	svc, _, mockTokenRepo, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	expiresAt := time.Now().Add(time.Minute)
+	mockRevokedRepo.On("DeleteExpiredRevokedTokens").Return(nil)
+	mockRevokedRepo.On("InsertRevokedToken", "jti-1", 1, expiresAt).Return(nil)
+
+	err := svc.Logout(context.Background(), &LogoutInput{UserId: 1, TokenId: "jti-1", TokenExpiresAt: expiresAt})
+
+	assert.NoError(t, err)
+	mockRevokedRepo.AssertExpectations(t)
+	mockTokenRepo.AssertNotCalled(t, "GetRefreshTokenByHash", mock.Anything)
+}
+
+func TestLogout_WithRefreshToken(t *testing.T) {
+	svc, _, mockTokenRepo, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	token := activeRefreshToken("refresh_token")
+	mockRevokedRepo.On("DeleteExpiredRevokedTokens").Return(nil)
+	mockRevokedRepo.On("InsertRevokedToken", "jti-1", 1, mock.Anything).Return(nil)
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", "family-1").Return(nil)
+
+	err := svc.Logout(context.Background(), &LogoutInput{UserId: 1, TokenId: "jti-1", RefreshToken: "refresh_token"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestLogout_RefreshTokenOfAnotherUser(t *testing.T) {
+	svc, _, mockTokenRepo, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	token := activeRefreshToken("refresh_token")
+	token.UserId = 2
+	mockRevokedRepo.On("DeleteExpiredRevokedTokens").Return(nil)
+	mockRevokedRepo.On("InsertRevokedToken", "jti-1", 1, mock.Anything).Return(nil)
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+
+	err := svc.Logout(context.Background(), &LogoutInput{UserId: 1, TokenId: "jti-1", RefreshToken: "refresh_token"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything)
+}
+
+func TestLogout_RevokesImmediately(t *testing.T) {
+	svc, mockUserRepo, _, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true}, nil)
+	mockRevokedRepo.On("ExistsRevokedToken", "jti-1").Return(false, nil).Once()
+	mockRevokedRepo.On("DeleteExpiredRevokedTokens").Return(nil)
+	mockRevokedRepo.On("InsertRevokedToken", "jti-1", 1, mock.Anything).Return(nil)
+
+	// Cache a negative lookup first
+	revoked, err := svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	err = svc.Logout(context.Background(), &LogoutInput{UserId: 1, TokenId: "jti-1"})
+	assert.NoError(t, err)
+
+	// The local cache must reflect the logout without waiting for the TTL
+	revoked, err = svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockRevokedRepo.AssertExpectations(t)
+}
+
+// ========== LogoutAll Tests ==========
+
+func TestLogoutAll_Success(t *testing.T) {
+	svc, mockUserRepo, mockTokenRepo, _, _ := setupTestServiceWithRevocation()
+
+	mockUserRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	mockTokenRepo.On("RevokeUserRefreshTokens", 1).Return(nil)
+
+	err := svc.LogoutAll(context.Background(), 1)
+
+	assert.NoError(t, err)
+	mockUserRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestLogoutAll_UserNotFound(t *testing.T) {
+	svc, mockUserRepo, _, _, _ := setupTestServiceWithRevocation()
+
+	mockUserRepo.On("IncrementUserTokenVersion", 999).Return(repository.ErrUserNotFound)
+
+	err := svc.LogoutAll(context.Background(), 999)
+
+	assert.Error(t, err)
+	assert.IsType(t, domain.UserNotFoundError{}, err)
+}
+
+func TestLogoutAll_InvalidatesCachedVersion(t *testing.T) {
+	svc, mockUserRepo, mockTokenRepo, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true, TokenVersion: 0}, nil).Once()
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true, TokenVersion: 1}, nil).Once()
+	mockUserRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	mockTokenRepo.On("RevokeUserRefreshTokens", 1).Return(nil)
+	mockRevokedRepo.On("ExistsRevokedToken", "jti-1").Return(false, nil)
+
+	revoked, err := svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, svc.LogoutAll(context.Background(), 1))
+
+	revoked, err = svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestForgetUserTokenState(t *testing.T) {
+	svc, mockUserRepo, _, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	// The user service changed the password, incrementing the token version
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true, TokenVersion: 0}, nil).Once()
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true, TokenVersion: 1}, nil).Once()
+	mockRevokedRepo.On("ExistsRevokedToken", "jti-1").Return(false, nil)
+
+	revoked, err := svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	svc.ForgetUserTokenState(1)
+
+	revoked, err = svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// ========== IsTokenRevoked Tests ==========
+
+func TestIsTokenRevoked(t *testing.T) {
+	testCases := []struct {
+		name         string
+		user         *entity.User
+		userErr      error
+		inDenylist   bool
+		tokenVersion int
+		expected     bool
+	}{
+		{"valid token", &entity.User{Id: 1, IsActive: true, TokenVersion: 2}, nil, false, 2, false},
+		{"denylisted token", &entity.User{Id: 1, IsActive: true, TokenVersion: 2}, nil, true, 2, true},
+		{"outdated token version", &entity.User{Id: 1, IsActive: true, TokenVersion: 3}, nil, false, 2, true},
+		{"inactive user", &entity.User{Id: 1, IsActive: false, TokenVersion: 2}, nil, false, 2, true},
+		{"deleted user", nil, repository.ErrUserNotFound, false, 2, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, mockUserRepo, _, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+			mockUserRepo.On("GetUserById", 1).Return(tc.user, tc.userErr)
+			mockRevokedRepo.On("ExistsRevokedToken", "jti-1").Return(tc.inDenylist, nil)
+
+			revoked, err := svc.IsTokenRevoked(context.Background(), 1, "jti-1", tc.tokenVersion)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, revoked)
+		})
+	}
+}
+
+func TestIsTokenRevoked_CachesLookups(t *testing.T) {
+	svc, mockUserRepo, _, mockRevokedRepo, _ := setupTestServiceWithRevocation()
+
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true}, nil).Once()
+	mockRevokedRepo.On("ExistsRevokedToken", "jti-1").Return(false, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		revoked, err := svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	}
+
+	mockUserRepo.AssertNumberOfCalls(t, "GetUserById", 1)
+	mockRevokedRepo.AssertNumberOfCalls(t, "ExistsRevokedToken", 1)
+}
+
+func TestIsTokenRevoked_RepositoryError(t *testing.T) {
+	svc, mockUserRepo, _, _, _ := setupTestServiceWithRevocation()
+
+	mockUserRepo.On("GetUserById", 1).Return(nil, errors.New("db down"))
+
+	_, err := svc.IsTokenRevoked(context.Background(), 1, "jti-1", 0)
+
+	assert.Error(t, err)
+	assert.IsType(t, domain.InternalServerError{}, err)
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/cache"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+var (
+	errNilUserRepository         = errors.New("user repository is nil")
+	errNilTokenRepository        = errors.New("refresh token repository is nil")
+	errNilRevokedTokenRepository = errors.New("revoked token repository is nil")
+	errNilTxManager              = errors.New("transaction manager is nil")
+	errNilTokenGenerator         = errors.New("token generator is nil")
+)
+
+const (
+	defaultRefreshTokenDuration = 30 * 24 * time.Hour
+	defaultRevocationCacheTTL   = 30 * time.Second
+)
+
+// Config holds auth service configuration.
+type Config struct {
+	RefreshTokenDuration time.Duration
+	// RevocationCacheTTL bounds how long revocation state is cached in memory.
+	// Revocations made by other instances take at most this long to be noticed.
+	RevocationCacheTTL time.Duration
+}
+
+// Service handles token issuance, rotation and revocation.
+type Service struct {
+	userRepo             IUserRepository
+	tokenRepo            IRefreshTokenRepository
+	revokedTokenRepo     IRevokedTokenRepository
+	txManager            ITxManager
+	tokenGenerator       ITokenGenerator
+	refreshTokenDuration time.Duration
+	userTokenStates      *cache.TTLCache[int, userTokenState]
+	revokedTokenIds      *cache.TTLCache[string, bool]
+	now                  func() time.Time
+}
+
+// NewService creates a new auth service.
+func NewService(
+	userRepo IUserRepository,
+	tokenRepo IRefreshTokenRepository,
+	revokedTokenRepo IRevokedTokenRepository,
+	txManager ITxManager,
+	tokenGenerator ITokenGenerator,
+	config Config,
+) (*Service, error) {
+	if userRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create auth service", Err: errNilUserRepository}
+	}
+	if tokenRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create auth service", Err: errNilTokenRepository}
+	}
+	if revokedTokenRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create auth service", Err: errNilRevokedTokenRepository}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create auth service", Err: errNilTxManager}
+	}
+	if tokenGenerator == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create auth service", Err: errNilTokenGenerator}
+	}
+
+	refreshTokenDuration := config.RefreshTokenDuration
+	if refreshTokenDuration <= 0 {
+		refreshTokenDuration = defaultRefreshTokenDuration
+	}
+
+	revocationCacheTTL := config.RevocationCacheTTL
+	if revocationCacheTTL <= 0 {
+		revocationCacheTTL = defaultRevocationCacheTTL
+	}
+
+	return &Service{
+		userRepo:             userRepo,
+		tokenRepo:            tokenRepo,
+		revokedTokenRepo:     revokedTokenRepo,
+		txManager:            txManager,
+		tokenGenerator:       tokenGenerator,
+		refreshTokenDuration: refreshTokenDuration,
+		userTokenStates:      cache.NewTTLCache[int, userTokenState](revocationCacheTTL),
+		revokedTokenIds:      cache.NewTTLCache[string, bool](revocationCacheTTL),
+		now:                  time.Now,
+	}, nil
+}
+
+// TokenPair is a short-lived access token together with its refresh token.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// ========== Issue Tokens ==========
+
+// IssueTokens issues an access token and a refresh token that starts a new token family.
+func (s *Service) IssueTokens(ctx context.Context, user *entity.User) (*TokenPair, error) {
+	familyId, err := pkgAuth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to generate token family", Err: err}
+	}
+
+	return s.issueTokens(ctx, user, familyId)
+}
+
+// ========== Refresh ==========
+
+// Refresh rotates a refresh token: the presented token is revoked and a new pair is issued
+// in the same family. Presenting an already rotated token is treated as token theft, and the
+// whole family is revoked so that neither the attacker nor the victim can keep using it.
+//
+// The lookup, revocation and new token are written in one transaction, and the revocation only
+// succeeds for a token that is still active, so that of two concurrent requests with the same
+// token exactly one rotates it and the other is treated as reuse.
+func (s *Service) Refresh(ctx context.Context, input *RefreshInput) (*TokenPair, error) {
+	var pair *TokenPair
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		token, err := s.tokenRepo.GetRefreshTokenByHash(ctx, pkgAuth.HashOpaqueToken(input.RefreshToken))
+		if err != nil {
+			if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+				return domain.UnauthorizedError{Reason: "invalid refresh token"}
+			}
+			return domain.InternalServerError{Msg: "failed to get refresh token", Err: err}
+		}
+
+		// Reuse of a rotated token
+		if token.IsRevoked() {
+			return familyRevocation{familyId: token.FamilyId, reason: "refresh token reuse detected"}
+		}
+
+		if token.IsExpired(s.now()) {
+			return domain.UnauthorizedError{Reason: "refresh token has expired"}
+		}
+
+		// The user may have been deactivated or deleted since the token was issued
+		user, err := s.userRepo.GetUserById(ctx, token.UserId)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return familyRevocation{familyId: token.FamilyId, reason: "invalid refresh token"}
+			}
+			return domain.InternalServerError{Msg: "failed to get user", Err: err}
+		}
+		if !user.IsActive {
+			return familyRevocation{familyId: token.FamilyId, reason: "invalid refresh token"}
+		}
+
+		// Revoke the presented token; no row is revoked when another request rotated it first
+		if err := s.tokenRepo.RevokeRefreshToken(ctx, token.Id); err != nil {
+			if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+				return familyRevocation{familyId: token.FamilyId, reason: "refresh token reuse detected"}
+			}
+			return domain.InternalServerError{Msg: "failed to revoke refresh token", Err: err}
+		}
+
+		pair, err = s.issueTokens(ctx, user, token.FamilyId)
+		return err
+	})
+
+	// The family is revoked once the transaction has rolled back, which would undo it otherwise
+	var revocation familyRevocation
+	if errors.As(err, &revocation) {
+		return nil, s.revokeFamily(ctx, revocation.familyId, revocation.reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// ========== Helpers ==========
+
+func (s *Service) issueTokens(ctx context.Context, user *entity.User, familyId string) (*TokenPair, error) {
+	accessToken, err := s.tokenGenerator.GenerateToken(user.Id, user.Role, user.TokenVersion)
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to generate access token", Err: err}
+	}
+
+	refreshToken, err := pkgAuth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to generate refresh token", Err: err}
+	}
+
+	token := &entity.RefreshToken{
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: pkgAuth.HashOpaqueToken(refreshToken),
+		ExpiresAt: s.now().Add(s.refreshTokenDuration),
+	}
+	if _, err := s.tokenRepo.InsertRefreshToken(ctx, token); err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to store refresh token", Err: err}
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// familyRevocation aborts the Refresh transaction when the token family must be revoked.
+type familyRevocation struct {
+	familyId string
+	reason   string
+}
+
+func (e familyRevocation) Error() string {
+	return e.reason
+}
+
+// revokeFamily revokes a token family and returns the unauthorized error to report.
+func (s *Service) revokeFamily(ctx context.Context, familyId, reason string) error {
+	if err := s.tokenRepo.RevokeRefreshTokenFamily(ctx, familyId); err != nil {
+		return domain.InternalServerError{Msg: "failed to revoke refresh token family", Err: err}
+	}
+	return domain.UnauthorizedError{Reason: reason}
+}
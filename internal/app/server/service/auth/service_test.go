@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Mock Repositories ==========
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) IncrementUserTokenVersion(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) InsertRefreshToken(ctx context.Context, token *entity.RefreshToken) (int, error) {
+	args := m.Called(token)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeRefreshToken(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyId string) error {
+	args := m.Called(familyId)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevokedTokenRepository) InsertRevokedToken(ctx context.Context, tokenId string, userId int, expiresAt time.Time) error {
+	args := m.Called(tokenId, userId, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRevokedTokenRepository) ExistsRevokedToken(ctx context.Context, tokenId string) (bool, error) {
+	args := m.Called(tokenId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRevokedTokenRepository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// ========== Mock Token Generator ==========
+
+type MockTokenGenerator struct {
+	mock.Mock
+}
+
+func (m *MockTokenGenerator) GenerateToken(userId int, role string, tokenVersion int) (string, error) {
+	args := m.Called(userId, role, tokenVersion)
+	return args.String(0), args.Error(1)
+}
+
+// ========== Fake Transaction Manager ==========
+
+// fakeTxManager runs fn directly, like a transaction that commits when fn succeeds.
+type fakeTxManager struct {
+	calls int
+	inTx  bool
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	f.inTx = true
+	defer func() { f.inTx = false }()
+	return fn(ctx)
+}
+
+// ========== Test Helper ==========
+
+func setupTestService() (*Service, *MockUserRepository, *MockRefreshTokenRepository, *MockTokenGenerator) {
+	service, mockUserRepo, mockTokenRepo, _, mockGenerator := setupTestServiceWithRevocation()
+	return service, mockUserRepo, mockTokenRepo, mockGenerator
+}
+
+func setupTestServiceWithRevocation() (*Service, *MockUserRepository, *MockRefreshTokenRepository, *MockRevokedTokenRepository, *MockTokenGenerator) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockRefreshTokenRepository)
+	mockRevokedRepo := new(MockRevokedTokenRepository)
+	mockGenerator := new(MockTokenGenerator)
+	service, _ := NewService(mockUserRepo, mockTokenRepo, mockRevokedRepo, &fakeTxManager{}, mockGenerator, Config{RefreshTokenDuration: time.Hour})
+	return service, mockUserRepo, mockTokenRepo, mockRevokedRepo, mockGenerator
+}
+
+func activeRefreshToken(refreshToken string) *entity.RefreshToken {
+	return &entity.RefreshToken{
+		Id:        10,
+		UserId:    1,
+		FamilyId:  "family-1",
+		TokenHash: pkgAuth.HashOpaqueToken(refreshToken),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+// ========== IssueTokens Tests ==========
+
+func TestIssueTokens_Success(t *testing.T) {
+	svc, _, mockTokenRepo, mockGenerator := setupTestService()
+
+	user := &entity.User{Id: 1, Role: entity.RoleUser, IsActive: true}
+
+	mockGenerator.On("GenerateToken", 1, entity.RoleUser, 0).Return("access_token", nil)
+	mockTokenRepo.On("InsertRefreshToken", mock.MatchedBy(func(token *entity.RefreshToken) bool {
+		return token.UserId == 1 && token.FamilyId != "" && token.TokenHash != ""
+	})).Return(1, nil)
+
+	pair, err := svc.IssueTokens(context.Background(), user)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "access_token", pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
+	mockGenerator.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestIssueTokens_StoresOnlyHash(t *testing.T) {
+	svc, _, mockTokenRepo, mockGenerator := setupTestService()
+
+	var stored *entity.RefreshToken
+	mockGenerator.On("GenerateToken", 1, entity.RoleUser, 0).Return("access_token", nil)
+	mockTokenRepo.On("InsertRefreshToken", mock.AnythingOfType("*entity.RefreshToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*entity.RefreshToken) }).
+		Return(1, nil)
+
+	pair, err := svc.IssueTokens(context.Background(), &entity.User{Id: 1, Role: entity.RoleUser})
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, stored.TokenHash)
+	assert.Equal(t, pkgAuth.HashOpaqueToken(pair.RefreshToken), stored.TokenHash)
+}
+
+func TestIssueTokens_GenerateError(t *testing.T) {
+	svc, _, _, mockGenerator := setupTestService()
+
+	mockGenerator.On("GenerateToken", 1, entity.RoleUser, 0).Return("", errors.New("sign error"))
+
+	pair, err := svc.IssueTokens(context.Background(), &entity.User{Id: 1, Role: entity.RoleUser})
+
+	assert.Error(t, err)
+	assert.Nil(t, pair)
+	assert.IsType(t, domain.InternalServerError{}, err)
+}
+
+// ========== Refresh Tests ==========
+
+func TestRefresh_Success(t *testing.T) {
+	svc, mockUserRepo, mockTokenRepo, mockGenerator := setupTestService()
+
+	token := activeRefreshToken("old_refresh_token")
+	user := &entity.User{Id: 1, Role: entity.RoleUser, IsActive: true}
+
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+	mockUserRepo.On("GetUserById", 1).Return(user, nil)
+	mockTokenRepo.On("RevokeRefreshToken", 10).Return(nil)
+	mockGenerator.On("GenerateToken", 1, entity.RoleUser, 0).Return("new_access_token", nil)
+	mockTokenRepo.On("InsertRefreshToken", mock.MatchedBy(func(t *entity.RefreshToken) bool {
+		return t.FamilyId == "family-1" // rotation stays in the same family
+	})).Return(11, nil)
+
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "old_refresh_token"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new_access_token", pair.AccessToken)
+	assert.NotEqual(t, "old_refresh_token", pair.RefreshToken)
+	assert.Equal(t, 1, svc.txManager.(*fakeTxManager).calls)
+	mockTokenRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+	mockGenerator.AssertExpectations(t)
+}
+
+func TestRefresh_UnknownToken(t *testing.T) {
+	svc, _, mockTokenRepo, _ := setupTestService()
+
+	mockTokenRepo.On("GetRefreshTokenByHash", mock.Anything).Return(nil, repository.ErrRefreshTokenNotFound)
+
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "unknown"})
+
+	assert.Error(t, err)
+	assert.Nil(t, pair)
+	assert.IsType(t, domain.UnauthorizedError{}, err)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	svc, _, mockTokenRepo, _ := setupTestService()
+
+	token := activeRefreshToken("rotated_token")
+	revokedAt := time.Now().Add(-time.Minute)
+	token.RevokedAt = &revokedAt
+
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", "family-1").Return(nil)
+
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "rotated_token"})
+
+	assert.Error(t, err)
+	assert.Nil(t, pair)
+	assert.Equal(t, domain.UnauthorizedError{Reason: "refresh token reuse detected"}, err)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestRefresh_ConcurrentRotationRevokesFamily(t *testing.T) {
+	svc, mockUserRepo, mockTokenRepo, _ := setupTestService()
+
+	token := activeRefreshToken("raced_token")
+
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: true}, nil)
+	mockTokenRepo.On("RevokeRefreshToken", 10).Return(repository.ErrRefreshTokenNotFound)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", "family-1").Run(func(mock.Arguments) {
+		// A revocation made in the failed transaction would be rolled back
+		assert.False(t, svc.txManager.(*fakeTxManager).inTx, "family must be revoked outside the transaction")
+	}).Return(nil)
+
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "raced_token"})
+
+	assert.Error(t, err)
+	assert.Nil(t, pair)
+	assert.Equal(t, domain.UnauthorizedError{Reason: "refresh token reuse detected"}, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockTokenRepo.AssertNotCalled(t, "InsertRefreshToken", mock.Anything)
+}
+
+func TestRefresh_InsertFails(t *testing.T) {
+	svc, mockUserRepo, mockTokenRepo, mockGenerator := setupTestService()
+
+	token := activeRefreshToken("token")
+
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: entity.RoleUser, IsActive: true}, nil)
+	mockTokenRepo.On("RevokeRefreshToken", 10).Return(nil)
+	mockGenerator.On("GenerateToken", 1, entity.RoleUser, 0).Return("new_access_token", nil)
+	mockTokenRepo.On("InsertRefreshToken", mock.Anything).Return(0, errors.New("db error"))
+
+	// The error rolls back the revocation, so the presented token stays usable
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "token"})
+
+	assert.Nil(t, pair)
+	assert.IsType(t, domain.InternalServerError{}, err)
+	mockTokenRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything)
+}
+
+func TestRefresh_ExpiredToken(t *testing.T) {
+	svc, _, mockTokenRepo, _ := setupTestService()
+
+	token := activeRefreshToken("expired_token")
+	token.ExpiresAt = time.Now().Add(-time.Minute)
+
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "expired_token"})
+
+	assert.Error(t, err)
+	assert.Nil(t, pair)
+	assert.Equal(t, domain.UnauthorizedError{Reason: "refresh token has expired"}, err)
+	mockTokenRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything)
+}
+
+func TestRefresh_InactiveUser(t *testing.T) {
+	svc, mockUserRepo, mockTokenRepo, _ := setupTestService()
+
+	token := activeRefreshToken("token")
+
+	mockTokenRepo.On("GetRefreshTokenByHash", token.TokenHash).Return(token, nil)
+	mockUserRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, IsActive: false}, nil)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", "family-1").Return(nil)
+
+	pair, err := svc.Refresh(context.Background(), &RefreshInput{RefreshToken: "token"})
+
+	assert.Error(t, err)
+	assert.Nil(t, pair)
+	assert.IsType(t, domain.UnauthorizedError{}, err)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+// ========== NewService Tests ==========
+
+func TestNewService_NilDependencies(t *testing.T) {
+	svc, err := NewService(nil, new(MockRefreshTokenRepository), new(MockRevokedTokenRepository), &fakeTxManager{}, new(MockTokenGenerator), Config{})
+	assert.Error(t, err)
+	assert.Nil(t, svc)
+
+	svc, err = NewService(new(MockUserRepository), nil, new(MockRevokedTokenRepository), &fakeTxManager{}, new(MockTokenGenerator), Config{})
+	assert.Error(t, err)
+	assert.Nil(t, svc)
+
+	svc, err = NewService(new(MockUserRepository), new(MockRefreshTokenRepository), nil, &fakeTxManager{}, new(MockTokenGenerator), Config{})
+	assert.Error(t, err)
+	assert.Nil(t, svc)
+
+	svc, err = NewService(new(MockUserRepository), new(MockRefreshTokenRepository), new(MockRevokedTokenRepository), nil, new(MockTokenGenerator), Config{})
+	assert.ErrorIs(t, err, errNilTxManager)
+	assert.Nil(t, svc)
+
+	svc, err = NewService(new(MockUserRepository), new(MockRefreshTokenRepository), new(MockRevokedTokenRepository), &fakeTxManager{}, nil, Config{})
+	assert.Error(t, err)
+	assert.Nil(t, svc)
+}
+
+func TestNewService_DefaultRefreshTokenDuration(t *testing.T) {
+	svc, err := NewService(new(MockUserRepository), new(MockRefreshTokenRepository), new(MockRevokedTokenRepository), &fakeTxManager{}, new(MockTokenGenerator), Config{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRefreshTokenDuration, svc.refreshTokenDuration)
+}
@@ -0,0 +1,196 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// Challenge is issued instead of access tokens when a login needs a second factor.
+// Its token completes the login, and is only valid for a short time.
+type Challenge struct {
+	Token     string
+	ExpiresAt time.Time
+	// EnrollmentRequired is set when the user's role requires a second factor that the user
+	// has not enrolled yet; the login completes with BeginChallengeEnrollment and
+	// ConfirmChallengeEnrollment instead of VerifyChallenge.
+	EnrollmentRequired bool
+}
+
+// ========== Start ==========
+
+// StartChallenge returns a challenge for a user whose password has just been verified,
+// or nil if the user signs in without a second factor.
+func (s *Service) StartChallenge(ctx context.Context, user *entity.User) (*Challenge, error) {
+	credential, err := s.getCredential(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := credential != nil && credential.IsConfirmed()
+	if !enabled && !s.requiredRoles[user.Role] {
+		return nil, nil
+	}
+
+	token, err := pkgAuth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to generate challenge token", Err: err}
+	}
+
+	expiresAt := s.now().Add(s.challengeDuration)
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		// Opportunistically drop tokens that can no longer be redeemed
+		if err := s.userTokenRepo.DeleteExpiredUserTokens(ctx); err != nil {
+			return domain.InternalServerError{Msg: "failed to clean up user tokens", Err: err}
+		}
+
+		// A user has at most one pending login challenge
+		if err := s.userTokenRepo.DeleteUserTokens(ctx, user.Id, entity.TokenPurposeMFAChallenge); err != nil {
+			return domain.InternalServerError{Msg: "failed to delete user tokens", Err: err}
+		}
+
+		_, err := s.userTokenRepo.InsertUserToken(ctx, &entity.UserToken{
+			UserId:    user.Id,
+			Purpose:   entity.TokenPurposeMFAChallenge,
+			TokenHash: pkgAuth.HashOpaqueToken(token),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to store challenge token", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Challenge{
+		Token:              token,
+		ExpiresAt:          expiresAt,
+		EnrollmentRequired: !enabled,
+	}, nil
+}
+
+// ========== Verify ==========
+
+// VerifyChallenge completes a login challenge with a TOTP code or a recovery code and returns
+// the signed-in user. The challenge token stays valid after a wrong code, until it expires;
+// the returned domain.InvalidMFACodeError identifies the user so that callers can count
+// the failure towards the login lockout.
+func (s *Service) VerifyChallenge(ctx context.Context, input *VerifyChallengeInput) (*entity.User, error) {
+	var user *entity.User
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		challengeUser, token, err := s.findChallenge(ctx, input.Token)
+		if err != nil {
+			return err
+		}
+
+		if err := s.verifySecondFactor(ctx, challengeUser.Id, input.Code, input.RecoveryCode); err != nil {
+			return err
+		}
+
+		if err := s.redeemChallenge(ctx, token); err != nil {
+			return err
+		}
+
+		user = challengeUser
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ========== Enrollment ==========
+
+// BeginChallengeEnrollment starts the enrollment of a user who must enroll a second factor
+// to complete their login. The challenge token stays valid.
+func (s *Service) BeginChallengeEnrollment(ctx context.Context, token string) (*Enrollment, error) {
+	user, _, err := s.findChallenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// ConfirmChallengeEnrollment confirms the enrollment started with BeginChallengeEnrollment,
+// which completes the login. It returns the signed-in user and their recovery codes.
+func (s *Service) ConfirmChallengeEnrollment(ctx context.Context, input *ConfirmChallengeEnrollmentInput) (*entity.User, []string, error) {
+	var (
+		user          *entity.User
+		recoveryCodes []string
+	)
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		challengeUser, token, err := s.findChallenge(ctx, input.Token)
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.confirmEnrollment(ctx, challengeUser.Id, input.Code)
+		if err != nil {
+			return err
+		}
+
+		if err := s.redeemChallenge(ctx, token); err != nil {
+			return err
+		}
+
+		user = challengeUser
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, recoveryCodes, nil
+}
+
+// ========== Helpers ==========
+
+// findChallenge returns the user of a pending login challenge, and the challenge token.
+// Challenges of users who have since been deactivated or locked out are invalid.
+func (s *Service) findChallenge(ctx context.Context, plainToken string) (*entity.User, *entity.UserToken, error) {
+	token, err := s.userTokenRepo.GetUserTokenByHash(ctx, pkgAuth.HashOpaqueToken(plainToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, nil, domain.InvalidTokenError{}
+		}
+		return nil, nil, domain.InternalServerError{Msg: "failed to get user token", Err: err}
+	}
+	now := s.now()
+	if token.Purpose != entity.TokenPurposeMFAChallenge || token.IsUsed() || token.IsExpired(now) {
+		return nil, nil, domain.InvalidTokenError{}
+	}
+
+	user, err := s.userRepo.GetUserById(ctx, token.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, nil, domain.InvalidTokenError{}
+		}
+		return nil, nil, domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+	if !user.IsActive || user.IsLocked(now) {
+		return nil, nil, domain.InvalidTokenError{}
+	}
+
+	return user, token, nil
+}
+
+// redeemChallenge consumes a challenge token. Must run inside a transaction.
+func (s *Service) redeemChallenge(ctx context.Context, token *entity.UserToken) error {
+	// Losing this race means a concurrent request completed the login first
+	if err := s.userTokenRepo.ConsumeUserToken(ctx, token.Id); err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return domain.InvalidTokenError{}
+		}
+		return domain.InternalServerError{Msg: "failed to consume user token", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package mfa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== StartChallenge Tests ==========
+
+func expectIssueChallenge(deps *testDeps, stored **entity.UserToken) {
+	deps.userTokenRepo.On("DeleteExpiredUserTokens").Return(nil)
+	deps.userTokenRepo.On("DeleteUserTokens", 1, entity.TokenPurposeMFAChallenge).Return(nil)
+	deps.userTokenRepo.On("InsertUserToken", mock.Anything).Run(func(args mock.Arguments) {
+		*stored = args.Get(0).(*entity.UserToken)
+	}).Return(1, nil)
+}
+
+func TestStartChallenge_NoSecondFactor(t *testing.T) {
+	svc, deps := setupTestService(Config{RequiredRoles: []string{entity.RoleAdmin}})
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(nil, repository.ErrTOTPCredentialNotFound)
+
+	challenge, err := svc.StartChallenge(context.Background(), activeUser())
+
+	assert.NoError(t, err)
+	assert.Nil(t, challenge, "users without a second factor sign in directly")
+	deps.userTokenRepo.AssertNotCalled(t, "InsertUserToken", mock.Anything)
+}
+
+func TestStartChallenge_PendingEnrollmentIsIgnored(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(&entity.TOTPCredential{UserId: 1, Secret: testSecret}, nil)
+
+	challenge, err := svc.StartChallenge(context.Background(), activeUser())
+
+	assert.NoError(t, err)
+	assert.Nil(t, challenge)
+}
+
+func TestStartChallenge_Enabled(t *testing.T) {
+	svc, deps := setupTestService(Config{ChallengeDuration: 2 * time.Minute})
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(confirmedCredential(), nil)
+
+	var stored *entity.UserToken
+	expectIssueChallenge(deps, &stored)
+
+	challenge, err := svc.StartChallenge(context.Background(), activeUser())
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, challenge) {
+		assert.False(t, challenge.EnrollmentRequired)
+		assert.Equal(t, testNow.Add(2*time.Minute), challenge.ExpiresAt)
+		if assert.NotNil(t, stored) {
+			assert.Equal(t, entity.TokenPurposeMFAChallenge, stored.Purpose)
+			assert.Equal(t, pkgAuth.HashOpaqueToken(challenge.Token), stored.TokenHash, "only the hash is stored")
+			assert.Equal(t, challenge.ExpiresAt, stored.ExpiresAt)
+		}
+	}
+}
+
+func TestStartChallenge_RequiredRoleWithoutEnrollment(t *testing.T) {
+	svc, deps := setupTestService(Config{RequiredRoles: []string{entity.RoleAdmin}})
+	admin := activeUser()
+	admin.Role = entity.RoleAdmin
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(nil, repository.ErrTOTPCredentialNotFound)
+
+	var stored *entity.UserToken
+	expectIssueChallenge(deps, &stored)
+
+	challenge, err := svc.StartChallenge(context.Background(), admin)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, challenge) {
+		assert.True(t, challenge.EnrollmentRequired)
+	}
+}
+
+// ========== VerifyChallenge Tests ==========
+
+func challengeToken() *entity.UserToken {
+	return &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposeMFAChallenge, ExpiresAt: testNow.Add(time.Minute)}
+}
+
+func TestVerifyChallenge_Success(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userTokenRepo.On("GetUserTokenByHash", pkgAuth.HashOpaqueToken("challenge")).Return(challengeToken(), nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(confirmedCredential(), nil)
+	deps.mfaRepo.On("UseTOTPStep", 1, pkgAuth.TOTPStep(testNow)).Return(nil)
+	deps.userTokenRepo.On("ConsumeUserToken", 7).Return(nil)
+
+	user, err := svc.VerifyChallenge(context.Background(), &VerifyChallengeInput{Token: "challenge", Code: codeAt(t, testNow)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, activeUser(), user)
+	deps.userTokenRepo.AssertExpectations(t)
+	deps.mfaRepo.AssertExpectations(t)
+}
+
+func TestVerifyChallenge_WrongCode(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(challengeToken(), nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(confirmedCredential(), nil)
+
+	user, err := svc.VerifyChallenge(context.Background(), &VerifyChallengeInput{Token: "challenge", Code: "000000"})
+
+	assert.Nil(t, user)
+	assert.Equal(t, domain.InvalidMFACodeError{UserId: 1}, err, "the user is identified so the failure can be counted")
+	deps.userTokenRepo.AssertNotCalled(t, "ConsumeUserToken", mock.Anything)
+}
+
+func TestVerifyChallenge_InvalidChallenge(t *testing.T) {
+	lockedUntil := testNow.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		token *entity.UserToken
+		user  *entity.User
+	}{
+		{
+			name:  "expired",
+			token: &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposeMFAChallenge, ExpiresAt: testNow},
+			user:  activeUser(),
+		},
+		{
+			name:  "other purpose",
+			token: &entity.UserToken{Id: 7, UserId: 1, Purpose: entity.TokenPurposePasswordReset, ExpiresAt: testNow.Add(time.Minute)},
+			user:  activeUser(),
+		},
+		{
+			name:  "locked user",
+			token: challengeToken(),
+			user:  &entity.User{Id: 1, IsActive: true, LockedUntil: &lockedUntil},
+		},
+		{
+			name:  "inactive user",
+			token: challengeToken(),
+			user:  &entity.User{Id: 1, IsActive: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(Config{})
+			deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(tt.token, nil)
+			deps.userRepo.On("GetUserById", 1).Return(tt.user, nil)
+
+			_, err := svc.VerifyChallenge(context.Background(), &VerifyChallengeInput{Token: "challenge", Code: codeAt(t, testNow)})
+
+			assert.Equal(t, domain.InvalidTokenError{}, err)
+			deps.mfaRepo.AssertNotCalled(t, "UseTOTPStep", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// ========== Challenge Enrollment Tests ==========
+
+func TestConfirmChallengeEnrollment_Success(t *testing.T) {
+	svc, deps := setupTestService(Config{RequiredRoles: []string{entity.RoleUser}})
+	deps.userTokenRepo.On("GetUserTokenByHash", mock.Anything).Return(challengeToken(), nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(&entity.TOTPCredential{UserId: 1, Secret: testSecret}, nil)
+	deps.mfaRepo.On("ConfirmTOTPCredential", 1, pkgAuth.TOTPStep(testNow)).Return(nil)
+	deps.mfaRepo.On("ReplaceRecoveryCodes", 1, mock.Anything).Return(nil)
+	deps.userTokenRepo.On("ConsumeUserToken", 7).Return(nil)
+
+	user, codes, err := svc.ConfirmChallengeEnrollment(context.Background(), &ConfirmChallengeEnrollmentInput{Token: "challenge", Code: codeAt(t, testNow)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.Id)
+	assert.Len(t, codes, defaultRecoveryCodeCount)
+	deps.userTokenRepo.AssertExpectations(t)
+}
@@ -0,0 +1,43 @@
+package mfa
+
+import (
+	"context"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Service Dependencies ==========
+// Interfaces that the MFA service depends on (injected from outside)
+
+// IUserRepository defines the user data access needed by the MFA service.
+type IUserRepository interface {
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+}
+
+// IMFARepository defines the interface for second factor data access.
+type IMFARepository interface {
+	UpsertTOTPCredential(ctx context.Context, credential *entity.TOTPCredential) error
+	GetTOTPCredential(ctx context.Context, userId int) (*entity.TOTPCredential, error)
+	ConfirmTOTPCredential(ctx context.Context, userId int, step int64) error
+	UseTOTPStep(ctx context.Context, userId int, step int64) error
+	DeleteTOTPCredential(ctx context.Context, userId int) error
+	ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userId int, codeHash string) error
+	CountRecoveryCodes(ctx context.Context, userId int) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, userId int) error
+}
+
+// IUserTokenRepository defines the single-use token data access used for login challenges.
+type IUserTokenRepository interface {
+	InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error)
+	GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error)
+	ConsumeUserToken(ctx context.Context, id int) error
+	DeleteUserTokens(ctx context.Context, userId int, purpose string) error
+	DeleteExpiredUserTokens(ctx context.Context) error
+}
+
+// ITxManager runs a function inside a database transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
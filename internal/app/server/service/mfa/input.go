@@ -0,0 +1,33 @@
+package mfa
+
+// ========== Enrollment ==========
+
+type ConfirmEnrollmentInput struct {
+	UserId int
+	Code   string
+}
+
+// ========== Second Factor ==========
+
+// VerifyInput proves possession of the second factor of a signed-in user,
+// with either a TOTP code or a recovery code.
+type VerifyInput struct {
+	UserId       int
+	Code         string
+	RecoveryCode string
+}
+
+// ========== Login Challenge ==========
+
+// VerifyChallengeInput completes a login challenge with either a TOTP code or a recovery code.
+type VerifyChallengeInput struct {
+	Token        string
+	Code         string
+	RecoveryCode string
+}
+
+// ConfirmChallengeEnrollmentInput completes a login challenge of a user who must enroll first.
+type ConfirmChallengeEnrollmentInput struct {
+	Token string
+	Code  string
+}
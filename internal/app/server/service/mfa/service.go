@@ -0,0 +1,396 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+var (
+	errNilUserRepository      = errors.New("user repository is nil")
+	errNilMFARepository       = errors.New("mfa repository is nil")
+	errNilUserTokenRepository = errors.New("user token repository is nil")
+	errNilTxManager           = errors.New("transaction manager is nil")
+)
+
+const (
+	defaultIssuer            = "Go Backend Template"
+	defaultChallengeDuration = 5 * time.Minute
+	defaultRecoveryCodeCount = 10
+)
+
+// Config holds MFA service configuration.
+type Config struct {
+	// Issuer names the account in authenticator apps.
+	Issuer string
+	// RequiredRoles lists the roles that cannot sign in without a second factor.
+	// Users in these roles who have not enrolled yet must enroll to complete their login.
+	RequiredRoles []string
+	// ChallengeDuration is the lifetime of the token that completes a login with a second factor.
+	ChallengeDuration time.Duration
+	// RecoveryCodeCount is the number of single-use recovery codes issued at a time.
+	RecoveryCodeCount int
+}
+
+// Service handles TOTP second factors: enrollment, recovery codes and login challenges.
+type Service struct {
+	userRepo          IUserRepository
+	mfaRepo           IMFARepository
+	userTokenRepo     IUserTokenRepository
+	txManager         ITxManager
+	issuer            string
+	requiredRoles     map[string]bool
+	challengeDuration time.Duration
+	recoveryCodeCount int
+	now               func() time.Time
+}
+
+// Status describes the second factor of a user.
+type Status struct {
+	Enabled                bool
+	Required               bool // the user's role requires a second factor
+	RecoveryCodesRemaining int
+}
+
+// Enrollment is a pending TOTP credential to add to an authenticator app.
+type Enrollment struct {
+	Secret string
+	URI    string // otpauth URI, usually shown as a QR code
+}
+
+// NewService creates a new MFA service.
+func NewService(
+	userRepo IUserRepository,
+	mfaRepo IMFARepository,
+	userTokenRepo IUserTokenRepository,
+	txManager ITxManager,
+	config Config,
+) (*Service, error) {
+	if userRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create mfa service", Err: errNilUserRepository}
+	}
+	if mfaRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create mfa service", Err: errNilMFARepository}
+	}
+	if userTokenRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create mfa service", Err: errNilUserTokenRepository}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create mfa service", Err: errNilTxManager}
+	}
+
+	issuer := config.Issuer
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+
+	requiredRoles := make(map[string]bool, len(config.RequiredRoles))
+	for _, role := range config.RequiredRoles {
+		requiredRoles[role] = true
+	}
+
+	challengeDuration := config.ChallengeDuration
+	if challengeDuration <= 0 {
+		challengeDuration = defaultChallengeDuration
+	}
+
+	recoveryCodeCount := config.RecoveryCodeCount
+	if recoveryCodeCount <= 0 {
+		recoveryCodeCount = defaultRecoveryCodeCount
+	}
+
+	return &Service{
+		userRepo:          userRepo,
+		mfaRepo:           mfaRepo,
+		userTokenRepo:     userTokenRepo,
+		txManager:         txManager,
+		issuer:            issuer,
+		requiredRoles:     requiredRoles,
+		challengeDuration: challengeDuration,
+		recoveryCodeCount: recoveryCodeCount,
+		now:               time.Now,
+	}, nil
+}
+
+// ========== Status ==========
+
+// GetStatus returns the second factor status of a user.
+func (s *Service) GetStatus(ctx context.Context, userId int) (*Status, error) {
+	user, err := s.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.getCredential(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Enabled:  credential != nil && credential.IsConfirmed(),
+		Required: s.requiredRoles[user.Role],
+	}
+	if status.Enabled {
+		count, err := s.mfaRepo.CountRecoveryCodes(ctx, userId)
+		if err != nil {
+			return nil, domain.InternalServerError{Msg: "failed to count recovery codes", Err: err}
+		}
+		status.RecoveryCodesRemaining = count
+	}
+
+	return status, nil
+}
+
+// ========== Enrollment ==========
+
+// BeginEnrollment generates a new TOTP secret for a user without a second factor.
+// The secret replaces any pending enrollment and takes effect once confirmed.
+func (s *Service) BeginEnrollment(ctx context.Context, userId int) (*Enrollment, error) {
+	user, err := s.getUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// ConfirmEnrollment enables a pending TOTP credential with a first code from the
+// authenticator app and returns the user's recovery codes. They are shown only once.
+func (s *Service) ConfirmEnrollment(ctx context.Context, input *ConfirmEnrollmentInput) ([]string, error) {
+	var recoveryCodes []string
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		recoveryCodes, err = s.confirmEnrollment(ctx, input.UserId, input.Code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// ========== Disable ==========
+
+// Disable removes the second factor of a user, who proves possession of it one last time.
+// Users whose role requires a second factor cannot disable it.
+func (s *Service) Disable(ctx context.Context, input *VerifyInput) error {
+	user, err := s.getUser(ctx, input.UserId)
+	if err != nil {
+		return err
+	}
+	if s.requiredRoles[user.Role] {
+		return domain.ForbiddenError{Reason: "two-factor authentication is required for your role"}
+	}
+
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.verifySecondFactor(ctx, input.UserId, input.Code, input.RecoveryCode); err != nil {
+			return err
+		}
+		return s.deleteSecondFactor(ctx, input.UserId)
+	})
+}
+
+// Reset removes the second factor of a user without proof of possession, e.g. when an admin
+// helps a user who lost both their authenticator and their recovery codes.
+// Users whose role requires a second factor must enroll again at their next login.
+func (s *Service) Reset(ctx context.Context, userId int) error {
+	if _, err := s.getUser(ctx, userId); err != nil {
+		return err
+	}
+
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		credential, err := s.getCredential(ctx, userId)
+		if err != nil || credential == nil {
+			return err
+		}
+		return s.deleteSecondFactor(ctx, userId)
+	})
+}
+
+// ========== Recovery Codes ==========
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user, who proves possession of the
+// second factor, and returns the new codes. They are shown only once.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, input *VerifyInput) ([]string, error) {
+	recoveryCodes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.verifySecondFactor(ctx, input.UserId, input.Code, input.RecoveryCode); err != nil {
+			return err
+		}
+		if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, input.UserId, hashes); err != nil {
+			return domain.InternalServerError{Msg: "failed to store recovery codes", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// ========== Helpers ==========
+
+func (s *Service) getUser(ctx context.Context, userId int) (*entity.User, error) {
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, domain.UserNotFoundError{Id: userId}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+	return user, nil
+}
+
+// getCredential returns the TOTP credential of a user, or nil if there is none.
+func (s *Service) getCredential(ctx context.Context, userId int) (*entity.TOTPCredential, error) {
+	credential, err := s.mfaRepo.GetTOTPCredential(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPCredentialNotFound) {
+			return nil, nil
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get totp credential", Err: err}
+	}
+	return credential, nil
+}
+
+func (s *Service) beginEnrollment(ctx context.Context, user *entity.User) (*Enrollment, error) {
+	credential, err := s.getCredential(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil && credential.IsConfirmed() {
+		return nil, domain.MFAAlreadyEnabledError{}
+	}
+
+	secret, err := pkgAuth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to generate totp secret", Err: err}
+	}
+
+	if err := s.mfaRepo.UpsertTOTPCredential(ctx, &entity.TOTPCredential{UserId: user.Id, Secret: secret}); err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to store totp credential", Err: err}
+	}
+
+	return &Enrollment{
+		Secret: secret,
+		URI:    pkgAuth.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// confirmEnrollment enables the pending credential of a user and issues their recovery codes.
+// Must run inside a transaction.
+func (s *Service) confirmEnrollment(ctx context.Context, userId int, code string) ([]string, error) {
+	credential, err := s.getCredential(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, domain.MFANotEnabledError{}
+	}
+	if credential.IsConfirmed() {
+		return nil, domain.MFAAlreadyEnabledError{}
+	}
+
+	step, ok := pkgAuth.VerifyTOTP(credential.Secret, code, s.now())
+	if !ok {
+		return nil, domain.InvalidMFACodeError{UserId: userId}
+	}
+
+	// Losing this race means a concurrent request confirmed the enrollment first
+	if err := s.mfaRepo.ConfirmTOTPCredential(ctx, userId, step); err != nil {
+		if errors.Is(err, repository.ErrTOTPCredentialNotFound) {
+			return nil, domain.MFAAlreadyEnabledError{}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to confirm totp credential", Err: err}
+	}
+
+	recoveryCodes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to store recovery codes", Err: err}
+	}
+
+	return recoveryCodes, nil
+}
+
+// verifySecondFactor checks a TOTP code or, if code is empty, a recovery code of a user with
+// an enabled second factor. Accepted codes cannot be used again. Must run inside a transaction.
+func (s *Service) verifySecondFactor(ctx context.Context, userId int, code, recoveryCode string) error {
+	credential, err := s.getCredential(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if credential == nil || !credential.IsConfirmed() {
+		return domain.MFANotEnabledError{}
+	}
+
+	if code == "" {
+		if recoveryCode == "" {
+			return domain.InvalidMFACodeError{UserId: userId}
+		}
+		err := s.mfaRepo.ConsumeRecoveryCode(ctx, userId, pkgAuth.HashRecoveryCode(recoveryCode))
+		if err != nil {
+			if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+				return domain.InvalidMFACodeError{UserId: userId}
+			}
+			return domain.InternalServerError{Msg: "failed to consume recovery code", Err: err}
+		}
+		return nil
+	}
+
+	step, ok := pkgAuth.VerifyTOTP(credential.Secret, code, s.now())
+	if !ok || step <= credential.LastUsedStep {
+		return domain.InvalidMFACodeError{UserId: userId}
+	}
+
+	// Losing this race means a concurrent request used the same code first
+	if err := s.mfaRepo.UseTOTPStep(ctx, userId, step); err != nil {
+		if errors.Is(err, repository.ErrTOTPCredentialNotFound) {
+			return domain.InvalidMFACodeError{UserId: userId}
+		}
+		return domain.InternalServerError{Msg: "failed to record totp code", Err: err}
+	}
+	return nil
+}
+
+// deleteSecondFactor removes the TOTP credential and recovery codes of a user.
+// Must run inside a transaction.
+func (s *Service) deleteSecondFactor(ctx context.Context, userId int) error {
+	if err := s.mfaRepo.DeleteTOTPCredential(ctx, userId); err != nil {
+		if errors.Is(err, repository.ErrTOTPCredentialNotFound) {
+			return domain.MFANotEnabledError{}
+		}
+		return domain.InternalServerError{Msg: "failed to delete totp credential", Err: err}
+	}
+	if err := s.mfaRepo.DeleteRecoveryCodes(ctx, userId); err != nil {
+		return domain.InternalServerError{Msg: "failed to delete recovery codes", Err: err}
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns new recovery codes and the hashes to store.
+func (s *Service) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, s.recoveryCodeCount)
+	hashes := make([]string, 0, s.recoveryCodeCount)
+	for i := 0; i < s.recoveryCodeCount; i++ {
+		code, err := pkgAuth.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, domain.InternalServerError{Msg: "failed to generate recovery code", Err: err}
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, pkgAuth.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,397 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Mock Repositories ==========
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) UpsertTOTPCredential(ctx context.Context, credential *entity.TOTPCredential) error {
+	args := m.Called(credential)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) GetTOTPCredential(ctx context.Context, userId int) (*entity.TOTPCredential, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TOTPCredential), args.Error(1)
+}
+
+func (m *MockMFARepository) ConfirmTOTPCredential(ctx context.Context, userId int, step int64) error {
+	args := m.Called(userId, step)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	args := m.Called(userId, step)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) DeleteTOTPCredential(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	args := m.Called(userId, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ConsumeRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	args := m.Called(userId, codeHash)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	args := m.Called(userId)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMFARepository) DeleteRecoveryCodes(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepository) InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error) {
+	args := m.Called(token)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserToken), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) ConsumeUserToken(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) DeleteUserTokens(ctx context.Context, userId int, purpose string) error {
+	args := m.Called(userId, purpose)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) DeleteExpiredUserTokens(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// ========== Fake Transaction Manager ==========
+
+// fakeTxManager runs fn directly, like a transaction that always commits.
+type fakeTxManager struct {
+	calls int
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+// ========== Test Helper ==========
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testSecret is a fixed TOTP secret; codeAt returns its codes.
+const testSecret = "JBSWY3DPEHPK3PXP"
+
+type testDeps struct {
+	userRepo      *MockUserRepository
+	mfaRepo       *MockMFARepository
+	userTokenRepo *MockUserTokenRepository
+	txManager     *fakeTxManager
+}
+
+func setupTestService(config Config) (*Service, *testDeps) {
+	deps := &testDeps{
+		userRepo:      new(MockUserRepository),
+		mfaRepo:       new(MockMFARepository),
+		userTokenRepo: new(MockUserTokenRepository),
+		txManager:     &fakeTxManager{},
+	}
+	svc, _ := NewService(deps.userRepo, deps.mfaRepo, deps.userTokenRepo, deps.txManager, config)
+	svc.now = func() time.Time { return testNow }
+	return svc, deps
+}
+
+func activeUser() *entity.User {
+	return &entity.User{Id: 1, Email: "test@example.com", Role: entity.RoleUser, IsActive: true}
+}
+
+func confirmedCredential() *entity.TOTPCredential {
+	confirmedAt := testNow.Add(-time.Hour)
+	return &entity.TOTPCredential{UserId: 1, Secret: testSecret, ConfirmedAt: &confirmedAt}
+}
+
+func codeAt(t *testing.T, at time.Time) string {
+	t.Helper()
+
+	code, err := pkgAuth.TOTPCode(testSecret, pkgAuth.TOTPStep(at))
+	if err != nil {
+		t.Fatalf("Failed to compute totp code: %v", err)
+	}
+	return code
+}
+
+// ========== NewService Tests ==========
+
+func TestNewService_NilDependencies(t *testing.T) {
+	_, err := NewService(nil, new(MockMFARepository), new(MockUserTokenRepository), &fakeTxManager{}, Config{})
+	assert.Error(t, err)
+
+	_, err = NewService(new(MockUserRepository), nil, new(MockUserTokenRepository), &fakeTxManager{}, Config{})
+	assert.Error(t, err)
+
+	_, err = NewService(new(MockUserRepository), new(MockMFARepository), nil, &fakeTxManager{}, Config{})
+	assert.Error(t, err)
+
+	_, err = NewService(new(MockUserRepository), new(MockMFARepository), new(MockUserTokenRepository), nil, Config{})
+	assert.Error(t, err)
+}
+
+// ========== Enrollment Tests ==========
+
+func TestBeginEnrollment_Success(t *testing.T) {
+	svc, deps := setupTestService(Config{Issuer: "Example"})
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(nil, repository.ErrTOTPCredentialNotFound)
+
+	var stored *entity.TOTPCredential
+	deps.mfaRepo.On("UpsertTOTPCredential", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*entity.TOTPCredential)
+	}).Return(nil)
+
+	enrollment, err := svc.BeginEnrollment(context.Background(), 1)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, 1, stored.UserId)
+		assert.Equal(t, stored.Secret, enrollment.Secret)
+	}
+	assert.Contains(t, enrollment.URI, "otpauth://totp/Example:test@example.com?")
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+}
+
+func TestBeginEnrollment_AlreadyEnabled(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(confirmedCredential(), nil)
+
+	_, err := svc.BeginEnrollment(context.Background(), 1)
+
+	assert.Equal(t, domain.MFAAlreadyEnabledError{}, err)
+	deps.mfaRepo.AssertNotCalled(t, "UpsertTOTPCredential", mock.Anything)
+}
+
+func TestConfirmEnrollment_Success(t *testing.T) {
+	svc, deps := setupTestService(Config{RecoveryCodeCount: 3})
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(&entity.TOTPCredential{UserId: 1, Secret: testSecret}, nil)
+	deps.mfaRepo.On("ConfirmTOTPCredential", 1, pkgAuth.TOTPStep(testNow)).Return(nil)
+
+	var storedHashes []string
+	deps.mfaRepo.On("ReplaceRecoveryCodes", 1, mock.Anything).Run(func(args mock.Arguments) {
+		storedHashes = args.Get(1).([]string)
+	}).Return(nil)
+
+	codes, err := svc.ConfirmEnrollment(context.Background(), &ConfirmEnrollmentInput{UserId: 1, Code: codeAt(t, testNow)})
+
+	assert.NoError(t, err)
+	assert.Len(t, codes, 3)
+	if assert.Len(t, storedHashes, 3) {
+		for i, code := range codes {
+			assert.Equal(t, pkgAuth.HashRecoveryCode(code), storedHashes[i], "only hashes are stored")
+		}
+	}
+	deps.mfaRepo.AssertExpectations(t)
+}
+
+func TestConfirmEnrollment_WrongCode(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(&entity.TOTPCredential{UserId: 1, Secret: testSecret}, nil)
+
+	_, err := svc.ConfirmEnrollment(context.Background(), &ConfirmEnrollmentInput{UserId: 1, Code: codeAt(t, testNow.Add(-time.Hour))})
+
+	assert.Equal(t, domain.InvalidMFACodeError{UserId: 1}, err)
+	deps.mfaRepo.AssertNotCalled(t, "ConfirmTOTPCredential", mock.Anything, mock.Anything)
+}
+
+func TestConfirmEnrollment_NothingPending(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(nil, repository.ErrTOTPCredentialNotFound)
+
+	_, err := svc.ConfirmEnrollment(context.Background(), &ConfirmEnrollmentInput{UserId: 1, Code: "123456"})
+
+	assert.Equal(t, domain.MFANotEnabledError{}, err)
+}
+
+// ========== Disable Tests ==========
+
+func TestDisable_WithCode(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(confirmedCredential(), nil)
+	deps.mfaRepo.On("UseTOTPStep", 1, pkgAuth.TOTPStep(testNow)).Return(nil)
+	deps.mfaRepo.On("DeleteTOTPCredential", 1).Return(nil)
+	deps.mfaRepo.On("DeleteRecoveryCodes", 1).Return(nil)
+
+	err := svc.Disable(context.Background(), &VerifyInput{UserId: 1, Code: codeAt(t, testNow)})
+
+	assert.NoError(t, err)
+	deps.mfaRepo.AssertExpectations(t)
+}
+
+func TestDisable_RequiredRole(t *testing.T) {
+	svc, deps := setupTestService(Config{RequiredRoles: []string{entity.RoleAdmin}})
+	admin := activeUser()
+	admin.Role = entity.RoleAdmin
+	deps.userRepo.On("GetUserById", 1).Return(admin, nil)
+
+	err := svc.Disable(context.Background(), &VerifyInput{UserId: 1, Code: codeAt(t, testNow)})
+
+	assert.IsType(t, domain.ForbiddenError{}, err)
+	deps.mfaRepo.AssertNotCalled(t, "DeleteTOTPCredential", mock.Anything)
+}
+
+func TestReset_WithoutSecondFactor(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(nil, repository.ErrTOTPCredentialNotFound)
+
+	err := svc.Reset(context.Background(), 1)
+
+	assert.NoError(t, err, "resetting is idempotent")
+	deps.mfaRepo.AssertNotCalled(t, "DeleteTOTPCredential", mock.Anything)
+}
+
+// ========== Second Factor Verification Tests ==========
+
+func TestRegenerateRecoveryCodes_VerifiesSecondFactor(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        *VerifyInput
+		currentCode  bool // sets input.Code to the code of testNow
+		lastUsedStep int64
+		setup        func(deps *testDeps)
+		wantErr      error
+	}{
+		{
+			name:        "current code",
+			input:       &VerifyInput{UserId: 1},
+			currentCode: true,
+			setup: func(deps *testDeps) {
+				deps.mfaRepo.On("UseTOTPStep", 1, pkgAuth.TOTPStep(testNow)).Return(nil)
+			},
+		},
+		{
+			name:         "replayed code",
+			input:        &VerifyInput{UserId: 1},
+			currentCode:  true,
+			lastUsedStep: pkgAuth.TOTPStep(testNow),
+			setup:        func(deps *testDeps) {},
+			wantErr:      domain.InvalidMFACodeError{UserId: 1},
+		},
+		{
+			name:  "recovery code",
+			input: &VerifyInput{UserId: 1, RecoveryCode: "ABCDE-FGHIJ"},
+			setup: func(deps *testDeps) {
+				deps.mfaRepo.On("ConsumeRecoveryCode", 1, pkgAuth.HashRecoveryCode("abcdefghij")).Return(nil)
+			},
+		},
+		{
+			name:  "used recovery code",
+			input: &VerifyInput{UserId: 1, RecoveryCode: "abcde-fghij"},
+			setup: func(deps *testDeps) {
+				deps.mfaRepo.On("ConsumeRecoveryCode", 1, mock.Anything).Return(repository.ErrRecoveryCodeNotFound)
+			},
+			wantErr: domain.InvalidMFACodeError{UserId: 1},
+		},
+		{
+			name:    "no code",
+			input:   &VerifyInput{UserId: 1},
+			setup:   func(deps *testDeps) {},
+			wantErr: domain.InvalidMFACodeError{UserId: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(Config{})
+			credential := confirmedCredential()
+			credential.LastUsedStep = tt.lastUsedStep
+			if tt.currentCode {
+				tt.input.Code = codeAt(t, testNow)
+			}
+			deps.mfaRepo.On("GetTOTPCredential", 1).Return(credential, nil)
+			deps.mfaRepo.On("ReplaceRecoveryCodes", 1, mock.Anything).Return(nil)
+			tt.setup(deps)
+
+			codes, err := svc.RegenerateRecoveryCodes(context.Background(), tt.input)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				deps.mfaRepo.AssertNotCalled(t, "ReplaceRecoveryCodes", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, codes, defaultRecoveryCodeCount)
+			}
+		})
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	svc, deps := setupTestService(Config{RequiredRoles: []string{entity.RoleUser}})
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(confirmedCredential(), nil)
+	deps.mfaRepo.On("CountRecoveryCodes", 1).Return(7, nil)
+
+	status, err := svc.GetStatus(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Status{Enabled: true, Required: true, RecoveryCodesRemaining: 7}, status)
+}
+
+func TestGetStatus_RepositoryError(t *testing.T) {
+	svc, deps := setupTestService(Config{})
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.mfaRepo.On("GetTOTPCredential", 1).Return(nil, errors.New("database error"))
+
+	_, err := svc.GetStatus(context.Background(), 1)
+
+	assert.IsType(t, domain.InternalServerError{}, err)
+}
@@ -0,0 +1,33 @@
+package passkey
+
+import (
+	"context"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Service Dependencies ==========
+// Interfaces that the passkey service depends on (injected from outside)
+
+// IUserRepository defines the user data access needed by the passkey service.
+type IUserRepository interface {
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+}
+
+// IPasskeyRepository defines the interface for passkey data access.
+type IPasskeyRepository interface {
+	InsertPasskey(ctx context.Context, passkey *entity.Passkey) (int, error)
+	GetPasskeysByUserId(ctx context.Context, userId int) ([]*entity.Passkey, error)
+	GetPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*entity.Passkey, error)
+	UpdatePasskeySignCount(ctx context.Context, id int, signCount uint32) error
+	DeletePasskey(ctx context.Context, userId, id int) error
+	InsertPasskeyChallenge(ctx context.Context, challenge *entity.PasskeyChallenge) (int, error)
+	ConsumePasskeyChallenge(ctx context.Context, challengeHash string) (*entity.PasskeyChallenge, error)
+	DeleteExpiredPasskeyChallenges(ctx context.Context) error
+}
+
+// ITxManager runs a function inside a database transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,19 @@
+package passkey
+
+import "github.com/go-webauthn/webauthn/protocol"
+
+// ========== Registration ==========
+
+// FinishRegistrationInput completes a registration started with BeginRegistration.
+type FinishRegistrationInput struct {
+	UserId   int
+	Name     string // label shown in the user's passkey list
+	Response *protocol.CredentialCreationResponse
+}
+
+// ========== Login ==========
+
+// FinishLoginInput completes a login started with BeginLogin.
+type FinishLoginInput struct {
+	Response *protocol.CredentialAssertionResponse
+}
@@ -0,0 +1,412 @@
+package passkey
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+var (
+	errNilUserRepository    = errors.New("user repository is nil")
+	errNilPasskeyRepository = errors.New("passkey repository is nil")
+	errNilTxManager         = errors.New("transaction manager is nil")
+)
+
+const (
+	defaultRPName            = "Go Backend Template"
+	defaultChallengeDuration = 5 * time.Minute
+	defaultPasskeyName       = "Passkey"
+)
+
+// Config holds passkey service configuration.
+type Config struct {
+	// RPID is the domain that scopes passkeys, e.g. example.com.
+	// Changing it orphans every registered passkey.
+	RPID string
+	// RPName names the service in authenticator prompts.
+	RPName string
+	// Origins lists the origins of the web apps that run the ceremonies, e.g. https://app.example.com.
+	// Every origin must be the RP ID or one of its subdomains.
+	Origins []string
+	// ChallengeDuration is the time a user has to complete a registration or login.
+	ChallengeDuration time.Duration
+	// RequireVerifiedEmail rejects logins of users who have not verified their email.
+	RequireVerifiedEmail bool
+}
+
+// Service handles passkeys: WebAuthn registration, usernameless login and management.
+//
+// Passkeys must be discoverable and user-verified, so that a passkey alone signs a user in.
+// Authenticator models are not checked against a trust anchor, so attestation is not requested.
+type Service struct {
+	userRepo             IUserRepository
+	passkeyRepo          IPasskeyRepository
+	txManager            ITxManager
+	rp                   *webauthn.WebAuthn
+	challengeDuration    time.Duration
+	requireVerifiedEmail bool
+	now                  func() time.Time
+}
+
+// NewService creates a new passkey service.
+func NewService(
+	userRepo IUserRepository,
+	passkeyRepo IPasskeyRepository,
+	txManager ITxManager,
+	config Config,
+) (*Service, error) {
+	if userRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey service", Err: errNilUserRepository}
+	}
+	if passkeyRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey service", Err: errNilPasskeyRepository}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey service", Err: errNilTxManager}
+	}
+
+	rpName := config.RPName
+	if rpName == "" {
+		rpName = defaultRPName
+	}
+
+	challengeDuration := config.ChallengeDuration
+	if challengeDuration <= 0 {
+		challengeDuration = defaultChallengeDuration
+	}
+
+	if err := checkOrigins(config.RPID, config.Origins); err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey service", Err: err}
+	}
+	timeout := webauthn.TimeoutConfig{Timeout: challengeDuration, TimeoutUVD: challengeDuration}
+	rp, err := webauthn.New(&webauthn.Config{
+		RPID:                  config.RPID,
+		RPDisplayName:         rpName,
+		RPOrigins:             config.Origins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey service", Err: err}
+	}
+
+	return &Service{
+		userRepo:             userRepo,
+		passkeyRepo:          passkeyRepo,
+		txManager:            txManager,
+		rp:                   rp,
+		challengeDuration:    challengeDuration,
+		requireVerifiedEmail: config.RequireVerifiedEmail,
+		now:                  time.Now,
+	}, nil
+}
+
+// ========== Registration ==========
+
+// BeginRegistration starts the registration of a new passkey for a signed-in user and returns
+// the options to pass to navigator.credentials.create. Authenticators that already hold one of
+// the user's passkeys are excluded.
+func (s *Service) BeginRegistration(ctx context.Context, userId int) (*protocol.PublicKeyCredentialCreationOptions, error) {
+	user, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, domain.UserNotFoundError{Id: userId}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+
+	passkeys, err := s.passkeyRepo.GetPasskeysByUserId(ctx, userId)
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to get passkeys", Err: err}
+	}
+	excluded := make([]protocol.CredentialDescriptor, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		excluded = append(excluded, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: passkey.CredentialId,
+		})
+	}
+
+	creation, session, err := s.rp.BeginRegistration(&webauthnUser{
+		handle:      userHandle(user.Id),
+		name:        user.Email,
+		displayName: user.Name,
+	}, webauthn.WithExclusions(excluded))
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey options", Err: err}
+	}
+
+	if err := s.storeChallenge(ctx, &user.Id, entity.PasskeyPurposeRegistration, session.Challenge); err != nil {
+		return nil, err
+	}
+
+	return &creation.Response, nil
+}
+
+// FinishRegistration verifies the new credential of a registration started with BeginRegistration
+// and stores it as a passkey of the user.
+func (s *Service) FinishRegistration(ctx context.Context, input *FinishRegistrationInput) (*entity.Passkey, error) {
+	response, err := input.Response.Parse()
+	if err != nil {
+		return nil, domain.InvalidPasskeyError{}
+	}
+	challenge := response.Response.CollectedClientData.Challenge
+
+	stored, err := s.consumeChallenge(ctx, challenge, entity.PasskeyPurposeRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if stored.UserId == nil || *stored.UserId != input.UserId {
+		return nil, domain.InvalidPasskeyError{}
+	}
+
+	handle := userHandle(input.UserId)
+	credential, err := s.rp.CreateCredential(&webauthnUser{handle: handle}, webauthn.SessionData{
+		Challenge:        challenge,
+		UserID:           handle,
+		UserVerification: protocol.VerificationRequired,
+	}, response)
+	if err != nil || !bytes.Equal(credential.ID, response.RawID) {
+		return nil, domain.InvalidPasskeyError{}
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	_, err = s.passkeyRepo.InsertPasskey(ctx, &entity.Passkey{
+		UserId:       input.UserId,
+		CredentialId: credential.ID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    credential.Authenticator.SignCount,
+		Name:         name,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicatePasskey) {
+			return nil, domain.PasskeyAlreadyRegisteredError{}
+		}
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, domain.UserNotFoundError{Id: input.UserId}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to store passkey", Err: err}
+	}
+
+	passkey, err := s.passkeyRepo.GetPasskeyByCredentialId(ctx, credential.ID)
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to get passkey", Err: err}
+	}
+
+	return passkey, nil
+}
+
+// ========== Login ==========
+
+// BeginLogin starts a passkey login and returns the options to pass to navigator.credentials.get.
+// No user is named: the authenticator offers the passkeys it holds for the RP ID, and the
+// chosen passkey identifies the user.
+func (s *Service) BeginLogin(ctx context.Context) (*protocol.PublicKeyCredentialRequestOptions, error) {
+	assertion, session, err := s.rp.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to create passkey options", Err: err}
+	}
+
+	if err := s.storeChallenge(ctx, nil, entity.PasskeyPurposeLogin, session.Challenge); err != nil {
+		return nil, err
+	}
+
+	return &assertion.Response, nil
+}
+
+// FinishLogin verifies the assertion of a login started with BeginLogin and returns the
+// signed-in user. The passkey's signature counter must increase, unless the authenticator
+// does not implement one, so that cloned authenticators are rejected.
+// Every failure is reported as domain.InvalidPasskeyError, except that users who have not
+// verified their email get domain.EmailNotVerifiedError when verification is required.
+func (s *Service) FinishLogin(ctx context.Context, input *FinishLoginInput) (*entity.User, error) {
+	response, err := input.Response.Parse()
+	if err != nil {
+		return nil, domain.InvalidPasskeyError{}
+	}
+	challenge := response.Response.CollectedClientData.Challenge
+
+	if _, err := s.consumeChallenge(ctx, challenge, entity.PasskeyPurposeLogin); err != nil {
+		return nil, err
+	}
+
+	var user *entity.User
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		passkey, err := s.passkeyRepo.GetPasskeyByCredentialId(ctx, response.RawID)
+		if err != nil {
+			if errors.Is(err, repository.ErrPasskeyNotFound) {
+				return domain.InvalidPasskeyError{}
+			}
+			return domain.InternalServerError{Msg: "failed to get passkey", Err: err}
+		}
+
+		// The user handle returned by the authenticator must be the one of the passkey's user
+		owner := &webauthnUser{
+			handle: userHandle(passkey.UserId),
+			credentials: []webauthn.Credential{{
+				ID:            passkey.CredentialId,
+				PublicKey:     passkey.PublicKey,
+				Authenticator: webauthn.Authenticator{SignCount: passkey.SignCount},
+			}},
+		}
+		credential, err := s.rp.ValidateDiscoverableLogin(func(_, _ []byte) (webauthn.User, error) {
+			return owner, nil
+		}, webauthn.SessionData{
+			Challenge:        challenge,
+			UserVerification: protocol.VerificationRequired,
+		}, response)
+		if err != nil || credential.Authenticator.CloneWarning {
+			return domain.InvalidPasskeyError{}
+		}
+
+		passkeyUser, err := s.userRepo.GetUserById(ctx, passkey.UserId)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return domain.InvalidPasskeyError{}
+			}
+			return domain.InternalServerError{Msg: "failed to get user", Err: err}
+		}
+		if !passkeyUser.IsActive || passkeyUser.IsLocked(s.now()) {
+			return domain.InvalidPasskeyError{}
+		}
+
+		if err := s.passkeyRepo.UpdatePasskeySignCount(ctx, passkey.Id, credential.Authenticator.SignCount); err != nil {
+			if errors.Is(err, repository.ErrPasskeyNotFound) {
+				return domain.InvalidPasskeyError{}
+			}
+			return domain.InternalServerError{Msg: "failed to update passkey", Err: err}
+		}
+
+		user = passkeyUser
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Checked after the assertion, like password logins, so that it does not reveal which users exist
+	if s.requireVerifiedEmail && !user.IsEmailVerified() {
+		return nil, domain.EmailNotVerifiedError{}
+	}
+
+	return user, nil
+}
+
+// ========== Management ==========
+
+// ListPasskeys returns the passkeys of a user, oldest first.
+func (s *Service) ListPasskeys(ctx context.Context, userId int) ([]*entity.Passkey, error) {
+	passkeys, err := s.passkeyRepo.GetPasskeysByUserId(ctx, userId)
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to get passkeys", Err: err}
+	}
+	return passkeys, nil
+}
+
+// DeletePasskey deletes a passkey of a user.
+func (s *Service) DeletePasskey(ctx context.Context, userId, id int) error {
+	if err := s.passkeyRepo.DeletePasskey(ctx, userId, id); err != nil {
+		if errors.Is(err, repository.ErrPasskeyNotFound) {
+			return domain.PasskeyNotFoundError{Id: id}
+		}
+		return domain.InternalServerError{Msg: "failed to delete passkey", Err: err}
+	}
+	return nil
+}
+
+// ========== Helpers ==========
+
+// userHandle returns the WebAuthn user handle of a user: the user ID as 8 big-endian bytes.
+// It contains no personal information and lets a login check whom a passkey was created for.
+func userHandle(userId int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(userId))
+}
+
+// webauthnUser is a user as seen by go-webauthn: a user handle, the names shown by
+// authenticators, and the passkeys a login may be verified with.
+type webauthnUser struct {
+	handle      []byte
+	name        string
+	displayName string
+	credentials []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte                         { return u.handle }
+func (u *webauthnUser) WebAuthnName() string                       { return u.name }
+func (u *webauthnUser) WebAuthnDisplayName() string                { return u.displayName }
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+func (u *webauthnUser) WebAuthnIcon() string                       { return "" }
+
+// checkOrigins checks that every origin is the RP ID or one of its subdomains, since
+// browsers refuse to run ceremonies for an RP ID outside of the origin.
+func checkOrigins(rpId string, origins []string) error {
+	for _, origin := range origins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			return fmt.Errorf("invalid origin %q", origin)
+		}
+		host := u.Hostname()
+		if host != rpId && !strings.HasSuffix(host, "."+rpId) {
+			return fmt.Errorf("origin %q is not within rp id %q", origin, rpId)
+		}
+	}
+	return nil
+}
+
+// storeChallenge stores the challenge of a new ceremony.
+func (s *Service) storeChallenge(ctx context.Context, userId *int, purpose, challenge string) error {
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		// Opportunistically drop challenges that can no longer be answered
+		if err := s.passkeyRepo.DeleteExpiredPasskeyChallenges(ctx); err != nil {
+			return domain.InternalServerError{Msg: "failed to clean up passkey challenges", Err: err}
+		}
+
+		_, err := s.passkeyRepo.InsertPasskeyChallenge(ctx, &entity.PasskeyChallenge{
+			UserId:        userId,
+			Purpose:       purpose,
+			ChallengeHash: pkgAuth.HashOpaqueToken(challenge),
+			ExpiresAt:     s.now().Add(s.challengeDuration),
+		})
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to store passkey challenge", Err: err}
+		}
+		return nil
+	})
+}
+
+// consumeChallenge redeems the challenge a ceremony response was created for. It is consumed
+// before the response is verified, so that a failed attempt cannot be retried with the same challenge.
+func (s *Service) consumeChallenge(ctx context.Context, challenge, purpose string) (*entity.PasskeyChallenge, error) {
+	stored, err := s.passkeyRepo.ConsumePasskeyChallenge(ctx, pkgAuth.HashOpaqueToken(challenge))
+	if err != nil {
+		if errors.Is(err, repository.ErrPasskeyChallengeNotFound) {
+			return nil, domain.InvalidPasskeyError{}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to consume passkey challenge", Err: err}
+	}
+	if stored.Purpose != purpose || stored.IsExpired(s.now()) {
+		return nil, domain.InvalidPasskeyError{}
+	}
+	return stored, nil
+}
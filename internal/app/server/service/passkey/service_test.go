@@ -0,0 +1,570 @@
+package passkey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	pkgAuth "github.com/your-org/go-backend-template/internal/pkg/auth"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"github.com/your-org/go-backend-template/internal/pkg/webauthntest"
+)
+
+// ========== Mock Repositories ==========
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+type MockPasskeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasskeyRepository) InsertPasskey(ctx context.Context, passkey *entity.Passkey) (int, error) {
+	args := m.Called(passkey)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) GetPasskeysByUserId(ctx context.Context, userId int) ([]*entity.Passkey, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Passkey), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) GetPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*entity.Passkey, error) {
+	args := m.Called(credentialId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Passkey), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) UpdatePasskeySignCount(ctx context.Context, id int, signCount uint32) error {
+	args := m.Called(id, signCount)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) DeletePasskey(ctx context.Context, userId, id int) error {
+	args := m.Called(userId, id)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) InsertPasskeyChallenge(ctx context.Context, challenge *entity.PasskeyChallenge) (int, error) {
+	args := m.Called(challenge)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) ConsumePasskeyChallenge(ctx context.Context, challengeHash string) (*entity.PasskeyChallenge, error) {
+	args := m.Called(challengeHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PasskeyChallenge), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) DeleteExpiredPasskeyChallenges(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// ========== Fake Transaction Manager ==========
+
+// fakeTxManager runs fn directly, like a transaction that always commits.
+type fakeTxManager struct {
+	calls int
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+// ========== Test Helper ==========
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const testOrigin = "https://app.example.com"
+
+var testConfig = Config{
+	RPID:    "example.com",
+	Origins: []string{testOrigin},
+}
+
+type testDeps struct {
+	userRepo    *MockUserRepository
+	passkeyRepo *MockPasskeyRepository
+	txManager   *fakeTxManager
+}
+
+func setupTestService(t *testing.T) (*Service, *testDeps) {
+	deps := &testDeps{
+		userRepo:    new(MockUserRepository),
+		passkeyRepo: new(MockPasskeyRepository),
+		txManager:   &fakeTxManager{},
+	}
+	svc, err := NewService(deps.userRepo, deps.passkeyRepo, deps.txManager, testConfig)
+	if err != nil {
+		t.Fatalf("Failed to create passkey service: %v", err)
+	}
+	svc.now = func() time.Time { return testNow }
+	return svc, deps
+}
+
+func activeUser() *entity.User {
+	return &entity.User{Id: 1, Email: "test@example.com", Name: "Test User", Role: entity.RoleUser, IsActive: true}
+}
+
+// pendingChallenge returns the stored form of a challenge issued for user (nil for logins).
+func pendingChallenge(challenge string, userId *int, purpose string) *entity.PasskeyChallenge {
+	return &entity.PasskeyChallenge{
+		Id:            1,
+		UserId:        userId,
+		Purpose:       purpose,
+		ChallengeHash: pkgAuth.HashOpaqueToken(challenge),
+		ExpiresAt:     testNow.Add(time.Minute),
+	}
+}
+
+// creationOptions returns registration options for user, bypassing the stored challenge.
+func creationOptions(t *testing.T, svc *Service, userId int) (*protocol.PublicKeyCredentialCreationOptions, string) {
+	t.Helper()
+
+	creation, session, err := svc.rp.BeginRegistration(&webauthnUser{handle: userHandle(userId)})
+	if err != nil {
+		t.Fatalf("Failed to create options: %v", err)
+	}
+	return &creation.Response, session.Challenge
+}
+
+// registerPasskey registers a credential of authenticator for user 1 and returns it as stored.
+func registerPasskey(t *testing.T, svc *Service, authenticator *webauthntest.Authenticator) *entity.Passkey {
+	t.Helper()
+
+	options, challenge := creationOptions(t, svc, 1)
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("Failed to create credential: %v", err)
+	}
+	parsed, err := resp.Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse credential: %v", err)
+	}
+	credential, err := svc.rp.CreateCredential(&webauthnUser{handle: userHandle(1)}, webauthn.SessionData{
+		Challenge:        challenge,
+		UserID:           userHandle(1),
+		UserVerification: protocol.VerificationRequired,
+	}, parsed)
+	if err != nil {
+		t.Fatalf("Failed to verify credential: %v", err)
+	}
+	return &entity.Passkey{
+		Id:           7,
+		UserId:       1,
+		CredentialId: credential.ID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    credential.Authenticator.SignCount,
+	}
+}
+
+// beginLogin starts a login and returns the authenticator's response to it.
+func beginLogin(t *testing.T, svc *Service, deps *testDeps, authenticator *webauthntest.Authenticator) *protocol.CredentialAssertionResponse {
+	t.Helper()
+
+	deps.passkeyRepo.On("DeleteExpiredPasskeyChallenges").Return(nil).Once()
+	deps.passkeyRepo.On("InsertPasskeyChallenge", mock.Anything).Return(1, nil).Once()
+
+	options, err := svc.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to begin login: %v", err)
+	}
+	resp, err := authenticator.Authenticate(options)
+	if err != nil {
+		t.Fatalf("Failed to get assertion: %v", err)
+	}
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", pkgAuth.HashOpaqueToken(options.Challenge.String())).
+		Return(pendingChallenge(options.Challenge.String(), nil, entity.PasskeyPurposeLogin), nil).Once()
+	return resp
+}
+
+// ========== NewService Tests ==========
+
+func TestNewService_NilDependencies(t *testing.T) {
+	_, err := NewService(nil, new(MockPasskeyRepository), &fakeTxManager{}, testConfig)
+	assert.Error(t, err)
+
+	_, err = NewService(new(MockUserRepository), nil, &fakeTxManager{}, testConfig)
+	assert.Error(t, err)
+
+	_, err = NewService(new(MockUserRepository), new(MockPasskeyRepository), nil, testConfig)
+	assert.Error(t, err)
+}
+
+func TestNewService_InvalidConfig(t *testing.T) {
+	_, err := NewService(new(MockUserRepository), new(MockPasskeyRepository), &fakeTxManager{}, Config{
+		RPID:    "example.com",
+		Origins: []string{"https://example.org"},
+	})
+	assert.Error(t, err)
+}
+
+// ========== Registration Tests ==========
+
+func TestRegistration_Success(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	existing := &entity.Passkey{Id: 3, UserId: 1, CredentialId: []byte{9, 9}}
+
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.passkeyRepo.On("GetPasskeysByUserId", 1).Return([]*entity.Passkey{existing}, nil)
+	deps.passkeyRepo.On("DeleteExpiredPasskeyChallenges").Return(nil)
+
+	var challenge *entity.PasskeyChallenge
+	deps.passkeyRepo.On("InsertPasskeyChallenge", mock.Anything).Run(func(args mock.Arguments) {
+		challenge = args.Get(0).(*entity.PasskeyChallenge)
+	}).Return(1, nil)
+
+	options, err := svc.BeginRegistration(context.Background(), 1)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, challenge) {
+		assert.Equal(t, 1, *challenge.UserId)
+		assert.Equal(t, entity.PasskeyPurposeRegistration, challenge.Purpose)
+		assert.Equal(t, pkgAuth.HashOpaqueToken(options.Challenge.String()), challenge.ChallengeHash, "only the hash is stored")
+		assert.Equal(t, testNow.Add(defaultChallengeDuration), challenge.ExpiresAt)
+	}
+	assert.Equal(t, protocol.URLEncodedBase64(userHandle(1)), options.User.ID)
+	assert.Equal(t, "test@example.com", options.User.Name)
+	assert.Equal(t, protocol.PreferNoAttestation, options.Attestation, "attestation is not verified")
+	assert.Equal(t, protocol.ResidentKeyRequirementRequired, options.AuthenticatorSelection.ResidentKey)
+	assert.Equal(t, protocol.VerificationRequired, options.AuthenticatorSelection.UserVerification)
+	if assert.Len(t, options.CredentialExcludeList, 1) {
+		assert.Equal(t, protocol.URLEncodedBase64{9, 9}, options.CredentialExcludeList[0].CredentialID)
+	}
+
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("Failed to create credential: %v", err)
+	}
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", challenge.ChallengeHash).Return(challenge, nil)
+
+	var inserted *entity.Passkey
+	deps.passkeyRepo.On("InsertPasskey", mock.Anything).Run(func(args mock.Arguments) {
+		inserted = args.Get(0).(*entity.Passkey)
+	}).Return(8, nil)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", authenticator.Credentials[0].Id).
+		Return(&entity.Passkey{Id: 8, UserId: 1, Name: "Laptop"}, nil)
+
+	passkey, err := svc.FinishRegistration(context.Background(), &FinishRegistrationInput{
+		UserId:   1,
+		Name:     " Laptop ",
+		Response: resp,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 8, passkey.Id)
+	if assert.NotNil(t, inserted) {
+		assert.Equal(t, 1, inserted.UserId)
+		assert.Equal(t, authenticator.Credentials[0].Id, inserted.CredentialId)
+		assert.NotEmpty(t, inserted.PublicKey)
+		assert.Equal(t, "Laptop", inserted.Name)
+	}
+}
+
+func TestFinishRegistration_ChallengeOfAnotherUser(t *testing.T) {
+	svc, deps := setupTestService(t)
+	options, challenge := creationOptions(t, svc, 2)
+	resp, err := webauthntest.New(testOrigin).Register(options)
+	if err != nil {
+		t.Fatalf("Failed to create credential: %v", err)
+	}
+
+	otherUserId := 2
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", pkgAuth.HashOpaqueToken(challenge)).
+		Return(pendingChallenge(challenge, &otherUserId, entity.PasskeyPurposeRegistration), nil)
+
+	_, err = svc.FinishRegistration(context.Background(), &FinishRegistrationInput{UserId: 1, Response: resp})
+
+	assert.Equal(t, domain.InvalidPasskeyError{}, err)
+	deps.passkeyRepo.AssertNotCalled(t, "InsertPasskey", mock.Anything)
+}
+
+func TestFinishRegistration_InvalidChallenge(t *testing.T) {
+	userId := 1
+	tests := []struct {
+		name   string
+		stored func(challenge string) *entity.PasskeyChallenge
+	}{
+		{
+			name:   "unknown",
+			stored: func(string) *entity.PasskeyChallenge { return nil },
+		},
+		{
+			name: "login challenge",
+			stored: func(challenge string) *entity.PasskeyChallenge {
+				return pendingChallenge(challenge, nil, entity.PasskeyPurposeLogin)
+			},
+		},
+		{
+			name: "expired",
+			stored: func(challenge string) *entity.PasskeyChallenge {
+				c := pendingChallenge(challenge, &userId, entity.PasskeyPurposeRegistration)
+				c.ExpiresAt = testNow
+				return c
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(t)
+			options, challenge := creationOptions(t, svc, 1)
+			resp, err := webauthntest.New(testOrigin).Register(options)
+			if err != nil {
+				t.Fatalf("Failed to create credential: %v", err)
+			}
+
+			if stored := tt.stored(challenge); stored != nil {
+				deps.passkeyRepo.On("ConsumePasskeyChallenge", mock.Anything).Return(stored, nil)
+			} else {
+				deps.passkeyRepo.On("ConsumePasskeyChallenge", mock.Anything).Return(nil, repository.ErrPasskeyChallengeNotFound)
+			}
+
+			_, err = svc.FinishRegistration(context.Background(), &FinishRegistrationInput{UserId: 1, Response: resp})
+
+			assert.Equal(t, domain.InvalidPasskeyError{}, err)
+			deps.passkeyRepo.AssertNotCalled(t, "InsertPasskey", mock.Anything)
+		})
+	}
+}
+
+func TestFinishRegistration_AlreadyRegistered(t *testing.T) {
+	svc, deps := setupTestService(t)
+	options, challenge := creationOptions(t, svc, 1)
+	resp, err := webauthntest.New(testOrigin).Register(options)
+	if err != nil {
+		t.Fatalf("Failed to create credential: %v", err)
+	}
+
+	userId := 1
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", mock.Anything).
+		Return(pendingChallenge(challenge, &userId, entity.PasskeyPurposeRegistration), nil)
+	deps.passkeyRepo.On("InsertPasskey", mock.Anything).Return(0, repository.ErrDuplicatePasskey)
+
+	_, err = svc.FinishRegistration(context.Background(), &FinishRegistrationInput{UserId: 1, Response: resp})
+
+	assert.Equal(t, domain.PasskeyAlreadyRegisteredError{}, err)
+}
+
+func TestFinishRegistration_UserNotVerified(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	authenticator.SkipUserVerification = true
+	options, challenge := creationOptions(t, svc, 1)
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("Failed to create credential: %v", err)
+	}
+
+	userId := 1
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", mock.Anything).
+		Return(pendingChallenge(challenge, &userId, entity.PasskeyPurposeRegistration), nil)
+
+	_, err = svc.FinishRegistration(context.Background(), &FinishRegistrationInput{UserId: 1, Response: resp})
+
+	assert.Equal(t, domain.InvalidPasskeyError{}, err)
+	deps.passkeyRepo.AssertNotCalled(t, "InsertPasskey", mock.Anything)
+}
+
+// ========== Login Tests ==========
+
+func TestLogin_Success(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	passkey := registerPasskey(t, svc, authenticator)
+
+	var challenge *entity.PasskeyChallenge
+	deps.passkeyRepo.On("DeleteExpiredPasskeyChallenges").Return(nil)
+	deps.passkeyRepo.On("InsertPasskeyChallenge", mock.Anything).Run(func(args mock.Arguments) {
+		challenge = args.Get(0).(*entity.PasskeyChallenge)
+	}).Return(1, nil)
+
+	options, err := svc.BeginLogin(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, options.AllowedCredentials, "logins are usernameless")
+	assert.Equal(t, protocol.VerificationRequired, options.UserVerification)
+	if assert.NotNil(t, challenge) {
+		assert.Nil(t, challenge.UserId)
+		assert.Equal(t, entity.PasskeyPurposeLogin, challenge.Purpose)
+	}
+
+	resp, err := authenticator.Authenticate(options)
+	if err != nil {
+		t.Fatalf("Failed to get assertion: %v", err)
+	}
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", challenge.ChallengeHash).Return(challenge, nil)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(passkey, nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.passkeyRepo.On("UpdatePasskeySignCount", 7, uint32(1)).Return(nil)
+
+	user, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.Id)
+	deps.passkeyRepo.AssertExpectations(t)
+}
+
+func TestFinishLogin_SignCountRegression(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	passkey := registerPasskey(t, svc, authenticator)
+
+	// The server has seen a larger counter than the authenticator reports: it is a clone
+	passkey.SignCount = 5
+	resp := beginLogin(t, svc, deps, authenticator)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(passkey, nil)
+
+	_, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+	assert.Equal(t, domain.InvalidPasskeyError{}, err)
+	deps.passkeyRepo.AssertNotCalled(t, "UpdatePasskeySignCount", mock.Anything, mock.Anything)
+}
+
+func TestFinishLogin_UserHandleMismatch(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	passkey := registerPasskey(t, svc, authenticator)
+
+	// The credential was created for user 1 but is stored for user 2
+	passkey.UserId = 2
+	resp := beginLogin(t, svc, deps, authenticator)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(passkey, nil)
+
+	_, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+	assert.Equal(t, domain.InvalidPasskeyError{}, err)
+	deps.userRepo.AssertNotCalled(t, "GetUserById", mock.Anything)
+}
+
+func TestFinishLogin_InactiveOrLockedUser(t *testing.T) {
+	lockedUntil := testNow.Add(time.Minute)
+	tests := []struct {
+		name string
+		user *entity.User
+	}{
+		{"inactive", &entity.User{Id: 1, IsActive: false}},
+		{"locked", &entity.User{Id: 1, IsActive: true, LockedUntil: &lockedUntil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(t)
+			authenticator := webauthntest.New(testOrigin)
+			passkey := registerPasskey(t, svc, authenticator)
+
+			resp := beginLogin(t, svc, deps, authenticator)
+			deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(passkey, nil)
+			deps.userRepo.On("GetUserById", 1).Return(tt.user, nil)
+
+			_, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+			assert.Equal(t, domain.InvalidPasskeyError{}, err)
+			deps.passkeyRepo.AssertNotCalled(t, "UpdatePasskeySignCount", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestFinishLogin_UnverifiedEmail(t *testing.T) {
+	svc, deps := setupTestService(t)
+	svc.requireVerifiedEmail = true
+	authenticator := webauthntest.New(testOrigin)
+	passkey := registerPasskey(t, svc, authenticator)
+
+	resp := beginLogin(t, svc, deps, authenticator)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(passkey, nil)
+	deps.userRepo.On("GetUserById", 1).Return(activeUser(), nil)
+	deps.passkeyRepo.On("UpdatePasskeySignCount", 7, uint32(1)).Return(nil)
+
+	user, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+	assert.Nil(t, user)
+	assert.Equal(t, domain.EmailNotVerifiedError{}, err)
+}
+
+func TestFinishLogin_VerifiedEmail(t *testing.T) {
+	svc, deps := setupTestService(t)
+	svc.requireVerifiedEmail = true
+	authenticator := webauthntest.New(testOrigin)
+	passkey := registerPasskey(t, svc, authenticator)
+
+	verifiedAt := testNow.Add(-time.Hour)
+	verifiedUser := activeUser()
+	verifiedUser.EmailVerifiedAt = &verifiedAt
+
+	resp := beginLogin(t, svc, deps, authenticator)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(passkey, nil)
+	deps.userRepo.On("GetUserById", 1).Return(verifiedUser, nil)
+	deps.passkeyRepo.On("UpdatePasskeySignCount", 7, uint32(1)).Return(nil)
+
+	user, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.Id)
+}
+
+func TestFinishLogin_UnknownPasskey(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	passkey := registerPasskey(t, svc, authenticator)
+
+	resp := beginLogin(t, svc, deps, authenticator)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", passkey.CredentialId).Return(nil, repository.ErrPasskeyNotFound)
+
+	_, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+
+	assert.Equal(t, domain.InvalidPasskeyError{}, err)
+}
+
+func TestFinishLogin_ReplayedChallenge(t *testing.T) {
+	svc, deps := setupTestService(t)
+	authenticator := webauthntest.New(testOrigin)
+	registerPasskey(t, svc, authenticator)
+
+	resp := beginLogin(t, svc, deps, authenticator)
+	deps.passkeyRepo.On("ConsumePasskeyChallenge", mock.Anything).Return(nil, repository.ErrPasskeyChallengeNotFound)
+	deps.passkeyRepo.On("GetPasskeyByCredentialId", mock.Anything).Return(nil, repository.ErrPasskeyNotFound)
+
+	_, err := svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+	assert.Equal(t, domain.InvalidPasskeyError{}, err)
+
+	_, err = svc.FinishLogin(context.Background(), &FinishLoginInput{Response: resp})
+	assert.Equal(t, domain.InvalidPasskeyError{}, err, "challenges are consumed even when verification fails")
+	deps.passkeyRepo.AssertNumberOfCalls(t, "GetPasskeyByCredentialId", 1)
+}
+
+// ========== Management Tests ==========
+
+func TestDeletePasskey_NotFound(t *testing.T) {
+	svc, deps := setupTestService(t)
+	deps.passkeyRepo.On("DeletePasskey", 1, 5).Return(repository.ErrPasskeyNotFound)
+
+	err := svc.DeletePasskey(context.Background(), 1, 5)
+
+	assert.Equal(t, domain.PasskeyNotFoundError{Id: 5}, err)
+}
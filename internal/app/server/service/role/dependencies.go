@@ -0,0 +1,30 @@
+package role
+
+import (
+	"context"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// ========== Service Dependencies ==========
+// Interfaces that the role service depends on (injected from outside)
+
+// IRoleRepository defines the interface for role data access.
+type IRoleRepository interface {
+	GetRoles(ctx context.Context) ([]*entity.Role, error)
+	GetRoleByName(ctx context.Context, name string) (*entity.Role, error)
+	InsertRole(ctx context.Context, role *entity.Role) error
+	UpdateRole(ctx context.Context, role *entity.Role) error
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// IPermissionCache defines the interface for dropping cached role permissions once a role changes.
+type IPermissionCache interface {
+	ForgetRolePermissions(role string)
+}
+
+// ITxManager runs a function inside a database transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,16 @@
+package role
+
+// CreateRoleInput represents input for creating a role.
+type CreateRoleInput struct {
+	Name        string
+	Description string
+	Permissions []string // see the entity.Permission constants
+}
+
+// UpdateRoleInput represents input for updating a role.
+// Nil fields are left unchanged; Permissions replaces every permission of the role.
+type UpdateRoleInput struct {
+	Name        string
+	Description *string
+	Permissions *[]string
+}
@@ -0,0 +1,209 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+var (
+	errNilRoleRepository  = errors.New("role repository is nil")
+	errNilPermissionCache = errors.New("permission cache is nil")
+	errNilTxManager       = errors.New("transaction manager is nil")
+)
+
+// Service handles roles: the permissions they grant and their management by admins.
+type Service struct {
+	roleRepo        IRoleRepository
+	permissionCache IPermissionCache
+	txManager       ITxManager
+}
+
+// NewService creates a new role service.
+func NewService(roleRepo IRoleRepository, permissionCache IPermissionCache, txManager ITxManager) (*Service, error) {
+	if roleRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create role service", Err: errNilRoleRepository}
+	}
+	if permissionCache == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create role service", Err: errNilPermissionCache}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create role service", Err: errNilTxManager}
+	}
+
+	return &Service{
+		roleRepo:        roleRepo,
+		permissionCache: permissionCache,
+		txManager:       txManager,
+	}, nil
+}
+
+// ========== Permissions ==========
+
+// GetRolePermissions returns the permissions granted by a role. A role that does not exist
+// (e.g. one deleted while a token naming it is still valid) grants no permissions.
+func (s *Service) GetRolePermissions(ctx context.Context, name string) ([]string, error) {
+	role, err := s.roleRepo.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return []string{}, nil
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get role", Err: err}
+	}
+	return role.Permissions, nil
+}
+
+// ========== Management ==========
+
+// ListRoles returns every role, ordered by name.
+func (s *Service) ListRoles(ctx context.Context) ([]*entity.Role, error) {
+	roles, err := s.roleRepo.GetRoles(ctx)
+	if err != nil {
+		return nil, domain.InternalServerError{Msg: "failed to get roles", Err: err}
+	}
+	return roles, nil
+}
+
+// GetRole returns a role by name.
+func (s *Service) GetRole(ctx context.Context, name string) (*entity.Role, error) {
+	role, err := s.roleRepo.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, domain.RoleNotFoundError{Name: name}
+		}
+		return nil, domain.InternalServerError{Msg: "failed to get role", Err: err}
+	}
+	return role, nil
+}
+
+// CreateRole creates a role.
+func (s *Service) CreateRole(ctx context.Context, input *CreateRoleInput) (*entity.Role, error) {
+	if !entity.IsValidRoleName(input.Name) {
+		return nil, domain.ValidationError{
+			Field:   "name",
+			Message: "must be lowercase letters, digits and hyphens, starting with a letter",
+		}
+	}
+	permissions, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *entity.Role
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		err := s.roleRepo.InsertRole(ctx, &entity.Role{
+			Name:        input.Name,
+			Description: input.Description,
+			Permissions: permissions,
+		})
+		if err != nil {
+			if errors.Is(err, repository.ErrDuplicateRole) {
+				return domain.RoleAlreadyExistsError{Name: input.Name}
+			}
+			return domain.InternalServerError{Msg: "failed to create role", Err: err}
+		}
+
+		created, err = s.roleRepo.GetRoleByName(ctx, input.Name)
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to get role", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// UpdateRole updates the description and permissions of a role. Permission changes apply
+// to every user of the role with their next request on this instance, and once the
+// permission cache has expired on others.
+func (s *Service) UpdateRole(ctx context.Context, input *UpdateRoleInput) (*entity.Role, error) {
+	var permissions []string
+	if input.Permissions != nil {
+		var err error
+		if permissions, err = normalizePermissions(*input.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated *entity.Role
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		role, err := s.roleRepo.GetRoleByName(ctx, input.Name)
+		if err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				return domain.RoleNotFoundError{Name: input.Name}
+			}
+			return domain.InternalServerError{Msg: "failed to get role", Err: err}
+		}
+
+		if input.Description != nil {
+			role.Description = *input.Description
+		}
+		if input.Permissions != nil {
+			// Taking permissions from the admin role could leave nobody able to manage roles
+			if role.Name == entity.RoleAdmin && !slices.Equal(permissions, role.Permissions) {
+				return domain.ProtectedRoleError{Name: role.Name}
+			}
+			role.Permissions = permissions
+		}
+
+		if err := s.roleRepo.UpdateRole(ctx, role); err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				return domain.RoleNotFoundError{Name: input.Name}
+			}
+			return domain.InternalServerError{Msg: "failed to update role", Err: err}
+		}
+
+		updated, err = s.roleRepo.GetRoleByName(ctx, input.Name)
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to get role", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.permissionCache.ForgetRolePermissions(input.Name)
+
+	return updated, nil
+}
+
+// DeleteRole deletes a role that no user or pending invitation has. Built-in roles cannot be deleted.
+func (s *Service) DeleteRole(ctx context.Context, name string) error {
+	if entity.IsSystemRole(name) {
+		return domain.ProtectedRoleError{Name: name}
+	}
+
+	if err := s.roleRepo.DeleteRole(ctx, name); err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return domain.RoleNotFoundError{Name: name}
+		}
+		if errors.Is(err, repository.ErrRoleInUse) {
+			return domain.RoleInUseError{Name: name}
+		}
+		return domain.InternalServerError{Msg: "failed to delete role", Err: err}
+	}
+	s.permissionCache.ForgetRolePermissions(name)
+	return nil
+}
+
+// ========== Helpers ==========
+
+// normalizePermissions validates permissions and returns them sorted and without duplicates.
+func normalizePermissions(permissions []string) ([]string, error) {
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !entity.IsValidPermission(permission) {
+			return nil, domain.ValidationError{Field: "permissions", Message: "unknown permission: " + permission}
+		}
+		normalized = append(normalized, permission)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
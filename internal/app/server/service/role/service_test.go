@@ -0,0 +1,274 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Mock Repository ==========
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) GetRoles(ctx context.Context) ([]*entity.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) InsertRole(ctx context.Context, role *entity.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) UpdateRole(ctx context.Context, role *entity.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+// ========== Fake Permission Cache ==========
+
+// fakePermissionCache records the roles whose permissions were dropped.
+type fakePermissionCache struct {
+	forgotten []string
+}
+
+func (f *fakePermissionCache) ForgetRolePermissions(role string) {
+	f.forgotten = append(f.forgotten, role)
+}
+
+// ========== Fake Transaction Manager ==========
+
+// fakeTxManager runs fn directly, like a transaction that always commits.
+type fakeTxManager struct {
+	calls int
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+// ========== Test Helper ==========
+
+type testDeps struct {
+	roleRepo        *MockRoleRepository
+	permissionCache *fakePermissionCache
+	txManager       *fakeTxManager
+}
+
+func setupTestService(t *testing.T) (*Service, *testDeps) {
+	deps := &testDeps{
+		roleRepo:        new(MockRoleRepository),
+		permissionCache: &fakePermissionCache{},
+		txManager:       &fakeTxManager{},
+	}
+	svc, err := NewService(deps.roleRepo, deps.permissionCache, deps.txManager)
+	if err != nil {
+		t.Fatalf("Failed to create role service: %v", err)
+	}
+	return svc, deps
+}
+
+func adminRole() *entity.Role {
+	return &entity.Role{Name: entity.RoleAdmin, Permissions: entity.AllPermissions(), IsSystem: true}
+}
+
+// ========== NewService Tests ==========
+
+func TestNewService_NilDependencies(t *testing.T) {
+	_, err := NewService(nil, &fakePermissionCache{}, &fakeTxManager{})
+	assert.ErrorIs(t, err, errNilRoleRepository)
+
+	_, err = NewService(new(MockRoleRepository), nil, &fakeTxManager{})
+	assert.ErrorIs(t, err, errNilPermissionCache)
+
+	_, err = NewService(new(MockRoleRepository), &fakePermissionCache{}, nil)
+	assert.ErrorIs(t, err, errNilTxManager)
+}
+
+// ========== GetRolePermissions Tests ==========
+
+func TestGetRolePermissions(t *testing.T) {
+	svc, deps := setupTestService(t)
+	deps.roleRepo.On("GetRoleByName", entity.RoleAdmin).Return(adminRole(), nil)
+	deps.roleRepo.On("GetRoleByName", "deleted").Return(nil, repository.ErrRoleNotFound)
+	deps.roleRepo.On("GetRoleByName", "broken").Return(nil, errors.New("db error"))
+
+	permissions, err := svc.GetRolePermissions(context.Background(), entity.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.AllPermissions(), permissions)
+
+	permissions, err = svc.GetRolePermissions(context.Background(), "deleted")
+	assert.NoError(t, err, "unknown roles grant nothing")
+	assert.Empty(t, permissions)
+
+	_, err = svc.GetRolePermissions(context.Background(), "broken")
+	assert.IsType(t, domain.InternalServerError{}, err)
+}
+
+// ========== CreateRole Tests ==========
+
+func TestCreateRole_Success(t *testing.T) {
+	svc, deps := setupTestService(t)
+
+	var inserted *entity.Role
+	deps.roleRepo.On("InsertRole", mock.Anything).Run(func(args mock.Arguments) {
+		inserted = args.Get(0).(*entity.Role)
+	}).Return(nil)
+	deps.roleRepo.On("GetRoleByName", "support").Return(&entity.Role{Name: "support"}, nil)
+
+	role, err := svc.CreateRole(context.Background(), &CreateRoleInput{
+		Name:        "support",
+		Description: "Support staff",
+		Permissions: []string{entity.PermissionUsersWrite, entity.PermissionUsersRead, entity.PermissionUsersRead},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "support", role.Name)
+	if assert.NotNil(t, inserted) {
+		assert.Equal(t, "Support staff", inserted.Description)
+		assert.Equal(t, []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}, inserted.Permissions,
+			"permissions are sorted and deduplicated")
+	}
+	assert.Equal(t, 1, deps.txManager.calls)
+}
+
+func TestCreateRole_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input CreateRoleInput
+		field string
+	}{
+		{"invalid name", CreateRoleInput{Name: "Support Staff"}, "name"},
+		{"unknown permission", CreateRoleInput{Name: "support", Permissions: []string{"users:delete"}}, "permissions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(t)
+
+			_, err := svc.CreateRole(context.Background(), &tt.input)
+
+			var validationErr domain.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+			deps.roleRepo.AssertNotCalled(t, "InsertRole", mock.Anything)
+		})
+	}
+}
+
+func TestCreateRole_Duplicate(t *testing.T) {
+	svc, deps := setupTestService(t)
+	deps.roleRepo.On("InsertRole", mock.Anything).Return(repository.ErrDuplicateRole)
+
+	_, err := svc.CreateRole(context.Background(), &CreateRoleInput{Name: entity.RoleUser})
+
+	assert.Equal(t, domain.RoleAlreadyExistsError{Name: entity.RoleUser}, err)
+}
+
+// ========== UpdateRole Tests ==========
+
+func TestUpdateRole_Success(t *testing.T) {
+	svc, deps := setupTestService(t)
+	deps.roleRepo.On("GetRoleByName", entity.RoleViewer).
+		Return(&entity.Role{Name: entity.RoleViewer, Description: "Read-only user", Permissions: []string{}, IsSystem: true}, nil)
+
+	var updated *entity.Role
+	deps.roleRepo.On("UpdateRole", mock.Anything).Run(func(args mock.Arguments) {
+		updated = args.Get(0).(*entity.Role)
+	}).Return(nil)
+
+	permissions := []string{entity.PermissionUsersRead}
+	_, err := svc.UpdateRole(context.Background(), &UpdateRoleInput{Name: entity.RoleViewer, Permissions: &permissions})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, updated) {
+		assert.Equal(t, "Read-only user", updated.Description, "nil fields are left unchanged")
+		assert.Equal(t, permissions, updated.Permissions)
+	}
+	assert.Equal(t, []string{entity.RoleViewer}, deps.permissionCache.forgotten)
+}
+
+func TestUpdateRole_AdminPermissionsProtected(t *testing.T) {
+	svc, deps := setupTestService(t)
+	deps.roleRepo.On("GetRoleByName", entity.RoleAdmin).Return(adminRole(), nil)
+	deps.roleRepo.On("UpdateRole", mock.Anything).Return(nil)
+
+	permissions := []string{entity.PermissionUsersRead}
+	_, err := svc.UpdateRole(context.Background(), &UpdateRoleInput{Name: entity.RoleAdmin, Permissions: &permissions})
+	assert.Equal(t, domain.ProtectedRoleError{Name: entity.RoleAdmin}, err)
+	deps.roleRepo.AssertNotCalled(t, "UpdateRole", mock.Anything)
+	assert.Empty(t, deps.permissionCache.forgotten)
+
+	description := "Administrators"
+	_, err = svc.UpdateRole(context.Background(), &UpdateRoleInput{Name: entity.RoleAdmin, Description: &description})
+	assert.NoError(t, err, "the description of the admin role can change")
+}
+
+func TestUpdateRole_NotFound(t *testing.T) {
+	svc, deps := setupTestService(t)
+	deps.roleRepo.On("GetRoleByName", "missing").Return(nil, repository.ErrRoleNotFound)
+
+	_, err := svc.UpdateRole(context.Background(), &UpdateRoleInput{Name: "missing"})
+
+	assert.Equal(t, domain.RoleNotFoundError{Name: "missing"}, err)
+	assert.Empty(t, deps.permissionCache.forgotten)
+}
+
+// ========== DeleteRole Tests ==========
+
+func TestDeleteRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		repoErr error
+		wantErr error
+	}{
+		{"success", "support", nil, nil},
+		{"system role", entity.RoleViewer, nil, domain.ProtectedRoleError{Name: entity.RoleViewer}},
+		{"not found", "support", repository.ErrRoleNotFound, domain.RoleNotFoundError{Name: "support"}},
+		{"in use", "support", repository.ErrRoleInUse, domain.RoleInUseError{Name: "support"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupTestService(t)
+			deps.roleRepo.On("DeleteRole", tt.role).Return(tt.repoErr)
+
+			err := svc.DeleteRole(context.Background(), tt.role)
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{tt.role}, deps.permissionCache.forgotten)
+			} else {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Empty(t, deps.permissionCache.forgotten)
+			}
+		})
+	}
+}
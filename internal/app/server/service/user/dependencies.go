@@ -1,6 +1,9 @@
 package user
 
 import (
+	"context"
+	"time"
+
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
 )
 
@@ -11,26 +14,52 @@ import (
 // This interface should contain only the methods needed by the user service.
 type IUserRepository interface {
 	// Create
-	InsertUser(user *entity.User) (int, error)
+	InsertUser(ctx context.Context, user *entity.User) (int, error)
 
 	// Read
-	GetUserById(id int) (*entity.User, error)
-	GetUserByEmail(email string) (*entity.User, error)
-	GetUsers(offset, limit int, onlyActive bool) ([]*entity.User, error)
-	GetUserCount(onlyActive bool) (int, error)
-	ExistsUserByEmail(email string) (bool, error)
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetUsers(ctx context.Context, offset, limit int, onlyActive bool) ([]*entity.User, error)
+	GetUserCount(ctx context.Context, onlyActive bool) (int, error)
+	ExistsUserByEmail(ctx context.Context, email string) (bool, error)
 
 	// Update
-	UpdateUser(user *entity.User) error
-	UpdateUserPassword(id int, hashedPassword string) error
+	UpdateUser(ctx context.Context, user *entity.User) error
+	UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error
+	// IncrementUserTokenVersion revokes all access tokens issued to the user before.
+	IncrementUserTokenVersion(ctx context.Context, id int) error
+
+	// Login lockout
+	IncrementFailedLoginAttempts(ctx context.Context, id int) (int, error)
+	LockUser(ctx context.Context, id int, until time.Time) error
+	ResetFailedLoginAttempts(ctx context.Context, id int) error
 
 	// Delete
-	DeleteUserById(id int) error
+	DeleteUserById(ctx context.Context, id int) error
+}
+
+// IRoleRepository defines the interface for role data access.
+type IRoleRepository interface {
+	GetRoleByName(ctx context.Context, name string) (*entity.Role, error)
+}
+
+// ITokenRepository defines the interface for refresh token access.
+type ITokenRepository interface {
+	RevokeUserRefreshTokens(ctx context.Context, userId int) error
+}
+
+// ITxManager runs a function inside a database transaction.
+// Repository calls made with the context passed to fn join the transaction.
+// fn may be retried on serialization failures, so it must not have side effects outside the database.
+type ITxManager interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // IPasswordHasher defines the interface for password hashing.
 type IPasswordHasher interface {
 	Hash(password string) (string, error)
 	Compare(hashedPassword, password string) error
+	// NeedsRehash reports whether a hash was written with an outdated algorithm or cost.
+	NeedsRehash(hashedPassword string) bool
 }
 
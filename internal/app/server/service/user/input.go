@@ -1,5 +1,31 @@
 package user
 
+import (
+	"slices"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// Actor is the user making a change, as far as the service needs to authorize it.
+type Actor struct {
+	// Permissions are the permissions of the actor's role.
+	Permissions []string
+}
+
+// covers reports whether the actor holds every permission of role, or may manage roles anyway.
+// A nil actor stands for the system and covers every role.
+func (a *Actor) covers(role *entity.Role) bool {
+	if a == nil || slices.Contains(a.Permissions, entity.PermissionRolesWrite) {
+		return true
+	}
+	for _, permission := range role.Permissions {
+		if !slices.Contains(a.Permissions, permission) {
+			return false
+		}
+	}
+	return true
+}
+
 // ========== Create User ==========
 
 type CreateUserInput struct {
@@ -8,6 +34,8 @@ type CreateUserInput struct {
 	Password string
 	Name     string
 	Role     string
+	// Actor is nil when the role is not picked by a user; it is then only checked to exist.
+	Actor *Actor
 }
 
 // ========== Update User ==========
@@ -19,6 +47,7 @@ type UpdateUserInput struct {
 	Name     *string
 	Role     *string
 	IsActive *bool
+	Actor    *Actor
 }
 
 // ========== Change Password ==========
@@ -1,88 +1,181 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/your-org/go-backend-template/internal/pkg/domain"
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
 	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	errNilRepository     = errors.New("user repository is nil")
-	errNilPasswordHasher = errors.New("password hasher is nil")
+	errNilRepository      = errors.New("user repository is nil")
+	errNilRoleRepository  = errors.New("role repository is nil")
+	errNilTokenRepository = errors.New("token repository is nil")
+	errNilTxManager       = errors.New("transaction manager is nil")
+	errNilPasswordHasher  = errors.New("password hasher is nil")
 )
 
+const (
+	defaultLockoutDuration    = time.Minute
+	defaultMaxLockoutDuration = time.Hour
+)
+
+// dummyPassword is hashed once to have something to compare passwords with when there is no
+// user to check them against.
+const dummyPassword = "dummy password"
+
+// Config holds user service configuration.
+type Config struct {
+	// RequireVerifiedEmail rejects logins of users who have not verified their email.
+	RequireVerifiedEmail bool
+
+	// MaxFailedLoginAttempts locks a user after that many consecutive failed logins; 0 disables lockout.
+	MaxFailedLoginAttempts int
+	// LockoutDuration is the duration of the first lock. Every further failure doubles it,
+	// up to MaxLockoutDuration.
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+}
+
 // Service handles user business logic.
 type Service struct {
-	userRepo       IUserRepository
-	passwordHasher IPasswordHasher
+	userRepo               IUserRepository
+	roleRepo               IRoleRepository
+	tokenRepo              ITokenRepository
+	txManager              ITxManager
+	passwordHasher         IPasswordHasher
+	dummyHash              func() (string, error)
+	requireVerifiedEmail   bool
+	maxFailedLoginAttempts int
+	lockoutDuration        time.Duration
+	maxLockoutDuration     time.Duration
+	now                    func() time.Time
 }
 
 // NewService creates a new user service.
-func NewService(userRepo IUserRepository, passwordHasher IPasswordHasher) (*Service, error) {
+func NewService(
+	userRepo IUserRepository,
+	roleRepo IRoleRepository,
+	tokenRepo ITokenRepository,
+	txManager ITxManager,
+	passwordHasher IPasswordHasher,
+	config Config,
+) (*Service, error) {
 	if userRepo == nil {
 		return nil, domain.InternalServerError{Msg: "failed to create user service", Err: errNilRepository}
 	}
+	if roleRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create user service", Err: errNilRoleRepository}
+	}
+	if tokenRepo == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create user service", Err: errNilTokenRepository}
+	}
+	if txManager == nil {
+		return nil, domain.InternalServerError{Msg: "failed to create user service", Err: errNilTxManager}
+	}
 	if passwordHasher == nil {
 		return nil, domain.InternalServerError{Msg: "failed to create user service", Err: errNilPasswordHasher}
 	}
 
+	lockoutDuration := config.LockoutDuration
+	if lockoutDuration <= 0 {
+		lockoutDuration = defaultLockoutDuration
+	}
+	maxLockoutDuration := config.MaxLockoutDuration
+	if maxLockoutDuration <= 0 {
+		maxLockoutDuration = defaultMaxLockoutDuration
+	}
+	if maxLockoutDuration < lockoutDuration {
+		maxLockoutDuration = lockoutDuration
+	}
+
 	return &Service{
-		userRepo:       userRepo,
-		passwordHasher: passwordHasher,
+		userRepo:               userRepo,
+		roleRepo:               roleRepo,
+		tokenRepo:              tokenRepo,
+		txManager:              txManager,
+		passwordHasher:         passwordHasher,
+		dummyHash:              sync.OnceValues(func() (string, error) { return passwordHasher.Hash(dummyPassword) }),
+		requireVerifiedEmail:   config.RequireVerifiedEmail,
+		maxFailedLoginAttempts: config.MaxFailedLoginAttempts,
+		lockoutDuration:        lockoutDuration,
+		maxLockoutDuration:     maxLockoutDuration,
+		now:                    time.Now,
 	}, nil
 }
 
 // ========== Create User ==========
 
-func (s *Service) CreateUser(input *CreateUserInput) (int, error) {
-	// Validate role
-	if !entity.IsValidRole(input.Role) {
-		return 0, domain.InvalidRoleError{Role: input.Role}
-	}
+func (s *Service) CreateUser(ctx context.Context, input *CreateUserInput) (_ int, err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.CreateUser")
+	defer func() { telemetry.End(span, err) }()
 
-	// Check if email already exists
-	exists, err := s.userRepo.ExistsUserByEmail(input.Email)
-	if err != nil {
-		return 0, domain.InternalServerError{Msg: "failed to check email existence", Err: err}
-	}
-	if exists {
-		return 0, domain.UserAlreadyExistsError{Email: input.Email}
+	// Validate role
+	if err := s.checkRole(ctx, input.Role, input.Actor); err != nil {
+		return 0, err
 	}
 
-	// Hash password
+	// Hash password outside the transaction; bcrypt is slow and would hold it open
 	hashedPassword, err := s.passwordHasher.Hash(input.Password)
 	if err != nil {
 		return 0, domain.InternalServerError{Msg: "failed to hash password", Err: err}
 	}
 
-	// Create user entity
-	user := &entity.User{
-		Email:    input.Email,
-		Username: input.Username,
-		Password: hashedPassword,
-		Name:     input.Name,
-		Role:     input.Role,
-		IsActive: true,
-	}
+	var userId int
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		// Check if email already exists
+		exists, err := s.userRepo.ExistsUserByEmail(ctx, input.Email)
+		if err != nil {
+			return domain.InternalServerError{Msg: "failed to check email existence", Err: err}
+		}
+		if exists {
+			return domain.UserAlreadyExistsError{Email: input.Email}
+		}
 
-	// Insert user
-	userId, err := s.userRepo.InsertUser(user)
-	if err != nil {
-		if errors.Is(err, repository.ErrDuplicateEmail) {
-			return 0, domain.UserAlreadyExistsError{Email: input.Email}
+		// Create user entity
+		user := &entity.User{
+			Email:    input.Email,
+			Username: input.Username,
+			Password: hashedPassword,
+			Name:     input.Name,
+			Role:     input.Role,
+			IsActive: true,
+		}
+
+		// Insert user
+		userId, err = s.userRepo.InsertUser(ctx, user)
+		if err != nil {
+			if errors.Is(err, repository.ErrDuplicateEmail) {
+				return domain.UserAlreadyExistsError{Email: input.Email}
+			}
+			return domain.InternalServerError{Msg: "failed to create user", Err: err}
 		}
-		return 0, domain.InternalServerError{Msg: "failed to create user", Err: err}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
+	span.SetAttributes(attribute.Int("user.id", userId))
 	return userId, nil
 }
 
 // ========== Get User ==========
 
-func (s *Service) GetUserById(id int) (*entity.User, error) {
-	user, err := s.userRepo.GetUserById(id)
+func (s *Service) GetUserById(ctx context.Context, id int) (_ *entity.User, err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.GetUserById", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { telemetry.End(span, err) }()
+
+	user, err := s.userRepo.GetUserById(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return nil, domain.UserNotFoundError{Id: id}
@@ -92,8 +185,11 @@ func (s *Service) GetUserById(id int) (*entity.User, error) {
 	return user, nil
 }
 
-func (s *Service) GetUserByEmail(email string) (*entity.User, error) {
-	user, err := s.userRepo.GetUserByEmail(email)
+func (s *Service) GetUserByEmail(ctx context.Context, email string) (_ *entity.User, err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.GetUserByEmail")
+	defer func() { telemetry.End(span, err) }()
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return nil, domain.UserNotFoundError{Email: email}
@@ -110,15 +206,18 @@ type GetUsersResult struct {
 	TotalCount int
 }
 
-func (s *Service) GetUsers(input *GetUsersInput) (*GetUsersResult, error) {
+func (s *Service) GetUsers(ctx context.Context, input *GetUsersInput) (_ *GetUsersResult, err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.GetUsers")
+	defer func() { telemetry.End(span, err) }()
+
 	offset := input.Size * (input.Page - 1)
 
-	users, err := s.userRepo.GetUsers(offset, input.Size, input.OnlyActive)
+	users, err := s.userRepo.GetUsers(ctx, offset, input.Size, input.OnlyActive)
 	if err != nil {
 		return nil, domain.InternalServerError{Msg: "failed to get users", Err: err}
 	}
 
-	totalCount, err := s.userRepo.GetUserCount(input.OnlyActive)
+	totalCount, err := s.userRepo.GetUserCount(ctx, input.OnlyActive)
 	if err != nil {
 		return nil, domain.InternalServerError{Msg: "failed to get user count", Err: err}
 	}
@@ -131,77 +230,113 @@ func (s *Service) GetUsers(input *GetUsersInput) (*GetUsersResult, error) {
 
 // ========== Update User ==========
 
-func (s *Service) UpdateUser(input *UpdateUserInput) error {
-	// Get existing user
-	user, err := s.userRepo.GetUserById(input.Id)
-	if err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return domain.UserNotFoundError{Id: input.Id}
+// UpdateUser applies the given changes. A new email is not applied directly: it is stored as
+// the pending email, and replaces the current one once verified (see account.Service.VerifyEmail).
+// Setting the email back to the current one cancels a pending change.
+func (s *Service) UpdateUser(ctx context.Context, input *UpdateUserInput) (err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.UpdateUser", trace.WithAttributes(attribute.Int("user.id", input.Id)))
+	defer func() { telemetry.End(span, err) }()
+
+	// Read, check and write in one transaction so concurrent updates cannot interleave
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		// Get existing user
+		user, err := s.userRepo.GetUserById(ctx, input.Id)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return domain.UserNotFoundError{Id: input.Id}
+			}
+			return domain.InternalServerError{Msg: "failed to get user", Err: err}
+		}
+		if err := s.checkTarget(ctx, user, input.Actor); err != nil {
+			return err
 		}
-		return domain.InternalServerError{Msg: "failed to get user", Err: err}
-	}
-
-	hasChanges := false
 
-	// Update email if provided
-	if input.Email != nil && *input.Email != user.Email {
-		// Check if new email already exists
-		exists, err := s.userRepo.ExistsUserByEmail(*input.Email)
-		if err != nil {
-			return domain.InternalServerError{Msg: "failed to check email existence", Err: err}
+		hasChanges := false
+		// Access tokens carry the role, so they must not outlive it
+		revokeTokens := false
+		// Sessions of a deactivated user end altogether
+		revokeSessions := false
+
+		// Request an email change if provided; the current email stays active until verified
+		if input.Email != nil && *input.Email != user.Email {
+			if user.PendingEmail == nil || *user.PendingEmail != *input.Email {
+				// Check if new email already exists
+				exists, err := s.userRepo.ExistsUserByEmail(ctx, *input.Email)
+				if err != nil {
+					return domain.InternalServerError{Msg: "failed to check email existence", Err: err}
+				}
+				if exists {
+					return domain.UserAlreadyExistsError{Email: *input.Email}
+				}
+				pendingEmail := *input.Email
+				user.PendingEmail = &pendingEmail
+				hasChanges = true
+			}
+		} else if input.Email != nil && user.PendingEmail != nil {
+			user.PendingEmail = nil
+			hasChanges = true
 		}
-		if exists {
-			return domain.UserAlreadyExistsError{Email: *input.Email}
+
+		// Update username if provided
+		if input.Username != nil && *input.Username != user.Username {
+			user.Username = *input.Username
+			hasChanges = true
 		}
-		user.Email = *input.Email
-		hasChanges = true
-	}
 
-	// Update username if provided
-	if input.Username != nil && *input.Username != user.Username {
-		user.Username = *input.Username
-		hasChanges = true
-	}
+		// Update name if provided
+		if input.Name != nil && *input.Name != user.Name {
+			user.Name = *input.Name
+			hasChanges = true
+		}
 
-	// Update name if provided
-	if input.Name != nil && *input.Name != user.Name {
-		user.Name = *input.Name
-		hasChanges = true
-	}
+		// Update role if provided
+		if input.Role != nil && *input.Role != user.Role {
+			if err := s.checkRole(ctx, *input.Role, input.Actor); err != nil {
+				return err
+			}
+			user.Role = *input.Role
+			hasChanges = true
+			revokeTokens = true
+		}
 
-	// Update role if provided
-	if input.Role != nil && *input.Role != user.Role {
-		if !entity.IsValidRole(*input.Role) {
-			return domain.InvalidRoleError{Role: *input.Role}
+		// Update is_active if provided
+		if input.IsActive != nil && *input.IsActive != user.IsActive {
+			user.IsActive = *input.IsActive
+			hasChanges = true
+			revokeSessions = true
 		}
-		user.Role = *input.Role
-		hasChanges = true
-	}
 
-	// Update is_active if provided
-	if input.IsActive != nil && *input.IsActive != user.IsActive {
-		user.IsActive = *input.IsActive
-		hasChanges = true
-	}
+		// Only update if there are changes
+		if hasChanges {
+			if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+				if errors.Is(err, repository.ErrDuplicateEmail) {
+					return domain.UserAlreadyExistsError{Email: user.Email}
+				}
+				return domain.InternalServerError{Msg: "failed to update user", Err: err}
+			}
+		}
 
-	// Only update if there are changes
-	if hasChanges {
-		if err := s.userRepo.UpdateUser(user); err != nil {
-			if errors.Is(err, repository.ErrDuplicateEmail) {
-				return domain.UserAlreadyExistsError{Email: user.Email}
+		if revokeSessions {
+			return s.revokeSessions(ctx, user.Id)
+		}
+		if revokeTokens {
+			if err := s.userRepo.IncrementUserTokenVersion(ctx, user.Id); err != nil {
+				return domain.InternalServerError{Msg: "failed to increment token version", Err: err}
 			}
-			return domain.InternalServerError{Msg: "failed to update user", Err: err}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // ========== Change Password ==========
 
-func (s *Service) ChangePassword(input *ChangePasswordInput) error {
+func (s *Service) ChangePassword(ctx context.Context, input *ChangePasswordInput) (err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.ChangePassword", trace.WithAttributes(attribute.Int("user.id", input.UserId)))
+	defer func() { telemetry.End(span, err) }()
+
 	// Get user
-	user, err := s.userRepo.GetUserById(input.UserId)
+	user, err := s.userRepo.GetUserById(ctx, input.UserId)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return domain.UserNotFoundError{Id: input.UserId}
@@ -220,47 +355,248 @@ func (s *Service) ChangePassword(input *ChangePasswordInput) error {
 		return domain.InternalServerError{Msg: "failed to hash password", Err: err}
 	}
 
-	// Update password
-	if err := s.userRepo.UpdateUserPassword(input.UserId, hashedPassword); err != nil {
-		return domain.InternalServerError{Msg: "failed to update password", Err: err}
-	}
-
-	return nil
+	// Update password, and end the sessions of whoever else knew the old one
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateUserPassword(ctx, input.UserId, hashedPassword); err != nil {
+			return domain.InternalServerError{Msg: "failed to update password", Err: err}
+		}
+		return s.revokeSessions(ctx, input.UserId)
+	})
 }
 
 // ========== Delete User ==========
 
-func (s *Service) DeleteUser(id int) error {
-	if err := s.userRepo.DeleteUserById(id); err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			return domain.UserNotFoundError{Id: id}
+// DeleteUser deletes a user on behalf of actor, who must cover the user's role (see AuthorizeUserChange).
+func (s *Service) DeleteUser(ctx context.Context, id int, actor *Actor) (err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.DeleteUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { telemetry.End(span, err) }()
+
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.AuthorizeUserChange(ctx, id, actor); err != nil {
+			return err
 		}
-		return domain.InternalServerError{Msg: "failed to delete user", Err: err}
-	}
-	return nil
+		if err := s.userRepo.DeleteUserById(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return domain.UserNotFoundError{Id: id}
+			}
+			return domain.InternalServerError{Msg: "failed to delete user", Err: err}
+		}
+		return nil
+	})
 }
 
 // ========== Login ==========
 
-func (s *Service) Login(input *LoginInput) (*entity.User, error) {
+func (s *Service) Login(ctx context.Context, input *LoginInput) (_ *entity.User, err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.Login")
+	defer func() { telemetry.End(span, err) }()
+
 	// Get user by email
-	user, err := s.userRepo.GetUserByEmail(input.Email)
+	user, err := s.userRepo.GetUserByEmail(ctx, input.Email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
+			s.compareDummyPassword(input.Password)
 			return nil, domain.InvalidCredentialsError{}
 		}
 		return nil, domain.InternalServerError{Msg: "failed to get user", Err: err}
 	}
 
+	span.SetAttributes(attribute.Int("user.id", user.Id))
+
 	// Check if user is active
 	if !user.IsActive {
+		s.compareDummyPassword(input.Password)
+		return nil, domain.InvalidCredentialsError{}
+	}
+
+	// A locked user looks like a wrong password, so that lockouts do not reveal which emails are registered.
+	// The password is not checked, so that it cannot be guessed while locked.
+	if user.IsLocked(s.now()) {
+		s.compareDummyPassword(input.Password)
 		return nil, domain.InvalidCredentialsError{}
 	}
 
 	// Verify password
 	if err := s.passwordHasher.Compare(user.Password, input.Password); err != nil {
+		s.RecordFailedLogin(ctx, user.Id)
 		return nil, domain.InvalidCredentialsError{}
 	}
 
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		// Failures are ignored: the count only matters once it reaches the limit again
+		if err := s.userRepo.ResetFailedLoginAttempts(ctx, user.Id); err != nil {
+			logger.FromContext(ctx).Warn("failed to reset failed login attempts", "user_id", user.Id, "error", err)
+		} else {
+			user.FailedLoginAttempts = 0
+			user.LockedUntil = nil
+		}
+	}
+
+	// Checked after the password so that it does not reveal which emails are registered
+	if s.requireVerifiedEmail && !user.IsEmailVerified() {
+		return nil, domain.EmailNotVerifiedError{}
+	}
+
+	// Upgrade outdated hashes while the plain text password is at hand
+	if s.passwordHasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user, input.Password)
+	}
+
 	return user, nil
 }
+
+// compareDummyPassword takes as long as checking a password, so that logins rejected without
+// checking one are not told apart by their response time, which would reveal registered emails.
+func (s *Service) compareDummyPassword(password string) {
+	hashedPassword, err := s.dummyHash()
+	if err != nil {
+		return
+	}
+	_ = s.passwordHasher.Compare(hashedPassword, password)
+}
+
+// RecordFailedLogin counts a failed login and locks the user once the count reaches the limit.
+// Login calls it on a wrong password; callers call it on a wrong second factor.
+// Failures are ignored, like a wrong password: the login is rejected either way.
+func (s *Service) RecordFailedLogin(ctx context.Context, userId int) {
+	ctx, span := telemetry.Start(ctx, "user.Service.RecordFailedLogin", trace.WithAttributes(attribute.Int("user.id", userId)))
+	defer span.End()
+
+	if s.maxFailedLoginAttempts <= 0 {
+		return
+	}
+
+	var lockedUntil time.Time
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		attempts, err := s.userRepo.IncrementFailedLoginAttempts(ctx, userId)
+		if err != nil || attempts < s.maxFailedLoginAttempts {
+			return err
+		}
+		lockedUntil = s.now().Add(s.lockoutDurationAfter(attempts))
+		return s.userRepo.LockUser(ctx, userId, lockedUntil)
+	})
+	if err != nil {
+		telemetry.RecordError(span, err)
+		logger.FromContext(ctx).Warn("failed to record failed login", "user_id", userId, "error", err)
+		return
+	}
+	if !lockedUntil.IsZero() {
+		logger.FromContext(ctx).Info("user locked after failed logins", "user_id", userId, "locked_until", lockedUntil)
+	}
+}
+
+// lockoutDurationAfter returns how long a user is locked after the given number of consecutive
+// failures: the lockout duration at the limit, doubled for every failure beyond it.
+func (s *Service) lockoutDurationAfter(attempts int) time.Duration {
+	d := s.lockoutDuration
+	for i := s.maxFailedLoginAttempts; i < attempts && d < s.maxLockoutDuration; i++ {
+		d *= 2
+	}
+	if d > s.maxLockoutDuration {
+		d = s.maxLockoutDuration
+	}
+	return d
+}
+
+// ========== Unlock User ==========
+
+// UnlockUser clears the lockout of a user, so that they can log in again right away.
+// actor must cover the user's role (see AuthorizeUserChange).
+func (s *Service) UnlockUser(ctx context.Context, id int, actor *Actor) (err error) {
+	ctx, span := telemetry.Start(ctx, "user.Service.UnlockUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { telemetry.End(span, err) }()
+
+	return s.txManager.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.AuthorizeUserChange(ctx, id, actor); err != nil {
+			return err
+		}
+		if err := s.userRepo.ResetFailedLoginAttempts(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return domain.UserNotFoundError{Id: id}
+			}
+			return domain.InternalServerError{Msg: "failed to unlock user", Err: err}
+		}
+		return nil
+	})
+}
+
+// ========== Authorization ==========
+
+// AuthorizeUserChange returns domain.ForbiddenError unless actor may change the user with the
+// given id: actors who cannot manage roles may only change users whose role grants no permission
+// they lack, or users:write would be enough to deactivate, delete or take over an admin.
+// Handlers call it before changes made by other services, e.g. resetting the second factor.
+func (s *Service) AuthorizeUserChange(ctx context.Context, id int, actor *Actor) error {
+	if actor == nil {
+		return nil
+	}
+	user, err := s.userRepo.GetUserById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.UserNotFoundError{Id: id}
+		}
+		return domain.InternalServerError{Msg: "failed to get user", Err: err}
+	}
+	return s.checkTarget(ctx, user, actor)
+}
+
+// rehashPassword stores a new hash of password for user.
+// Failures are ignored: the old hash still works, and the next login retries.
+func (s *Service) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to rehash password", "user_id", user.Id, "error", err)
+		return
+	}
+	if err := s.userRepo.UpdateUserPassword(ctx, user.Id, hashedPassword); err != nil {
+		logger.FromContext(ctx).Warn("failed to store rehashed password", "user_id", user.Id, "error", err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// revokeSessions revokes every access and refresh token of the user, like auth.Service.LogoutAll.
+// Access tokens may still pass for up to the revocation cache TTL of the auth service.
+func (s *Service) revokeSessions(ctx context.Context, userId int) error {
+	if err := s.userRepo.IncrementUserTokenVersion(ctx, userId); err != nil {
+		return domain.InternalServerError{Msg: "failed to increment token version", Err: err}
+	}
+	if err := s.tokenRepo.RevokeUserRefreshTokens(ctx, userId); err != nil {
+		return domain.InternalServerError{Msg: "failed to revoke refresh tokens", Err: err}
+	}
+	return nil
+}
+
+// checkRole returns domain.InvalidRoleError unless role exists, and domain.ForbiddenError if actor
+// may not assign it. Users who cannot manage roles can only assign roles that grant no permission
+// they lack, or users:write would be enough to make anyone an admin.
+func (s *Service) checkRole(ctx context.Context, role string, actor *Actor) error {
+	r, err := s.roleRepo.GetRoleByName(ctx, role)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return domain.InvalidRoleError{Role: role}
+		}
+		return domain.InternalServerError{Msg: "failed to get role", Err: err}
+	}
+
+	if !actor.covers(r) {
+		return domain.ForbiddenError{Reason: fmt.Sprintf("role %s grants permissions you do not have", role)}
+	}
+	return nil
+}
+
+// checkTarget returns domain.ForbiddenError unless actor covers the current role of user.
+func (s *Service) checkTarget(ctx context.Context, user *entity.User, actor *Actor) error {
+	if actor == nil {
+		return nil
+	}
+	r, err := s.roleRepo.GetRoleByName(ctx, user.Role)
+	if err != nil {
+		return domain.InternalServerError{Msg: "failed to get role", Err: err}
+	}
+
+	if !actor.covers(r) {
+		return domain.ForbiddenError{Reason: "the user has permissions you do not have"}
+	}
+	return nil
+}
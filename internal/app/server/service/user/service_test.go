@@ -1,14 +1,20 @@
 package user
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/your-org/go-backend-template/internal/pkg/domain"
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
 	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // ========== Mock Repository ==========
@@ -17,12 +23,12 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) InsertUser(user *entity.User) (int, error) {
+func (m *MockUserRepository) InsertUser(ctx context.Context, user *entity.User) (int, error) {
 	args := m.Called(user)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserById(id int) (*entity.User, error) {
+func (m *MockUserRepository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,7 +36,7 @@ func (m *MockUserRepository) GetUserById(id int) (*entity.User, error) {
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserByEmail(email string) (*entity.User, error) {
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -38,36 +44,82 @@ func (m *MockUserRepository) GetUserByEmail(email string) (*entity.User, error)
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetUsers(offset, limit int, onlyActive bool) ([]*entity.User, error) {
+func (m *MockUserRepository) GetUsers(ctx context.Context, offset, limit int, onlyActive bool) ([]*entity.User, error) {
 	args := m.Called(offset, limit, onlyActive)
 	return args.Get(0).([]*entity.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserCount(onlyActive bool) (int, error) {
+func (m *MockUserRepository) GetUserCount(ctx context.Context, onlyActive bool) (int, error) {
 	args := m.Called(onlyActive)
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockUserRepository) ExistsUserByEmail(email string) (bool, error) {
+func (m *MockUserRepository) ExistsUserByEmail(ctx context.Context, email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepository) UpdateUser(user *entity.User) error {
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user *entity.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) UpdateUserPassword(id int, hashedPassword string) error {
+func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error {
 	args := m.Called(id, hashedPassword)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) DeleteUserById(id int) error {
+func (m *MockUserRepository) IncrementUserTokenVersion(ctx context.Context, id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) RevokeUserRefreshTokens(ctx context.Context, userId int) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) IncrementFailedLoginAttempts(ctx context.Context, id int) (int, error) {
+	args := m.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) LockUser(ctx context.Context, id int, until time.Time) error {
+	args := m.Called(id, until)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ResetFailedLoginAttempts(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteUserById(ctx context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// ========== Fake Role Repository ==========
+
+// fakeRoleRepository knows the built-in roles, where admin has every permission,
+// plus the custom roles in roles, mapped to their permissions.
+type fakeRoleRepository struct {
+	roles map[string][]string
+}
+
+func (f *fakeRoleRepository) GetRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	if name == entity.RoleAdmin {
+		return &entity.Role{Name: name, Permissions: entity.AllPermissions(), IsSystem: true}, nil
+	}
+	if entity.IsSystemRole(name) {
+		return &entity.Role{Name: name, IsSystem: true}, nil
+	}
+	if permissions, ok := f.roles[name]; ok {
+		return &entity.Role{Name: name, Permissions: permissions}, nil
+	}
+	return nil, repository.ErrRoleNotFound
+}
+
 // ========== Mock Password Hasher ==========
 
 type MockPasswordHasher struct {
@@ -84,13 +136,39 @@ func (m *MockPasswordHasher) Compare(hashedPassword, password string) error {
 	return args.Error(0)
 }
 
+func (m *MockPasswordHasher) NeedsRehash(hashedPassword string) bool {
+	args := m.Called(hashedPassword)
+	return args.Bool(0)
+}
+
+// ========== Fake Transaction Manager ==========
+
+// fakeTxManager runs fn directly, like a transaction that always commits.
+type fakeTxManager struct {
+	calls int
+}
+
+func (f *fakeTxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
 // ========== Test Helper ==========
 
 func setupTestService() (*Service, *MockUserRepository, *MockPasswordHasher) {
+	svc, mockRepo, mockHasher, _ := setupTestServiceWithTx()
+	return svc, mockRepo, mockHasher
+}
+
+func setupTestServiceWithTx() (*Service, *MockUserRepository, *MockPasswordHasher, *fakeTxManager) {
 	mockRepo := new(MockUserRepository)
 	mockHasher := new(MockPasswordHasher)
-	service, _ := NewService(mockRepo, mockHasher)
-	return service, mockRepo, mockHasher
+	txManager := &fakeTxManager{}
+	service, _ := NewService(mockRepo, &fakeRoleRepository{roles: map[string][]string{
+		"support":      {entity.PermissionUsersRead},
+		"user-manager": {entity.PermissionUsersRead, entity.PermissionUsersWrite},
+	}}, mockRepo, txManager, mockHasher, Config{})
+	return service, mockRepo, mockHasher, txManager
 }
 
 // ========== CreateUser Tests ==========
@@ -110,7 +188,7 @@ func TestCreateUser_Success(t *testing.T) {
 	mockHasher.On("Hash", input.Password).Return("hashed_password", nil)
 	mockRepo.On("InsertUser", mock.AnythingOfType("*entity.User")).Return(1, nil)
 
-	userId, err := svc.CreateUser(input)
+	userId, err := svc.CreateUser(context.Background(), input)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, userId)
@@ -129,15 +207,57 @@ func TestCreateUser_InvalidRole(t *testing.T) {
 		Role:     "invalid_role",
 	}
 
-	userId, err := svc.CreateUser(input)
+	userId, err := svc.CreateUser(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, userId)
 	assert.IsType(t, domain.InvalidRoleError{}, err)
 }
 
+func TestCreateUser_CustomRole(t *testing.T) {
+	svc, mockRepo, mockHasher := setupTestService()
+
+	input := &CreateUserInput{
+		Email:    "test@example.com",
+		Username: "testuser",
+		Password: "password123",
+		Name:     "Test User",
+		Role:     "support",
+	}
+
+	mockRepo.On("ExistsUserByEmail", input.Email).Return(false, nil)
+	mockHasher.On("Hash", input.Password).Return("hashed_password", nil)
+	mockRepo.On("InsertUser", mock.MatchedBy(func(user *entity.User) bool {
+		return user.Role == "support"
+	})).Return(1, nil)
+
+	_, err := svc.CreateUser(context.Background(), input)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateUser_RoleBeyondActorPermissions(t *testing.T) {
+	svc, mockRepo, mockHasher := setupTestService()
+
+	input := &CreateUserInput{
+		Email:    "test@example.com",
+		Username: "testuser",
+		Password: "password123",
+		Name:     "Test User",
+		Role:     entity.RoleAdmin,
+		Actor:    &Actor{Permissions: []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}},
+	}
+
+	_, err := svc.CreateUser(context.Background(), input)
+
+	assert.IsType(t, domain.ForbiddenError{}, err)
+	mockHasher.AssertNotCalled(t, "Hash", mock.Anything)
+	mockRepo.AssertNotCalled(t, "InsertUser", mock.Anything)
+}
+
 func TestCreateUser_EmailAlreadyExists(t *testing.T) {
-	svc, mockRepo, _ := setupTestService()
+	svc, mockRepo, mockHasher := setupTestService()
 
 	input := &CreateUserInput{
 		Email:    "existing@example.com",
@@ -147,14 +267,16 @@ func TestCreateUser_EmailAlreadyExists(t *testing.T) {
 		Role:     entity.RoleUser,
 	}
 
+	mockHasher.On("Hash", input.Password).Return("hashed_password", nil)
 	mockRepo.On("ExistsUserByEmail", input.Email).Return(true, nil)
 
-	userId, err := svc.CreateUser(input)
+	userId, err := svc.CreateUser(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, userId)
 	assert.IsType(t, domain.UserAlreadyExistsError{}, err)
 	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "InsertUser", mock.Anything)
 }
 
 func TestCreateUser_HashError(t *testing.T) {
@@ -168,14 +290,37 @@ func TestCreateUser_HashError(t *testing.T) {
 		Role:     entity.RoleUser,
 	}
 
-	mockRepo.On("ExistsUserByEmail", input.Email).Return(false, nil)
 	mockHasher.On("Hash", input.Password).Return("", errors.New("hash error"))
 
-	userId, err := svc.CreateUser(input)
+	userId, err := svc.CreateUser(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, userId)
 	assert.IsType(t, domain.InternalServerError{}, err)
+	mockRepo.AssertNotCalled(t, "ExistsUserByEmail", mock.Anything)
+}
+
+func TestCreateUser_RunsInTransaction(t *testing.T) {
+	svc, mockRepo, mockHasher, txManager := setupTestServiceWithTx()
+
+	input := &CreateUserInput{
+		Email:    "test@example.com",
+		Username: "testuser",
+		Password: "password123",
+		Name:     "Test User",
+		Role:     entity.RoleUser,
+	}
+
+	mockHasher.On("Hash", input.Password).Return("hashed_password", nil)
+	mockRepo.On("ExistsUserByEmail", input.Email).Return(false, nil)
+	mockRepo.On("InsertUser", mock.AnythingOfType("*entity.User")).Return(0, repository.ErrDuplicateEmail)
+
+	userId, err := svc.CreateUser(context.Background(), input)
+
+	// A concurrent insert that wins the race surfaces as a duplicate email
+	assert.Equal(t, 0, userId)
+	assert.IsType(t, domain.UserAlreadyExistsError{}, err)
+	assert.Equal(t, 1, txManager.calls)
 }
 
 // ========== GetUserById Tests ==========
@@ -193,7 +338,7 @@ func TestGetUserById_Success(t *testing.T) {
 
 	mockRepo.On("GetUserById", 1).Return(expectedUser, nil)
 
-	user, err := svc.GetUserById(1)
+	user, err := svc.GetUserById(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser, user)
@@ -205,7 +350,7 @@ func TestGetUserById_NotFound(t *testing.T) {
 
 	mockRepo.On("GetUserById", 999).Return(nil, repository.ErrUserNotFound)
 
-	user, err := svc.GetUserById(999)
+	user, err := svc.GetUserById(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
@@ -232,7 +377,7 @@ func TestGetUsers_Success(t *testing.T) {
 	mockRepo.On("GetUsers", 0, 10, false).Return(expectedUsers, nil)
 	mockRepo.On("GetUserCount", false).Return(2, nil)
 
-	result, err := svc.GetUsers(input)
+	result, err := svc.GetUsers(context.Background(), input)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 2, result.TotalCount)
@@ -256,7 +401,7 @@ func TestGetUsers_Pagination(t *testing.T) {
 	mockRepo.On("GetUsers", 10, 10, true).Return(expectedUsers, nil)
 	mockRepo.On("GetUserCount", true).Return(11, nil)
 
-	result, err := svc.GetUsers(input)
+	result, err := svc.GetUsers(context.Background(), input)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 11, result.TotalCount)
@@ -267,7 +412,7 @@ func TestGetUsers_Pagination(t *testing.T) {
 // ========== UpdateUser Tests ==========
 
 func TestUpdateUser_Success(t *testing.T) {
-	svc, mockRepo, _ := setupTestService()
+	svc, mockRepo, _, txManager := setupTestServiceWithTx()
 
 	existingUser := &entity.User{
 		Id:       1,
@@ -286,11 +431,51 @@ func TestUpdateUser_Success(t *testing.T) {
 		Name:  &newName,
 	}
 
+	var updated *entity.User
 	mockRepo.On("GetUserById", 1).Return(existingUser, nil)
 	mockRepo.On("ExistsUserByEmail", newEmail).Return(false, nil)
-	mockRepo.On("UpdateUser", mock.AnythingOfType("*entity.User")).Return(nil)
+	mockRepo.On("UpdateUser", mock.AnythingOfType("*entity.User")).
+		Run(func(args mock.Arguments) { updated = args.Get(0).(*entity.User) }).
+		Return(nil)
+
+	err := svc.UpdateUser(context.Background(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, txManager.calls)
+	mockRepo.AssertExpectations(t)
+	// Sessions are revoked once the new email is verified, not while it is pending
+	mockRepo.AssertNotCalled(t, "IncrementUserTokenVersion", mock.Anything)
+
+	// The new email waits for verification
+	assert.Equal(t, "old@example.com", updated.Email)
+	if assert.NotNil(t, updated.PendingEmail) {
+		assert.Equal(t, newEmail, *updated.PendingEmail)
+	}
+	assert.Equal(t, newName, updated.Name)
+}
+
+func TestUpdateUser_CancelPendingEmail(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	pendingEmail := "new@example.com"
+	existingUser := &entity.User{
+		Id:           1,
+		Email:        "old@example.com",
+		PendingEmail: &pendingEmail,
+	}
+
+	currentEmail := "old@example.com"
+	input := &UpdateUserInput{
+		Id:    1,
+		Email: &currentEmail,
+	}
+
+	mockRepo.On("GetUserById", 1).Return(existingUser, nil)
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == currentEmail && u.PendingEmail == nil
+	})).Return(nil)
 
-	err := svc.UpdateUser(input)
+	err := svc.UpdateUser(context.Background(), input)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -307,13 +492,142 @@ func TestUpdateUser_NotFound(t *testing.T) {
 
 	mockRepo.On("GetUserById", 999).Return(nil, repository.ErrUserNotFound)
 
-	err := svc.UpdateUser(input)
+	err := svc.UpdateUser(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.IsType(t, domain.UserNotFoundError{}, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateUser_UnknownRole(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: entity.RoleUser}, nil)
+
+	role := "deleted"
+	err := svc.UpdateUser(context.Background(), &UpdateUserInput{Id: 1, Role: &role})
+
+	assert.Equal(t, domain.InvalidRoleError{Role: "deleted"}, err)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
+
+func TestUpdateUser_RoleBeyondActorPermissions(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	mockRepo.On("GetUserById", 2).Return(&entity.User{Id: 2, Role: entity.RoleUser}, nil)
+
+	// A custom role with users:write cannot promote anyone to admin
+	role := entity.RoleAdmin
+	err := svc.UpdateUser(context.Background(), &UpdateUserInput{
+		Id:    2,
+		Role:  &role,
+		Actor: &Actor{Permissions: []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}},
+	})
+
+	assert.IsType(t, domain.ForbiddenError{}, err)
+	mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+}
+
+func TestUpdateUser_RoleWithinActorPermissions(t *testing.T) {
+	tests := []struct {
+		name  string
+		role  string
+		actor []string
+	}{
+		{"subset of own permissions", "support", []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}},
+		{"same permissions", "user-manager", []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}},
+		{"role manager", entity.RoleAdmin, []string{entity.PermissionUsersWrite, entity.PermissionRolesWrite}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mockRepo, _ := setupTestService()
+
+			mockRepo.On("GetUserById", 2).Return(&entity.User{Id: 2, Role: entity.RoleUser}, nil)
+			mockRepo.On("UpdateUser", mock.MatchedBy(func(u *entity.User) bool {
+				return u.Role == tt.role
+			})).Return(nil)
+			mockRepo.On("IncrementUserTokenVersion", 2).Return(nil)
+
+			role := tt.role
+			err := svc.UpdateUser(context.Background(), &UpdateUserInput{
+				Id:    2,
+				Role:  &role,
+				Actor: &Actor{Permissions: tt.actor},
+			})
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateUser_TargetBeyondActorPermissions(t *testing.T) {
+	isActive := false
+	email := "attacker@example.com"
+	name := "Renamed"
+	tests := []struct {
+		name  string
+		input UpdateUserInput
+	}{
+		{"deactivate", UpdateUserInput{IsActive: &isActive}},
+		{"change email", UpdateUserInput{Email: &email}},
+		{"rename", UpdateUserInput{Name: &name}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mockRepo, _ := setupTestService()
+
+			mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Email: "admin@example.com", Role: entity.RoleAdmin, IsActive: true}, nil)
+
+			// users:write alone must not be enough to take over an admin
+			input := tt.input
+			input.Id = 1
+			input.Actor = &Actor{Permissions: []string{entity.PermissionUsersWrite}}
+			err := svc.UpdateUser(context.Background(), &input)
+
+			assert.IsType(t, domain.ForbiddenError{}, err)
+			mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything)
+			mockRepo.AssertNotCalled(t, "IncrementUserTokenVersion", mock.Anything)
+		})
+	}
+}
+
+func TestUpdateUser_RoleChangeRevokesTokens(t *testing.T) {
+	svc, mockRepo, _, txManager := setupTestServiceWithTx()
+
+	mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: entity.RoleAdmin}, nil)
+	mockRepo.On("UpdateUser", mock.AnythingOfType("*entity.User")).Return(nil)
+	mockRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+
+	// A demoted admin must not keep admin access tokens
+	role := entity.RoleUser
+	err := svc.UpdateUser(context.Background(), &UpdateUserInput{Id: 1, Role: &role})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, txManager.calls)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateUser_DeactivationRevokesSessions(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: entity.RoleUser, IsActive: true}, nil)
+	mockRepo.On("UpdateUser", mock.MatchedBy(func(u *entity.User) bool {
+		return !u.IsActive
+	})).Return(nil)
+	mockRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	mockRepo.On("RevokeUserRefreshTokens", 1).Return(nil)
+
+	// Refresh tokens would otherwise work again once the user is reactivated
+	isActive := false
+	err := svc.UpdateUser(context.Background(), &UpdateUserInput{Id: 1, IsActive: &isActive})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestUpdateUser_EmailConflict(t *testing.T) {
 	svc, mockRepo, _ := setupTestService()
 
@@ -331,7 +645,7 @@ func TestUpdateUser_EmailConflict(t *testing.T) {
 	mockRepo.On("GetUserById", 1).Return(existingUser, nil)
 	mockRepo.On("ExistsUserByEmail", newEmail).Return(true, nil)
 
-	err := svc.UpdateUser(input)
+	err := svc.UpdateUser(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.IsType(t, domain.UserAlreadyExistsError{}, err)
@@ -360,7 +674,7 @@ func TestUpdateUser_NoChanges(t *testing.T) {
 	mockRepo.On("GetUserById", 1).Return(existingUser, nil)
 	// UpdateUser should NOT be called since there are no changes
 
-	err := svc.UpdateUser(input)
+	err := svc.UpdateUser(context.Background(), input)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -374,7 +688,7 @@ func TestDeleteUser_Success(t *testing.T) {
 
 	mockRepo.On("DeleteUserById", 1).Return(nil)
 
-	err := svc.DeleteUser(1)
+	err := svc.DeleteUser(context.Background(), 1, nil)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -385,13 +699,37 @@ func TestDeleteUser_NotFound(t *testing.T) {
 
 	mockRepo.On("DeleteUserById", 999).Return(repository.ErrUserNotFound)
 
-	err := svc.DeleteUser(999)
+	err := svc.DeleteUser(context.Background(), 999, nil)
 
 	assert.Error(t, err)
 	assert.IsType(t, domain.UserNotFoundError{}, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestDeleteUser_WithinActorPermissions(t *testing.T) {
+	svc, mockRepo, _, txManager := setupTestServiceWithTx()
+
+	mockRepo.On("GetUserById", 2).Return(&entity.User{Id: 2, Role: "support"}, nil)
+	mockRepo.On("DeleteUserById", 2).Return(nil)
+
+	err := svc.DeleteUser(context.Background(), 2, &Actor{Permissions: []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, txManager.calls)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteUser_TargetBeyondActorPermissions(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: entity.RoleAdmin}, nil)
+
+	err := svc.DeleteUser(context.Background(), 1, &Actor{Permissions: []string{entity.PermissionUsersWrite}})
+
+	assert.IsType(t, domain.ForbiddenError{}, err)
+	mockRepo.AssertNotCalled(t, "DeleteUserById", mock.Anything)
+}
+
 // ========== Login Tests ==========
 
 func TestLogin_Success(t *testing.T) {
@@ -411,8 +749,9 @@ func TestLogin_Success(t *testing.T) {
 
 	mockRepo.On("GetUserByEmail", input.Email).Return(expectedUser, nil)
 	mockHasher.On("Compare", "hashed_password", "password123").Return(nil)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
 
-	user, err := svc.Login(input)
+	user, err := svc.Login(context.Background(), input)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser, user)
@@ -420,8 +759,105 @@ func TestLogin_Success(t *testing.T) {
 	mockHasher.AssertExpectations(t)
 }
 
+func TestLogin_UnverifiedEmail(t *testing.T) {
+	tests := []struct {
+		name                 string
+		requireVerifiedEmail bool
+		wantErr              error
+	}{
+		{name: "verification required", requireVerifiedEmail: true, wantErr: domain.EmailNotVerifiedError{}},
+		{name: "verification optional", requireVerifiedEmail: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mockRepo, mockHasher := setupTestService()
+			svc.requireVerifiedEmail = tt.requireVerifiedEmail
+
+			unverifiedUser := &entity.User{
+				Id:       1,
+				Email:    "test@example.com",
+				Password: "hashed_password",
+				IsActive: true,
+			}
+
+			mockRepo.On("GetUserByEmail", "test@example.com").Return(unverifiedUser, nil)
+			mockHasher.On("Compare", "hashed_password", "password123").Return(nil)
+			mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+
+			user, err := svc.Login(context.Background(), &LoginInput{Email: "test@example.com", Password: "password123"})
+
+			if tt.wantErr != nil {
+				assert.Nil(t, user)
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, unverifiedUser, user)
+			}
+		})
+	}
+}
+
+func TestLogin_RehashesOutdatedHash(t *testing.T) {
+	svc, mockRepo, mockHasher := setupTestService()
+
+	input := &LoginInput{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	existingUser := &entity.User{
+		Id:       1,
+		Email:    "test@example.com",
+		Password: "legacy_hash",
+		IsActive: true,
+	}
+
+	mockRepo.On("GetUserByEmail", input.Email).Return(existingUser, nil)
+	mockHasher.On("Compare", "legacy_hash", "password123").Return(nil)
+	mockHasher.On("NeedsRehash", "legacy_hash").Return(true)
+	mockHasher.On("Hash", "password123").Return("new_hash", nil)
+	mockRepo.On("UpdateUserPassword", 1, "new_hash").Return(nil)
+
+	user, err := svc.Login(context.Background(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new_hash", user.Password)
+	mockRepo.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestLogin_RehashFailureDoesNotFailLogin(t *testing.T) {
+	svc, mockRepo, mockHasher := setupTestService()
+
+	input := &LoginInput{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	existingUser := &entity.User{
+		Id:       1,
+		Email:    "test@example.com",
+		Password: "legacy_hash",
+		IsActive: true,
+	}
+
+	mockRepo.On("GetUserByEmail", input.Email).Return(existingUser, nil)
+	mockHasher.On("Compare", "legacy_hash", "password123").Return(nil)
+	mockHasher.On("NeedsRehash", "legacy_hash").Return(true)
+	mockHasher.On("Hash", "password123").Return("new_hash", nil)
+	mockRepo.On("UpdateUserPassword", 1, "new_hash").Return(errors.New("database error"))
+
+	user, err := svc.Login(context.Background(), input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy_hash", user.Password)
+	mockRepo.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
 func TestLogin_UserNotFound(t *testing.T) {
-	svc, mockRepo, _ := setupTestService()
+	svc, mockRepo, mockHasher := setupTestService()
 
 	input := &LoginInput{
 		Email:    "nonexistent@example.com",
@@ -429,17 +865,34 @@ func TestLogin_UserNotFound(t *testing.T) {
 	}
 
 	mockRepo.On("GetUserByEmail", input.Email).Return(nil, repository.ErrUserNotFound)
+	expectDummyCompare(mockHasher, input.Password)
 
-	user, err := svc.Login(input)
+	user, err := svc.Login(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	assert.IsType(t, domain.InvalidCredentialsError{}, err)
 	mockRepo.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestLogin_HashesDummyPasswordOnce(t *testing.T) {
+	svc, mockRepo, mockHasher := setupTestService()
+
+	mockRepo.On("GetUserByEmail", "nonexistent@example.com").Return(nil, repository.ErrUserNotFound)
+	expectDummyCompare(mockHasher, "password123")
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Login(context.Background(), &LoginInput{Email: "nonexistent@example.com", Password: "password123"})
+		assert.IsType(t, domain.InvalidCredentialsError{}, err)
+	}
+
+	mockHasher.AssertNumberOfCalls(t, "Hash", 1)
+	mockHasher.AssertNumberOfCalls(t, "Compare", 3)
 }
 
 func TestLogin_InactiveUser(t *testing.T) {
-	svc, mockRepo, _ := setupTestService()
+	svc, mockRepo, mockHasher := setupTestService()
 
 	input := &LoginInput{
 		Email:    "inactive@example.com",
@@ -453,13 +906,15 @@ func TestLogin_InactiveUser(t *testing.T) {
 	}
 
 	mockRepo.On("GetUserByEmail", input.Email).Return(inactiveUser, nil)
+	expectDummyCompare(mockHasher, input.Password)
 
-	user, err := svc.Login(input)
+	user, err := svc.Login(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	assert.IsType(t, domain.InvalidCredentialsError{}, err)
 	mockRepo.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
 }
 
 func TestLogin_WrongPassword(t *testing.T) {
@@ -480,7 +935,7 @@ func TestLogin_WrongPassword(t *testing.T) {
 	mockRepo.On("GetUserByEmail", input.Email).Return(existingUser, nil)
 	mockHasher.On("Compare", "hashed_password", "wrong_password").Return(errors.New("password mismatch"))
 
-	user, err := svc.Login(input)
+	user, err := svc.Login(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
@@ -489,6 +944,191 @@ func TestLogin_WrongPassword(t *testing.T) {
 	mockHasher.AssertExpectations(t)
 }
 
+// expectDummyCompare expects a login rejected before checking the password to compare it
+// with the dummy hash instead, to take as long as a wrong password.
+func expectDummyCompare(mockHasher *MockPasswordHasher, password string) {
+	mockHasher.On("Hash", dummyPassword).Return("hashed_dummy_password", nil)
+	mockHasher.On("Compare", "hashed_dummy_password", password).Return(errors.New("mismatch"))
+}
+
+// ========== Login Lockout Tests ==========
+
+var testNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setupLockoutTestService() (*Service, *MockUserRepository, *MockPasswordHasher) {
+	svc, mockRepo, mockHasher := setupTestService()
+	svc.maxFailedLoginAttempts = 5
+	svc.lockoutDuration = time.Minute
+	svc.maxLockoutDuration = time.Hour
+	svc.now = func() time.Time { return testNow }
+	return svc, mockRepo, mockHasher
+}
+
+func TestLogin_WrongPasswordCountsFailure(t *testing.T) {
+	svc, mockRepo, mockHasher := setupLockoutTestService()
+
+	existingUser := &entity.User{Id: 1, Email: "test@example.com", Password: "hashed_password", IsActive: true}
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(existingUser, nil)
+	mockHasher.On("Compare", "hashed_password", "wrong_password").Return(errors.New("password mismatch"))
+	mockRepo.On("IncrementFailedLoginAttempts", 1).Return(4, nil)
+
+	user, err := svc.Login(context.Background(), &LoginInput{Email: "test@example.com", Password: "wrong_password"})
+
+	assert.Nil(t, user)
+	assert.Equal(t, domain.InvalidCredentialsError{}, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "LockUser", mock.Anything, mock.Anything)
+}
+
+func TestLogin_LocksAfterMaxFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  int
+		wantUntil time.Time
+	}{
+		{name: "first lock", attempts: 5, wantUntil: testNow.Add(time.Minute)},
+		{name: "doubles per further failure", attempts: 7, wantUntil: testNow.Add(4 * time.Minute)},
+		{name: "capped at max duration", attempts: 20, wantUntil: testNow.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mockRepo, mockHasher := setupLockoutTestService()
+
+			existingUser := &entity.User{Id: 1, Email: "test@example.com", Password: "hashed_password", IsActive: true}
+			mockRepo.On("GetUserByEmail", "test@example.com").Return(existingUser, nil)
+			mockHasher.On("Compare", "hashed_password", "wrong_password").Return(errors.New("password mismatch"))
+			mockRepo.On("IncrementFailedLoginAttempts", 1).Return(tt.attempts, nil)
+			mockRepo.On("LockUser", 1, tt.wantUntil).Return(nil)
+
+			_, err := svc.Login(context.Background(), &LoginInput{Email: "test@example.com", Password: "wrong_password"})
+
+			assert.Equal(t, domain.InvalidCredentialsError{}, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogin_LockedUser(t *testing.T) {
+	svc, mockRepo, mockHasher := setupLockoutTestService()
+
+	lockedUntil := testNow.Add(time.Minute)
+	lockedUser := &entity.User{Id: 1, Email: "test@example.com", Password: "hashed_password", IsActive: true, FailedLoginAttempts: 5, LockedUntil: &lockedUntil}
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(lockedUser, nil)
+	expectDummyCompare(mockHasher, "password123")
+
+	user, err := svc.Login(context.Background(), &LoginInput{Email: "test@example.com", Password: "password123"})
+
+	assert.Nil(t, user)
+	assert.Equal(t, domain.InvalidCredentialsError{}, err, "a lock must look like a wrong password")
+	mockHasher.AssertNotCalled(t, "Compare", "hashed_password", mock.Anything)
+	mockRepo.AssertNotCalled(t, "IncrementFailedLoginAttempts", mock.Anything)
+}
+
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	svc, mockRepo, mockHasher := setupLockoutTestService()
+
+	expiredLock := testNow.Add(-time.Second)
+	existingUser := &entity.User{Id: 1, Email: "test@example.com", Password: "hashed_password", IsActive: true, FailedLoginAttempts: 5, LockedUntil: &expiredLock}
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(existingUser, nil)
+	mockHasher.On("Compare", "hashed_password", "password123").Return(nil)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+	mockRepo.On("ResetFailedLoginAttempts", 1).Return(nil)
+
+	user, err := svc.Login(context.Background(), &LoginInput{Email: "test@example.com", Password: "password123"})
+
+	assert.NoError(t, err)
+	assert.Zero(t, user.FailedLoginAttempts)
+	assert.Nil(t, user.LockedUntil)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_LockoutDisabled(t *testing.T) {
+	svc, mockRepo, mockHasher := setupTestService()
+
+	existingUser := &entity.User{Id: 1, Email: "test@example.com", Password: "hashed_password", IsActive: true}
+	mockRepo.On("GetUserByEmail", "test@example.com").Return(existingUser, nil)
+	mockHasher.On("Compare", "hashed_password", "wrong_password").Return(errors.New("password mismatch"))
+
+	_, err := svc.Login(context.Background(), &LoginInput{Email: "test@example.com", Password: "wrong_password"})
+
+	assert.Equal(t, domain.InvalidCredentialsError{}, err)
+	mockRepo.AssertNotCalled(t, "IncrementFailedLoginAttempts", mock.Anything)
+}
+
+// ========== UnlockUser Tests ==========
+
+func TestUnlockUser_Success(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+	mockRepo.On("ResetFailedLoginAttempts", 1).Return(nil)
+
+	err := svc.UnlockUser(context.Background(), 1, nil)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUnlockUser_NotFound(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+	mockRepo.On("ResetFailedLoginAttempts", 999).Return(repository.ErrUserNotFound)
+
+	err := svc.UnlockUser(context.Background(), 999, nil)
+
+	assert.Equal(t, domain.UserNotFoundError{Id: 999}, err)
+}
+
+func TestUnlockUser_TargetBeyondActorPermissions(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: entity.RoleAdmin}, nil)
+
+	err := svc.UnlockUser(context.Background(), 1, &Actor{Permissions: []string{entity.PermissionUsersWrite}})
+
+	assert.IsType(t, domain.ForbiddenError{}, err)
+	mockRepo.AssertNotCalled(t, "ResetFailedLoginAttempts", mock.Anything)
+}
+
+// ========== AuthorizeUserChange Tests ==========
+
+func TestAuthorizeUserChange(t *testing.T) {
+	usersWrite := []string{entity.PermissionUsersWrite}
+	tests := []struct {
+		name    string
+		role    string
+		actor   *Actor
+		wantErr error
+	}{
+		{"system", entity.RoleAdmin, nil, nil},
+		{"role without permissions", entity.RoleUser, &Actor{Permissions: usersWrite}, nil},
+		{"admin", entity.RoleAdmin, &Actor{Permissions: usersWrite}, domain.ForbiddenError{Reason: "the user has permissions you do not have"}},
+		{"role with more permissions", "user-manager", &Actor{Permissions: usersWrite}, domain.ForbiddenError{Reason: "the user has permissions you do not have"}},
+		{"role manager", entity.RoleAdmin, &Actor{Permissions: []string{entity.PermissionUsersWrite, entity.PermissionRolesWrite}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mockRepo, _ := setupTestService()
+
+			mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1, Role: tt.role}, nil)
+
+			// Handlers check this before resetting the second factor of the user
+			err := svc.AuthorizeUserChange(context.Background(), 1, tt.actor)
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestAuthorizeUserChange_NotFound(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+
+	mockRepo.On("GetUserById", 999).Return(nil, repository.ErrUserNotFound)
+
+	err := svc.AuthorizeUserChange(context.Background(), 999, &Actor{Permissions: []string{entity.PermissionUsersWrite}})
+
+	assert.Equal(t, domain.UserNotFoundError{Id: 999}, err)
+}
+
 // ========== ChangePassword Tests ==========
 
 func TestChangePassword_Success(t *testing.T) {
@@ -509,8 +1149,10 @@ func TestChangePassword_Success(t *testing.T) {
 	mockHasher.On("Compare", "hashed_old_password", "old_password").Return(nil)
 	mockHasher.On("Hash", "new_password").Return("hashed_new_password", nil)
 	mockRepo.On("UpdateUserPassword", 1, "hashed_new_password").Return(nil)
+	mockRepo.On("IncrementUserTokenVersion", 1).Return(nil)
+	mockRepo.On("RevokeUserRefreshTokens", 1).Return(nil)
 
-	err := svc.ChangePassword(input)
+	err := svc.ChangePassword(context.Background(), input)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -534,7 +1176,7 @@ func TestChangePassword_WrongCurrentPassword(t *testing.T) {
 	mockRepo.On("GetUserById", 1).Return(existingUser, nil)
 	mockHasher.On("Compare", "hashed_old_password", "wrong_password").Return(errors.New("mismatch"))
 
-	err := svc.ChangePassword(input)
+	err := svc.ChangePassword(context.Background(), input)
 
 	assert.Error(t, err)
 	assert.IsType(t, domain.InvalidCredentialsError{}, err)
@@ -542,21 +1184,89 @@ func TestChangePassword_WrongCurrentPassword(t *testing.T) {
 	mockHasher.AssertExpectations(t)
 }
 
+// ========== Tracing Tests ==========
+
+// spanAttribute returns the value of the span attribute with the given key, or nil.
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) any {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+func TestService_RecordsSpans(t *testing.T) {
+	svc, mockRepo, _ := setupTestService()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, root := provider.Tracer("test").Start(context.Background(), "request")
+
+	mockRepo.On("GetUserById", 1).Return(&entity.User{Id: 1}, nil)
+	mockRepo.On("GetUserById", 999).Return(nil, repository.ErrUserNotFound)
+	mockRepo.On("DeleteUserById", 2).Return(errors.New("connection refused"))
+
+	svc.GetUserById(ctx, 1)
+	svc.GetUserById(ctx, 999)
+	svc.DeleteUser(ctx, 2, nil)
+	root.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 4)
+
+	found := spans[0]
+	assert.Equal(t, "user.Service.GetUserById", found.Name())
+	assert.Equal(t, root.SpanContext().SpanID(), found.Parent().SpanID())
+	assert.Equal(t, codes.Unset, found.Status().Code)
+	assert.Equal(t, int64(1), spanAttribute(found, "user.id"))
+
+	// Client errors are classified, but do not fail the span
+	notFound := spans[1]
+	assert.Equal(t, int64(404), spanAttribute(notFound, "error.http_status"))
+	assert.Equal(t, codes.Unset, notFound.Status().Code)
+
+	failed := spans[2]
+	assert.Equal(t, "user.Service.DeleteUser", failed.Name())
+	assert.Equal(t, int64(500), spanAttribute(failed, "error.http_status"))
+	assert.Equal(t, codes.Error, failed.Status().Code)
+}
+
 // ========== NewService Tests ==========
 
 func TestNewService_NilRepository(t *testing.T) {
 	mockHasher := new(MockPasswordHasher)
 
-	svc, err := NewService(nil, mockHasher)
+	svc, err := NewService(nil, &fakeRoleRepository{}, new(MockUserRepository), &fakeTxManager{}, mockHasher, Config{})
 
 	assert.Error(t, err)
 	assert.Nil(t, svc)
 }
 
+func TestNewService_NilRoleRepository(t *testing.T) {
+	svc, err := NewService(new(MockUserRepository), nil, new(MockUserRepository), &fakeTxManager{}, new(MockPasswordHasher), Config{})
+
+	assert.ErrorIs(t, err, errNilRoleRepository)
+	assert.Nil(t, svc)
+}
+
+func TestNewService_NilTokenRepository(t *testing.T) {
+	svc, err := NewService(new(MockUserRepository), &fakeRoleRepository{}, nil, &fakeTxManager{}, new(MockPasswordHasher), Config{})
+
+	assert.ErrorIs(t, err, errNilTokenRepository)
+	assert.Nil(t, svc)
+}
+
 func TestNewService_NilPasswordHasher(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 
-	svc, err := NewService(mockRepo, nil)
+	svc, err := NewService(mockRepo, &fakeRoleRepository{}, mockRepo, &fakeTxManager{}, nil, Config{})
+
+	assert.Error(t, err)
+	assert.Nil(t, svc)
+}
+
+func TestNewService_NilTxManager(t *testing.T) {
+	svc, err := NewService(new(MockUserRepository), &fakeRoleRepository{}, new(MockUserRepository), nil, new(MockPasswordHasher), Config{})
 
 	assert.Error(t, err)
 	assert.Nil(t, svc)
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so that keys are easy to tell apart from JWTs
+// and easy to find in leaked source code or logs.
+const APIKeyPrefix = "gbt_"
+
+const (
+	apiKeyIdBytes     = 6  // public part, stored in plain text to look the key up
+	apiKeySecretBytes = 32 // secret part (256 bits)
+)
+
+// GenerateAPIKey generates a new API key of the form gbt_<id>_<secret> and returns it
+// together with its public prefix gbt_<id>, which identifies the key without revealing it.
+func GenerateAPIKey() (key, prefix string, err error) {
+	b := make([]byte, apiKeyIdBytes+apiKeySecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(b[:apiKeyIdBytes])
+	secret := base64.RawURLEncoding.EncodeToString(b[apiKeyIdBytes:])
+	return prefix + "_" + secret, prefix, nil
+}
+
+// IsAPIKey reports whether a bearer token looks like an API key rather than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// ParseAPIKey returns the public prefix of an API key, or false if the key is malformed.
+// It does not check the secret; compare HashOpaqueToken(key) with the stored hash for that.
+func ParseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(id) != hex.EncodedLen(apiKeyIdBytes) || len(secret) != base64.RawURLEncoding.EncodedLen(apiKeySecretBytes) {
+		return "", false
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+	return APIKeyPrefix + id, true
+}
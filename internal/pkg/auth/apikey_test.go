@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(prefix, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.True(t, IsAPIKey(key))
+
+	parsed, ok := ParseAPIKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsed)
+
+	other, otherPrefix, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, prefix, otherPrefix)
+}
+
+func TestParseAPIKey_Malformed(t *testing.T) {
+	key, _, err := GenerateAPIKey()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"jwt", "eyJhbGciOiJIUzI1NiJ9.e30.sig"},
+		{"prefix only", APIKeyPrefix},
+		{"no secret", key[:len(APIKeyPrefix)+12]},
+		{"short secret", key[:len(key)-1]},
+		{"id not hex", APIKeyPrefix + "zzzzzzzzzzzz" + key[len(APIKeyPrefix)+12:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := ParseAPIKey(tt.key)
+			assert.False(t, ok)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,8 +17,11 @@ var (
 )
 
 // JWTConfig holds JWT configuration.
+// When Keys is set tokens are signed with the single active key and verified by "kid";
+// otherwise SecretKey is used with HS256.
 type JWTConfig struct {
 	SecretKey     string
+	Keys          []*SigningKey
 	TokenDuration time.Duration
 	Issuer        string
 }
@@ -23,23 +29,46 @@ type JWTConfig struct {
 // JWTService handles JWT operations.
 type JWTService struct {
 	secretKey     []byte
+	keys          map[string]*SigningKey
+	activeKey     *SigningKey
 	tokenDuration time.Duration
 	issuer        string
 }
 
 // CustomClaims represents JWT claims.
+// The token ID is carried in the registered "jti" claim.
 type CustomClaims struct {
-	UserId int    `json:"user_id"`
-	Role   string `json:"role"`
+	UserId       int    `json:"user_id"`
+	Role         string `json:"role"`
+	TokenVersion int    `json:"ver"` // must match the user's current token version
 	jwt.RegisteredClaims
 }
 
 // NewJWTService creates a new JWT service.
 func NewJWTService(config JWTConfig) (*JWTService, error) {
-	if config.SecretKey == "" {
+	if config.SecretKey == "" && len(config.Keys) == 0 {
 		return nil, errors.New("secret key is required")
 	}
 
+	keys := make(map[string]*SigningKey, len(config.Keys))
+	var activeKey *SigningKey
+	for _, key := range config.Keys {
+		if _, exists := keys[key.Id]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateSigningKey, key.Id)
+		}
+		keys[key.Id] = key
+
+		if key.Status == KeyStatusActive {
+			if activeKey != nil {
+				return nil, ErrNoActiveSigningKey
+			}
+			activeKey = key
+		}
+	}
+	if len(keys) > 0 && activeKey == nil {
+		return nil, ErrNoActiveSigningKey
+	}
+
 	tokenDuration := config.TokenDuration
 	if tokenDuration == 0 {
 		tokenDuration = 24 * time.Hour // default 24 hours
@@ -52,37 +81,48 @@ func NewJWTService(config JWTConfig) (*JWTService, error) {
 
 	return &JWTService{
 		secretKey:     []byte(config.SecretKey),
+		keys:          keys,
+		activeKey:     activeKey,
 		tokenDuration: tokenDuration,
 		issuer:        issuer,
 	}, nil
 }
 
 // GenerateToken generates a new JWT token for the given user.
-func (s *JWTService) GenerateToken(userId int, role string) (string, error) {
+// tokenVersion is the user's current token version; bumping it revokes all earlier tokens.
+func (s *JWTService) GenerateToken(userId int, role string, tokenVersion int) (string, error) {
 	now := time.Now()
 
+	tokenId, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
 	claims := CustomClaims{
-		UserId: userId,
-		Role:   role,
+		UserId:       userId,
+		Role:         role,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
 			Issuer:    s.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenDuration)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	if s.activeKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(s.secretKey)
+	}
+
+	token := jwt.NewWithClaims(s.activeKey.method, claims)
+	token.Header["kid"] = s.activeKey.Id
+	return token.SignedString(s.activeKey.privateKey)
 }
 
 // ValidateToken validates a JWT token and returns the claims.
 func (s *JWTService) ValidateToken(tokenString string) (*authMiddleware.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return s.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, s.verificationKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -96,9 +136,60 @@ func (s *JWTService) ValidateToken(tokenString string) (*authMiddleware.Claims,
 		return nil, ErrInvalidToken
 	}
 
+	// Tokens without an ID cannot be revoked individually
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+
 	return &authMiddleware.Claims{
-		UserId: claims.UserId,
-		Role:   claims.Role,
+		UserId:       claims.UserId,
+		Role:         claims.Role,
+		TokenId:      claims.ID,
+		TokenVersion: claims.TokenVersion,
+		ExpiresAt:    claims.ExpiresAt.Time,
 	}, nil
 }
 
+// Check signs and verifies a throwaway token, proving that the loaded keys can issue
+// and validate tokens. It is meant for readiness checks.
+func (s *JWTService) Check(ctx context.Context) error {
+	token, err := s.GenerateToken(0, "", 0)
+	if err != nil {
+		return fmt.Errorf("failed to sign token: %w", err)
+	}
+	if _, err := s.ValidateToken(token); err != nil {
+		return fmt.Errorf("failed to verify token: %w", err)
+	}
+	return nil
+}
+
+// verificationKey selects the key to verify a token with.
+// In asymmetric mode the key is looked up by "kid" and must match the token's algorithm,
+// which rules out algorithm confusion (e.g. an HS256 token "signed" with a public key).
+func (s *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	if s.activeKey == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return s.secretKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok || token.Method.Alg() != key.Algorithm() {
+		return nil, ErrInvalidToken
+	}
+	return key.publicKey, nil
+}
+
+// JWKS returns the public keys used to verify tokens.
+// The set is empty when tokens are signed with a shared secret.
+func (s *JWTService) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
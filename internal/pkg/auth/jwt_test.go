@@ -1,9 +1,11 @@
 package auth
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,7 +53,7 @@ func TestJWTService_GenerateToken(t *testing.T) {
 		TokenDuration: time.Hour,
 	})
 
-	token, err := service.GenerateToken(1, "user")
+	token, err := service.GenerateToken(1, "user", 0)
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
@@ -63,7 +65,7 @@ func TestJWTService_ValidateToken_Success(t *testing.T) {
 		TokenDuration: time.Hour,
 	})
 
-	token, _ := service.GenerateToken(123, "admin")
+	token, _ := service.GenerateToken(123, "admin", 0)
 
 	claims, err := service.ValidateToken(token)
 
@@ -98,7 +100,7 @@ func TestJWTService_ValidateToken_WrongSignature(t *testing.T) {
 	})
 
 	// Generate token with service1
-	token, _ := service1.GenerateToken(1, "user")
+	token, _ := service1.GenerateToken(1, "user", 0)
 
 	// Try to validate with service2 (different secret key)
 	claims, err := service2.ValidateToken(token)
@@ -113,7 +115,7 @@ func TestJWTService_ValidateToken_ExpiredToken(t *testing.T) {
 		TokenDuration: -time.Hour, // Already expired
 	})
 
-	token, _ := service.GenerateToken(1, "user")
+	token, _ := service.GenerateToken(1, "user", 0)
 
 	claims, err := service.ValidateToken(token)
 
@@ -157,7 +159,7 @@ func TestJWTService_TokenContainsClaims(t *testing.T) {
 	userId := 42
 	role := "viewer"
 
-	token, err := service.GenerateToken(userId, role)
+	token, err := service.GenerateToken(userId, role, 0)
 	assert.NoError(t, err)
 
 	claims, err := service.ValidateToken(token)
@@ -166,6 +168,52 @@ func TestJWTService_TokenContainsClaims(t *testing.T) {
 	assert.Equal(t, role, claims.Role)
 }
 
+func TestJWTService_TokenIdAndVersion(t *testing.T) {
+	service, _ := NewJWTService(JWTConfig{
+		SecretKey:     "test-secret-key",
+		TokenDuration: time.Hour,
+	})
+
+	token1, err := service.GenerateToken(1, "user", 3)
+	assert.NoError(t, err)
+	token2, err := service.GenerateToken(1, "user", 3)
+	assert.NoError(t, err)
+
+	claims1, err := service.ValidateToken(token1)
+	assert.NoError(t, err)
+	claims2, err := service.ValidateToken(token2)
+	assert.NoError(t, err)
+
+	// Every token gets its own ID
+	assert.NotEmpty(t, claims1.TokenId)
+	assert.NotEqual(t, claims1.TokenId, claims2.TokenId)
+	assert.Equal(t, 3, claims1.TokenVersion)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), claims1.ExpiresAt, 5*time.Second)
+}
+
+func TestJWTService_ValidateToken_MissingTokenId(t *testing.T) {
+	service, _ := NewJWTService(JWTConfig{
+		SecretKey:     "test-secret-key",
+		TokenDuration: time.Hour,
+	})
+
+	// Token signed with the right key but without a jti claim
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, CustomClaims{
+		UserId: 1,
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	tokenString, err := token.SignedString(service.secretKey)
+	assert.NoError(t, err)
+
+	claims, err := service.ValidateToken(tokenString)
+
+	assert.Nil(t, claims)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
 func BenchmarkJWTService_GenerateToken(b *testing.B) {
 	service, _ := NewJWTService(JWTConfig{
 		SecretKey:     "benchmark-secret-key",
@@ -174,7 +222,7 @@ func BenchmarkJWTService_GenerateToken(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		service.GenerateToken(i, "user")
+		service.GenerateToken(i, "user", 0)
 	}
 }
 
@@ -184,7 +232,7 @@ func BenchmarkJWTService_ValidateToken(b *testing.B) {
 		TokenDuration: time.Hour,
 	})
 
-	token, _ := service.GenerateToken(1, "user")
+	token, _ := service.GenerateToken(1, "user", 0)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -192,3 +240,11 @@ func BenchmarkJWTService_ValidateToken(b *testing.B) {
 	}
 }
 
+
+func TestJWTService_Check(t *testing.T) {
+	service, _ := NewJWTService(JWTConfig{SecretKey: "test-secret-key"})
+	assert.NoError(t, service.Check(context.Background()))
+
+	rotated, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{newECKey(t, "ec", KeyStatusActive)}})
+	assert.NoError(t, rotated.Check(context.Background()))
+}
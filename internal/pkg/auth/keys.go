@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrEmptyKeyId          = errors.New("key id is required")
+	ErrUnsupportedKeyType  = errors.New("unsupported key type")
+	ErrInvalidPEM          = errors.New("no PEM block found")
+	ErrNoActiveSigningKey  = errors.New("exactly one active signing key is required")
+	ErrDuplicateSigningKey = errors.New("duplicate signing key id")
+)
+
+// KeyStatus represents where a signing key is in its rotation lifecycle.
+type KeyStatus string
+
+const (
+	// KeyStatusActive keys sign new tokens and verify existing ones.
+	KeyStatusActive KeyStatus = "active"
+	// KeyStatusRetiring keys only verify tokens signed before the rotation.
+	KeyStatusRetiring KeyStatus = "retiring"
+)
+
+// SigningKey is an asymmetric key identified by its key ID ("kid").
+// Retiring keys may be loaded from a public key only.
+type SigningKey struct {
+	Id         string
+	Status     KeyStatus
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey // nil for verify-only keys
+	publicKey  crypto.PublicKey
+}
+
+// NewSigningKey creates a signing key from a private or public key.
+// The algorithm is derived from the key type: RSA → RS256, ECDSA → ES256/ES384/ES512 by curve,
+// Ed25519 → EdDSA.
+func NewSigningKey(id string, key any, status KeyStatus) (*SigningKey, error) {
+	if id == "" {
+		return nil, ErrEmptyKeyId
+	}
+
+	k := &SigningKey{Id: id, Status: status}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.privateKey, k.publicKey = jwt.SigningMethodRS256, key, &key.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.publicKey = jwt.SigningMethodRS256, key
+	case *ecdsa.PrivateKey:
+		method, err := ecdsaSigningMethod(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		k.method, k.privateKey, k.publicKey = method, key, &key.PublicKey
+	case *ecdsa.PublicKey:
+		method, err := ecdsaSigningMethod(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		k.method, k.publicKey = method, key
+	case ed25519.PrivateKey:
+		k.method, k.privateKey, k.publicKey = jwt.SigningMethodEdDSA, key, key.Public()
+	case ed25519.PublicKey:
+		k.method, k.publicKey = jwt.SigningMethodEdDSA, key
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, key)
+	}
+
+	if status == KeyStatusActive && k.privateKey == nil {
+		return nil, fmt.Errorf("active key %q requires a private key", id)
+	}
+
+	return k, nil
+}
+
+// LoadSigningKeyFromPEM loads a signing key from a PEM file.
+// PKCS#8, PKCS#1 (RSA) and SEC 1 (EC) private keys and PKIX public keys are supported.
+func LoadSigningKeyFromPEM(id, path string, status KeyStatus) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := ParsePEMKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	return NewSigningKey(id, key, status)
+}
+
+// ParsePEMKey parses the first PEM block in data as a private or public key.
+func ParsePEMKey(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: PEM block %q", ErrUnsupportedKeyType, block.Type)
+	}
+}
+
+// Algorithm returns the JWS algorithm name of the key (e.g. "RS256").
+func (k *SigningKey) Algorithm() string {
+	return k.method.Alg()
+}
+
+// CanSign reports whether the key holds a private key.
+func (k *SigningKey) CanSign() bool {
+	return k.privateKey != nil
+}
+
+func ecdsaSigningMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported curve", ErrUnsupportedKeyType)
+	}
+}
+
+// ========== JWKS ==========
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public part of the key as a JWK.
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{Use: "sig", Kid: k.Id, Alg: k.Algorithm()}
+
+	switch pub := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk
+}
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRSAKey(t *testing.T, id string, status KeyStatus) *SigningKey {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key, err := NewSigningKey(id, privateKey, status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return key
+}
+
+func newECKey(t *testing.T, id string, status KeyStatus) *SigningKey {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key, err := NewSigningKey(id, privateKey, status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T, id string, status KeyStatus) *SigningKey {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key, err := NewSigningKey(id, privateKey, status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	return path
+}
+
+// ========== NewSigningKey ==========
+
+func TestNewSigningKey_Algorithms(t *testing.T) {
+	assert.Equal(t, "RS256", newRSAKey(t, "rsa", KeyStatusActive).Algorithm())
+	assert.Equal(t, "ES256", newECKey(t, "ec", KeyStatusActive).Algorithm())
+	assert.Equal(t, "EdDSA", newEd25519Key(t, "ed", KeyStatusActive).Algorithm())
+}
+
+func TestNewSigningKey_EmptyId(t *testing.T) {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	key, err := NewSigningKey("", privateKey, KeyStatusActive)
+
+	assert.ErrorIs(t, err, ErrEmptyKeyId)
+	assert.Nil(t, key)
+}
+
+func TestNewSigningKey_UnsupportedKeyType(t *testing.T) {
+	key, err := NewSigningKey("kid", []byte("secret"), KeyStatusActive)
+
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+	assert.Nil(t, key)
+}
+
+func TestNewSigningKey_ActiveKeyRequiresPrivateKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	_, err := NewSigningKey("kid", publicKey, KeyStatusActive)
+	assert.Error(t, err)
+
+	key, err := NewSigningKey("kid", publicKey, KeyStatusRetiring)
+	assert.NoError(t, err)
+	assert.False(t, key.CanSign())
+}
+
+// ========== PEM loading ==========
+
+func TestLoadSigningKeyFromPEM_PKCS8(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, err := LoadSigningKeyFromPEM("kid", writePEM(t, "PRIVATE KEY", der), KeyStatusActive)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ES256", key.Algorithm())
+	assert.True(t, key.CanSign())
+}
+
+func TestLoadSigningKeyFromPEM_PKCS1(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	key, err := LoadSigningKeyFromPEM("kid", writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey)), KeyStatusActive)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "RS256", key.Algorithm())
+}
+
+func TestLoadSigningKeyFromPEM_PublicKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, err := LoadSigningKeyFromPEM("kid", writePEM(t, "PUBLIC KEY", der), KeyStatusRetiring)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "EdDSA", key.Algorithm())
+	assert.False(t, key.CanSign())
+}
+
+func TestLoadSigningKeyFromPEM_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, []byte("not a pem"), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+
+	_, err := LoadSigningKeyFromPEM("kid", path, KeyStatusActive)
+	assert.ErrorIs(t, err, ErrInvalidPEM)
+
+	_, err = LoadSigningKeyFromPEM("kid", filepath.Join(t.TempDir(), "missing.pem"), KeyStatusActive)
+	assert.Error(t, err)
+}
+
+// ========== JWTService with signing keys ==========
+
+func TestNewJWTService_RequiresOneActiveKey(t *testing.T) {
+	retiring := newEd25519Key(t, "old", KeyStatusRetiring)
+
+	_, err := NewJWTService(JWTConfig{Keys: []*SigningKey{retiring}})
+	assert.ErrorIs(t, err, ErrNoActiveSigningKey)
+
+	_, err = NewJWTService(JWTConfig{Keys: []*SigningKey{
+		newEd25519Key(t, "a", KeyStatusActive),
+		newEd25519Key(t, "b", KeyStatusActive),
+	}})
+	assert.ErrorIs(t, err, ErrNoActiveSigningKey)
+
+	_, err = NewJWTService(JWTConfig{Keys: []*SigningKey{
+		newEd25519Key(t, "a", KeyStatusActive),
+		newEd25519Key(t, "a", KeyStatusRetiring),
+	}})
+	assert.ErrorIs(t, err, ErrDuplicateSigningKey)
+}
+
+func TestJWTService_AsymmetricRoundTrip(t *testing.T) {
+	keys := map[string]*SigningKey{
+		"RS256": newRSAKey(t, "rsa", KeyStatusActive),
+		"ES256": newECKey(t, "ec", KeyStatusActive),
+		"EdDSA": newEd25519Key(t, "ed", KeyStatusActive),
+	}
+
+	for alg, key := range keys {
+		t.Run(alg, func(t *testing.T) {
+			service, err := NewJWTService(JWTConfig{Keys: []*SigningKey{key}, TokenDuration: time.Hour})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tokenString, err := service.GenerateToken(7, "admin", 2)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, &CustomClaims{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, alg, parsed.Method.Alg())
+			assert.Equal(t, key.Id, parsed.Header["kid"])
+
+			claims, err := service.ValidateToken(tokenString)
+			assert.NoError(t, err)
+			assert.Equal(t, 7, claims.UserId)
+			assert.Equal(t, "admin", claims.Role)
+		})
+	}
+}
+
+func TestJWTService_KeyRotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "old", KeyStatusActive)
+	newKey := newRSAKey(t, "new", KeyStatusActive)
+
+	before, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{oldKey}})
+	oldToken, _ := before.GenerateToken(1, "user", 0)
+
+	// Rotate: the old key keeps verifying but no longer signs
+	oldKey.Status = KeyStatusRetiring
+	after, err := NewJWTService(JWTConfig{Keys: []*SigningKey{newKey, oldKey}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = after.ValidateToken(oldToken)
+	assert.NoError(t, err)
+
+	newToken, _ := after.GenerateToken(1, "user", 0)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &CustomClaims{})
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	// Once the old key is removed its tokens stop validating
+	removed, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{newKey}})
+	_, err = removed.ValidateToken(oldToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTService_ValidateToken_UnknownKid(t *testing.T) {
+	other, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{newEd25519Key(t, "other", KeyStatusActive)}})
+	service, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{newEd25519Key(t, "kid", KeyStatusActive)}})
+
+	tokenString, _ := other.GenerateToken(1, "user", 0)
+
+	_, err := service.ValidateToken(tokenString)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTService_ValidateToken_RejectsAlgorithmConfusion(t *testing.T) {
+	key := newRSAKey(t, "kid", KeyStatusActive)
+	service, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{key}})
+
+	// An HS256 token "signed" with the public key must not be accepted
+	publicDER, _ := x509.MarshalPKIXPublicKey(key.publicKey)
+	claims := CustomClaims{
+		UserId: 1,
+		Role:   "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "forged",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "kid"
+	forged, err := token.SignedString(publicDER)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = service.ValidateToken(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// ========== JWKS ==========
+
+func TestJWTService_JWKS(t *testing.T) {
+	service, _ := NewJWTService(JWTConfig{Keys: []*SigningKey{
+		newRSAKey(t, "a-rsa", KeyStatusActive),
+		newECKey(t, "b-ec", KeyStatusRetiring),
+		newEd25519Key(t, "c-ed", KeyStatusRetiring),
+	}})
+
+	jwks := service.JWKS()
+
+	if !assert.Len(t, jwks.Keys, 3) {
+		return
+	}
+
+	assert.Equal(t, JWK{Kty: "RSA", Use: "sig", Kid: "a-rsa", Alg: "RS256", N: jwks.Keys[0].N, E: "AQAB"}, jwks.Keys[0])
+	assert.NotEmpty(t, jwks.Keys[0].N)
+
+	assert.Equal(t, "EC", jwks.Keys[1].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[1].Crv)
+	assert.Len(t, jwks.Keys[1].X, 43) // 32 bytes, base64url without padding
+	assert.Len(t, jwks.Keys[1].Y, 43)
+
+	assert.Equal(t, "OKP", jwks.Keys[2].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[2].Crv)
+	assert.Equal(t, "EdDSA", jwks.Keys[2].Alg)
+}
+
+func TestJWTService_JWKS_SecretKeyOnly(t *testing.T) {
+	service, _ := NewJWTService(JWTConfig{SecretKey: "test-secret-key"})
+
+	jwks := service.JWKS()
+
+	assert.NotNil(t, jwks.Keys)
+	assert.Empty(t, jwks.Keys)
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Login methods, as recorded by Metrics.RecordLogin.
+const (
+	LoginMethodPassword = "password"
+	LoginMethodMFA      = "mfa"
+	LoginMethodPasskey  = "passkey"
+)
+
+// Metrics counts authentication outcomes.
+type Metrics struct {
+	logins        *prometheus.CounterVec
+	tokenFailures *prometheus.CounterVec
+}
+
+// NewMetrics registers the authentication metrics in registry.
+func NewMetrics(registry prometheus.Registerer) *Metrics {
+	factory := promauto.With(registry)
+	return &Metrics{
+		logins: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_logins_total",
+			Help: "Total number of login attempts by method and result (success or failure).",
+		}, []string{"method", "result"}),
+		tokenFailures: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_token_validation_failures_total",
+			Help: "Total number of rejected access tokens by reason (expired or invalid).",
+		}, []string{"reason"}),
+	}
+}
+
+// RecordLogin counts a login attempt that failed with err, or succeeded if err is nil.
+func (m *Metrics) RecordLogin(method string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.logins.WithLabelValues(method, result).Inc()
+}
+
+// RecordTokenFailure counts an access token that JWTService.ValidateToken rejected with err.
+func (m *Metrics) RecordTokenFailure(err error) {
+	m.tokenFailures.WithLabelValues(TokenFailureReason(err)).Inc()
+}
+
+// TokenFailureReason classifies an error returned by JWTService.ValidateToken.
+func TokenFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrExpiredToken):
+		return "expired"
+	case errors.Is(err, ErrInvalidToken):
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_RecordLogin(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+
+	m.RecordLogin(LoginMethodPassword, nil)
+	m.RecordLogin(LoginMethodPassword, errors.New("invalid credentials"))
+	m.RecordLogin(LoginMethodPasskey, nil)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.logins.WithLabelValues(LoginMethodPassword, "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.logins.WithLabelValues(LoginMethodPassword, "failure")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.logins.WithLabelValues(LoginMethodPasskey, "success")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.logins.WithLabelValues(LoginMethodMFA, "success")))
+}
+
+func TestMetrics_RecordTokenFailure(t *testing.T) {
+	service, err := NewJWTService(JWTConfig{
+		SecretKey:     "test-secret-key-that-is-long-enough",
+		TokenDuration: -time.Minute,
+		Issuer:        "test",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create JWT service: %v", err)
+	}
+
+	expired, err := service.GenerateToken(1, "user", 0)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	m := NewMetrics(prometheus.NewRegistry())
+
+	_, err = service.ValidateToken(expired)
+	m.RecordTokenFailure(err)
+	_, err = service.ValidateToken("not-a-token")
+	m.RecordTokenFailure(err)
+	m.RecordTokenFailure(errors.New("other"))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.tokenFailures.WithLabelValues("expired")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.tokenFailures.WithLabelValues("invalid")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.tokenFailures.WithLabelValues("unknown")))
+}
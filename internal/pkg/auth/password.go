@@ -1,24 +1,118 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// PasswordHasher handles password hashing using bcrypt.
+// Supported password hashing algorithms.
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrPasswordMismatch     = errors.New("password does not match")
+	ErrInvalidHash          = errors.New("invalid password hash")
+	ErrUnsupportedAlgorithm = errors.New("unsupported password hashing algorithm")
+)
+
+// Argon2idParams holds argon2id cost parameters.
+type Argon2idParams struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32 // bytes
+	KeyLength   uint32 // bytes
+}
+
+// DefaultArgon2idParams follows the RFC 9106 recommendation for memory-constrained environments.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// PasswordConfig holds password hasher configuration.
+type PasswordConfig struct {
+	Algorithm  string         // algorithm for new hashes: argon2id (default) or bcrypt
+	Argon2id   Argon2idParams // zero fields use DefaultArgon2idParams
+	BcryptCost int            // default bcrypt.DefaultCost
+}
+
+// PasswordHasher hashes passwords with the configured algorithm and verifies hashes written
+// by any supported algorithm, so that the algorithm and its cost can change without a mass reset.
+//
+// Argon2id hashes use the PHC string format ($argon2id$v=19$m=..,t=..,p=..$salt$hash);
+// bcrypt hashes use their own modular crypt format ($2a$..).
 type PasswordHasher struct {
-	cost int
+	algorithm string
+	argon2id  Argon2idParams
+	cost      int // bcrypt cost
 }
 
-// NewPasswordHasher creates a new password hasher.
+// NewPasswordHasher creates a new password hasher that writes bcrypt hashes with the given cost.
 func NewPasswordHasher(cost int) *PasswordHasher {
 	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
 		cost = bcrypt.DefaultCost
 	}
-	return &PasswordHasher{cost: cost}
+	return &PasswordHasher{
+		algorithm: AlgorithmBcrypt,
+		argon2id:  DefaultArgon2idParams,
+		cost:      cost,
+	}
 }
 
-// Hash hashes a password using bcrypt.
+// NewPasswordHasherWithConfig creates a new password hasher from the given configuration.
+func NewPasswordHasherWithConfig(config PasswordConfig) (*PasswordHasher, error) {
+	algorithm := config.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmArgon2id
+	}
+	if algorithm != AlgorithmArgon2id && algorithm != AlgorithmBcrypt {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	h := NewPasswordHasher(config.BcryptCost)
+	h.algorithm = algorithm
+	h.argon2id = withDefaults(config.Argon2id)
+	return h, nil
+}
+
+func withDefaults(p Argon2idParams) Argon2idParams {
+	if p.Memory == 0 {
+		p.Memory = DefaultArgon2idParams.Memory
+	}
+	if p.Iterations == 0 {
+		p.Iterations = DefaultArgon2idParams.Iterations
+	}
+	if p.Parallelism == 0 {
+		p.Parallelism = DefaultArgon2idParams.Parallelism
+	}
+	if p.SaltLength == 0 {
+		p.SaltLength = DefaultArgon2idParams.SaltLength
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = DefaultArgon2idParams.KeyLength
+	}
+	return p
+}
+
+// Hash hashes a password with the configured algorithm.
 func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmArgon2id {
+		return hashArgon2id(password, h.argon2id)
+	}
+
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		return "", err
@@ -29,6 +123,111 @@ func (h *PasswordHasher) Hash(password string) (string, error) {
 // Compare compares a hashed password with a plain text password.
 // Returns nil if they match, error otherwise.
 func (h *PasswordHasher) Compare(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if isArgon2idHash(hashedPassword) {
+		return compareArgon2id(hashedPassword, password)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
 }
 
+// NeedsRehash reports whether a stored hash was written with a different algorithm or different
+// cost parameters than the hasher uses now. Callers should rehash the password after a successful
+// Compare, while the plain text password is at hand.
+func (h *PasswordHasher) NeedsRehash(hashedPassword string) bool {
+	if isArgon2idHash(hashedPassword) {
+		if h.algorithm != AlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return true
+		}
+		return params.Memory != h.argon2id.Memory ||
+			params.Iterations != h.argon2id.Iterations ||
+			params.Parallelism != h.argon2id.Parallelism ||
+			params.KeyLength != h.argon2id.KeyLength
+	}
+
+	if h.algorithm != AlgorithmBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != h.cost
+}
+
+// ========== Argon2id ==========
+
+const argon2idPrefix = "$" + AlgorithmArgon2id + "$"
+
+func isArgon2idHash(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, argon2idPrefix)
+}
+
+func hashArgon2id(password string, p Argon2idParams) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id,
+		argon2.Version,
+		p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func compareArgon2id(hashedPassword, password string) error {
+	params, salt, key, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// decodeArgon2id parses a PHC string: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>.
+func decodeArgon2id(hashedPassword string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -160,6 +161,123 @@ func TestPasswordHasher_Hash_SpecialCharacters(t *testing.T) {
 	}
 }
 
+// ========== Argon2id ==========
+
+// testArgon2idParams keeps argon2id fast in tests.
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func newTestArgon2idHasher(t *testing.T) *PasswordHasher {
+	t.Helper()
+
+	hasher, err := NewPasswordHasherWithConfig(PasswordConfig{Argon2id: testArgon2idParams})
+	if err != nil {
+		t.Fatalf("Failed to create hasher: %v", err)
+	}
+	return hasher
+}
+
+func TestNewPasswordHasherWithConfig_Defaults(t *testing.T) {
+	hasher, err := NewPasswordHasherWithConfig(PasswordConfig{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmArgon2id, hasher.algorithm)
+	assert.Equal(t, DefaultArgon2idParams, hasher.argon2id)
+	assert.Equal(t, bcrypt.DefaultCost, hasher.cost)
+}
+
+func TestNewPasswordHasherWithConfig_UnsupportedAlgorithm(t *testing.T) {
+	hasher, err := NewPasswordHasherWithConfig(PasswordConfig{Algorithm: "md5"})
+
+	assert.Nil(t, hasher)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestPasswordHasher_Argon2id_HashAndCompare(t *testing.T) {
+	hasher := newTestArgon2idHasher(t)
+
+	hash, err := hasher.Hash("myPassword123")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`, hash)
+
+	assert.NoError(t, hasher.Compare(hash, "myPassword123"))
+	assert.ErrorIs(t, hasher.Compare(hash, "wrongPassword"), ErrPasswordMismatch)
+
+	// Salted: the same password hashes differently
+	other, _ := hasher.Hash("myPassword123")
+	assert.NotEqual(t, hash, other)
+}
+
+func TestPasswordHasher_Argon2id_LongPassword(t *testing.T) {
+	hasher := newTestArgon2idHasher(t)
+	longPassword := strings.Repeat("a", 200)
+
+	// Unlike bcrypt, argon2id has no 72 byte limit
+	hash, err := hasher.Hash(longPassword)
+	assert.NoError(t, err)
+	assert.NoError(t, hasher.Compare(hash, longPassword))
+	assert.Error(t, hasher.Compare(hash, longPassword[:72]))
+}
+
+func TestPasswordHasher_Argon2id_InvalidHash(t *testing.T) {
+	hasher := newTestArgon2idHasher(t)
+
+	hashes := []string{
+		"$argon2id$",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1$c2FsdHNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$",
+	}
+
+	for _, hash := range hashes {
+		assert.ErrorIs(t, hasher.Compare(hash, "password"), ErrInvalidHash, hash)
+	}
+}
+
+func TestPasswordHasher_VerifiesLegacyBcrypt(t *testing.T) {
+	legacy, err := NewPasswordHasher(bcrypt.MinCost).Hash("myPassword123")
+	assert.NoError(t, err)
+
+	hasher := newTestArgon2idHasher(t)
+
+	assert.NoError(t, hasher.Compare(legacy, "myPassword123"))
+	assert.ErrorIs(t, hasher.Compare(legacy, "wrongPassword"), ErrPasswordMismatch)
+}
+
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	argon2idHasher := newTestArgon2idHasher(t)
+	bcryptHasher := NewPasswordHasher(bcrypt.MinCost)
+
+	argon2idHash, _ := argon2idHasher.Hash("password")
+	bcryptHash, _ := bcryptHasher.Hash("password")
+
+	stronger, _ := NewPasswordHasherWithConfig(PasswordConfig{
+		Argon2id: Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1},
+	})
+
+	tests := []struct {
+		name     string
+		hasher   *PasswordHasher
+		hash     string
+		expected bool
+	}{
+		{name: "current argon2id", hasher: argon2idHasher, hash: argon2idHash, expected: false},
+		{name: "legacy bcrypt", hasher: argon2idHasher, hash: bcryptHash, expected: true},
+		{name: "outdated argon2id params", hasher: stronger, hash: argon2idHash, expected: true},
+		{name: "current bcrypt", hasher: bcryptHasher, hash: bcryptHash, expected: false},
+		{name: "outdated bcrypt cost", hasher: NewPasswordHasher(bcrypt.MinCost + 1), hash: bcryptHash, expected: true},
+		{name: "argon2id when bcrypt is configured", hasher: bcryptHasher, hash: argon2idHash, expected: true},
+		{name: "unparseable hash", hasher: argon2idHasher, hash: "not-a-valid-hash", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.hasher.NeedsRehash(tt.hash))
+		})
+	}
+}
+
 // Benchmark tests
 func BenchmarkPasswordHasher_Hash_MinCost(b *testing.B) {
 	hasher := NewPasswordHasher(bcrypt.MinCost)
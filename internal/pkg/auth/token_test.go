@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateOpaqueToken(t *testing.T) {
+	token1, err := GenerateOpaqueToken()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token1)
+
+	token2, err := GenerateOpaqueToken()
+	assert.NoError(t, err)
+
+	// Tokens must be random
+	assert.NotEqual(t, token1, token2)
+}
+
+func TestHashOpaqueToken(t *testing.T) {
+	token := "some-opaque-token"
+
+	hash1 := HashOpaqueToken(token)
+	hash2 := HashOpaqueToken(token)
+
+	assert.Len(t, hash1, 64) // hex-encoded SHA-256
+	assert.Equal(t, hash1, hash2)
+	assert.NotEqual(t, token, hash1)
+	assert.NotEqual(t, hash1, HashOpaqueToken("another-token"))
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors ("12345678901234567890").
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, code, "time %d", tt.unix)
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	step := TOTPStep(now)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, err := TOTPCode(secret, step+offset)
+		assert.NoError(t, err)
+
+		matched, ok := VerifyTOTP(secret, code, now)
+		assert.True(t, ok, "offset %d", offset)
+		assert.Equal(t, step+offset, matched)
+	}
+
+	tooOld, _ := TOTPCode(secret, step-2)
+	_, ok := VerifyTOTP(secret, tooOld, now)
+	assert.False(t, ok, "codes outside the skew window must be rejected")
+
+	_, ok = VerifyTOTP(secret, "12345", now)
+	assert.False(t, ok)
+
+	_, ok = VerifyTOTP("not base32!", "123456", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Example", "user@example.com", "SECRET")
+
+	u, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Example:user@example.com", u.Path)
+	assert.Equal(t, "SECRET", u.Query().Get("secret"))
+	assert.Equal(t, "Example", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code1, err := GenerateRecoveryCode()
+	assert.NoError(t, err)
+	code2, err := GenerateRecoveryCode()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, code1, code2)
+	assert.Len(t, code1, 17) // 16 base32 characters and a dash
+	assert.Equal(t, HashRecoveryCode(code1), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code1, "-", " "))),
+		"case and separators must not matter")
+	assert.NotEqual(t, HashRecoveryCode(code1), HashRecoveryCode(code2))
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of writes between sweeps of expired entries.
+const sweepInterval = 1024
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache is a thread-safe in-memory cache whose entries expire after a fixed duration.
+type TTLCache[K comparable, V any] struct {
+	mu     sync.Mutex
+	items  map[K]entry[V]
+	ttl    time.Duration
+	writes int
+	now    func() time.Time
+}
+
+// NewTTLCache creates a new cache whose entries live for the given duration.
+func NewTTLCache[K comparable, V any](ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		items: make(map[K]entry[V]),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Get returns the cached value for the key, if present and not expired.
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !c.now().Before(e.expiresAt) {
+		delete(c.items, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores a value for the key, replacing any previous value.
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+
+	// Periodically drop expired entries so that the map does not grow unbounded
+	c.writes++
+	if c.writes >= sweepInterval {
+		c.writes = 0
+		for k, e := range c.items {
+			if !now.Before(e.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+	}
+}
+
+// Delete removes the key from the cache.
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+// Len returns the number of entries in the cache, including expired entries not yet swept.
+func (c *TTLCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
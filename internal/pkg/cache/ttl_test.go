@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTLCache_SetAndGet(t *testing.T) {
+	c := NewTTLCache[string, int](time.Minute)
+
+	c.Set("a", 1)
+
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	_, ok = c.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestTTLCache_Expiry(t *testing.T) {
+	now := time.Now()
+	c := NewTTLCache[string, int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestTTLCache_Delete(t *testing.T) {
+	c := NewTTLCache[int, string](time.Minute)
+
+	c.Set(1, "one")
+	c.Delete(1)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+}
+
+func TestTTLCache_SweepsExpiredEntries(t *testing.T) {
+	now := time.Now()
+	c := NewTTLCache[int, int](time.Minute)
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < sweepInterval-1; i++ {
+		c.Set(i, i)
+	}
+
+	now = now.Add(time.Hour)
+	c.Set(-1, -1) // triggers a sweep
+
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestTTLCache_Concurrent(t *testing.T) {
+	c := NewTTLCache[int, int](time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Set(i, i)
+			c.Get(i)
+			c.Delete(i)
+		}(i)
+	}
+	wg.Wait()
+}
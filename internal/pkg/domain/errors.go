@@ -6,12 +6,55 @@ import (
 )
 
 // DomainError is an interface that domain errors should implement.
-// It provides HTTP status code mapping for error handling in handlers.
+// It provides HTTP status code mapping for error handling in handlers,
+// and a stable machine-readable code that clients can match on.
 type DomainError interface {
 	error
 	HTTPStatus() int
+	Code() string
 }
 
+// Error codes returned by DomainError.Code. They are part of the API:
+// never change a published code, add a new one instead.
+const (
+	// Common
+	CodeInternal     = "internal_error"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeValidation   = "validation_failed"
+
+	// User
+	CodeUserNotFound       = "user_not_found"
+	CodeUserAlreadyExists  = "user_already_exists"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeInvalidRole        = "invalid_role"
+
+	// Account
+	CodeInvalidToken     = "invalid_token"
+	CodeEmailNotVerified = "email_not_verified"
+
+	// MFA
+	CodeInvalidMFACode    = "invalid_mfa_code"
+	CodeMFAAlreadyEnabled = "mfa_already_enabled"
+	CodeMFANotEnabled     = "mfa_not_enabled"
+
+	// Passkey
+	CodeInvalidPasskey           = "invalid_passkey"
+	CodePasskeyNotFound          = "passkey_not_found"
+	CodePasskeyAlreadyRegistered = "passkey_already_registered"
+
+	// API key
+	CodeInvalidAPIKey       = "invalid_api_key"
+	CodeAPIKeyNotFound      = "api_key_not_found"
+	CodeAPIKeyLimitExceeded = "api_key_limit_exceeded"
+
+	// Role
+	CodeRoleNotFound      = "role_not_found"
+	CodeRoleAlreadyExists = "role_already_exists"
+	CodeRoleInUse         = "role_in_use"
+	CodeProtectedRole     = "protected_role"
+)
+
 // ========== Common Errors ==========
 
 // InternalServerError represents an unexpected server error.
@@ -31,6 +74,14 @@ func (e InternalServerError) HTTPStatus() int {
 	return http.StatusInternalServerError
 }
 
+func (e InternalServerError) Code() string {
+	return CodeInternal
+}
+
+func (e InternalServerError) Unwrap() error {
+	return e.Err
+}
+
 // UnauthorizedError represents an authentication failure.
 type UnauthorizedError struct {
 	Reason string
@@ -44,6 +95,10 @@ func (e UnauthorizedError) HTTPStatus() int {
 	return http.StatusUnauthorized
 }
 
+func (e UnauthorizedError) Code() string {
+	return CodeUnauthorized
+}
+
 // ForbiddenError represents an authorization failure.
 type ForbiddenError struct {
 	Reason string
@@ -57,6 +112,10 @@ func (e ForbiddenError) HTTPStatus() int {
 	return http.StatusForbidden
 }
 
+func (e ForbiddenError) Code() string {
+	return CodeForbidden
+}
+
 // ValidationError represents a validation failure.
 type ValidationError struct {
 	Field   string
@@ -74,6 +133,10 @@ func (e ValidationError) HTTPStatus() int {
 	return http.StatusBadRequest
 }
 
+func (e ValidationError) Code() string {
+	return CodeValidation
+}
+
 // ========== User Domain Errors ==========
 
 // UserNotFoundError represents a user not found error.
@@ -96,6 +159,10 @@ func (e UserNotFoundError) HTTPStatus() int {
 	return http.StatusNotFound
 }
 
+func (e UserNotFoundError) Code() string {
+	return CodeUserNotFound
+}
+
 // UserAlreadyExistsError represents a duplicate user error.
 type UserAlreadyExistsError struct {
 	Email string
@@ -109,6 +176,10 @@ func (e UserAlreadyExistsError) HTTPStatus() int {
 	return http.StatusConflict
 }
 
+func (e UserAlreadyExistsError) Code() string {
+	return CodeUserAlreadyExists
+}
+
 // InvalidCredentialsError represents an invalid login attempt.
 type InvalidCredentialsError struct{}
 
@@ -120,6 +191,10 @@ func (e InvalidCredentialsError) HTTPStatus() int {
 	return http.StatusUnauthorized
 }
 
+func (e InvalidCredentialsError) Code() string {
+	return CodeInvalidCredentials
+}
+
 // InvalidRoleError represents an invalid role error.
 type InvalidRoleError struct {
 	Role string
@@ -133,3 +208,264 @@ func (e InvalidRoleError) HTTPStatus() int {
 	return http.StatusBadRequest
 }
 
+func (e InvalidRoleError) Code() string {
+	return CodeInvalidRole
+}
+
+// ========== Account Domain Errors ==========
+
+// InvalidTokenError represents a single-use token (e.g. a password reset token)
+// that is unknown, expired or already used. The cause is deliberately not revealed.
+type InvalidTokenError struct{}
+
+func (e InvalidTokenError) Error() string {
+	return "invalid or expired token"
+}
+
+func (e InvalidTokenError) HTTPStatus() int {
+	return http.StatusBadRequest
+}
+
+func (e InvalidTokenError) Code() string {
+	return CodeInvalidToken
+}
+
+// EmailNotVerifiedError represents a login of a user who has not verified their email yet.
+type EmailNotVerifiedError struct{}
+
+func (e EmailNotVerifiedError) Error() string {
+	return "email address has not been verified"
+}
+
+func (e EmailNotVerifiedError) HTTPStatus() int {
+	return http.StatusForbidden
+}
+
+func (e EmailNotVerifiedError) Code() string {
+	return CodeEmailNotVerified
+}
+
+// ========== MFA Domain Errors ==========
+
+// InvalidMFACodeError represents a wrong, expired or replayed second factor code.
+// UserId identifies the user who failed, so that callers can count the failure
+// towards the login lockout; it is not part of the message.
+type InvalidMFACodeError struct {
+	UserId int
+}
+
+func (e InvalidMFACodeError) Error() string {
+	return "invalid verification code"
+}
+
+func (e InvalidMFACodeError) HTTPStatus() int {
+	return http.StatusUnauthorized
+}
+
+func (e InvalidMFACodeError) Code() string {
+	return CodeInvalidMFACode
+}
+
+// MFAAlreadyEnabledError represents an enrollment of a user whose second factor is already enabled.
+type MFAAlreadyEnabledError struct{}
+
+func (e MFAAlreadyEnabledError) Error() string {
+	return "two-factor authentication is already enabled"
+}
+
+func (e MFAAlreadyEnabledError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e MFAAlreadyEnabledError) Code() string {
+	return CodeMFAAlreadyEnabled
+}
+
+// MFANotEnabledError represents an operation that needs a second factor the user has not enrolled.
+type MFANotEnabledError struct{}
+
+func (e MFANotEnabledError) Error() string {
+	return "two-factor authentication is not enabled"
+}
+
+func (e MFANotEnabledError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e MFANotEnabledError) Code() string {
+	return CodeMFANotEnabled
+}
+
+// ========== Passkey Domain Errors ==========
+
+// InvalidPasskeyError represents a passkey ceremony that failed verification, e.g. an unknown
+// credential, an expired challenge or a bad signature. The cause is deliberately not revealed.
+type InvalidPasskeyError struct{}
+
+func (e InvalidPasskeyError) Error() string {
+	return "passkey verification failed"
+}
+
+func (e InvalidPasskeyError) HTTPStatus() int {
+	return http.StatusUnauthorized
+}
+
+func (e InvalidPasskeyError) Code() string {
+	return CodeInvalidPasskey
+}
+
+// PasskeyNotFoundError represents a passkey that does not exist or belongs to another user.
+type PasskeyNotFoundError struct {
+	Id int
+}
+
+func (e PasskeyNotFoundError) Error() string {
+	return fmt.Sprintf("passkey not found with id: %d", e.Id)
+}
+
+func (e PasskeyNotFoundError) HTTPStatus() int {
+	return http.StatusNotFound
+}
+
+func (e PasskeyNotFoundError) Code() string {
+	return CodePasskeyNotFound
+}
+
+// PasskeyAlreadyRegisteredError represents a registration of a credential that is already registered.
+type PasskeyAlreadyRegisteredError struct{}
+
+func (e PasskeyAlreadyRegisteredError) Error() string {
+	return "passkey is already registered"
+}
+
+func (e PasskeyAlreadyRegisteredError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e PasskeyAlreadyRegisteredError) Code() string {
+	return CodePasskeyAlreadyRegistered
+}
+
+// ========== API Key Domain Errors ==========
+
+// InvalidAPIKeyError represents an API key that is unknown, revoked or expired, or whose owner
+// can no longer sign in. The cause is deliberately not revealed.
+type InvalidAPIKeyError struct{}
+
+func (e InvalidAPIKeyError) Error() string {
+	return "invalid api key"
+}
+
+func (e InvalidAPIKeyError) HTTPStatus() int {
+	return http.StatusUnauthorized
+}
+
+func (e InvalidAPIKeyError) Code() string {
+	return CodeInvalidAPIKey
+}
+
+// APIKeyNotFoundError represents an API key that does not exist, is already revoked or belongs to another user.
+type APIKeyNotFoundError struct {
+	Id int
+}
+
+func (e APIKeyNotFoundError) Error() string {
+	return fmt.Sprintf("api key not found with id: %d", e.Id)
+}
+
+func (e APIKeyNotFoundError) HTTPStatus() int {
+	return http.StatusNotFound
+}
+
+func (e APIKeyNotFoundError) Code() string {
+	return CodeAPIKeyNotFound
+}
+
+// APIKeyLimitExceededError represents a user who already has the maximum number of active API keys.
+type APIKeyLimitExceededError struct {
+	Max int
+}
+
+func (e APIKeyLimitExceededError) Error() string {
+	return fmt.Sprintf("api key limit of %d reached", e.Max)
+}
+
+func (e APIKeyLimitExceededError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e APIKeyLimitExceededError) Code() string {
+	return CodeAPIKeyLimitExceeded
+}
+
+// ========== Role Domain Errors ==========
+
+// RoleNotFoundError represents a role that does not exist.
+type RoleNotFoundError struct {
+	Name string
+}
+
+func (e RoleNotFoundError) Error() string {
+	return fmt.Sprintf("role not found: %s", e.Name)
+}
+
+func (e RoleNotFoundError) HTTPStatus() int {
+	return http.StatusNotFound
+}
+
+func (e RoleNotFoundError) Code() string {
+	return CodeRoleNotFound
+}
+
+// RoleAlreadyExistsError represents a role name that is already taken.
+type RoleAlreadyExistsError struct {
+	Name string
+}
+
+func (e RoleAlreadyExistsError) Error() string {
+	return fmt.Sprintf("role already exists: %s", e.Name)
+}
+
+func (e RoleAlreadyExistsError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e RoleAlreadyExistsError) Code() string {
+	return CodeRoleAlreadyExists
+}
+
+// RoleInUseError represents the deletion of a role that users or pending invitations still have.
+type RoleInUseError struct {
+	Name string
+}
+
+func (e RoleInUseError) Error() string {
+	return fmt.Sprintf("role is still assigned: %s", e.Name)
+}
+
+func (e RoleInUseError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e RoleInUseError) Code() string {
+	return CodeRoleInUse
+}
+
+// ProtectedRoleError represents a change that built-in roles do not allow: they cannot be deleted,
+// and the admin role always holds every permission so that roles can always be managed.
+type ProtectedRoleError struct {
+	Name string
+}
+
+func (e ProtectedRoleError) Error() string {
+	return fmt.Sprintf("role is protected: %s", e.Name)
+}
+
+func (e ProtectedRoleError) HTTPStatus() int {
+	return http.StatusConflict
+}
+
+func (e ProtectedRoleError) Code() string {
+	return CodeProtectedRole
+}
+
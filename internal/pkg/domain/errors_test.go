@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"testing"
@@ -174,6 +175,107 @@ func TestInvalidRoleError_HTTPStatus(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, err.HTTPStatus())
 }
 
+// ========== InvalidTokenError Tests ==========
+
+func TestInvalidTokenError_Error(t *testing.T) {
+	err := InvalidTokenError{}
+	assert.Equal(t, "invalid or expired token", err.Error())
+}
+
+func TestInvalidTokenError_HTTPStatus(t *testing.T) {
+	err := InvalidTokenError{}
+	assert.Equal(t, http.StatusBadRequest, err.HTTPStatus())
+}
+
+// ========== EmailNotVerifiedError Tests ==========
+
+func TestEmailNotVerifiedError_Error(t *testing.T) {
+	err := EmailNotVerifiedError{}
+	assert.Equal(t, "email address has not been verified", err.Error())
+}
+
+func TestEmailNotVerifiedError_HTTPStatus(t *testing.T) {
+	err := EmailNotVerifiedError{}
+	assert.Equal(t, http.StatusForbidden, err.HTTPStatus())
+}
+
+// ========== MFA Error Tests ==========
+
+func TestMFAErrors(t *testing.T) {
+	tests := []struct {
+		err     DomainError
+		message string
+		status  int
+	}{
+		{InvalidMFACodeError{}, "invalid verification code", http.StatusUnauthorized},
+		{MFAAlreadyEnabledError{}, "two-factor authentication is already enabled", http.StatusConflict},
+		{MFANotEnabledError{}, "two-factor authentication is not enabled", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.message, tt.err.Error())
+		assert.Equal(t, tt.status, tt.err.HTTPStatus())
+	}
+}
+
+// ========== Passkey Error Tests ==========
+
+func TestPasskeyErrors(t *testing.T) {
+	tests := []struct {
+		err     DomainError
+		message string
+		status  int
+	}{
+		{InvalidPasskeyError{}, "passkey verification failed", http.StatusUnauthorized},
+		{PasskeyNotFoundError{Id: 3}, "passkey not found with id: 3", http.StatusNotFound},
+		{PasskeyAlreadyRegisteredError{}, "passkey is already registered", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.message, tt.err.Error())
+		assert.Equal(t, tt.status, tt.err.HTTPStatus())
+	}
+}
+
+// ========== API Key Error Tests ==========
+
+func TestAPIKeyErrors(t *testing.T) {
+	tests := []struct {
+		err     DomainError
+		message string
+		status  int
+	}{
+		{InvalidAPIKeyError{}, "invalid api key", http.StatusUnauthorized},
+		{APIKeyNotFoundError{Id: 4}, "api key not found with id: 4", http.StatusNotFound},
+		{APIKeyLimitExceededError{Max: 25}, "api key limit of 25 reached", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.message, tt.err.Error())
+		assert.Equal(t, tt.status, tt.err.HTTPStatus())
+	}
+}
+
+// ========== Role Error Tests ==========
+
+func TestRoleErrors(t *testing.T) {
+	tests := []struct {
+		err     DomainError
+		message string
+		status  int
+	}{
+		{RoleNotFoundError{Name: "support"}, "role not found: support", http.StatusNotFound},
+		{RoleAlreadyExistsError{Name: "admin"}, "role already exists: admin", http.StatusConflict},
+		{RoleInUseError{Name: "support"}, "role is still assigned: support", http.StatusConflict},
+		{ProtectedRoleError{Name: "admin"}, "role is protected: admin", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.message, tt.err.Error())
+		assert.Equal(t, tt.status, tt.err.HTTPStatus())
+	}
+}
+
 // ========== DomainError Interface Tests ==========
 
 func TestDomainError_Interface(t *testing.T) {
@@ -186,6 +288,43 @@ func TestDomainError_Interface(t *testing.T) {
 	var _ DomainError = UserAlreadyExistsError{}
 	var _ DomainError = InvalidCredentialsError{}
 	var _ DomainError = InvalidRoleError{}
+	var _ DomainError = InvalidTokenError{}
+	var _ DomainError = EmailNotVerifiedError{}
+	var _ DomainError = InvalidMFACodeError{}
+	var _ DomainError = MFAAlreadyEnabledError{}
+	var _ DomainError = MFANotEnabledError{}
+	var _ DomainError = InvalidPasskeyError{}
+	var _ DomainError = PasskeyNotFoundError{}
+	var _ DomainError = PasskeyAlreadyRegisteredError{}
+	var _ DomainError = InvalidAPIKeyError{}
+	var _ DomainError = APIKeyNotFoundError{}
+	var _ DomainError = APIKeyLimitExceededError{}
+	var _ DomainError = RoleNotFoundError{}
+	var _ DomainError = RoleAlreadyExistsError{}
+	var _ DomainError = RoleInUseError{}
+	var _ DomainError = ProtectedRoleError{}
+}
+
+func TestDomainError_Codes(t *testing.T) {
+	errs := []DomainError{
+		InternalServerError{}, UnauthorizedError{}, ForbiddenError{}, ValidationError{},
+		UserNotFoundError{}, UserAlreadyExistsError{}, InvalidCredentialsError{}, InvalidRoleError{},
+		InvalidTokenError{}, EmailNotVerifiedError{},
+		InvalidMFACodeError{}, MFAAlreadyEnabledError{}, MFANotEnabledError{},
+		InvalidPasskeyError{}, PasskeyNotFoundError{}, PasskeyAlreadyRegisteredError{},
+		InvalidAPIKeyError{}, APIKeyNotFoundError{}, APIKeyLimitExceededError{},
+		RoleNotFoundError{}, RoleAlreadyExistsError{}, RoleInUseError{}, ProtectedRoleError{},
+	}
+
+	// Every error type has its own code
+	seen := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		code := err.Code()
+		assert.Regexp(t, `^[a-z][a-z_]*$`, code)
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+	assert.Equal(t, CodeUserNotFound, UserNotFoundError{Id: 1}.Code())
 }
 
 func TestDomainError_TypeAssertion(t *testing.T) {
@@ -202,7 +341,11 @@ func TestInternalServerError_Unwrap(t *testing.T) {
 	underlyingErr := errors.New("database connection failed")
 	err := InternalServerError{Msg: "query failed", Err: underlyingErr}
 
-	// While InternalServerError doesn't implement Unwrap, we can still check the Err field
 	assert.Equal(t, underlyingErr, err.Err)
+	assert.ErrorIs(t, err, underlyingErr)
+
+	// Lets handlers detect cancelled or timed out requests
+	timedOut := InternalServerError{Msg: "failed to get user", Err: context.DeadlineExceeded}
+	assert.ErrorIs(t, timedOut, context.DeadlineExceeded)
 }
 
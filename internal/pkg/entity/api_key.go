@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// APIKey is a user-owned key that machine clients use instead of logging in.
+// It acts as its owner, limited to its scopes.
+type APIKey struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // public part of the key, used to look it up
+	KeyHash    string     `json:"-"`      // only the hash of the key is stored
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"` // nil for keys that never expire
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKey scopes
+const (
+	APIKeyScopeUsersRead  = "users:read"  // read users and the owner's profile
+	APIKeyScopeUsersWrite = "users:write" // create, update and delete users, as far as the owner's role allows
+)
+
+// IsValidAPIKeyScope checks if the given API key scope is valid.
+func IsValidAPIKeyScope(scope string) bool {
+	switch scope {
+	case APIKeyScopeUsersRead, APIKeyScopeUsersWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasScope reports whether the key grants the given scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// IsRevoked reports whether the owner has revoked the key.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsExpired reports whether the key is expired at the given time.
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Invitation is a single-use token that lets the invited email self-register,
+// with the role chosen by the admin who sent it.
+type Invitation struct {
+	Id        int        `json:"id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	TokenHash string     `json:"-"` // only the hash of the token is stored
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsUsed reports whether the invitation has already been accepted.
+func (i *Invitation) IsUsed() bool {
+	return i.UsedAt != nil
+}
+
+// IsExpired reports whether the invitation is expired at the given time.
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// Passkey is a WebAuthn credential that signs a user in without a password.
+type Passkey struct {
+	Id           int        `json:"id"`
+	UserId       int        `json:"user_id"`
+	CredentialId []byte     `json:"-"` // chosen by the authenticator, unique across users
+	PublicKey    []byte     `json:"-"` // COSE_Key
+	SignCount    uint32     `json:"-"` // last signature counter reported by the authenticator
+	Name         string     `json:"name"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+}
+
+// PasskeyChallenge is the challenge of a pending passkey registration or login ceremony.
+type PasskeyChallenge struct {
+	Id            int       `json:"id"`
+	UserId        *int      `json:"user_id"` // nil for logins, where the user is not known yet
+	Purpose       string    `json:"purpose"`
+	ChallengeHash string    `json:"-"` // only the hash of the challenge is stored
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PasskeyChallenge purposes
+const (
+	PasskeyPurposeRegistration = "registration"
+	PasskeyPurposeLogin        = "login"
+)
+
+// IsExpired reports whether the challenge is expired at the given time.
+func (c *PasskeyChallenge) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
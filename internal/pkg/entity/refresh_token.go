@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// RefreshToken represents an opaque refresh token issued to a user.
+// Tokens created by rotating one another share the same FamilyId.
+type RefreshToken struct {
+	Id        int        `json:"id"`
+	UserId    int        `json:"user_id"`
+	FamilyId  string     `json:"family_id"`
+	TokenHash string     `json:"-"` // only the hash of the token is stored
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsRevoked reports whether the token has been revoked or rotated.
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsExpired reports whether the token is expired at the given time.
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
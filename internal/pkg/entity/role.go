@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Role is a named set of permissions. Every user has exactly one role.
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"` // sorted
+	IsSystem    bool      `json:"is_system"`   // built-in role that cannot be deleted
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Permissions checked by the routes. Roles can only grant permissions from this list.
+const (
+	PermissionUsersRead  = "users:read"  // list and view any user
+	PermissionUsersWrite = "users:write" // create, invite, update, delete and unlock users, reset their second factor
+	PermissionRolesRead  = "roles:read"  // list and view roles
+	PermissionRolesWrite = "roles:write" // create, update and delete roles
+)
+
+// AllPermissions returns every permission, sorted.
+func AllPermissions() []string {
+	return []string{PermissionRolesRead, PermissionRolesWrite, PermissionUsersRead, PermissionUsersWrite}
+}
+
+// IsValidPermission checks if the given permission is valid.
+func IsValidPermission(permission string) bool {
+	return slices.Contains(AllPermissions(), permission)
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,49}$`)
+
+// IsValidRoleName checks if the given role name is well-formed: lowercase letters, digits
+// and hyphens, starting with a letter, at most 50 characters. It does not check that the role exists.
+func IsValidRoleName(name string) bool {
+	return roleNamePattern.MatchString(name)
+}
+
+// HasPermission reports whether the role grants the given permission.
+func (r *Role) HasPermission(permission string) bool {
+	return slices.Contains(r.Permissions, permission)
+}
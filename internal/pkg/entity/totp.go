@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// TOTPCredential is a user's TOTP (RFC 6238) second factor. It is pending until the user
+// proves the authenticator works by confirming the enrollment with a first code.
+type TOTPCredential struct {
+	UserId       int        `json:"user_id"`
+	Secret       string     `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-"` // time step of the last accepted code; codes up to it are replays
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IsConfirmed reports whether the enrollment is complete, i.e. logins require a code.
+func (c *TOTPCredential) IsConfirmed() bool {
+	return c.ConfirmedAt != nil
+}
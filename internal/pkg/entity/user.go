@@ -4,26 +4,44 @@ import "time"
 
 // User represents a user entity in the system.
 type User struct {
-	Id        int       `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	Password  string    `json:"-"` // never expose password in JSON
-	Name      string    `json:"name"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Id              int        `json:"id"`
+	Email           string     `json:"email"`
+	Username        string     `json:"username"`
+	Password        string     `json:"-"` // never expose password in JSON
+	Name            string     `json:"name"`
+	Role            string     `json:"role"`
+	IsActive        bool       `json:"is_active"`
+	TokenVersion    int        `json:"-"` // embedded in access tokens; incrementing it revokes them all
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PendingEmail    *string    `json:"pending_email"` // requested new email; replaces Email once verified
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Login lockout state, maintained by the login flow rather than by UpdateUser
+	FailedLoginAttempts int        `json:"-"` // consecutive failed logins since the last successful one
+	LockedUntil         *time.Time `json:"locked_until"`
+}
+
+// IsEmailVerified reports whether the user has confirmed that they own Email.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
-// UserRole constants
+// IsLocked reports whether logins are refused at the given time after too many failures.
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// Built-in roles, created by the schema migrations. They cannot be deleted; further roles
+// are managed at runtime, see Role.
 const (
 	RoleAdmin  = "admin"
 	RoleUser   = "user"
 	RoleViewer = "viewer"
 )
 
-// IsValidRole checks if the given role is valid.
-func IsValidRole(role string) bool {
+// IsSystemRole reports whether the given role is one of the built-in roles.
+func IsSystemRole(role string) bool {
 	switch role {
 	case RoleAdmin, RoleUser, RoleViewer:
 		return true
@@ -31,4 +49,3 @@ func IsValidRole(role string) bool {
 		return false
 	}
 }
-
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// UserToken is a single-use token sent to a user out of band (e.g. a password reset link).
+type UserToken struct {
+	Id        int        `json:"id"`
+	UserId    int        `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	TokenHash string     `json:"-"` // only the hash of the token is stored
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserToken purposes
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMFAChallenge      = "mfa_challenge" // completes a login that needs a second factor
+)
+
+// IsUsed reports whether the token has already been redeemed.
+func (t *UserToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsExpired reports whether the token is expired at the given time.
+func (t *UserToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,126 @@
+// Package health runs named dependency checks for liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each check when the registry has no timeout of its own.
+const DefaultTimeout = 2 * time.Second
+
+// Check statuses.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check reports whether a dependency is usable. It should return promptly once ctx is done.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Report is the outcome of all checks. Its status fails if any check failed.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// OK reports whether all checks passed.
+func (r *Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// namedCheck is a registered check.
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry holds the checks of the components that must be usable to serve traffic.
+// Checks are registered at startup; registering an empty or duplicate name panics.
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	names  map[string]bool
+	checks []namedCheck
+}
+
+// NewRegistry creates an empty registry whose checks are each bounded by timeout
+// (DefaultTimeout if zero).
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Registry{timeout: timeout, names: make(map[string]bool)}
+}
+
+// Register adds a named check.
+func (r *Registry) Register(name string, check Check) {
+	if name == "" {
+		panic("health: empty check name")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[name] {
+		panic(fmt.Sprintf("health: duplicate check %s", name))
+	}
+	r.names[name] = true
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Run runs all checks concurrently and reports their results in registration order.
+// A nil registry has no checks and always passes.
+func (r *Registry) Run(ctx context.Context) *Report {
+	report := &Report{Status: StatusOK}
+	if r == nil {
+		return report
+	}
+
+	r.mu.Lock()
+	checks := r.checks
+	r.mu.Unlock()
+
+	report.Checks = make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.check(ctx)
+	result := Result{Name: c.name, Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
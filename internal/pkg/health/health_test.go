@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("database", func(ctx context.Context) error { return nil })
+	registry.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := registry.Run(context.Background())
+
+	assert.False(t, report.OK())
+	assert.Equal(t, StatusFail, report.Status)
+	if assert.Len(t, report.Checks, 2) {
+		assert.Equal(t, "database", report.Checks[0].Name)
+		assert.Equal(t, StatusOK, report.Checks[0].Status)
+		assert.Empty(t, report.Checks[0].Error)
+		assert.Equal(t, "cache", report.Checks[1].Name)
+		assert.Equal(t, StatusFail, report.Checks[1].Status)
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+	}
+}
+
+func TestRegistry_Run_Timeout(t *testing.T) {
+	registry := NewRegistry(50 * time.Millisecond)
+	registry.Register("stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.False(t, report.OK())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestRegistry_Run_Empty(t *testing.T) {
+	var registry *Registry
+
+	assert.True(t, registry.Run(context.Background()).OK())
+	assert.True(t, NewRegistry(0).Run(context.Background()).OK())
+}
+
+func TestRegistry_Register_Duplicate(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.Register("database", func(ctx context.Context) error { return nil })
+
+	assert.Panics(t, func() {
+		registry.Register("database", func(ctx context.Context) error { return nil })
+	})
+	assert.Panics(t, func() {
+		registry.Register("", func(ctx context.Context) error { return nil })
+	})
+}
@@ -0,0 +1,125 @@
+// Package logger builds the structured logger of the application on top of log/slog.
+//
+// Loggers created by New redact the values of sensitive attributes (passwords, tokens,
+// secrets, authorization headers) wherever they appear, so callers can log request data
+// without filtering it first. The logger of a request is carried in its context; use
+// FromContext to log with the request ID and other request attributes attached.
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Supported formats.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Redacted replaces the values of sensitive attributes.
+const Redacted = "[REDACTED]"
+
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// sensitiveKeys are matched against attribute keys, case-insensitively and ignoring
+// separators, so that "password", "new_password" and "X-Refresh-Token" are all redacted.
+var sensitiveKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"apikey",
+	"recoverycode",
+	"credential",
+}
+
+// Config holds logger configuration.
+type Config struct {
+	Level  string // debug, info (default), warn or error
+	Format string // json (default) or text
+}
+
+// New creates a logger that writes records at or above the configured level to w.
+func New(w io.Writer, config Config) (*slog.Logger, error) {
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}
+
+	switch config.Format {
+	case "", FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, config.Format)
+	}
+}
+
+// ParseLevel parses a level name. The empty string means info.
+func ParseLevel(s string) (slog.Level, error) {
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// IsSensitiveKey reports whether the value of an attribute or header with the given key must not be logged.
+func IsSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", ".", "", " ", "").Replace(strings.ToLower(key))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact is the slog.HandlerOptions.ReplaceAttr function of loggers created by New.
+// Groups are not passed to ReplaceAttr, so the attributes inside them are checked one by one.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() == slog.KindString {
+		// Credentials in values of innocent keys, e.g. a header dump
+		if strings.HasPrefix(a.Value.String(), "Bearer ") {
+			return slog.String(a.Key, "Bearer "+Redacted)
+		}
+	}
+	return a
+}
+
+// ========== Context ==========
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx that carries logger.
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default() if there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Formats(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{})
+	assert.NoError(t, err)
+	logger.Info("hello", "user_id", 1)
+
+	var record map[string]any
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &record), "json is the default format") {
+		assert.Equal(t, "hello", record["msg"])
+		assert.Equal(t, float64(1), record["user_id"])
+	}
+
+	buf.Reset()
+	logger, err = New(&buf, Config{Format: FormatText})
+	assert.NoError(t, err)
+	logger.Info("hello", "user_id", 1)
+	assert.Contains(t, buf.String(), "msg=hello user_id=1")
+
+	_, err = New(&buf, Config{Format: "xml"})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: "warn"})
+	assert.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+
+	_, err = New(&buf, Config{Level: "verbose"})
+	assert.Error(t, err)
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, expected := range map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		level, err := ParseLevel(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, level, s)
+	}
+}
+
+func TestIsSensitiveKey(t *testing.T) {
+	for key, expected := range map[string]bool{
+		"password":        true,
+		"new_password":    true,
+		"Authorization":   true,
+		"X-Refresh-Token": true,
+		"refresh_token":   true,
+		"client_secret":   true,
+		"api_key":         true,
+		"recovery_codes":  true,
+		"Cookie":          true,
+		"user_id":         false,
+		"email":           false,
+		"path":            false,
+		"request_id":      false,
+		"Content-Type":    false,
+	} {
+		assert.Equal(t, expected, IsSensitiveKey(key), key)
+	}
+}
+
+func TestNew_Redacts(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{})
+	assert.NoError(t, err)
+
+	logger.Info("login",
+		"email", "test@example.com",
+		"password", "hunter22",
+		slog.Group("headers", "Authorization", "Bearer abc.def.ghi", "X-Forwarded-For", "10.0.0.1"),
+		"note", "Bearer abc.def.ghi",
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter22")
+	assert.NotContains(t, out, "abc.def.ghi")
+	assert.Contains(t, out, "test@example.com")
+	assert.Contains(t, out, "10.0.0.1")
+	assert.Contains(t, out, Redacted)
+}
+
+func TestContext(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	ctx := WithContext(context.Background(), logger)
+	assert.Same(t, logger, FromContext(ctx))
+}
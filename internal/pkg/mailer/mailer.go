@@ -0,0 +1,169 @@
+// Package mailer sends transactional email (password resets, verification links).
+//
+// Transports are pluggable behind the Mailer interface. The built-in transports do not
+// deliver mail: LogMailer writes messages to the log and FileMailer writes them to a
+// directory, which is enough for development and tests. Production deployments plug in
+// a transport backed by SMTP or an email provider.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported transports.
+const (
+	TransportLog  = "log"
+	TransportFile = "file"
+)
+
+var (
+	ErrEmptyRecipient   = errors.New("empty recipient")
+	ErrInvalidHeader    = errors.New("header contains a line break")
+	ErrEmptyDir         = errors.New("empty directory")
+	ErrUnknownTransport = errors.New("unknown mail transport")
+
+	defaultFrom = "no-reply@localhost"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Validate checks if the message can be sent.
+func (m Message) Validate() error {
+	if m.To == "" {
+		return ErrEmptyRecipient
+	}
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+	return nil
+}
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config holds mailer configuration.
+type Config struct {
+	Transport string // log (default) or file
+	From      string // sender address
+	Dir       string // output directory of the file transport
+}
+
+// New creates the mailer selected by config.Transport.
+func New(config Config) (Mailer, error) {
+	switch config.Transport {
+	case "", TransportLog:
+		return NewLogMailer(slog.Default(), config.From), nil
+	case TransportFile:
+		return NewFileMailer(config.Dir, config.From)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTransport, config.Transport)
+	}
+}
+
+// format renders a message in RFC 5322 form.
+func format(from string, msg Message, date time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
+
+// ========== Log Transport ==========
+
+// LogMailer writes messages to a logger instead of delivering them.
+// Messages may contain secrets such as reset links, so it must not be used in production.
+type LogMailer struct {
+	logger *slog.Logger
+	from   string
+}
+
+// NewLogMailer creates a mailer that writes messages to logger.
+func NewLogMailer(logger *slog.Logger, from string) *LogMailer {
+	if from == "" {
+		from = defaultFrom
+	}
+	return &LogMailer{logger: logger, from: from}
+}
+
+// Send logs the message.
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	m.logger.InfoContext(ctx, "mail", "from", m.from, "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// ========== File Transport ==========
+
+// FileMailer writes every message to its own .eml file in a directory.
+type FileMailer struct {
+	dir  string
+	from string
+	now  func() time.Time
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewFileMailer creates a mailer that writes messages to dir, creating it if needed.
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, ErrEmptyDir
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	if from == "" {
+		from = defaultFrom
+	}
+	return &FileMailer{dir: dir, from: from, now: time.Now}, nil
+}
+
+// Send writes the message to a new file named after the time it was sent.
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.seq++
+	seq := m.seq
+	m.mu.Unlock()
+
+	now := m.now()
+	name := fmt.Sprintf("%s-%06d.eml", now.UTC().Format("20060102T150405.000000000"), seq)
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, []byte(format(m.from, msg, now)), 0o600); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
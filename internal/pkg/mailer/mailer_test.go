@@ -0,0 +1,106 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     Message
+		wantErr error
+	}{
+		{name: "valid", msg: Message{To: "user@example.com", Subject: "Hello"}},
+		{name: "empty recipient", msg: Message{Subject: "Hello"}, wantErr: ErrEmptyRecipient},
+		{name: "line break in recipient", msg: Message{To: "user@example.com\r\nBcc: evil@example.com"}, wantErr: ErrInvalidHeader},
+		{name: "line break in subject", msg: Message{To: "user@example.com", Subject: "Hello\nBcc: evil@example.com"}, wantErr: ErrInvalidHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(Config{})
+	assert.NoError(t, err)
+	assert.IsType(t, &LogMailer{}, m)
+
+	m, err = New(Config{Transport: TransportFile, Dir: t.TempDir()})
+	assert.NoError(t, err)
+	assert.IsType(t, &FileMailer{}, m)
+
+	_, err = New(Config{Transport: TransportFile})
+	assert.ErrorIs(t, err, ErrEmptyDir)
+
+	_, err = New(Config{Transport: "smtp"})
+	assert.ErrorIs(t, err, ErrUnknownTransport)
+}
+
+func TestLogMailer_Send(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(slog.New(slog.NewTextHandler(&buf, nil)), "")
+
+	err := m.Send(context.Background(), Message{To: "user@example.com", Subject: "Reset", Body: "https://example.com/reset?token=abc"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "from=no-reply@localhost to=user@example.com")
+	assert.Contains(t, buf.String(), "https://example.com/reset?token=abc")
+
+	assert.ErrorIs(t, m.Send(context.Background(), Message{}), ErrEmptyRecipient)
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "sender@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create file mailer: %v", err)
+	}
+	m.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	ctx := context.Background()
+	assert.NoError(t, m.Send(ctx, Message{To: "first@example.com", Subject: "First", Body: "first body"}))
+	assert.NoError(t, m.Send(ctx, Message{To: "second@example.com", Subject: "Second", Body: "second body"}))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 2, "messages sent at the same time must not overwrite each other") {
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	assert.NoError(t, err)
+	content := string(data)
+	assert.True(t, strings.HasSuffix(entries[0].Name(), ".eml"))
+	assert.Contains(t, content, "From: sender@example.com\r\n")
+	assert.Contains(t, content, "To: first@example.com\r\n")
+	assert.Contains(t, content, "Subject: First\r\n")
+	assert.True(t, strings.HasSuffix(content, "\r\n\r\nfirst body"))
+}
+
+func TestFileMailer_CanceledContext(t *testing.T) {
+	m, err := NewFileMailer(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("Failed to create file mailer: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, m.Send(ctx, Message{To: "user@example.com"}), context.Canceled)
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RegisterDBStats registers gauges and counters for the connection pool statistics returned by stats,
+// typically (*sql.DB).Stats.
+//
+// collectors.NewDBStatsCollector is not used because it names the metrics go_sql_*
+// and labels them by database, which would break existing dashboards.
+func RegisterDBStats(registry prometheus.Registerer, stats func() sql.DBStats) {
+	factory := promauto.With(registry)
+
+	gauge := func(name, help string, fn func(sql.DBStats) float64) {
+		factory.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, func() float64 { return fn(stats()) })
+	}
+	counter := func(name, help string, fn func(sql.DBStats) float64) {
+		factory.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 { return fn(stats()) })
+	}
+
+	gauge("db_max_open_connections", "Maximum number of open connections to the database.", func(s sql.DBStats) float64 {
+		return float64(s.MaxOpenConnections)
+	})
+	gauge("db_open_connections", "Number of established connections, both in use and idle.", func(s sql.DBStats) float64 {
+		return float64(s.OpenConnections)
+	})
+	gauge("db_in_use_connections", "Number of connections currently in use.", func(s sql.DBStats) float64 {
+		return float64(s.InUse)
+	})
+	gauge("db_idle_connections", "Number of idle connections.", func(s sql.DBStats) float64 {
+		return float64(s.Idle)
+	})
+	counter("db_wait_count_total", "Total number of connections waited for.", func(s sql.DBStats) float64 {
+		return float64(s.WaitCount)
+	})
+	counter("db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", func(s sql.DBStats) float64 {
+		return s.WaitDuration.Seconds()
+	})
+	counter("db_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", func(s sql.DBStats) float64 {
+		return float64(s.MaxIdleClosed)
+	})
+	counter("db_max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.", func(s sql.DBStats) float64 {
+		return float64(s.MaxIdleTimeClosed)
+	})
+	counter("db_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", func(s sql.DBStats) float64 {
+		return float64(s.MaxLifetimeClosed)
+	})
+}
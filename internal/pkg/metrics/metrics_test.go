@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, registry prometheus.Gatherer) string {
+	w := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to scrape metrics: status %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestNewRegistry(t *testing.T) {
+	out := scrape(t, NewRegistry())
+
+	assert.Contains(t, out, "go_goroutines ")
+	assert.Contains(t, out, "process_start_time_seconds ")
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	r := prometheus.NewRegistry()
+	RegisterDBStats(r, func() sql.DBStats {
+		return sql.DBStats{
+			MaxOpenConnections: 25,
+			OpenConnections:    3,
+			InUse:              1,
+			Idle:               2,
+			WaitCount:          4,
+			WaitDuration:       1500 * time.Millisecond,
+		}
+	})
+
+	out := scrape(t, r)
+	assert.Contains(t, out, "# TYPE db_wait_count_total counter\n")
+	assert.Contains(t, out, "db_max_open_connections 25\n")
+	assert.Contains(t, out, "db_open_connections 3\n")
+	assert.Contains(t, out, "db_in_use_connections 1\n")
+	assert.Contains(t, out, "db_idle_connections 2\n")
+	assert.Contains(t, out, "db_wait_count_total 4\n")
+	assert.Contains(t, out, "db_wait_duration_seconds_total 1.5\n")
+}
+
+func TestHandler(t *testing.T) {
+	r := prometheus.NewRegistry()
+	r.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total", Help: "Total requests."}))
+
+	w := httptest.NewRecorder()
+	Handler(r).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), "requests_total 0\n")
+}
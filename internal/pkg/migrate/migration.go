@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	ErrInvalidFileName     = errors.New("invalid migration file name")
+	ErrDuplicateMigration  = errors.New("duplicate migration version")
+	ErrMissingUpMigration  = errors.New("missing up migration")
+	ErrIrreversible        = errors.New("migration has no down script")
+	ErrChecksumMismatch    = errors.New("migration checksum mismatch")
+	ErrUnknownMigration    = errors.New("applied migration not found in source")
+	ErrNothingToRollback   = errors.New("no applied migrations to roll back")
+	ErrInvalidStepCount    = errors.New("step count must be positive")
+	ErrNoMigrationsLoaded  = errors.New("no migrations loaded")
+	ErrMigrationSourceRead = errors.New("failed to read migration source")
+	ErrPendingMigrations   = errors.New("migrations pending")
+)
+
+// fileNamePattern matches "<version>_<name>.<up|down>.sql", e.g. "000001_create_users.up.sql".
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a single numbered schema change.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string // empty if the migration cannot be rolled back
+	Checksum string // sha256 of the up script
+}
+
+// Load reads migrations from dir in fsys, sorted by version.
+// Every version needs an up script; down scripts are optional.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMigrationSourceRead, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMigrationSourceRead, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateMigration, version)
+		}
+
+		switch match[3] {
+		case "up":
+			m.Up = string(content)
+			m.Checksum = checksum(content)
+		case "down":
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUpMigration, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
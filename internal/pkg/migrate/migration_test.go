@@ -0,0 +1,87 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_Success(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/000002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON users(name);")},
+		"migrations/000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT);")},
+		"migrations/000001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}
+
+	migrations, err := Load(fsys, "migrations")
+
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_users", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE users (id INT);", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE users;", migrations[0].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Empty(t, migrations[1].Down)
+}
+
+func TestLoad_ChecksumTracksUpScript(t *testing.T) {
+	load := func(up string) string {
+		migrations, err := Load(fstest.MapFS{
+			"m/000001_init.up.sql": {Data: []byte(up)},
+		}, "m")
+		assert.NoError(t, err)
+		return migrations[0].Checksum
+	}
+
+	assert.Equal(t, load("SELECT 1;"), load("SELECT 1;"))
+	assert.NotEqual(t, load("SELECT 1;"), load("SELECT 2;"))
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     fstest.MapFS
+		errorType error
+	}{
+		{
+			name:      "invalid file name",
+			files:     fstest.MapFS{"m/create_users.sql": {Data: []byte("x")}},
+			errorType: ErrInvalidFileName,
+		},
+		{
+			name:      "zero version",
+			files:     fstest.MapFS{"m/0_init.up.sql": {Data: []byte("x")}},
+			errorType: ErrInvalidFileName,
+		},
+		{
+			name:      "missing up script",
+			files:     fstest.MapFS{"m/000001_init.down.sql": {Data: []byte("x")}},
+			errorType: ErrMissingUpMigration,
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"m/000001_init.up.sql":  {Data: []byte("x")},
+				"m/000001_other.up.sql": {Data: []byte("y")},
+			},
+			errorType: ErrDuplicateMigration,
+		},
+		{
+			name:      "missing directory",
+			files:     fstest.MapFS{},
+			errorType: ErrMigrationSourceRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.files, "m")
+			assert.ErrorIs(t, err, tt.errorType)
+		})
+	}
+}
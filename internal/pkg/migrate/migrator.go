@@ -0,0 +1,355 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const versionTable = "schema_migrations"
+
+// IDialect abstracts the database-specific parts of the migrator.
+type IDialect interface {
+	// Lock blocks until an exclusive migration lock is held on conn.
+	Lock(ctx context.Context, conn *sql.Conn) error
+	// Unlock releases the lock taken by Lock.
+	Unlock(ctx context.Context, conn *sql.Conn) error
+	// Placeholder returns the bind parameter for the n-th (1-based) query argument.
+	Placeholder(n int) string
+}
+
+// Migrator applies and rolls back migrations, recording them in the schema_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	dialect    IDialect
+	migrations []Migration
+}
+
+// MigrationStatus describes a migration and whether it has been applied.
+type MigrationStatus struct {
+	Version          int64
+	Name             string
+	Applied          bool
+	AppliedAt        time.Time
+	ChecksumMismatch bool // the up script changed after it was applied
+	Missing          bool // applied, but no longer present in the source
+}
+
+// appliedMigration is a row of the schema_migrations table.
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// New creates a new Migrator.
+func New(db *sql.DB, dialect IDialect, migrations []Migration) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if dialect == nil {
+		return nil, errors.New("dialect is nil")
+	}
+	if len(migrations) == 0 {
+		return nil, ErrNoMigrationsLoaded
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies all pending migrations in version order and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		if err := verify(m.migrations, applied); err != nil {
+			return err
+		}
+
+		for _, migration := range pending(m.migrations, applied) {
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations and returns the rolled back ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		if err := verify(m.migrations, applied); err != nil {
+			return err
+		}
+
+		targets, err := rollbackTargets(m.migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range targets {
+			if err := m.rollback(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Redo rolls back the last applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		if err := verify(m.migrations, applied); err != nil {
+			return err
+		}
+
+		targets, err := rollbackTargets(m.migrations, applied, 1)
+		if err != nil {
+			return err
+		}
+
+		migration := targets[0]
+		if err := m.rollback(ctx, conn, migration); err != nil {
+			return err
+		}
+		if err := m.apply(ctx, conn, migration); err != nil {
+			return err
+		}
+		redone = &migration
+		return nil
+	})
+	return redone, err
+}
+
+// Status reports every known migration, plus applied ones missing from the source.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(_ *sql.Conn, applied map[int64]appliedMigration) error {
+		statuses = status(m.migrations, applied)
+		return nil
+	})
+	return statuses, err
+}
+
+// Check fails if a migration is pending, or an applied one was edited or removed from the source.
+// Unlike Status it does not take the migration lock or create the version table,
+// so it is cheap enough to run on every readiness probe.
+func (m *Migrator) Check(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	applied, err := m.loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := verify(m.migrations, applied); err != nil {
+		return err
+	}
+	if p := pending(m.migrations, applied); len(p) > 0 {
+		return fmt.Errorf("%w: %d, starting at %d_%s", ErrPendingMigrations, len(p), p[0].Version, p[0].Name)
+	}
+	return nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]appliedMigration) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.dialect.Lock(ctx, conn); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	// Release with a fresh context so a cancelled ctx does not leave the lock held
+	defer m.dialect.Unlock(context.Background(), conn)
+
+	if err := m.ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	applied, err := m.loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create %s table: %w", versionTable, err)
+	}
+	return nil
+}
+
+func (m *Migrator) loadApplied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	query := `SELECT version, name, checksum, applied_at FROM ` + versionTable
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[a.Version] = a
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// apply runs the up script and records the version in one transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)`,
+		versionTable, m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3), m.dialect.Placeholder(4),
+	)
+
+	return m.inTx(ctx, conn, migration, "apply", migration.Up, query,
+		migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+}
+
+// rollback runs the down script and removes the version in one transaction.
+func (m *Migrator) rollback(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrIrreversible, migration.Version, migration.Name)
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE version = %s`, versionTable, m.dialect.Placeholder(1))
+
+	return m.inTx(ctx, conn, migration, "roll back", migration.Down, query, migration.Version)
+}
+
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, migration Migration, action, script, versionQuery string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("failed to %s migration %d_%s: %w", action, migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, versionQuery, args...); err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+// ========== Planning ==========
+
+// verify fails if an applied migration was edited or removed from the source.
+func verify(migrations []Migration, applied map[int64]appliedMigration) error {
+	known := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, a := range applied {
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrUnknownMigration, a.Version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// pending returns the unapplied migrations in version order.
+func pending(migrations []Migration, applied map[int64]appliedMigration) []Migration {
+	var result []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			result = append(result, migration)
+		}
+	}
+	return result
+}
+
+// rollbackTargets returns the last steps applied migrations, newest first.
+func rollbackTargets(migrations []Migration, applied map[int64]appliedMigration, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, ErrInvalidStepCount
+	}
+
+	var result []Migration
+	for i := len(migrations) - 1; i >= 0 && len(result) < steps; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			result = append(result, migrations[i])
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNothingToRollback
+	}
+	return result, nil
+}
+
+func status(migrations []Migration, applied map[int64]appliedMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		s := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = a.AppliedAt
+			s.ChecksumMismatch = a.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+
+	for version, a := range applied {
+		if !known[version] {
+			statuses = append(statuses, MigrationStatus{
+				Version:   a.Version,
+				Name:      a.Name,
+				Applied:   true,
+				AppliedAt: a.AppliedAt,
+				Missing:   true,
+			})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_users", Up: "up1", Down: "down1", Checksum: "c1"},
+		{Version: 2, Name: "create_tokens", Up: "up2", Down: "down2", Checksum: "c2"},
+		{Version: 3, Name: "add_index", Up: "up3", Checksum: "c3"},
+	}
+}
+
+func appliedVersions(migrations []Migration, versions ...int64) map[int64]appliedMigration {
+	applied := make(map[int64]appliedMigration)
+	for _, m := range migrations {
+		for _, v := range versions {
+			if m.Version == v {
+				applied[v] = appliedMigration{Version: v, Name: m.Name, Checksum: m.Checksum, AppliedAt: time.Now()}
+			}
+		}
+	}
+	return applied
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, nil, testMigrations())
+	assert.Error(t, err)
+
+	_, err = New(&sql.DB{}, nil, testMigrations())
+	assert.Error(t, err)
+}
+
+// ========== verify ==========
+
+func TestVerify(t *testing.T) {
+	migrations := testMigrations()
+
+	assert.NoError(t, verify(migrations, appliedVersions(migrations, 1, 2)))
+
+	modified := appliedVersions(migrations, 1)
+	modified[1] = appliedMigration{Version: 1, Name: "create_users", Checksum: "edited"}
+	assert.ErrorIs(t, verify(migrations, modified), ErrChecksumMismatch)
+
+	unknown := appliedVersions(migrations, 1)
+	unknown[99] = appliedMigration{Version: 99, Name: "removed"}
+	assert.ErrorIs(t, verify(migrations, unknown), ErrUnknownMigration)
+}
+
+// ========== pending ==========
+
+func TestPending(t *testing.T) {
+	migrations := testMigrations()
+
+	all := pending(migrations, appliedVersions(migrations))
+	assert.Len(t, all, 3)
+
+	rest := pending(migrations, appliedVersions(migrations, 1))
+	assert.Equal(t, []int64{2, 3}, versionsOf(rest))
+
+	// Gaps are filled in version order
+	gap := pending(migrations, appliedVersions(migrations, 1, 3))
+	assert.Equal(t, []int64{2}, versionsOf(gap))
+
+	assert.Empty(t, pending(migrations, appliedVersions(migrations, 1, 2, 3)))
+}
+
+// ========== rollbackTargets ==========
+
+func TestRollbackTargets(t *testing.T) {
+	migrations := testMigrations()
+	applied := appliedVersions(migrations, 1, 2, 3)
+
+	targets, err := rollbackTargets(migrations, applied, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, versionsOf(targets))
+
+	targets, err = rollbackTargets(migrations, applied, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2, 1}, versionsOf(targets))
+
+	_, err = rollbackTargets(migrations, appliedVersions(migrations), 1)
+	assert.ErrorIs(t, err, ErrNothingToRollback)
+
+	_, err = rollbackTargets(migrations, applied, 0)
+	assert.ErrorIs(t, err, ErrInvalidStepCount)
+}
+
+// ========== status ==========
+
+func TestStatus(t *testing.T) {
+	migrations := testMigrations()
+	applied := appliedVersions(migrations, 1, 2)
+	applied[2] = appliedMigration{Version: 2, Name: "create_tokens", Checksum: "edited"}
+	applied[7] = appliedMigration{Version: 7, Name: "removed", Checksum: "c7"}
+
+	statuses := status(migrations, applied)
+
+	assert.Len(t, statuses, 4)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[0].ChecksumMismatch)
+	assert.True(t, statuses[1].ChecksumMismatch)
+	assert.False(t, statuses[2].Applied)
+	assert.Equal(t, int64(7), statuses[3].Version)
+	assert.True(t, statuses[3].Missing)
+}
+
+func versionsOf(migrations []Migration) []int64 {
+	versions := make([]int64, 0, len(migrations))
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	return versions
+}
@@ -10,5 +10,32 @@ var (
 	// User repository errors
 	ErrUserNotFound   = errors.New("user not found")
 	ErrDuplicateEmail = errors.New("email already exists")
+
+	// Refresh token repository errors
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+	// User token repository errors
+	ErrUserTokenNotFound = errors.New("user token not found")
+
+	// Invitation repository errors
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	// MFA repository errors
+	ErrTOTPCredentialNotFound = errors.New("totp credential not found")
+	ErrRecoveryCodeNotFound   = errors.New("recovery code not found")
+
+	// Passkey repository errors
+	ErrPasskeyNotFound          = errors.New("passkey not found")
+	ErrDuplicatePasskey         = errors.New("passkey already registered")
+	ErrPasskeyChallengeNotFound = errors.New("passkey challenge not found")
+
+	// API key repository errors
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrDuplicateAPIKey = errors.New("api key prefix already exists")
+
+	// Role repository errors
+	ErrRoleNotFound  = errors.New("role not found")
+	ErrDuplicateRole = errors.New("role already exists")
+	ErrRoleInUse     = errors.New("role is assigned to users or invitations")
 )
 
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertAPIKey stores a new API key and returns the created key ID.
+// Returns repository.ErrDuplicateAPIKey if the prefix is already taken.
+func (r *Repository) InsertAPIKey(ctx context.Context, key *entity.APIKey) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Foreign key and unique constraints
+	if _, ok := r.data.users[key.UserId]; !ok {
+		return 0, fmt.Errorf("api key references unknown user %d: %w", key.UserId, repository.ErrUserNotFound)
+	}
+	for _, existing := range r.data.apiKeys {
+		if existing.Prefix == key.Prefix {
+			return 0, repository.ErrDuplicateAPIKey
+		}
+	}
+
+	r.data.nextAPIKeyId++
+
+	stored := copyAPIKey(key)
+	stored.Id = r.data.nextAPIKeyId
+	stored.CreatedAt = r.now()
+	stored.LastUsedAt = nil
+	stored.RevokedAt = nil
+	r.data.apiKeys[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// GetAPIKeysByUserId retrieves the API keys of a user, revoked ones included, oldest first.
+func (r *Repository) GetAPIKeysByUserId(ctx context.Context, userId int) ([]*entity.APIKey, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	keys := make([]*entity.APIKey, 0)
+	for _, key := range r.data.apiKeys {
+		if key.UserId == userId {
+			c := copyAPIKey(&key)
+			keys = append(keys, &c)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Id < keys[j].Id
+	})
+
+	return keys, nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its public prefix.
+func (r *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, key := range r.data.apiKeys {
+		if key.Prefix == prefix {
+			c := copyAPIKey(&key)
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+// UpdateAPIKeyLastUsed records when an API key was last used.
+// Returns repository.ErrAPIKeyNotFound if the key does not exist.
+func (r *Repository) UpdateAPIKeyLastUsed(ctx context.Context, id int, usedAt time.Time) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	key, ok := r.data.apiKeys[id]
+	if !ok {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	// A new time value is allocated so that transaction snapshots never observe the change
+	key.LastUsedAt = &usedAt
+	r.data.apiKeys[id] = key
+	return nil
+}
+
+// RevokeAPIKey revokes an API key of a user. Revoked keys are kept for auditing.
+// Returns repository.ErrAPIKeyNotFound if the user has no unrevoked key with that ID.
+func (r *Repository) RevokeAPIKey(ctx context.Context, userId, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	key, ok := r.data.apiKeys[id]
+	if !ok || key.UserId != userId || key.RevokedAt != nil {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	now := r.now()
+	key.RevokedAt = &now
+	r.data.apiKeys[id] = key
+	return nil
+}
+
+// copyAPIKey returns a copy of key that shares no memory with it.
+func copyAPIKey(key *entity.APIKey) entity.APIKey {
+	c := *key
+	c.Scopes = slices.Clone(key.Scopes)
+	c.ExpiresAt = copyTime(key.ExpiresAt)
+	c.LastUsedAt = copyTime(key.LastUsedAt)
+	c.RevokedAt = copyTime(key.RevokedAt)
+	return c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertInvitation stores a new invitation and returns the created invitation ID.
+func (r *Repository) InsertInvitation(ctx context.Context, invitation *entity.Invitation) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Unique constraint
+	for _, existing := range r.data.invitations {
+		if existing.TokenHash == invitation.TokenHash {
+			return 0, fmt.Errorf("invitation token hash already exists")
+		}
+	}
+
+	r.data.nextInvitationId++
+
+	stored := *invitation
+	stored.Id = r.data.nextInvitationId
+	stored.UsedAt = nil
+	stored.CreatedAt = r.now()
+	r.data.invitations[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// GetInvitationByHash retrieves an invitation (accepted or not) by its token hash.
+func (r *Repository) GetInvitationByHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, invitation := range r.data.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, repository.ErrInvitationNotFound
+}
+
+// ConsumeInvitation marks an unused invitation as used.
+// Returns repository.ErrInvitationNotFound if no unused invitation matched.
+func (r *Repository) ConsumeInvitation(ctx context.Context, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	invitation, ok := r.data.invitations[id]
+	if !ok || invitation.IsUsed() {
+		return repository.ErrInvitationNotFound
+	}
+
+	// A new time value is allocated so that transaction snapshots never observe the change
+	now := r.now()
+	invitation.UsedAt = &now
+	r.data.invitations[id] = invitation
+	return nil
+}
+
+// DeleteInvitations deletes every invitation sent to the given email.
+func (r *Repository) DeleteInvitations(ctx context.Context, email string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for id, invitation := range r.data.invitations {
+		if invitation.Email == email {
+			delete(r.data.invitations, id)
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredInvitations removes invitations that can no longer be accepted.
+func (r *Repository) DeleteExpiredInvitations(ctx context.Context) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	now := r.now()
+	for id, invitation := range r.data.invitations {
+		if invitation.ExpiresAt.Before(now) {
+			delete(r.data.invitations, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== TOTP Credentials ==========
+
+// UpsertTOTPCredential stores a pending TOTP credential, replacing any credential of the user.
+func (r *Repository) UpsertTOTPCredential(ctx context.Context, credential *entity.TOTPCredential) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Foreign key constraint
+	if _, ok := r.data.users[credential.UserId]; !ok {
+		return fmt.Errorf("totp credential references unknown user %d: %w", credential.UserId, repository.ErrUserNotFound)
+	}
+
+	r.data.totpCredentials[credential.UserId] = entity.TOTPCredential{
+		UserId:    credential.UserId,
+		Secret:    credential.Secret,
+		CreatedAt: r.now(),
+	}
+	return nil
+}
+
+// GetTOTPCredential retrieves the TOTP credential (confirmed or not) of a user.
+func (r *Repository) GetTOTPCredential(ctx context.Context, userId int) (*entity.TOTPCredential, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	credential, ok := r.data.totpCredentials[userId]
+	if !ok {
+		return nil, repository.ErrTOTPCredentialNotFound
+	}
+	return &credential, nil
+}
+
+// ConfirmTOTPCredential completes a pending enrollment with the time step of its first code.
+// Returns repository.ErrTOTPCredentialNotFound if the user has no pending credential.
+func (r *Repository) ConfirmTOTPCredential(ctx context.Context, userId int, step int64) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	credential, ok := r.data.totpCredentials[userId]
+	if !ok || credential.IsConfirmed() {
+		return repository.ErrTOTPCredentialNotFound
+	}
+
+	// A new time value is allocated so that transaction snapshots never observe the change
+	now := r.now()
+	credential.ConfirmedAt = &now
+	credential.LastUsedStep = step
+	r.data.totpCredentials[userId] = credential
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code, so that the code cannot be replayed.
+// Returns repository.ErrTOTPCredentialNotFound if the user has no credential or step is not
+// newer than the last accepted one.
+func (r *Repository) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	credential, ok := r.data.totpCredentials[userId]
+	if !ok || credential.LastUsedStep >= step {
+		return repository.ErrTOTPCredentialNotFound
+	}
+
+	credential.LastUsedStep = step
+	r.data.totpCredentials[userId] = credential
+	return nil
+}
+
+// DeleteTOTPCredential removes the TOTP credential of a user.
+// Returns repository.ErrTOTPCredentialNotFound if the user has none.
+func (r *Repository) DeleteTOTPCredential(ctx context.Context, userId int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := r.data.totpCredentials[userId]; !ok {
+		return repository.ErrTOTPCredentialNotFound
+	}
+	delete(r.data.totpCredentials, userId)
+	return nil
+}
+
+// ========== Recovery Codes ==========
+
+// ReplaceRecoveryCodes replaces every recovery code of a user with the given code hashes.
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Foreign key constraint
+	if _, ok := r.data.users[userId]; !ok {
+		return fmt.Errorf("recovery code references unknown user %d: %w", userId, repository.ErrUserNotFound)
+	}
+
+	r.deleteRecoveryCodes(userId)
+	for _, hash := range codeHashes {
+		r.data.nextRecoveryId++
+		r.data.recoveryCodes[r.data.nextRecoveryId] = recoveryCode{userId: userId, codeHash: hash}
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code of a user as used.
+// Returns repository.ErrRecoveryCodeNotFound if no unused code matched.
+func (r *Repository) ConsumeRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for id, code := range r.data.recoveryCodes {
+		if code.userId == userId && code.codeHash == codeHash && code.usedAt == nil {
+			now := r.now()
+			code.usedAt = &now
+			r.data.recoveryCodes[id] = code
+			return nil
+		}
+	}
+	return repository.ErrRecoveryCodeNotFound
+}
+
+// CountRecoveryCodes returns the number of unused recovery codes of a user.
+func (r *Repository) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	count := 0
+	for _, code := range r.data.recoveryCodes {
+		if code.userId == userId && code.usedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteRecoveryCodes deletes every recovery code of a user.
+func (r *Repository) DeleteRecoveryCodes(ctx context.Context, userId int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	r.deleteRecoveryCodes(userId)
+	return nil
+}
+
+func (r *Repository) deleteRecoveryCodes(userId int) {
+	for id, code := range r.data.recoveryCodes {
+		if code.userId == userId {
+			delete(r.data.recoveryCodes, id)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Passkeys ==========
+
+// InsertPasskey stores a new passkey and returns the created passkey ID.
+// Returns repository.ErrDuplicatePasskey if the credential ID is already registered.
+func (r *Repository) InsertPasskey(ctx context.Context, passkey *entity.Passkey) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Foreign key and unique constraints
+	if _, ok := r.data.users[passkey.UserId]; !ok {
+		return 0, fmt.Errorf("passkey references unknown user %d: %w", passkey.UserId, repository.ErrUserNotFound)
+	}
+	for _, existing := range r.data.passkeys {
+		if bytes.Equal(existing.CredentialId, passkey.CredentialId) {
+			return 0, repository.ErrDuplicatePasskey
+		}
+	}
+
+	r.data.nextPasskeyId++
+
+	stored := copyPasskey(passkey)
+	stored.Id = r.data.nextPasskeyId
+	stored.CreatedAt = r.now()
+	stored.LastUsedAt = nil
+	r.data.passkeys[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// GetPasskeysByUserId retrieves the passkeys of a user, oldest first.
+func (r *Repository) GetPasskeysByUserId(ctx context.Context, userId int) ([]*entity.Passkey, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	passkeys := make([]*entity.Passkey, 0)
+	for _, passkey := range r.data.passkeys {
+		if passkey.UserId == userId {
+			c := copyPasskey(&passkey)
+			passkeys = append(passkeys, &c)
+		}
+	}
+	sort.Slice(passkeys, func(i, j int) bool {
+		return passkeys[i].Id < passkeys[j].Id
+	})
+
+	return passkeys, nil
+}
+
+// GetPasskeyByCredentialId retrieves a passkey by the credential ID of its authenticator.
+func (r *Repository) GetPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*entity.Passkey, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, passkey := range r.data.passkeys {
+		if bytes.Equal(passkey.CredentialId, credentialId) {
+			c := copyPasskey(&passkey)
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrPasskeyNotFound
+}
+
+// UpdatePasskeySignCount records a login with a passkey and its new signature counter.
+// Returns repository.ErrPasskeyNotFound if the passkey does not exist.
+func (r *Repository) UpdatePasskeySignCount(ctx context.Context, id int, signCount uint32) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	passkey, ok := r.data.passkeys[id]
+	if !ok {
+		return repository.ErrPasskeyNotFound
+	}
+
+	// A new time value is allocated so that transaction snapshots never observe the change
+	now := r.now()
+	passkey.SignCount = signCount
+	passkey.LastUsedAt = &now
+	r.data.passkeys[id] = passkey
+	return nil
+}
+
+// DeletePasskey deletes a passkey of a user.
+// Returns repository.ErrPasskeyNotFound if the user has no passkey with that ID.
+func (r *Repository) DeletePasskey(ctx context.Context, userId, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	passkey, ok := r.data.passkeys[id]
+	if !ok || passkey.UserId != userId {
+		return repository.ErrPasskeyNotFound
+	}
+	delete(r.data.passkeys, id)
+	return nil
+}
+
+// copyPasskey returns a copy of passkey that shares no memory with it.
+func copyPasskey(passkey *entity.Passkey) entity.Passkey {
+	c := *passkey
+	c.CredentialId = bytes.Clone(passkey.CredentialId)
+	c.PublicKey = bytes.Clone(passkey.PublicKey)
+	if passkey.LastUsedAt != nil {
+		t := *passkey.LastUsedAt
+		c.LastUsedAt = &t
+	}
+	return c
+}
+
+// ========== Passkey Challenges ==========
+
+// InsertPasskeyChallenge stores the challenge of a new ceremony and returns its ID.
+func (r *Repository) InsertPasskeyChallenge(ctx context.Context, challenge *entity.PasskeyChallenge) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Foreign key and unique constraints
+	if challenge.UserId != nil {
+		if _, ok := r.data.users[*challenge.UserId]; !ok {
+			return 0, fmt.Errorf("passkey challenge references unknown user %d: %w", *challenge.UserId, repository.ErrUserNotFound)
+		}
+	}
+	for _, existing := range r.data.passkeyChallenges {
+		if existing.ChallengeHash == challenge.ChallengeHash {
+			return 0, fmt.Errorf("passkey challenge hash already exists")
+		}
+	}
+
+	r.data.nextPasskeyChallengeId++
+
+	stored := *challenge
+	stored.Id = r.data.nextPasskeyChallengeId
+	if challenge.UserId != nil {
+		userId := *challenge.UserId
+		stored.UserId = &userId
+	}
+	stored.CreatedAt = r.now()
+	r.data.passkeyChallenges[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// ConsumePasskeyChallenge deletes a challenge, expired or not, and returns it, so that every
+// challenge is answered at most once.
+// Returns repository.ErrPasskeyChallengeNotFound if no challenge matched, which means it was
+// never issued or was already consumed (e.g. by a concurrent request).
+func (r *Repository) ConsumePasskeyChallenge(ctx context.Context, challengeHash string) (*entity.PasskeyChallenge, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for id, challenge := range r.data.passkeyChallenges {
+		if challenge.ChallengeHash == challengeHash {
+			delete(r.data.passkeyChallenges, id)
+			return &challenge, nil
+		}
+	}
+	return nil, repository.ErrPasskeyChallengeNotFound
+}
+
+// DeleteExpiredPasskeyChallenges removes challenges that can no longer be answered.
+func (r *Repository) DeleteExpiredPasskeyChallenges(ctx context.Context) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	now := r.now()
+	for id, challenge := range r.data.passkeyChallenges {
+		if challenge.ExpiresAt.Before(now) {
+			delete(r.data.passkeyChallenges, id)
+		}
+	}
+	return nil
+}
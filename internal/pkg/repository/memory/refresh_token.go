@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertRefreshToken stores a new refresh token and returns the created token ID.
+func (r *Repository) InsertRefreshToken(ctx context.Context, token *entity.RefreshToken) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Foreign key and unique constraints
+	if _, ok := r.data.users[token.UserId]; !ok {
+		return 0, fmt.Errorf("refresh token references unknown user %d: %w", token.UserId, repository.ErrUserNotFound)
+	}
+	for _, existing := range r.data.refreshTokens {
+		if existing.TokenHash == token.TokenHash {
+			return 0, fmt.Errorf("refresh token hash already exists")
+		}
+	}
+
+	r.data.nextTokenId++
+
+	stored := *token
+	stored.Id = r.data.nextTokenId
+	stored.RevokedAt = nil
+	stored.CreatedAt = r.now()
+	r.data.refreshTokens[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token (revoked or not) by its hash.
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, token := range r.data.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repository.ErrRefreshTokenNotFound
+}
+
+// RevokeRefreshToken revokes a single active refresh token.
+// Returns repository.ErrRefreshTokenNotFound if no active token matched,
+// which means the token was already revoked (e.g. by a concurrent rotation).
+func (r *Repository) RevokeRefreshToken(ctx context.Context, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	token, ok := r.data.refreshTokens[id]
+	if !ok || token.IsRevoked() {
+		return repository.ErrRefreshTokenNotFound
+	}
+
+	r.revoke(id, token)
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every active token in the given family.
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyId string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for id, token := range r.data.refreshTokens {
+		if token.FamilyId == familyId && !token.IsRevoked() {
+			r.revoke(id, token)
+		}
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every active refresh token of the given user.
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userId int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for id, token := range r.data.refreshTokens {
+		if token.UserId == userId && !token.IsRevoked() {
+			r.revoke(id, token)
+		}
+	}
+	return nil
+}
+
+// revoke marks a token revoked. A new time value is allocated so that snapshots taken
+// for transactions never observe the change. The caller must hold the lock.
+func (r *Repository) revoke(id int, token entity.RefreshToken) {
+	now := r.now()
+	token.RevokedAt = &now
+	r.data.refreshTokens[id] = token
+}
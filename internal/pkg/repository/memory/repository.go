@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+// Repository is a thread-safe in-memory implementation of the repository layer.
+// It mirrors the postgres implementation (errors, ordering, cascading deletes) and is meant
+// for tests and local development; all data is lost when the process exits.
+type Repository struct {
+	mu   sync.RWMutex
+	data *tables
+	now  func() time.Time
+}
+
+// tables holds the stored rows. Rows are stored by value so that callers never share memory
+// with the repository.
+type tables struct {
+	users            map[int]entity.User
+	nextUserId       int
+	refreshTokens    map[int]entity.RefreshToken
+	nextTokenId      int
+	revokedTokens    map[string]revokedToken
+	userTokens       map[int]entity.UserToken
+	nextUserTokenId  int
+	invitations      map[int]entity.Invitation
+	nextInvitationId int
+	totpCredentials  map[int]entity.TOTPCredential // by user ID
+	recoveryCodes    map[int]recoveryCode
+	nextRecoveryId   int
+
+	passkeys               map[int]entity.Passkey
+	nextPasskeyId          int
+	passkeyChallenges      map[int]entity.PasskeyChallenge
+	nextPasskeyChallengeId int
+
+	apiKeys      map[int]entity.APIKey
+	nextAPIKeyId int
+
+	roles map[string]entity.Role
+}
+
+type revokedToken struct {
+	userId    int
+	expiresAt time.Time
+}
+
+type recoveryCode struct {
+	userId   int
+	codeHash string
+	usedAt   *time.Time
+}
+
+// New creates a new Repository that holds only the built-in roles, like a freshly migrated database.
+func New() *Repository {
+	r := &Repository{
+		data: &tables{
+			users:           make(map[int]entity.User),
+			refreshTokens:   make(map[int]entity.RefreshToken),
+			revokedTokens:   make(map[string]revokedToken),
+			userTokens:      make(map[int]entity.UserToken),
+			invitations:     make(map[int]entity.Invitation),
+			totpCredentials: make(map[int]entity.TOTPCredential),
+			recoveryCodes:   make(map[int]recoveryCode),
+
+			passkeys:          make(map[int]entity.Passkey),
+			passkeyChallenges: make(map[int]entity.PasskeyChallenge),
+
+			apiKeys: make(map[int]entity.APIKey),
+
+			roles: make(map[string]entity.Role),
+		},
+		now: time.Now,
+	}
+	r.seedRoles()
+	return r
+}
+
+// Close is a no-op; it exists so that Repository can replace the postgres implementation.
+func (r *Repository) Close() error {
+	return nil
+}
+
+// ========== Transactions ==========
+
+// txKey marks a context that runs inside a transaction of the given repository.
+type txKey struct{}
+
+// RunInTx runs fn with exclusive access to the repository. Repository calls made with the
+// context passed to fn join the transaction; if fn returns an error every change is rolled back.
+// Nested calls join the outer transaction.
+func (r *Repository) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.inTx(ctx) {
+		return fn(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := r.data.clone()
+	defer func() {
+		if p := recover(); p != nil {
+			r.data = snapshot
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, r)); err != nil {
+		r.data = snapshot
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) inTx(ctx context.Context) bool {
+	owner, _ := ctx.Value(txKey{}).(*Repository)
+	return owner == r
+}
+
+// lock acquires the write lock, unless ctx belongs to a transaction that already holds it.
+func (r *Repository) lock(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r.inTx(ctx) {
+		return func() {}, nil
+	}
+	r.mu.Lock()
+	return r.mu.Unlock, nil
+}
+
+// rlock acquires the read lock, unless ctx belongs to a transaction that already holds the write lock.
+func (r *Repository) rlock(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r.inTx(ctx) {
+		return func() {}, nil
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock, nil
+}
+
+func (t *tables) clone() *tables {
+	c := &tables{
+		users:            make(map[int]entity.User, len(t.users)),
+		nextUserId:       t.nextUserId,
+		refreshTokens:    make(map[int]entity.RefreshToken, len(t.refreshTokens)),
+		nextTokenId:      t.nextTokenId,
+		revokedTokens:    make(map[string]revokedToken, len(t.revokedTokens)),
+		userTokens:       make(map[int]entity.UserToken, len(t.userTokens)),
+		nextUserTokenId:  t.nextUserTokenId,
+		invitations:      make(map[int]entity.Invitation, len(t.invitations)),
+		nextInvitationId: t.nextInvitationId,
+		totpCredentials:  make(map[int]entity.TOTPCredential, len(t.totpCredentials)),
+		recoveryCodes:    make(map[int]recoveryCode, len(t.recoveryCodes)),
+		nextRecoveryId:   t.nextRecoveryId,
+
+		passkeys:               make(map[int]entity.Passkey, len(t.passkeys)),
+		nextPasskeyId:          t.nextPasskeyId,
+		passkeyChallenges:      make(map[int]entity.PasskeyChallenge, len(t.passkeyChallenges)),
+		nextPasskeyChallengeId: t.nextPasskeyChallengeId,
+
+		apiKeys:      make(map[int]entity.APIKey, len(t.apiKeys)),
+		nextAPIKeyId: t.nextAPIKeyId,
+
+		roles: make(map[string]entity.Role, len(t.roles)),
+	}
+	for k, v := range t.users {
+		c.users[k] = v
+	}
+	for k, v := range t.refreshTokens {
+		c.refreshTokens[k] = v
+	}
+	for k, v := range t.revokedTokens {
+		c.revokedTokens[k] = v
+	}
+	for k, v := range t.userTokens {
+		c.userTokens[k] = v
+	}
+	for k, v := range t.invitations {
+		c.invitations[k] = v
+	}
+	for k, v := range t.totpCredentials {
+		c.totpCredentials[k] = v
+	}
+	for k, v := range t.recoveryCodes {
+		c.recoveryCodes[k] = v
+	}
+	for k, v := range t.passkeys {
+		c.passkeys[k] = v
+	}
+	for k, v := range t.passkeyChallenges {
+		c.passkeyChallenges[k] = v
+	}
+	for k, v := range t.apiKeys {
+		c.apiKeys[k] = v
+	}
+	for k, v := range t.roles {
+		c.roles[k] = v
+	}
+	return c
+}
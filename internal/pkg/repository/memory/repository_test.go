@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+func TestRepository_RunInTx_Commit(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+
+	err := repo.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := repo.InsertUser(ctx, &entity.User{Email: "test@example.com"})
+		return err
+	})
+
+	assert.NoError(t, err)
+	exists, _ := repo.ExistsUserByEmail(ctx, "test@example.com")
+	assert.True(t, exists)
+}
+
+func TestRepository_RunInTx_Rollback(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+	id := createTestUser(t, repo, "existing@example.com", true)
+	errFail := errors.New("fail")
+
+	err := repo.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := repo.InsertUser(ctx, &entity.User{Email: "new@example.com"}); err != nil {
+			return err
+		}
+		if err := repo.IncrementUserTokenVersion(ctx, id); err != nil {
+			return err
+		}
+		// Nested calls join the outer transaction
+		return repo.RunInTx(ctx, func(ctx context.Context) error {
+			return errFail
+		})
+	})
+
+	assert.ErrorIs(t, err, errFail)
+	exists, _ := repo.ExistsUserByEmail(ctx, "new@example.com")
+	assert.False(t, exists)
+	user, _ := repo.GetUserById(ctx, id)
+	assert.Equal(t, 0, user.TokenVersion)
+
+	// IDs handed out inside the rolled back transaction are reused
+	nextId := createTestUser(t, repo, "next@example.com", true)
+	assert.Equal(t, id+1, nextId)
+}
+
+func TestRepository_RunInTx_RollbackOnPanic(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+
+	assert.Panics(t, func() {
+		repo.RunInTx(ctx, func(ctx context.Context) error {
+			repo.InsertUser(ctx, &entity.User{Email: "test@example.com"})
+			panic("boom")
+		})
+	})
+
+	exists, _ := repo.ExistsUserByEmail(ctx, "test@example.com")
+	assert.False(t, exists)
+}
+
+func TestRepository_RefreshTokens(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+	userId := createTestUser(t, repo, "test@example.com", true)
+	expiresAt := time.Now().Add(time.Hour)
+
+	id, err := repo.InsertRefreshToken(ctx, &entity.RefreshToken{UserId: userId, FamilyId: "family", TokenHash: "hash-1", ExpiresAt: expiresAt})
+	assert.NoError(t, err)
+	_, err = repo.InsertRefreshToken(ctx, &entity.RefreshToken{UserId: userId, FamilyId: "family", TokenHash: "hash-2", ExpiresAt: expiresAt})
+	assert.NoError(t, err)
+
+	_, err = repo.InsertRefreshToken(ctx, &entity.RefreshToken{UserId: 99999, TokenHash: "hash-3", ExpiresAt: expiresAt})
+	assert.Error(t, err, "unknown user")
+
+	// A token can only be revoked once
+	assert.NoError(t, repo.RevokeRefreshToken(ctx, id))
+	assert.ErrorIs(t, repo.RevokeRefreshToken(ctx, id), repository.ErrRefreshTokenNotFound)
+
+	assert.NoError(t, repo.RevokeRefreshTokenFamily(ctx, "family"))
+	token, err := repo.GetRefreshTokenByHash(ctx, "hash-2")
+	assert.NoError(t, err)
+	assert.True(t, token.IsRevoked())
+}
+
+func TestRepository_RevokedTokens(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+	userId := createTestUser(t, repo, "test@example.com", true)
+
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "expired", userId, time.Now().Add(-time.Hour)))
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "active", userId, time.Now().Add(time.Hour)))
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "active", userId, time.Now().Add(time.Hour)), "idempotent")
+
+	repo.now = time.Now
+	assert.NoError(t, repo.DeleteExpiredRevokedTokens(ctx))
+
+	exists, _ := repo.ExistsRevokedToken(ctx, "expired")
+	assert.False(t, exists)
+	exists, _ = repo.ExistsRevokedToken(ctx, "active")
+	assert.True(t, exists)
+}
+
+func TestRepository_Concurrent(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			repo.InsertUser(ctx, &entity.User{Email: fmt.Sprintf("user%d@example.com", i), IsActive: true})
+			repo.GetUsers(ctx, 0, 10, true)
+			repo.RunInTx(ctx, func(ctx context.Context) error {
+				_, err := repo.GetUserCount(ctx, false)
+				return err
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := repo.GetUserCount(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, count)
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+// InsertRevokedToken adds an access token ID to the denylist.
+// Revoking an already revoked token is not an error.
+func (r *Repository) InsertRevokedToken(ctx context.Context, tokenId string, userId int, expiresAt time.Time) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, exists := r.data.revokedTokens[tokenId]; !exists {
+		r.data.revokedTokens[tokenId] = revokedToken{userId: userId, expiresAt: expiresAt}
+	}
+	return nil
+}
+
+// ExistsRevokedToken checks if an access token ID is on the denylist.
+func (r *Repository) ExistsRevokedToken(ctx context.Context, tokenId string) (bool, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	_, exists := r.data.revokedTokens[tokenId]
+	return exists, nil
+}
+
+// DeleteExpiredRevokedTokens removes denylist entries whose tokens have expired anyway.
+func (r *Repository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	now := r.now()
+	for tokenId, token := range r.data.revokedTokens {
+		if token.expiresAt.Before(now) {
+			delete(r.data.revokedTokens, tokenId)
+		}
+	}
+	return nil
+}
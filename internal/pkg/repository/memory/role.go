@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// seedRoles stores the built-in roles created by the schema migrations of the SQL backends.
+func (r *Repository) seedRoles() {
+	now := r.now()
+	for _, role := range []entity.Role{
+		{
+			Name:        entity.RoleAdmin,
+			Description: "Manages users and roles",
+			Permissions: entity.AllPermissions(),
+		},
+		{Name: entity.RoleUser, Description: "Regular user"},
+		{Name: entity.RoleViewer, Description: "Read-only user"},
+	} {
+		role.IsSystem = true
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		r.data.roles[role.Name] = copyRole(&role)
+	}
+}
+
+// GetRoles retrieves all roles with their permissions, ordered by name.
+func (r *Repository) GetRoles(ctx context.Context) ([]*entity.Role, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	roles := make([]*entity.Role, 0, len(r.data.roles))
+	for _, role := range r.data.roles {
+		c := copyRole(&role)
+		roles = append(roles, &c)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+// GetRoleByName retrieves a role with its permissions.
+func (r *Repository) GetRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	role, ok := r.data.roles[name]
+	if !ok {
+		return nil, repository.ErrRoleNotFound
+	}
+	c := copyRole(&role)
+	return &c, nil
+}
+
+// InsertRole stores a new role with its permissions. Roles created at runtime are never system roles.
+// Returns repository.ErrDuplicateRole if a role with that name exists.
+func (r *Repository) InsertRole(ctx context.Context, role *entity.Role) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := r.data.roles[role.Name]; ok {
+		return repository.ErrDuplicateRole
+	}
+
+	stored := copyRole(role)
+	stored.IsSystem = false
+	stored.CreatedAt = r.now()
+	stored.UpdatedAt = stored.CreatedAt
+	r.data.roles[stored.Name] = stored
+	return nil
+}
+
+// UpdateRole updates the description of a role and replaces its permissions.
+// Returns repository.ErrRoleNotFound if the role does not exist.
+func (r *Repository) UpdateRole(ctx context.Context, role *entity.Role) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, ok := r.data.roles[role.Name]
+	if !ok {
+		return repository.ErrRoleNotFound
+	}
+
+	updated := copyRole(role)
+	stored.Description = updated.Description
+	stored.Permissions = updated.Permissions
+	stored.UpdatedAt = r.now()
+	r.data.roles[role.Name] = stored
+	return nil
+}
+
+// DeleteRole deletes a role and its permissions.
+// Returns repository.ErrRoleInUse if a user or a pending invitation has the role,
+// and repository.ErrRoleNotFound if the role does not exist.
+func (r *Repository) DeleteRole(ctx context.Context, name string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, user := range r.data.users {
+		if user.Role == name {
+			return repository.ErrRoleInUse
+		}
+	}
+	now := r.now()
+	for _, invitation := range r.data.invitations {
+		if invitation.Role == name && invitation.UsedAt == nil && invitation.ExpiresAt.After(now) {
+			return repository.ErrRoleInUse
+		}
+	}
+
+	if _, ok := r.data.roles[name]; !ok {
+		return repository.ErrRoleNotFound
+	}
+	delete(r.data.roles, name)
+	return nil
+}
+
+// copyRole returns a copy of role that shares no memory with it, with sorted, distinct permissions.
+func copyRole(role *entity.Role) entity.Role {
+	c := *role
+	c.Permissions = append(make([]string, 0, len(role.Permissions)), role.Permissions...)
+	slices.Sort(c.Permissions)
+	c.Permissions = slices.Compact(c.Permissions)
+	return c
+}
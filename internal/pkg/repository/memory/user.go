@@ -0,0 +1,354 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertUser creates a new user and returns the created user ID.
+func (r *Repository) InsertUser(ctx context.Context, user *entity.User) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if r.emailTaken(user.Email, 0) {
+		return 0, repository.ErrDuplicateEmail
+	}
+
+	r.data.nextUserId++
+	now := r.now()
+
+	stored := copyUser(user)
+	stored.Id = r.data.nextUserId
+	stored.TokenVersion = 0
+	stored.FailedLoginAttempts = 0
+	stored.LockedUntil = nil
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	r.data.users[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// GetUserById retrieves a user by ID.
+func (r *Repository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	user, ok := r.data.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	c := copyUser(&user)
+	return &c, nil
+}
+
+// GetUserByEmail retrieves a user by email.
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, user := range r.data.users {
+		if user.Email == email {
+			c := copyUser(&user)
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+// GetUsers retrieves users with pagination, newest first.
+// A non-positive limit returns every user after offset.
+func (r *Repository) GetUsers(ctx context.Context, offset, limit int, onlyActive bool) ([]*entity.User, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	users := make([]*entity.User, 0, len(r.data.users))
+	for _, user := range r.data.users {
+		if onlyActive && !user.IsActive {
+			continue
+		}
+		c := copyUser(&user)
+		users = append(users, &c)
+	}
+
+	// ORDER BY created_at DESC; ties are broken by ID so that pages are stable
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.After(users[j].CreatedAt)
+		}
+		return users[i].Id > users[j].Id
+	})
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(users) {
+		return make([]*entity.User, 0), nil
+	}
+	users = users[offset:]
+
+	if limit > 0 && limit < len(users) {
+		users = users[:limit]
+	}
+
+	return users, nil
+}
+
+// GetUserCount returns the total number of users.
+func (r *Repository) GetUserCount(ctx context.Context, onlyActive bool) (int, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	count := 0
+	for _, user := range r.data.users {
+		if !onlyActive || user.IsActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// UpdateUser updates an existing user.
+// Deactivating a user also increments their token version so that reactivation
+// does not bring previously issued tokens back to life.
+func (r *Repository) UpdateUser(ctx context.Context, user *entity.User) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, ok := r.data.users[user.Id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if r.emailTaken(user.Email, user.Id) {
+		return repository.ErrDuplicateEmail
+	}
+
+	if stored.IsActive && !user.IsActive {
+		stored.TokenVersion++
+	}
+	stored.Email = user.Email
+	stored.Username = user.Username
+	stored.Name = user.Name
+	stored.Role = user.Role
+	stored.IsActive = user.IsActive
+	updated := copyUser(user)
+	stored.EmailVerifiedAt = updated.EmailVerifiedAt
+	stored.PendingEmail = updated.PendingEmail
+	stored.UpdatedAt = r.now()
+	r.data.users[user.Id] = stored
+
+	return nil
+}
+
+// UpdateUserPassword updates a user's password.
+func (r *Repository) UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, ok := r.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	stored.Password = hashedPassword
+	stored.UpdatedAt = r.now()
+	r.data.users[id] = stored
+
+	return nil
+}
+
+// IncrementUserTokenVersion increments a user's token version, revoking all access tokens issued before.
+func (r *Repository) IncrementUserTokenVersion(ctx context.Context, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, ok := r.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	stored.TokenVersion++
+	stored.UpdatedAt = r.now()
+	r.data.users[id] = stored
+
+	return nil
+}
+
+// IncrementFailedLoginAttempts records a failed login and returns the number of consecutive failures.
+func (r *Repository) IncrementFailedLoginAttempts(ctx context.Context, id int) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	stored, ok := r.data.users[id]
+	if !ok {
+		return 0, repository.ErrUserNotFound
+	}
+
+	stored.FailedLoginAttempts++
+	r.data.users[id] = stored
+
+	return stored.FailedLoginAttempts, nil
+}
+
+// LockUser refuses logins of a user until the given time.
+func (r *Repository) LockUser(ctx context.Context, id int, until time.Time) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, ok := r.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	stored.LockedUntil = &until
+	r.data.users[id] = stored
+
+	return nil
+}
+
+// ResetFailedLoginAttempts clears the failed login count and any lock of a user.
+func (r *Repository) ResetFailedLoginAttempts(ctx context.Context, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stored, ok := r.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	stored.FailedLoginAttempts = 0
+	stored.LockedUntil = nil
+	r.data.users[id] = stored
+
+	return nil
+}
+
+// DeleteUserById deletes a user by ID, together with their tokens.
+func (r *Repository) DeleteUserById(ctx context.Context, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := r.data.users[id]; !ok {
+		return repository.ErrUserNotFound
+	}
+	delete(r.data.users, id)
+
+	// ON DELETE CASCADE
+	for tokenId, token := range r.data.refreshTokens {
+		if token.UserId == id {
+			delete(r.data.refreshTokens, tokenId)
+		}
+	}
+	for tokenId, token := range r.data.revokedTokens {
+		if token.userId == id {
+			delete(r.data.revokedTokens, tokenId)
+		}
+	}
+	for tokenId, token := range r.data.userTokens {
+		if token.UserId == id {
+			delete(r.data.userTokens, tokenId)
+		}
+	}
+	delete(r.data.totpCredentials, id)
+	for codeId, code := range r.data.recoveryCodes {
+		if code.userId == id {
+			delete(r.data.recoveryCodes, codeId)
+		}
+	}
+	for passkeyId, passkey := range r.data.passkeys {
+		if passkey.UserId == id {
+			delete(r.data.passkeys, passkeyId)
+		}
+	}
+	for challengeId, challenge := range r.data.passkeyChallenges {
+		if challenge.UserId != nil && *challenge.UserId == id {
+			delete(r.data.passkeyChallenges, challengeId)
+		}
+	}
+	for keyId, key := range r.data.apiKeys {
+		if key.UserId == id {
+			delete(r.data.apiKeys, keyId)
+		}
+	}
+
+	return nil
+}
+
+// copyUser returns a copy of user that shares no memory with it, pointer fields included.
+func copyUser(user *entity.User) entity.User {
+	c := *user
+	if user.EmailVerifiedAt != nil {
+		t := *user.EmailVerifiedAt
+		c.EmailVerifiedAt = &t
+	}
+	if user.PendingEmail != nil {
+		e := *user.PendingEmail
+		c.PendingEmail = &e
+	}
+	if user.LockedUntil != nil {
+		t := *user.LockedUntil
+		c.LockedUntil = &t
+	}
+	return c
+}
+
+// ExistsUserByEmail checks if a user with the given email exists.
+func (r *Repository) ExistsUserByEmail(ctx context.Context, email string) (bool, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	return r.emailTaken(email, 0), nil
+}
+
+// emailTaken reports whether a user other than exceptId uses email. The caller must hold the lock.
+func (r *Repository) emailTaken(email string, exceptId int) bool {
+	for id, user := range r.data.users {
+		if id != exceptId && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Test Helpers ==========
+
+// setupTestRepo creates a repository whose clock advances by one second per call,
+// so that rows inserted in sequence get distinct timestamps.
+func setupTestRepo() *Repository {
+	repo := New()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return repo
+}
+
+func createTestUser(t *testing.T, repo *Repository, email string, isActive bool) int {
+	t.Helper()
+
+	id, err := repo.InsertUser(context.Background(), &entity.User{
+		Email:    email,
+		Username: email,
+		Password: "hashed_password",
+		Name:     "Test User",
+		Role:     entity.RoleUser,
+		IsActive: isActive,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	return id
+}
+
+// ========== User Tests ==========
+
+func TestRepository_InsertUser(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+
+	input := &entity.User{Email: "test@example.com", Username: "testuser", Role: entity.RoleUser, IsActive: true}
+	id, err := repo.InsertUser(ctx, input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Zero(t, input.Id, "input must not be modified")
+
+	user, err := repo.GetUserById(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", user.Email)
+	assert.False(t, user.CreatedAt.IsZero())
+	assert.Equal(t, user.CreatedAt, user.UpdatedAt)
+}
+
+func TestRepository_GetUserById_ReturnsCopy(t *testing.T) {
+	repo := setupTestRepo()
+	id := createTestUser(t, repo, "test@example.com", true)
+
+	user, _ := repo.GetUserById(context.Background(), id)
+	user.Email = "changed@example.com"
+
+	stored, _ := repo.GetUserById(context.Background(), id)
+	assert.Equal(t, "test@example.com", stored.Email)
+}
+
+func TestRepository_GetUsers(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+
+	first := createTestUser(t, repo, "first@example.com", true)
+	second := createTestUser(t, repo, "second@example.com", false)
+	third := createTestUser(t, repo, "third@example.com", true)
+
+	tests := []struct {
+		name       string
+		offset     int
+		limit      int
+		onlyActive bool
+		wantIds    []int
+	}{
+		{name: "newest first", offset: 0, limit: 10, wantIds: []int{third, second, first}},
+		{name: "only active", offset: 0, limit: 10, onlyActive: true, wantIds: []int{third, first}},
+		{name: "limit", offset: 0, limit: 2, wantIds: []int{third, second}},
+		{name: "offset", offset: 1, limit: 10, wantIds: []int{second, first}},
+		{name: "no limit", offset: 1, limit: 0, wantIds: []int{second, first}},
+		{name: "offset past end", offset: 5, limit: 10, wantIds: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := repo.GetUsers(ctx, tt.offset, tt.limit, tt.onlyActive)
+
+			assert.NoError(t, err)
+			ids := make([]int, 0, len(users))
+			for _, u := range users {
+				ids = append(ids, u.Id)
+			}
+			assert.Equal(t, tt.wantIds, ids)
+		})
+	}
+
+	count, err := repo.GetUserCount(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = repo.GetUserCount(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestRepository_DeleteUserById(t *testing.T) {
+	repo := setupTestRepo()
+	ctx := context.Background()
+	id := createTestUser(t, repo, "test@example.com", true)
+
+	_, err := repo.InsertRefreshToken(ctx, &entity.RefreshToken{UserId: id, FamilyId: "family", TokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "jti", id, time.Now().Add(time.Hour)))
+
+	assert.NoError(t, repo.DeleteUserById(ctx, id))
+
+	_, err = repo.GetUserById(ctx, id)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	_, err = repo.GetRefreshTokenByHash(ctx, "hash")
+	assert.ErrorIs(t, err, repository.ErrRefreshTokenNotFound)
+	revoked, _ := repo.ExistsRevokedToken(ctx, "jti")
+	assert.False(t, revoked)
+
+	assert.ErrorIs(t, repo.DeleteUserById(ctx, id), repository.ErrUserNotFound)
+}
+
+func TestRepository_CanceledContext(t *testing.T) {
+	repo := setupTestRepo()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.GetUserById(ctx, 1)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertUserToken stores a new user token and returns the created token ID.
+func (r *Repository) InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Foreign key and unique constraints
+	if _, ok := r.data.users[token.UserId]; !ok {
+		return 0, fmt.Errorf("user token references unknown user %d: %w", token.UserId, repository.ErrUserNotFound)
+	}
+	for _, existing := range r.data.userTokens {
+		if existing.TokenHash == token.TokenHash {
+			return 0, fmt.Errorf("user token hash already exists")
+		}
+	}
+
+	r.data.nextUserTokenId++
+
+	stored := *token
+	stored.Id = r.data.nextUserTokenId
+	stored.UsedAt = nil
+	stored.CreatedAt = r.now()
+	r.data.userTokens[stored.Id] = stored
+
+	return stored.Id, nil
+}
+
+// GetUserTokenByHash retrieves a user token (used or not) by its hash.
+func (r *Repository) GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error) {
+	unlock, err := r.rlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, token := range r.data.userTokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, repository.ErrUserTokenNotFound
+}
+
+// ConsumeUserToken marks an unused user token as used.
+// Returns repository.ErrUserTokenNotFound if no unused token matched,
+// which means the token was already used (e.g. by a concurrent request).
+func (r *Repository) ConsumeUserToken(ctx context.Context, id int) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	token, ok := r.data.userTokens[id]
+	if !ok || token.IsUsed() {
+		return repository.ErrUserTokenNotFound
+	}
+
+	// A new time value is allocated so that transaction snapshots never observe the change
+	now := r.now()
+	token.UsedAt = &now
+	r.data.userTokens[id] = token
+	return nil
+}
+
+// DeleteUserTokens deletes every token of the given user issued for the given purpose.
+func (r *Repository) DeleteUserTokens(ctx context.Context, userId int, purpose string) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for id, token := range r.data.userTokens {
+		if token.UserId == userId && token.Purpose == purpose {
+			delete(r.data.userTokens, id)
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredUserTokens removes user tokens that can no longer be redeemed.
+func (r *Repository) DeleteExpiredUserTokens(ctx context.Context) error {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	now := r.now()
+	for id, token := range r.data.userTokens {
+		if token.ExpiresAt.Before(now) {
+			delete(r.data.userTokens, id)
+		}
+	}
+	return nil
+}
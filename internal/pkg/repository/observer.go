@@ -0,0 +1,7 @@
+package repository
+
+import "time"
+
+// QueryObserver is called by the SQL repositories with the name and duration of every
+// repository method, e.g. to record query latency metrics. It must be safe for concurrent use.
+type QueryObserver func(method string, duration time.Duration)
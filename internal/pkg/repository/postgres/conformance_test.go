@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/your-org/go-backend-template/internal/pkg/repository/repositorytest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	repositorytest.TestUserRepository(t, func(t *testing.T) repositorytest.UserRepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
+
+func TestRepository_UserTokenConformance(t *testing.T) {
+	repositorytest.TestUserTokenRepository(t, func(t *testing.T) repositorytest.UserTokenRepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
+
+func TestRepository_InvitationConformance(t *testing.T) {
+	repositorytest.TestInvitationRepository(t, func(t *testing.T) repositorytest.InvitationRepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
+
+func TestRepository_MFAConformance(t *testing.T) {
+	repositorytest.TestMFARepository(t, func(t *testing.T) repositorytest.MFARepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
+
+func TestRepository_PasskeyConformance(t *testing.T) {
+	repositorytest.TestPasskeyRepository(t, func(t *testing.T) repositorytest.PasskeyRepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
+
+func TestRepository_APIKeyConformance(t *testing.T) {
+	repositorytest.TestAPIKeyRepository(t, func(t *testing.T) repositorytest.APIKeyRepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
+
+func TestRepository_RoleConformance(t *testing.T) {
+	repositorytest.TestRoleRepository(t, func(t *testing.T) repositorytest.RoleRepository {
+		repo := setupTestDB(t)
+		t.Cleanup(repo.cleanup)
+		return repo
+	})
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== TOTP Credentials ==========
+
+// UpsertTOTPCredential stores a pending TOTP credential, replacing any credential of the user.
+func (r *Repository) UpsertTOTPCredential(ctx context.Context, credential *entity.TOTPCredential) error {
+	ctx, cancel := r.GetContext(ctx, "UpsertTOTPCredential")
+	defer cancel()
+
+	query := `
+		INSERT INTO totp_credentials (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0, created_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, credential.UserId, credential.Secret)
+	return err
+}
+
+// GetTOTPCredential retrieves the TOTP credential (confirmed or not) of a user.
+func (r *Repository) GetTOTPCredential(ctx context.Context, userId int) (*entity.TOTPCredential, error) {
+	ctx, cancel := r.GetContext(ctx, "GetTOTPCredential")
+	defer cancel()
+
+	query := `
+		SELECT user_id, secret, confirmed_at, last_used_step, created_at
+		FROM totp_credentials
+		WHERE user_id = $1
+	`
+
+	credential := &entity.TOTPCredential{}
+	err := r.conn(ctx).QueryRowContext(ctx, query, userId).Scan(
+		&credential.UserId,
+		&credential.Secret,
+		&credential.ConfirmedAt,
+		&credential.LastUsedStep,
+		&credential.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrTOTPCredentialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// ConfirmTOTPCredential completes a pending enrollment with the time step of its first code.
+// Returns repository.ErrTOTPCredentialNotFound if the user has no pending credential.
+func (r *Repository) ConfirmTOTPCredential(ctx context.Context, userId int, step int64) error {
+	ctx, cancel := r.GetContext(ctx, "ConfirmTOTPCredential")
+	defer cancel()
+
+	query := `
+		UPDATE totp_credentials
+		SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL
+	`
+
+	return r.execTOTPUpdate(ctx, query, userId, step)
+}
+
+// UseTOTPStep records the time step of an accepted code, so that the code cannot be replayed.
+// Returns repository.ErrTOTPCredentialNotFound if the user has no credential or step is not
+// newer than the last accepted one.
+func (r *Repository) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	ctx, cancel := r.GetContext(ctx, "UseTOTPStep")
+	defer cancel()
+
+	query := `
+		UPDATE totp_credentials
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+
+	return r.execTOTPUpdate(ctx, query, userId, step)
+}
+
+// DeleteTOTPCredential removes the TOTP credential of a user.
+// Returns repository.ErrTOTPCredentialNotFound if the user has none.
+func (r *Repository) DeleteTOTPCredential(ctx context.Context, userId int) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteTOTPCredential")
+	defer cancel()
+
+	query := `DELETE FROM totp_credentials WHERE user_id = $1`
+
+	return r.execTOTPUpdate(ctx, query, userId)
+}
+
+func (r *Repository) execTOTPUpdate(ctx context.Context, query string, args ...any) error {
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrTOTPCredentialNotFound
+	}
+
+	return nil
+}
+
+// ========== Recovery Codes ==========
+
+// ReplaceRecoveryCodes replaces every recovery code of a user with the given code hashes.
+// Should run inside a transaction, so that a failure does not leave the user without codes.
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	ctx, cancel := r.GetContext(ctx, "ReplaceRecoveryCodes")
+	defer cancel()
+
+	if _, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+	for _, hash := range codeHashes {
+		if _, err := r.conn(ctx).ExecContext(ctx, query, userId, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code of a user as used.
+// Returns repository.ErrRecoveryCodeNotFound if no unused code matched.
+func (r *Repository) ConsumeRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	ctx, cancel := r.GetContext(ctx, "ConsumeRecoveryCode")
+	defer cancel()
+
+	query := `
+		UPDATE recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, userId, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// CountRecoveryCodes returns the number of unused recovery codes of a user.
+func (r *Repository) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "CountRecoveryCodes")
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	if err := r.conn(ctx).QueryRowContext(ctx, query, userId).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteRecoveryCodes deletes every recovery code of a user.
+func (r *Repository) DeleteRecoveryCodes(ctx context.Context, userId int) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteRecoveryCodes")
+	defer cancel()
+
+	query := `DELETE FROM recovery_codes WHERE user_id = $1`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, userId)
+	return err
+}
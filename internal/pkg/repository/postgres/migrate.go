@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strconv"
+
+	"github.com/your-org/go-backend-template/internal/pkg/migrate"
+)
+
+// Schema changes live in migrations/ as "<version>_<name>.<up|down>.sql".
+// Applied migrations must never be edited; add a new version instead.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockKey identifies the advisory lock held while migrating.
+// Any constant works as long as every replica uses the same one.
+const migrationLockKey int64 = 4283015610937162
+
+// dialect implements migrate.IDialect for PostgreSQL.
+type dialect struct{}
+
+func (dialect) Lock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey)
+	return err
+}
+
+func (dialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	return err
+}
+
+func (dialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Migrations returns the embedded schema migrations.
+func Migrations() ([]migrate.Migration, error) {
+	return migrate.Load(migrationsFS, "migrations")
+}
+
+// Migrator returns a migrator for the embedded schema migrations.
+func (r *Repository) Migrator() (*migrate.Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrate.New(r.db, dialect{}, migrations)
+}
+
+// Migrate applies all pending migrations.
+func (r *Repository) Migrate(ctx context.Context) error {
+	migrator, err := r.Migrator()
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(ctx)
+	return err
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations_Embedded(t *testing.T) {
+	migrations, err := Migrations()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "migration versions must be contiguous")
+		assert.NotEmpty(t, m.Down, "migration %d_%s has no down script", m.Version, m.Name)
+	}
+}
+
+func TestDialect_Placeholder(t *testing.T) {
+	assert.Equal(t, "$1", dialect{}.Placeholder(1))
+	assert.Equal(t, "$12", dialect{}.Placeholder(12))
+}
+
+func TestMigrations_BackfillEmailVerifiedAt(t *testing.T) {
+	repo := setupTestDB(t)
+	t.Cleanup(repo.cleanup)
+	ctx := context.Background()
+
+	migrator, err := repo.Migrator()
+	if err != nil {
+		t.Fatalf("Failed to create migrator: %v", err)
+	}
+
+	// Roll back to before 000005_add_email_verification and create a user there
+	migrations, _ := Migrations()
+	if _, err := migrator.Down(ctx, len(migrations)-4); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := repo.db.ExecContext(ctx, `
+		INSERT INTO users (email, username, password, name, role, is_active, created_at, updated_at)
+		VALUES ('old@example.com', 'old', 'hashed_password', 'Old User', 'user', true, $1, $1)`, createdAt); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+
+	user, err := repo.GetUserByEmail(ctx, "old@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if assert.NotNil(t, user.EmailVerifiedAt) {
+		assert.True(t, createdAt.Equal(*user.EmailVerifiedAt))
+	}
+}
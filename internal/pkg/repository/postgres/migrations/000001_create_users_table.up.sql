@@ -1,8 +1,3 @@
-package postgres
-
-// Database schema definitions
-
-const createUsersTableQuery = `
 CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     email VARCHAR(255) NOT NULL UNIQUE,
@@ -17,9 +12,3 @@ CREATE TABLE IF NOT EXISTS users (
 
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
 CREATE INDEX IF NOT EXISTS idx_users_is_active ON users(is_active);
-`
-
-// Add more table queries here as needed:
-
-// const createOrdersTableQuery = `...`
-// const createProductsTableQuery = `...`
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);
+
+-- Accounts created before email verification existed keep signing in
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
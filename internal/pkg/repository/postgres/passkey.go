@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// ========== Passkeys ==========
+
+// InsertPasskey stores a new passkey and returns the created passkey ID.
+// Returns repository.ErrDuplicatePasskey if the credential ID is already registered.
+func (r *Repository) InsertPasskey(ctx context.Context, passkey *entity.Passkey) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertPasskey")
+	defer cancel()
+
+	query := `
+		INSERT INTO passkeys (user_id, credential_id, public_key, sign_count, name)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		passkey.UserId,
+		passkey.CredentialId,
+		passkey.PublicKey,
+		int64(passkey.SignCount),
+		passkey.Name,
+	).Scan(&id)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint") ||
+			strings.Contains(err.Error(), "duplicate key") {
+			return 0, repository.ErrDuplicatePasskey
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPasskeysByUserId retrieves the passkeys of a user, oldest first.
+func (r *Repository) GetPasskeysByUserId(ctx context.Context, userId int) ([]*entity.Passkey, error) {
+	ctx, cancel := r.GetContext(ctx, "GetPasskeysByUserId")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, credential_id, public_key, sign_count, name, created_at, last_used_at
+		FROM passkeys
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passkeys := make([]*entity.Passkey, 0)
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, passkey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return passkeys, nil
+}
+
+// GetPasskeyByCredentialId retrieves a passkey by the credential ID of its authenticator.
+func (r *Repository) GetPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*entity.Passkey, error) {
+	ctx, cancel := r.GetContext(ctx, "GetPasskeyByCredentialId")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, credential_id, public_key, sign_count, name, created_at, last_used_at
+		FROM passkeys
+		WHERE credential_id = $1
+	`
+
+	passkey, err := scanPasskey(r.conn(ctx).QueryRowContext(ctx, query, credentialId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrPasskeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return passkey, nil
+}
+
+// UpdatePasskeySignCount records a login with a passkey and its new signature counter.
+// Returns repository.ErrPasskeyNotFound if the passkey does not exist.
+func (r *Repository) UpdatePasskeySignCount(ctx context.Context, id int, signCount uint32) error {
+	ctx, cancel := r.GetContext(ctx, "UpdatePasskeySignCount")
+	defer cancel()
+
+	query := `
+		UPDATE passkeys
+		SET sign_count = $2, last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	return r.execPasskeyUpdate(ctx, query, id, int64(signCount))
+}
+
+// DeletePasskey deletes a passkey of a user.
+// Returns repository.ErrPasskeyNotFound if the user has no passkey with that ID.
+func (r *Repository) DeletePasskey(ctx context.Context, userId, id int) error {
+	ctx, cancel := r.GetContext(ctx, "DeletePasskey")
+	defer cancel()
+
+	query := `DELETE FROM passkeys WHERE id = $1 AND user_id = $2`
+
+	return r.execPasskeyUpdate(ctx, query, id, userId)
+}
+
+func (r *Repository) execPasskeyUpdate(ctx context.Context, query string, args ...any) error {
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrPasskeyNotFound
+	}
+
+	return nil
+}
+
+func scanPasskey(row interface{ Scan(dest ...any) error }) (*entity.Passkey, error) {
+	passkey := &entity.Passkey{}
+	var signCount int64
+	err := row.Scan(
+		&passkey.Id,
+		&passkey.UserId,
+		&passkey.CredentialId,
+		&passkey.PublicKey,
+		&signCount,
+		&passkey.Name,
+		&passkey.CreatedAt,
+		&passkey.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	passkey.SignCount = uint32(signCount)
+	return passkey, nil
+}
+
+// ========== Passkey Challenges ==========
+
+// InsertPasskeyChallenge stores the challenge of a new ceremony and returns its ID.
+func (r *Repository) InsertPasskeyChallenge(ctx context.Context, challenge *entity.PasskeyChallenge) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertPasskeyChallenge")
+	defer cancel()
+
+	query := `
+		INSERT INTO passkey_challenges (user_id, purpose, challenge_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		challenge.UserId,
+		challenge.Purpose,
+		challenge.ChallengeHash,
+		challenge.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ConsumePasskeyChallenge deletes a challenge, expired or not, and returns it, so that every
+// challenge is answered at most once.
+// Returns repository.ErrPasskeyChallengeNotFound if no challenge matched, which means it was
+// never issued or was already consumed (e.g. by a concurrent request).
+func (r *Repository) ConsumePasskeyChallenge(ctx context.Context, challengeHash string) (*entity.PasskeyChallenge, error) {
+	ctx, cancel := r.GetContext(ctx, "ConsumePasskeyChallenge")
+	defer cancel()
+
+	query := `
+		DELETE FROM passkey_challenges
+		WHERE challenge_hash = $1
+		RETURNING id, user_id, purpose, challenge_hash, expires_at, created_at
+	`
+
+	challenge := &entity.PasskeyChallenge{}
+	err := r.conn(ctx).QueryRowContext(ctx, query, challengeHash).Scan(
+		&challenge.Id,
+		&challenge.UserId,
+		&challenge.Purpose,
+		&challenge.ChallengeHash,
+		&challenge.ExpiresAt,
+		&challenge.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrPasskeyChallengeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// DeleteExpiredPasskeyChallenges removes challenges that can no longer be answered.
+func (r *Repository) DeleteExpiredPasskeyChallenges(ctx context.Context) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteExpiredPasskeyChallenges")
+	defer cancel()
+
+	query := `DELETE FROM passkey_challenges WHERE expires_at < CURRENT_TIMESTAMP`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query)
+	return err
+}
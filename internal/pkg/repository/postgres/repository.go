@@ -8,6 +8,7 @@ import (
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
 )
 
 var (
@@ -20,6 +21,7 @@ var (
 	connectTimeout   = 10 // seconds
 	operationTimeout = 20 // seconds
 	driverPostgres   = "pgx"
+	dbSystem         = "postgresql" // db.system span attribute
 )
 
 // Config holds database connection configuration.
@@ -30,6 +32,8 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string // disable, require, verify-ca, verify-full
+
+	QueryObserver repository.QueryObserver // optional; receives the duration of every repository method
 }
 
 // Validate checks if the configuration is valid.
@@ -54,7 +58,8 @@ func (c *Config) Validate() error {
 
 // Repository provides database access methods.
 type Repository struct {
-	db *sql.DB
+	db           *sql.DB
+	observeQuery repository.QueryObserver
 }
 
 // New creates a new Repository instance with the given configuration.
@@ -97,7 +102,7 @@ func New(config *Config) (*Repository, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Repository{db: db}, nil
+	return &Repository{db: db, observeQuery: config.QueryObserver}, nil
 }
 
 // Close closes the database connection.
@@ -105,30 +110,25 @@ func (r *Repository) Close() error {
 	return r.db.Close()
 }
 
-// GetContext returns a context with operation timeout.
-func (r *Repository) GetContext() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), time.Duration(operationTimeout)*time.Second)
-}
-
-// DB returns the underlying database connection for transactions.
-func (r *Repository) DB() *sql.DB {
-	return r.db
-}
-
-// CreateTables creates all required tables.
-func (r *Repository) CreateTables() error {
-	queries := []string{
-		createUsersTableQuery,
+// GetContext returns a child of ctx bounded by the operation timeout for the named repository method.
+// Cancelling ctx (e.g. when the client disconnects) cancels the query.
+// The returned cancel func also reports the duration of the method to the query observer,
+// and the method names the spans of its statements when ctx is traced.
+func (r *Repository) GetContext(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(repository.TraceMethod(ctx, method), time.Duration(operationTimeout)*time.Second)
+	if r.observeQuery == nil {
+		return ctx, cancel
 	}
 
-	ctx, cancel := r.GetContext()
-	defer cancel()
-
-	for _, query := range queries {
-		if _, err := r.db.ExecContext(ctx, query); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
-		}
+	start := time.Now()
+	return ctx, func() {
+		cancel()
+		r.observeQuery(method, time.Since(start))
 	}
+}
 
-	return nil
+// DB returns the underlying database connection.
+// Use TxManager to run repository methods inside a transaction.
+func (r *Repository) DB() *sql.DB {
+	return r.db
 }
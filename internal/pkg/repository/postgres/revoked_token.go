@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+// InsertRevokedToken adds an access token ID to the denylist.
+// Revoking an already revoked token is not an error.
+func (r *Repository) InsertRevokedToken(ctx context.Context, tokenId string, userId int, expiresAt time.Time) error {
+	ctx, cancel := r.GetContext(ctx, "InsertRevokedToken")
+	defer cancel()
+
+	query := `
+		INSERT INTO revoked_tokens (token_id, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token_id) DO NOTHING
+	`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, tokenId, userId, expiresAt)
+	return err
+}
+
+// ExistsRevokedToken checks if an access token ID is on the denylist.
+func (r *Repository) ExistsRevokedToken(ctx context.Context, tokenId string) (bool, error) {
+	ctx, cancel := r.GetContext(ctx, "ExistsRevokedToken")
+	defer cancel()
+
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_id = $1)`
+
+	var exists bool
+	if err := r.conn(ctx).QueryRowContext(ctx, query, tokenId).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// DeleteExpiredRevokedTokens removes denylist entries whose tokens have expired anyway.
+func (r *Repository) DeleteExpiredRevokedTokens(ctx context.Context) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteExpiredRevokedTokens")
+	defer cancel()
+
+	query := `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// GetRoles retrieves all roles with their permissions, ordered by name.
+func (r *Repository) GetRoles(ctx context.Context) ([]*entity.Role, error) {
+	ctx, cancel := r.GetContext(ctx, "GetRoles")
+	defer cancel()
+
+	query := `
+		SELECT name, description, is_system, created_at, updated_at
+		FROM roles
+		ORDER BY name
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]*entity.Role, 0)
+	byName := make(map[string]*entity.Role)
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+		byName[role.Name] = role
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	permissions, err := r.conn(ctx).QueryContext(ctx, `
+		SELECT role_name, permission
+		FROM role_permissions
+		ORDER BY role_name, permission
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer permissions.Close()
+
+	for permissions.Next() {
+		var roleName, permission string
+		if err := permissions.Scan(&roleName, &permission); err != nil {
+			return nil, err
+		}
+		if role, ok := byName[roleName]; ok {
+			role.Permissions = append(role.Permissions, permission)
+		}
+	}
+	if err := permissions.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// GetRoleByName retrieves a role with its permissions.
+func (r *Repository) GetRoleByName(ctx context.Context, name string) (*entity.Role, error) {
+	ctx, cancel := r.GetContext(ctx, "GetRoleByName")
+	defer cancel()
+
+	query := `
+		SELECT name, description, is_system, created_at, updated_at
+		FROM roles
+		WHERE name = $1
+	`
+
+	role, err := scanRole(r.conn(ctx).QueryRowContext(ctx, query, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx, `
+		SELECT permission
+		FROM role_permissions
+		WHERE role_name = $1
+		ORDER BY permission
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		role.Permissions = append(role.Permissions, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// InsertRole stores a new role with its permissions. Roles created at runtime are never system roles.
+// Returns repository.ErrDuplicateRole if a role with that name exists.
+// Run it in a transaction so that a failure does not leave a role without its permissions.
+func (r *Repository) InsertRole(ctx context.Context, role *entity.Role) error {
+	ctx, cancel := r.GetContext(ctx, "InsertRole")
+	defer cancel()
+
+	query := `
+		INSERT INTO roles (name, description, is_system)
+		VALUES ($1, $2, FALSE)
+	`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, role.Name, role.Description); err != nil {
+		if strings.Contains(err.Error(), "unique constraint") ||
+			strings.Contains(err.Error(), "duplicate key") {
+			return repository.ErrDuplicateRole
+		}
+		return err
+	}
+
+	return r.insertRolePermissions(ctx, role.Name, role.Permissions)
+}
+
+// UpdateRole updates the description of a role and replaces its permissions.
+// Returns repository.ErrRoleNotFound if the role does not exist.
+// Run it in a transaction so that a failure does not leave a role without its permissions.
+func (r *Repository) UpdateRole(ctx context.Context, role *entity.Role) error {
+	ctx, cancel := r.GetContext(ctx, "UpdateRole")
+	defer cancel()
+
+	query := `
+		UPDATE roles
+		SET description = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE name = $1
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, role.Name, role.Description)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrRoleNotFound
+	}
+
+	if _, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM role_permissions WHERE role_name = $1`, role.Name); err != nil {
+		return err
+	}
+	return r.insertRolePermissions(ctx, role.Name, role.Permissions)
+}
+
+// DeleteRole deletes a role and its permissions.
+// Returns repository.ErrRoleInUse if a user or a pending invitation has the role,
+// and repository.ErrRoleNotFound if the role does not exist.
+func (r *Repository) DeleteRole(ctx context.Context, name string) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteRole")
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM users WHERE role = $1)
+			OR EXISTS (SELECT 1 FROM invitations WHERE role = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP)
+	`
+
+	var inUse bool
+	if err := r.conn(ctx).QueryRowContext(ctx, query, name).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return repository.ErrRoleInUse
+	}
+
+	result, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM roles WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) insertRolePermissions(ctx context.Context, roleName string, permissions []string) error {
+	for _, permission := range permissions {
+		query := `INSERT INTO role_permissions (role_name, permission) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+		if _, err := r.conn(ctx).ExecContext(ctx, query, roleName, permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanRole(row interface{ Scan(dest ...any) error }) (*entity.Role, error) {
+	role := &entity.Role{Permissions: make([]string, 0)}
+	err := row.Scan(
+		&role.Name,
+		&role.Description,
+		&role.IsSystem,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
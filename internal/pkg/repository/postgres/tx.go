@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/your-org/go-backend-template/internal/pkg/logger"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultTxMaxAttempts = 3
+	txRetryBaseDelay     = 10 * time.Millisecond
+
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// txKey is the context key of the transaction started by TxManager.
+type txKey struct{}
+
+// dbtx is the query interface shared by *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction carried by ctx, or the connection pool if there is none.
+// This makes every repository method join a transaction started by TxManager.RunInTx.
+// Statements are traced when ctx carries a recording span.
+func (r *Repository) conn(ctx context.Context) dbtx {
+	var conn dbtx = r.db
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		conn = tx
+	}
+	if trace.SpanFromContext(ctx).IsRecording() {
+		return tracedConn{conn}
+	}
+	return conn
+}
+
+// tracedConn starts a span around every statement run on the wrapped connection.
+// Query spans end once the first rows are available, so they do not cover reading the result.
+type tracedConn struct {
+	dbtx
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := repository.StartStatement(ctx, dbSystem, query)
+	result, err := c.dbtx.ExecContext(ctx, query, args...)
+	telemetry.End(span, err)
+	return result, err
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := repository.StartStatement(ctx, dbSystem, query)
+	rows, err := c.dbtx.QueryContext(ctx, query, args...)
+	telemetry.End(span, err)
+	return rows, err
+}
+
+// QueryRowContext records the query error; sql.ErrNoRows is only reported by Scan and
+// is an expected outcome, so it is not recorded.
+func (c tracedConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := repository.StartStatement(ctx, dbSystem, query)
+	row := c.dbtx.QueryRowContext(ctx, query, args...)
+	telemetry.End(span, row.Err())
+	return row
+}
+
+// TxConfig holds transaction manager configuration.
+type TxConfig struct {
+	MaxAttempts int                // attempts on serialization failure or deadlock (default 3)
+	Isolation   sql.IsolationLevel // default serializable
+}
+
+// TxManager runs functions inside database transactions.
+type TxManager struct {
+	db          *sql.DB
+	maxAttempts int
+	isolation   sql.IsolationLevel
+}
+
+// NewTxManager creates a new transaction manager for the repository's database.
+func NewTxManager(repo *Repository, config TxConfig) *TxManager {
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultTxMaxAttempts
+	}
+
+	isolation := config.Isolation
+	if isolation == sql.LevelDefault {
+		isolation = sql.LevelSerializable
+	}
+
+	return &TxManager{
+		db:          repo.db,
+		maxAttempts: maxAttempts,
+		isolation:   isolation,
+	}
+}
+
+// RunInTx runs fn inside a transaction. Repository calls made with the context passed to fn
+// use the transaction. The transaction is committed if fn returns nil and rolled back otherwise.
+//
+// On serialization failures and deadlocks the whole function is retried, so fn must be safe
+// to run more than once. Nested calls join the outer transaction and are not retried on their own.
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := m.runOnce(ctx, fn)
+		if err == nil || !isRetryableTxError(err) || attempt >= m.maxAttempts {
+			return err
+		}
+
+		// Back off with jitter so that conflicting transactions do not collide again
+		delay := time.Duration(attempt)*txRetryBaseDelay + time.Duration(rand.Int63n(int64(txRetryBaseDelay)))
+		logger.FromContext(ctx).Debug("retrying transaction", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (m *TxManager) runOnce(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: m.isolation})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxError reports whether err means the transaction lost a conflict and may succeed on retry.
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+}
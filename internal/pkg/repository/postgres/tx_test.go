@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, expected: true},
+		{name: "deadlock detected", err: &pgconn.PgError{Code: "40P01"}, expected: true},
+		{name: "wrapped serialization failure", err: fmt.Errorf("failed to commit transaction: %w", &pgconn.PgError{Code: "40001"}), expected: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, expected: false},
+		{name: "other error", err: errors.New("connection refused"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isRetryableTxError(tt.err))
+		})
+	}
+}
+
+func TestNewTxManager_Defaults(t *testing.T) {
+	m := NewTxManager(&Repository{}, TxConfig{})
+
+	assert.Equal(t, defaultTxMaxAttempts, m.maxAttempts)
+	assert.Equal(t, sql.LevelSerializable, m.isolation)
+}
+
+func TestTxManager_RunInTx_JoinsOuterTransaction(t *testing.T) {
+	m := NewTxManager(&Repository{}, TxConfig{})
+	outer := &sql.Tx{}
+	ctx := context.WithValue(context.Background(), txKey{}, outer)
+
+	var inner dbtx
+	err := m.RunInTx(ctx, func(ctx context.Context) error {
+		inner = (&Repository{}).conn(ctx)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Same(t, outer, inner)
+}
+
+func TestRepository_Conn_WithoutTransaction(t *testing.T) {
+	db := &sql.DB{}
+	repo := &Repository{db: db}
+
+	assert.Same(t, db, repo.conn(context.Background()))
+}
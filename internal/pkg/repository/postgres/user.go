@@ -1,33 +1,37 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/your-org/go-backend-template/internal/pkg/entity"
 	"github.com/your-org/go-backend-template/internal/pkg/repository"
 )
 
 // InsertUser creates a new user and returns the created user ID.
-func (r *Repository) InsertUser(user *entity.User) (int, error) {
-	ctx, cancel := r.GetContext()
+func (r *Repository) InsertUser(ctx context.Context, user *entity.User) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertUser")
 	defer cancel()
 
 	query := `
-		INSERT INTO users (email, username, password, name, role, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (email, username, password, name, role, is_active, email_verified_at, pending_email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 
 	var id int
-	err := r.db.QueryRowContext(ctx, query,
+	err := r.conn(ctx).QueryRowContext(ctx, query,
 		user.Email,
 		user.Username,
 		user.Password,
 		user.Name,
 		user.Role,
 		user.IsActive,
+		user.EmailVerifiedAt,
+		user.PendingEmail,
 	).Scan(&id)
 
 	if err != nil {
@@ -43,18 +47,19 @@ func (r *Repository) InsertUser(user *entity.User) (int, error) {
 }
 
 // GetUserById retrieves a user by ID.
-func (r *Repository) GetUserById(id int) (*entity.User, error) {
-	ctx, cancel := r.GetContext()
+func (r *Repository) GetUserById(ctx context.Context, id int) (*entity.User, error) {
+	ctx, cancel := r.GetContext(ctx, "GetUserById")
 	defer cancel()
 
 	query := `
-		SELECT id, email, username, password, name, role, is_active, created_at, updated_at
+		SELECT id, email, username, password, name, role, is_active, token_version,
+			email_verified_at, pending_email, failed_login_attempts, locked_until, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 
 	user := &entity.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.conn(ctx).QueryRowContext(ctx, query, id).Scan(
 		&user.Id,
 		&user.Email,
 		&user.Username,
@@ -62,6 +67,11 @@ func (r *Repository) GetUserById(id int) (*entity.User, error) {
 		&user.Name,
 		&user.Role,
 		&user.IsActive,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
+		&user.PendingEmail,
+		&user.FailedLoginAttempts,
+		&user.LockedUntil,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -77,18 +87,19 @@ func (r *Repository) GetUserById(id int) (*entity.User, error) {
 }
 
 // GetUserByEmail retrieves a user by email.
-func (r *Repository) GetUserByEmail(email string) (*entity.User, error) {
-	ctx, cancel := r.GetContext()
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	ctx, cancel := r.GetContext(ctx, "GetUserByEmail")
 	defer cancel()
 
 	query := `
-		SELECT id, email, username, password, name, role, is_active, created_at, updated_at
+		SELECT id, email, username, password, name, role, is_active, token_version,
+			email_verified_at, pending_email, failed_login_attempts, locked_until, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
 
 	user := &entity.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := r.conn(ctx).QueryRowContext(ctx, query, email).Scan(
 		&user.Id,
 		&user.Email,
 		&user.Username,
@@ -96,6 +107,11 @@ func (r *Repository) GetUserByEmail(email string) (*entity.User, error) {
 		&user.Name,
 		&user.Role,
 		&user.IsActive,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
+		&user.PendingEmail,
+		&user.FailedLoginAttempts,
+		&user.LockedUntil,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -111,12 +127,13 @@ func (r *Repository) GetUserByEmail(email string) (*entity.User, error) {
 }
 
 // GetUsers retrieves users with pagination.
-func (r *Repository) GetUsers(offset, limit int, onlyActive bool) ([]*entity.User, error) {
-	ctx, cancel := r.GetContext()
+func (r *Repository) GetUsers(ctx context.Context, offset, limit int, onlyActive bool) ([]*entity.User, error) {
+	ctx, cancel := r.GetContext(ctx, "GetUsers")
 	defer cancel()
 
 	query := `
-		SELECT id, email, username, password, name, role, is_active, created_at, updated_at
+		SELECT id, email, username, password, name, role, is_active, token_version,
+			email_verified_at, pending_email, failed_login_attempts, locked_until, created_at, updated_at
 		FROM users
 		WHERE ($1 = false OR is_active = true)
 		ORDER BY created_at DESC
@@ -130,7 +147,7 @@ func (r *Repository) GetUsers(offset, limit int, onlyActive bool) ([]*entity.Use
 		args = append(args, limit)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +164,11 @@ func (r *Repository) GetUsers(offset, limit int, onlyActive bool) ([]*entity.Use
 			&user.Name,
 			&user.Role,
 			&user.IsActive,
+			&user.TokenVersion,
+			&user.EmailVerifiedAt,
+			&user.PendingEmail,
+			&user.FailedLoginAttempts,
+			&user.LockedUntil,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		); err != nil {
@@ -159,14 +181,14 @@ func (r *Repository) GetUsers(offset, limit int, onlyActive bool) ([]*entity.Use
 }
 
 // GetUserCount returns the total number of users.
-func (r *Repository) GetUserCount(onlyActive bool) (int, error) {
-	ctx, cancel := r.GetContext()
+func (r *Repository) GetUserCount(ctx context.Context, onlyActive bool) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "GetUserCount")
 	defer cancel()
 
 	query := `SELECT COUNT(*) FROM users WHERE ($1 = false OR is_active = true)`
 
 	var count int
-	if err := r.db.QueryRowContext(ctx, query, onlyActive).Scan(&count); err != nil {
+	if err := r.conn(ctx).QueryRowContext(ctx, query, onlyActive).Scan(&count); err != nil {
 		return 0, err
 	}
 
@@ -174,22 +196,29 @@ func (r *Repository) GetUserCount(onlyActive bool) (int, error) {
 }
 
 // UpdateUser updates an existing user.
-func (r *Repository) UpdateUser(user *entity.User) error {
-	ctx, cancel := r.GetContext()
+// Deactivating a user also increments their token version so that reactivation
+// does not bring previously issued tokens back to life.
+func (r *Repository) UpdateUser(ctx context.Context, user *entity.User) error {
+	ctx, cancel := r.GetContext(ctx, "UpdateUser")
 	defer cancel()
 
 	query := `
 		UPDATE users
-		SET email = $1, username = $2, name = $3, role = $4, is_active = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
+		SET email = $1, username = $2, name = $3, role = $4, is_active = $5,
+			email_verified_at = $6, pending_email = $7,
+			token_version = CASE WHEN is_active AND NOT $5 THEN token_version + 1 ELSE token_version END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.conn(ctx).ExecContext(ctx, query,
 		user.Email,
 		user.Username,
 		user.Name,
 		user.Role,
 		user.IsActive,
+		user.EmailVerifiedAt,
+		user.PendingEmail,
 		user.Id,
 	)
 	if err != nil {
@@ -212,8 +241,8 @@ func (r *Repository) UpdateUser(user *entity.User) error {
 }
 
 // UpdateUserPassword updates a user's password.
-func (r *Repository) UpdateUserPassword(id int, hashedPassword string) error {
-	ctx, cancel := r.GetContext()
+func (r *Repository) UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error {
+	ctx, cancel := r.GetContext(ctx, "UpdateUserPassword")
 	defer cancel()
 
 	query := `
@@ -222,7 +251,104 @@ func (r *Repository) UpdateUserPassword(id int, hashedPassword string) error {
 		WHERE id = $2
 	`
 
-	result, err := r.db.ExecContext(ctx, query, hashedPassword, id)
+	result, err := r.conn(ctx).ExecContext(ctx, query, hashedPassword, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// IncrementUserTokenVersion increments a user's token version, revoking all access tokens issued before.
+func (r *Repository) IncrementUserTokenVersion(ctx context.Context, id int) error {
+	ctx, cancel := r.GetContext(ctx, "IncrementUserTokenVersion")
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// IncrementFailedLoginAttempts records a failed login and returns the number of consecutive failures.
+func (r *Repository) IncrementFailedLoginAttempts(ctx context.Context, id int) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "IncrementFailedLoginAttempts")
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET failed_login_attempts = failed_login_attempts + 1
+		WHERE id = $1
+		RETURNING failed_login_attempts
+	`
+
+	var attempts int
+	err := r.conn(ctx).QueryRowContext(ctx, query, id).Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repository.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// LockUser refuses logins of a user until the given time.
+func (r *Repository) LockUser(ctx context.Context, id int, until time.Time) error {
+	ctx, cancel := r.GetContext(ctx, "LockUser")
+	defer cancel()
+
+	query := `UPDATE users SET locked_until = $2 WHERE id = $1`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, id, until)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ResetFailedLoginAttempts clears the failed login count and any lock of a user.
+func (r *Repository) ResetFailedLoginAttempts(ctx context.Context, id int) error {
+	ctx, cancel := r.GetContext(ctx, "ResetFailedLoginAttempts")
+	defer cancel()
+
+	query := `UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -239,13 +365,13 @@ func (r *Repository) UpdateUserPassword(id int, hashedPassword string) error {
 }
 
 // DeleteUserById deletes a user by ID.
-func (r *Repository) DeleteUserById(id int) error {
-	ctx, cancel := r.GetContext()
+func (r *Repository) DeleteUserById(ctx context.Context, id int) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteUserById")
 	defer cancel()
 
 	query := `DELETE FROM users WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.conn(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -262,14 +388,14 @@ func (r *Repository) DeleteUserById(id int) error {
 }
 
 // ExistsUserByEmail checks if a user with the given email exists.
-func (r *Repository) ExistsUserByEmail(email string) (bool, error) {
-	ctx, cancel := r.GetContext()
+func (r *Repository) ExistsUserByEmail(ctx context.Context, email string) (bool, error) {
+	ctx, cancel := r.GetContext(ctx, "ExistsUserByEmail")
 	defer cancel()
 
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
 
 	var exists bool
-	if err := r.db.QueryRowContext(ctx, query, email).Scan(&exists); err != nil {
+	if err := r.conn(ctx).QueryRowContext(ctx, query, email).Scan(&exists); err != nil {
 		return false, err
 	}
 
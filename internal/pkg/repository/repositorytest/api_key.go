@@ -0,0 +1,189 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// APIKeyRepository is the API key data access that every backend must provide.
+type APIKeyRepository interface {
+	UserRepository
+	InsertAPIKey(ctx context.Context, key *entity.APIKey) (int, error)
+	GetAPIKeysByUserId(ctx context.Context, userId int) ([]*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id int, usedAt time.Time) error
+	RevokeAPIKey(ctx context.Context, userId, id int) error
+}
+
+// APIKeyFactory returns an empty repository for a single test, like Factory.
+type APIKeyFactory func(t *testing.T) APIKeyRepository
+
+// TestAPIKeyRepository runs the API key repository conformance suite against the repositories created by newRepo.
+func TestAPIKeyRepository(t *testing.T, newRepo APIKeyFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo APIKeyRepository)
+	}{
+		{"InsertAndGet", testAPIKeyInsertAndGet},
+		{"DuplicatePrefix", testAPIKeyDuplicatePrefix},
+		{"UnknownUser", testAPIKeyUnknownUser},
+		{"UpdateLastUsed", testAPIKeyUpdateLastUsed},
+		{"Revoke", testAPIKeyRevoke},
+		{"CascadeOnUserDelete", testAPIKeyCascade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Helpers ==========
+
+func insertAPIKey(t *testing.T, repo APIKeyRepository, userId int, prefix string) int {
+	t.Helper()
+
+	id, err := repo.InsertAPIKey(context.Background(), &entity.APIKey{
+		UserId:  userId,
+		Name:    "CI",
+		Prefix:  prefix,
+		KeyHash: "hash-" + prefix,
+		Scopes:  []string{entity.APIKeyScopeUsersRead},
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert api key: %v", err)
+	}
+	return id
+}
+
+// ========== Cases ==========
+
+func testAPIKeyInsertAndGet(t *testing.T, repo APIKeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+
+	keys, err := repo.GetAPIKeysByUserId(ctx, userId)
+	assert.NoError(t, err)
+	assert.NotNil(t, keys)
+	assert.Empty(t, keys)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	firstId, err := repo.InsertAPIKey(ctx, &entity.APIKey{
+		UserId:    userId,
+		Name:      "Deploy",
+		Prefix:    "gbt_first",
+		KeyHash:   "first hash",
+		Scopes:    []string{entity.APIKeyScopeUsersRead, entity.APIKeyScopeUsersWrite},
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert api key: %v", err)
+	}
+	secondId := insertAPIKey(t, repo, userId, "gbt_second")
+	insertAPIKey(t, repo, otherUserId, "gbt_other")
+
+	keys, err = repo.GetAPIKeysByUserId(ctx, userId)
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 2) {
+		assert.Equal(t, firstId, keys[0].Id)
+		assert.Equal(t, secondId, keys[1].Id)
+		assert.Nil(t, keys[1].ExpiresAt, "keys without expiry never expire")
+	}
+
+	key, err := repo.GetAPIKeyByPrefix(ctx, "gbt_first")
+	assert.NoError(t, err)
+	if assert.NotNil(t, key) {
+		assert.Equal(t, firstId, key.Id)
+		assert.Equal(t, userId, key.UserId)
+		assert.Equal(t, "Deploy", key.Name)
+		assert.Equal(t, "gbt_first", key.Prefix)
+		assert.Equal(t, "first hash", key.KeyHash)
+		assert.Equal(t, []string{entity.APIKeyScopeUsersRead, entity.APIKeyScopeUsersWrite}, key.Scopes)
+		if assert.NotNil(t, key.ExpiresAt) {
+			assert.True(t, expiresAt.Equal(*key.ExpiresAt), "expires_at: want %v, got %v", expiresAt, *key.ExpiresAt)
+		}
+		assert.Nil(t, key.LastUsedAt)
+		assert.Nil(t, key.RevokedAt)
+		assert.False(t, key.CreatedAt.IsZero())
+	}
+
+	_, err = repo.GetAPIKeyByPrefix(ctx, "gbt_missing")
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+}
+
+func testAPIKeyDuplicatePrefix(t *testing.T, repo APIKeyRepository) {
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+	insertAPIKey(t, repo, userId, "gbt_prefix")
+
+	_, err := repo.InsertAPIKey(context.Background(), &entity.APIKey{
+		UserId:  otherUserId,
+		Name:    "Other",
+		Prefix:  "gbt_prefix",
+		KeyHash: "other hash",
+	})
+	assert.ErrorIs(t, err, repository.ErrDuplicateAPIKey)
+}
+
+func testAPIKeyUnknownUser(t *testing.T, repo APIKeyRepository) {
+	_, err := repo.InsertAPIKey(context.Background(), &entity.APIKey{
+		UserId:  999999,
+		Name:    "Orphan",
+		Prefix:  "gbt_orphan",
+		KeyHash: "hash",
+	})
+	assert.Error(t, err)
+}
+
+func testAPIKeyUpdateLastUsed(t *testing.T, repo APIKeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	id := insertAPIKey(t, repo, userId, "gbt_used")
+	usedAt := time.Now().Truncate(time.Second)
+
+	assert.NoError(t, repo.UpdateAPIKeyLastUsed(ctx, id, usedAt))
+	assert.ErrorIs(t, repo.UpdateAPIKeyLastUsed(ctx, id+1000, usedAt), repository.ErrAPIKeyNotFound)
+
+	key, err := repo.GetAPIKeyByPrefix(ctx, "gbt_used")
+	assert.NoError(t, err)
+	if assert.NotNil(t, key) && assert.NotNil(t, key.LastUsedAt) {
+		assert.True(t, usedAt.Equal(*key.LastUsedAt), "last_used_at: want %v, got %v", usedAt, *key.LastUsedAt)
+	}
+}
+
+func testAPIKeyRevoke(t *testing.T, repo APIKeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+	id := insertAPIKey(t, repo, userId, "gbt_revoke")
+
+	assert.ErrorIs(t, repo.RevokeAPIKey(ctx, otherUserId, id), repository.ErrAPIKeyNotFound,
+		"api keys of other users cannot be revoked")
+	assert.NoError(t, repo.RevokeAPIKey(ctx, userId, id))
+	assert.ErrorIs(t, repo.RevokeAPIKey(ctx, userId, id), repository.ErrAPIKeyNotFound,
+		"revoked keys cannot be revoked again")
+
+	key, err := repo.GetAPIKeyByPrefix(ctx, "gbt_revoke")
+	assert.NoError(t, err, "revoked keys are kept")
+	if assert.NotNil(t, key) {
+		assert.NotNil(t, key.RevokedAt)
+	}
+}
+
+func testAPIKeyCascade(t *testing.T, repo APIKeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	insertAPIKey(t, repo, userId, "gbt_cascade")
+
+	assert.NoError(t, repo.DeleteUserById(ctx, userId))
+
+	_, err := repo.GetAPIKeyByPrefix(ctx, "gbt_cascade")
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+}
@@ -0,0 +1,139 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InvitationRepository is the invitation data access that every backend must provide.
+type InvitationRepository interface {
+	InsertInvitation(ctx context.Context, invitation *entity.Invitation) (int, error)
+	GetInvitationByHash(ctx context.Context, tokenHash string) (*entity.Invitation, error)
+	ConsumeInvitation(ctx context.Context, id int) error
+	DeleteInvitations(ctx context.Context, email string) error
+	DeleteExpiredInvitations(ctx context.Context) error
+}
+
+// InvitationFactory returns an empty repository for a single test, like Factory.
+type InvitationFactory func(t *testing.T) InvitationRepository
+
+// TestInvitationRepository runs the invitation repository conformance suite against the repositories created by newRepo.
+func TestInvitationRepository(t *testing.T, newRepo InvitationFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo InvitationRepository)
+	}{
+		{"InsertAndGet", testInvitationInsertAndGet},
+		{"ConsumeOnce", testInvitationConsumeOnce},
+		{"DeleteByEmail", testInvitationDeleteByEmail},
+		{"DeleteExpired", testInvitationDeleteExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Helpers ==========
+
+func insertInvitation(t *testing.T, repo InvitationRepository, email, hash string, expiresAt time.Time) int {
+	t.Helper()
+
+	id, err := repo.InsertInvitation(context.Background(), &entity.Invitation{
+		Email:     email,
+		Role:      entity.RoleUser,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert invitation: %v", err)
+	}
+	return id
+}
+
+// ========== Cases ==========
+
+func testInvitationInsertAndGet(t *testing.T, repo InvitationRepository) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	id := insertInvitation(t, repo, "invitee@example.com", "hash", expiresAt)
+	assert.Greater(t, id, 0)
+
+	invitation, err := repo.GetInvitationByHash(ctx, "hash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, invitation) {
+		assert.Equal(t, id, invitation.Id)
+		assert.Equal(t, "invitee@example.com", invitation.Email)
+		assert.Equal(t, entity.RoleUser, invitation.Role)
+		assert.Equal(t, "hash", invitation.TokenHash)
+		assert.True(t, expiresAt.Equal(invitation.ExpiresAt), "expires_at: want %v, got %v", expiresAt, invitation.ExpiresAt)
+		assert.False(t, invitation.IsUsed())
+		assert.False(t, invitation.CreatedAt.IsZero())
+	}
+
+	_, err = repo.GetInvitationByHash(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrInvitationNotFound)
+
+	_, err = repo.InsertInvitation(ctx, &entity.Invitation{
+		Email:     "other@example.com",
+		Role:      entity.RoleUser,
+		TokenHash: "hash",
+		ExpiresAt: expiresAt,
+	})
+	assert.Error(t, err, "token hashes must be unique")
+}
+
+func testInvitationConsumeOnce(t *testing.T, repo InvitationRepository) {
+	ctx := context.Background()
+	id := insertInvitation(t, repo, "invitee@example.com", "hash", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.ConsumeInvitation(ctx, id))
+	assert.ErrorIs(t, repo.ConsumeInvitation(ctx, id), repository.ErrInvitationNotFound)
+	assert.ErrorIs(t, repo.ConsumeInvitation(ctx, id+1000), repository.ErrInvitationNotFound)
+
+	invitation, err := repo.GetInvitationByHash(ctx, "hash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, invitation) {
+		assert.True(t, invitation.IsUsed())
+	}
+}
+
+func testInvitationDeleteByEmail(t *testing.T, repo InvitationRepository) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	insertInvitation(t, repo, "invitee@example.com", "first", expiresAt)
+	insertInvitation(t, repo, "invitee@example.com", "second", expiresAt)
+	insertInvitation(t, repo, "other@example.com", "other", expiresAt)
+
+	assert.NoError(t, repo.DeleteInvitations(ctx, "invitee@example.com"))
+
+	for _, hash := range []string{"first", "second"} {
+		_, err := repo.GetInvitationByHash(ctx, hash)
+		assert.ErrorIs(t, err, repository.ErrInvitationNotFound, hash)
+	}
+	_, err := repo.GetInvitationByHash(ctx, "other")
+	assert.NoError(t, err)
+}
+
+func testInvitationDeleteExpired(t *testing.T, repo InvitationRepository) {
+	ctx := context.Background()
+
+	insertInvitation(t, repo, "expired@example.com", "expired", time.Now().Add(-time.Hour))
+	insertInvitation(t, repo, "valid@example.com", "valid", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.DeleteExpiredInvitations(ctx))
+
+	_, err := repo.GetInvitationByHash(ctx, "expired")
+	assert.ErrorIs(t, err, repository.ErrInvitationNotFound)
+	_, err = repo.GetInvitationByHash(ctx, "valid")
+	assert.NoError(t, err)
+}
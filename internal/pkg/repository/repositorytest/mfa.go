@@ -0,0 +1,189 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// MFARepository is the second factor data access that every backend must provide.
+type MFARepository interface {
+	UserRepository
+	UpsertTOTPCredential(ctx context.Context, credential *entity.TOTPCredential) error
+	GetTOTPCredential(ctx context.Context, userId int) (*entity.TOTPCredential, error)
+	ConfirmTOTPCredential(ctx context.Context, userId int, step int64) error
+	UseTOTPStep(ctx context.Context, userId int, step int64) error
+	DeleteTOTPCredential(ctx context.Context, userId int) error
+	ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userId int, codeHash string) error
+	CountRecoveryCodes(ctx context.Context, userId int) (int, error)
+	DeleteRecoveryCodes(ctx context.Context, userId int) error
+}
+
+// MFAFactory returns an empty repository for a single test, like Factory.
+type MFAFactory func(t *testing.T) MFARepository
+
+// TestMFARepository runs the MFA repository conformance suite against the repositories created by newRepo.
+func TestMFARepository(t *testing.T, newRepo MFAFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo MFARepository)
+	}{
+		{"TOTPEnrollment", testTOTPEnrollment},
+		{"TOTPReEnrollment", testTOTPReEnrollment},
+		{"TOTPStepReplay", testTOTPStepReplay},
+		{"TOTPDelete", testTOTPDelete},
+		{"RecoveryCodes", testRecoveryCodes},
+		{"CascadeOnUserDelete", testMFACascade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Helpers ==========
+
+func upsertTOTPCredential(t *testing.T, repo MFARepository, userId int, secret string) {
+	t.Helper()
+
+	if err := repo.UpsertTOTPCredential(context.Background(), &entity.TOTPCredential{UserId: userId, Secret: secret}); err != nil {
+		t.Fatalf("Failed to upsert totp credential: %v", err)
+	}
+}
+
+func getTOTPCredential(t *testing.T, repo MFARepository, userId int) *entity.TOTPCredential {
+	t.Helper()
+
+	credential, err := repo.GetTOTPCredential(context.Background(), userId)
+	if err != nil {
+		t.Fatalf("Failed to get totp credential of user %d: %v", userId, err)
+	}
+	return credential
+}
+
+// ========== Cases ==========
+
+func testTOTPEnrollment(t *testing.T, repo MFARepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+
+	_, err := repo.GetTOTPCredential(ctx, userId)
+	assert.ErrorIs(t, err, repository.ErrTOTPCredentialNotFound)
+
+	upsertTOTPCredential(t, repo, userId, "SECRET")
+
+	credential := getTOTPCredential(t, repo, userId)
+	assert.Equal(t, userId, credential.UserId)
+	assert.Equal(t, "SECRET", credential.Secret)
+	assert.False(t, credential.IsConfirmed())
+	assert.False(t, credential.CreatedAt.IsZero())
+
+	assert.NoError(t, repo.ConfirmTOTPCredential(ctx, userId, 100))
+	assert.ErrorIs(t, repo.ConfirmTOTPCredential(ctx, userId, 101), repository.ErrTOTPCredentialNotFound,
+		"a confirmed credential cannot be confirmed again")
+	assert.ErrorIs(t, repo.ConfirmTOTPCredential(ctx, userId+1000, 100), repository.ErrTOTPCredentialNotFound)
+
+	credential = getTOTPCredential(t, repo, userId)
+	assert.True(t, credential.IsConfirmed())
+	assert.Equal(t, int64(100), credential.LastUsedStep)
+}
+
+func testTOTPReEnrollment(t *testing.T, repo MFARepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+
+	upsertTOTPCredential(t, repo, userId, "OLD")
+	assert.NoError(t, repo.ConfirmTOTPCredential(ctx, userId, 100))
+
+	upsertTOTPCredential(t, repo, userId, "NEW")
+
+	credential := getTOTPCredential(t, repo, userId)
+	assert.Equal(t, "NEW", credential.Secret)
+	assert.False(t, credential.IsConfirmed(), "a replaced credential starts pending")
+	assert.Zero(t, credential.LastUsedStep)
+}
+
+func testTOTPStepReplay(t *testing.T, repo MFARepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	upsertTOTPCredential(t, repo, userId, "SECRET")
+	assert.NoError(t, repo.ConfirmTOTPCredential(ctx, userId, 100))
+
+	assert.ErrorIs(t, repo.UseTOTPStep(ctx, userId, 100), repository.ErrTOTPCredentialNotFound, "the confirmation code is used")
+	assert.NoError(t, repo.UseTOTPStep(ctx, userId, 101))
+	assert.ErrorIs(t, repo.UseTOTPStep(ctx, userId, 101), repository.ErrTOTPCredentialNotFound, "codes cannot be replayed")
+	assert.ErrorIs(t, repo.UseTOTPStep(ctx, userId, 99), repository.ErrTOTPCredentialNotFound, "older codes are rejected")
+	assert.ErrorIs(t, repo.UseTOTPStep(ctx, userId+1000, 200), repository.ErrTOTPCredentialNotFound)
+
+	assert.Equal(t, int64(101), getTOTPCredential(t, repo, userId).LastUsedStep)
+}
+
+func testTOTPDelete(t *testing.T, repo MFARepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	upsertTOTPCredential(t, repo, userId, "SECRET")
+
+	assert.NoError(t, repo.DeleteTOTPCredential(ctx, userId))
+	assert.ErrorIs(t, repo.DeleteTOTPCredential(ctx, userId), repository.ErrTOTPCredentialNotFound)
+
+	_, err := repo.GetTOTPCredential(ctx, userId)
+	assert.ErrorIs(t, err, repository.ErrTOTPCredentialNotFound)
+}
+
+func testRecoveryCodes(t *testing.T, repo MFARepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+
+	assert.NoError(t, repo.ReplaceRecoveryCodes(ctx, userId, []string{"a", "b", "c"}))
+	assert.NoError(t, repo.ReplaceRecoveryCodes(ctx, otherUserId, []string{"d"}))
+
+	count, err := repo.CountRecoveryCodes(ctx, userId)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	assert.NoError(t, repo.ConsumeRecoveryCode(ctx, userId, "a"))
+	assert.ErrorIs(t, repo.ConsumeRecoveryCode(ctx, userId, "a"), repository.ErrRecoveryCodeNotFound, "codes are single-use")
+	assert.ErrorIs(t, repo.ConsumeRecoveryCode(ctx, userId, "d"), repository.ErrRecoveryCodeNotFound, "codes of other users are rejected")
+
+	count, err = repo.CountRecoveryCodes(ctx, userId)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Replacing drops every previous code, used or not
+	assert.NoError(t, repo.ReplaceRecoveryCodes(ctx, userId, []string{"e", "f"}))
+	assert.ErrorIs(t, repo.ConsumeRecoveryCode(ctx, userId, "b"), repository.ErrRecoveryCodeNotFound)
+	count, err = repo.CountRecoveryCodes(ctx, userId)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.NoError(t, repo.DeleteRecoveryCodes(ctx, userId))
+	count, err = repo.CountRecoveryCodes(ctx, userId)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = repo.CountRecoveryCodes(ctx, otherUserId)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func testMFACascade(t *testing.T, repo MFARepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	upsertTOTPCredential(t, repo, userId, "SECRET")
+	assert.NoError(t, repo.ReplaceRecoveryCodes(ctx, userId, []string{"a"}))
+
+	assert.NoError(t, repo.DeleteUserById(ctx, userId))
+
+	_, err := repo.GetTOTPCredential(ctx, userId)
+	assert.ErrorIs(t, err, repository.ErrTOTPCredentialNotFound)
+	count, err := repo.CountRecoveryCodes(ctx, userId)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
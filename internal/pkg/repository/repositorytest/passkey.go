@@ -0,0 +1,224 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// PasskeyRepository is the passkey data access that every backend must provide.
+type PasskeyRepository interface {
+	UserRepository
+	InsertPasskey(ctx context.Context, passkey *entity.Passkey) (int, error)
+	GetPasskeysByUserId(ctx context.Context, userId int) ([]*entity.Passkey, error)
+	GetPasskeyByCredentialId(ctx context.Context, credentialId []byte) (*entity.Passkey, error)
+	UpdatePasskeySignCount(ctx context.Context, id int, signCount uint32) error
+	DeletePasskey(ctx context.Context, userId, id int) error
+	InsertPasskeyChallenge(ctx context.Context, challenge *entity.PasskeyChallenge) (int, error)
+	ConsumePasskeyChallenge(ctx context.Context, challengeHash string) (*entity.PasskeyChallenge, error)
+	DeleteExpiredPasskeyChallenges(ctx context.Context) error
+}
+
+// PasskeyFactory returns an empty repository for a single test, like Factory.
+type PasskeyFactory func(t *testing.T) PasskeyRepository
+
+// TestPasskeyRepository runs the passkey repository conformance suite against the repositories created by newRepo.
+func TestPasskeyRepository(t *testing.T, newRepo PasskeyFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo PasskeyRepository)
+	}{
+		{"InsertAndGet", testPasskeyInsertAndGet},
+		{"DuplicateCredential", testPasskeyDuplicateCredential},
+		{"UpdateSignCount", testPasskeyUpdateSignCount},
+		{"Delete", testPasskeyDelete},
+		{"ChallengeConsumeOnce", testPasskeyChallengeConsumeOnce},
+		{"ChallengeDeleteExpired", testPasskeyChallengeDeleteExpired},
+		{"CascadeOnUserDelete", testPasskeyCascade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Helpers ==========
+
+func insertPasskey(t *testing.T, repo PasskeyRepository, userId int, credentialId []byte) int {
+	t.Helper()
+
+	id, err := repo.InsertPasskey(context.Background(), &entity.Passkey{
+		UserId:       userId,
+		CredentialId: credentialId,
+		PublicKey:    []byte("public key"),
+		Name:         "Laptop",
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert passkey: %v", err)
+	}
+	return id
+}
+
+func insertPasskeyChallenge(t *testing.T, repo PasskeyRepository, userId *int, hash string, expiresAt time.Time) int {
+	t.Helper()
+
+	id, err := repo.InsertPasskeyChallenge(context.Background(), &entity.PasskeyChallenge{
+		UserId:        userId,
+		Purpose:       entity.PasskeyPurposeRegistration,
+		ChallengeHash: hash,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert passkey challenge: %v", err)
+	}
+	return id
+}
+
+// ========== Cases ==========
+
+func testPasskeyInsertAndGet(t *testing.T, repo PasskeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+
+	passkeys, err := repo.GetPasskeysByUserId(ctx, userId)
+	assert.NoError(t, err)
+	assert.NotNil(t, passkeys)
+	assert.Empty(t, passkeys)
+
+	firstId := insertPasskey(t, repo, userId, []byte{1, 2, 3})
+	secondId := insertPasskey(t, repo, userId, []byte{4, 5, 6})
+	insertPasskey(t, repo, otherUserId, []byte{7, 8, 9})
+
+	passkeys, err = repo.GetPasskeysByUserId(ctx, userId)
+	assert.NoError(t, err)
+	if assert.Len(t, passkeys, 2) {
+		assert.Equal(t, firstId, passkeys[0].Id)
+		assert.Equal(t, secondId, passkeys[1].Id)
+	}
+
+	passkey, err := repo.GetPasskeyByCredentialId(ctx, []byte{1, 2, 3})
+	assert.NoError(t, err)
+	if assert.NotNil(t, passkey) {
+		assert.Equal(t, firstId, passkey.Id)
+		assert.Equal(t, userId, passkey.UserId)
+		assert.Equal(t, []byte{1, 2, 3}, passkey.CredentialId)
+		assert.Equal(t, []byte("public key"), passkey.PublicKey)
+		assert.Zero(t, passkey.SignCount)
+		assert.Equal(t, "Laptop", passkey.Name)
+		assert.False(t, passkey.CreatedAt.IsZero())
+		assert.Nil(t, passkey.LastUsedAt)
+	}
+
+	_, err = repo.GetPasskeyByCredentialId(ctx, []byte{1, 2})
+	assert.ErrorIs(t, err, repository.ErrPasskeyNotFound, "credential ids match exactly")
+}
+
+func testPasskeyDuplicateCredential(t *testing.T, repo PasskeyRepository) {
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+	insertPasskey(t, repo, userId, []byte{1, 2, 3})
+
+	_, err := repo.InsertPasskey(context.Background(), &entity.Passkey{
+		UserId:       otherUserId,
+		CredentialId: []byte{1, 2, 3},
+		PublicKey:    []byte("other key"),
+	})
+	assert.ErrorIs(t, err, repository.ErrDuplicatePasskey)
+}
+
+func testPasskeyUpdateSignCount(t *testing.T, repo PasskeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	id := insertPasskey(t, repo, userId, []byte{1, 2, 3})
+
+	// The largest counter value must survive backends with signed integer columns
+	assert.NoError(t, repo.UpdatePasskeySignCount(ctx, id, 0xffffffff))
+	assert.ErrorIs(t, repo.UpdatePasskeySignCount(ctx, id+1000, 1), repository.ErrPasskeyNotFound)
+
+	passkey, err := repo.GetPasskeyByCredentialId(ctx, []byte{1, 2, 3})
+	assert.NoError(t, err)
+	if assert.NotNil(t, passkey) {
+		assert.Equal(t, uint32(0xffffffff), passkey.SignCount)
+		assert.NotNil(t, passkey.LastUsedAt)
+	}
+}
+
+func testPasskeyDelete(t *testing.T, repo PasskeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+	id := insertPasskey(t, repo, userId, []byte{1, 2, 3})
+
+	assert.ErrorIs(t, repo.DeletePasskey(ctx, otherUserId, id), repository.ErrPasskeyNotFound,
+		"passkeys of other users cannot be deleted")
+	assert.NoError(t, repo.DeletePasskey(ctx, userId, id))
+	assert.ErrorIs(t, repo.DeletePasskey(ctx, userId, id), repository.ErrPasskeyNotFound)
+
+	_, err := repo.GetPasskeyByCredentialId(ctx, []byte{1, 2, 3})
+	assert.ErrorIs(t, err, repository.ErrPasskeyNotFound)
+}
+
+func testPasskeyChallengeConsumeOnce(t *testing.T, repo PasskeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	id := insertPasskeyChallenge(t, repo, &userId, "registration", expiresAt)
+	insertPasskeyChallenge(t, repo, nil, "login", expiresAt)
+
+	challenge, err := repo.ConsumePasskeyChallenge(ctx, "registration")
+	assert.NoError(t, err)
+	if assert.NotNil(t, challenge) {
+		assert.Equal(t, id, challenge.Id)
+		if assert.NotNil(t, challenge.UserId) {
+			assert.Equal(t, userId, *challenge.UserId)
+		}
+		assert.Equal(t, entity.PasskeyPurposeRegistration, challenge.Purpose)
+		assert.True(t, expiresAt.Equal(challenge.ExpiresAt), "expires_at: want %v, got %v", expiresAt, challenge.ExpiresAt)
+		assert.False(t, challenge.CreatedAt.IsZero())
+	}
+
+	_, err = repo.ConsumePasskeyChallenge(ctx, "registration")
+	assert.ErrorIs(t, err, repository.ErrPasskeyChallengeNotFound, "challenges are single-use")
+
+	challenge, err = repo.ConsumePasskeyChallenge(ctx, "login")
+	assert.NoError(t, err)
+	if assert.NotNil(t, challenge) {
+		assert.Nil(t, challenge.UserId, "login challenges belong to no user")
+	}
+}
+
+func testPasskeyChallengeDeleteExpired(t *testing.T, repo PasskeyRepository) {
+	ctx := context.Background()
+
+	insertPasskeyChallenge(t, repo, nil, "expired", time.Now().Add(-time.Hour))
+	insertPasskeyChallenge(t, repo, nil, "valid", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.DeleteExpiredPasskeyChallenges(ctx))
+
+	_, err := repo.ConsumePasskeyChallenge(ctx, "expired")
+	assert.ErrorIs(t, err, repository.ErrPasskeyChallengeNotFound)
+	_, err = repo.ConsumePasskeyChallenge(ctx, "valid")
+	assert.NoError(t, err)
+}
+
+func testPasskeyCascade(t *testing.T, repo PasskeyRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	insertPasskey(t, repo, userId, []byte{1, 2, 3})
+	insertPasskeyChallenge(t, repo, &userId, "hash", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.DeleteUserById(ctx, userId))
+
+	_, err := repo.GetPasskeyByCredentialId(ctx, []byte{1, 2, 3})
+	assert.ErrorIs(t, err, repository.ErrPasskeyNotFound)
+	_, err = repo.ConsumePasskeyChallenge(ctx, "hash")
+	assert.ErrorIs(t, err, repository.ErrPasskeyChallengeNotFound)
+}
@@ -0,0 +1,160 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// RoleRepository is the role data access that every backend must provide.
+type RoleRepository interface {
+	UserRepository
+	GetRoles(ctx context.Context) ([]*entity.Role, error)
+	GetRoleByName(ctx context.Context, name string) (*entity.Role, error)
+	InsertRole(ctx context.Context, role *entity.Role) error
+	UpdateRole(ctx context.Context, role *entity.Role) error
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// RoleFactory returns a repository for a single test that holds only the built-in roles, like Factory.
+type RoleFactory func(t *testing.T) RoleRepository
+
+// TestRoleRepository runs the role repository conformance suite against the repositories created by newRepo.
+func TestRoleRepository(t *testing.T, newRepo RoleFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo RoleRepository)
+	}{
+		{"SystemRoles", testRoleSystemRoles},
+		{"InsertAndGet", testRoleInsertAndGet},
+		{"Duplicate", testRoleDuplicate},
+		{"Update", testRoleUpdate},
+		{"Delete", testRoleDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Cases ==========
+
+func testRoleSystemRoles(t *testing.T, repo RoleRepository) {
+	roles, err := repo.GetRoles(context.Background())
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+		assert.True(t, role.IsSystem, role.Name)
+		assert.NotNil(t, role.Permissions, role.Name)
+		assert.False(t, role.CreatedAt.IsZero(), role.Name)
+	}
+	assert.Equal(t, []string{entity.RoleAdmin, entity.RoleUser, entity.RoleViewer}, names)
+
+	admin, err := repo.GetRoleByName(context.Background(), entity.RoleAdmin)
+	assert.NoError(t, err)
+	if assert.NotNil(t, admin) {
+		assert.Equal(t, entity.AllPermissions(), admin.Permissions)
+	}
+
+	user, err := repo.GetRoleByName(context.Background(), entity.RoleUser)
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Empty(t, user.Permissions)
+	}
+}
+
+func testRoleInsertAndGet(t *testing.T, repo RoleRepository) {
+	ctx := context.Background()
+
+	err := repo.InsertRole(ctx, &entity.Role{
+		Name:        "support",
+		Description: "Helps users",
+		Permissions: []string{entity.PermissionUsersWrite, entity.PermissionUsersRead},
+		IsSystem:    true,
+	})
+	assert.NoError(t, err)
+
+	role, err := repo.GetRoleByName(ctx, "support")
+	assert.NoError(t, err)
+	if assert.NotNil(t, role) {
+		assert.Equal(t, "Helps users", role.Description)
+		assert.Equal(t, []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}, role.Permissions,
+			"permissions are sorted")
+		assert.False(t, role.IsSystem, "roles created at runtime are never system roles")
+		assert.False(t, role.CreatedAt.IsZero())
+		assert.False(t, role.UpdatedAt.IsZero())
+	}
+
+	roles, err := repo.GetRoles(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, roles, 4) {
+		assert.Equal(t, "support", roles[1].Name, "roles are ordered by name")
+		assert.Equal(t, []string{entity.PermissionUsersRead, entity.PermissionUsersWrite}, roles[1].Permissions)
+	}
+
+	_, err = repo.GetRoleByName(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrRoleNotFound)
+}
+
+func testRoleDuplicate(t *testing.T, repo RoleRepository) {
+	err := repo.InsertRole(context.Background(), &entity.Role{Name: entity.RoleAdmin})
+	assert.ErrorIs(t, err, repository.ErrDuplicateRole)
+}
+
+func testRoleUpdate(t *testing.T, repo RoleRepository) {
+	ctx := context.Background()
+	if err := repo.InsertRole(ctx, &entity.Role{Name: "support", Permissions: []string{entity.PermissionUsersRead}}); err != nil {
+		t.Fatalf("Failed to insert role: %v", err)
+	}
+
+	err := repo.UpdateRole(ctx, &entity.Role{
+		Name:        "support",
+		Description: "Manages roles",
+		Permissions: []string{entity.PermissionRolesRead, entity.PermissionRolesWrite},
+	})
+	assert.NoError(t, err)
+
+	role, err := repo.GetRoleByName(ctx, "support")
+	assert.NoError(t, err)
+	if assert.NotNil(t, role) {
+		assert.Equal(t, "Manages roles", role.Description)
+		assert.Equal(t, []string{entity.PermissionRolesRead, entity.PermissionRolesWrite}, role.Permissions,
+			"permissions are replaced")
+	}
+
+	assert.NoError(t, repo.UpdateRole(ctx, &entity.Role{Name: "support"}))
+	role, err = repo.GetRoleByName(ctx, "support")
+	assert.NoError(t, err)
+	if assert.NotNil(t, role) {
+		assert.NotNil(t, role.Permissions)
+		assert.Empty(t, role.Permissions)
+	}
+
+	assert.ErrorIs(t, repo.UpdateRole(ctx, &entity.Role{Name: "missing"}), repository.ErrRoleNotFound)
+}
+
+func testRoleDelete(t *testing.T, repo RoleRepository) {
+	ctx := context.Background()
+	if err := repo.InsertRole(ctx, &entity.Role{Name: "support", Permissions: []string{entity.PermissionUsersRead}}); err != nil {
+		t.Fatalf("Failed to insert role: %v", err)
+	}
+	user := newUser(1, true)
+	user.Role = "support"
+	userId := insertUser(t, repo, user)
+
+	assert.ErrorIs(t, repo.DeleteRole(ctx, "support"), repository.ErrRoleInUse, "roles of users cannot be deleted")
+
+	assert.NoError(t, repo.DeleteUserById(ctx, userId))
+	assert.NoError(t, repo.DeleteRole(ctx, "support"))
+	assert.ErrorIs(t, repo.DeleteRole(ctx, "support"), repository.ErrRoleNotFound)
+
+	_, err := repo.GetRoleByName(ctx, "support")
+	assert.ErrorIs(t, err, repository.ErrRoleNotFound)
+}
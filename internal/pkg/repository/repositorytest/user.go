@@ -0,0 +1,414 @@
+// Package repositorytest provides a conformance test suite for repository implementations.
+// Every backend runs the same suite so that they stay interchangeable.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// timestampGap separates writes whose timestamps are compared,
+// so that backends with coarse clocks still produce distinct values.
+const timestampGap = 10 * time.Millisecond
+
+// UserRepository is the user data access that every backend must provide.
+type UserRepository interface {
+	InsertUser(ctx context.Context, user *entity.User) (int, error)
+	GetUserById(ctx context.Context, id int) (*entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetUsers(ctx context.Context, offset, limit int, onlyActive bool) ([]*entity.User, error)
+	GetUserCount(ctx context.Context, onlyActive bool) (int, error)
+	ExistsUserByEmail(ctx context.Context, email string) (bool, error)
+	UpdateUser(ctx context.Context, user *entity.User) error
+	UpdateUserPassword(ctx context.Context, id int, hashedPassword string) error
+	IncrementUserTokenVersion(ctx context.Context, id int) error
+	IncrementFailedLoginAttempts(ctx context.Context, id int) (int, error)
+	LockUser(ctx context.Context, id int, until time.Time) error
+	ResetFailedLoginAttempts(ctx context.Context, id int) error
+	DeleteUserById(ctx context.Context, id int) error
+}
+
+// Factory returns an empty repository for a single test.
+// It should register any cleanup with t.Cleanup, and may call t.Skip if the backend is unavailable.
+type Factory func(t *testing.T) UserRepository
+
+// TestUserRepository runs the user repository conformance suite against the repositories created by newRepo.
+func TestUserRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo UserRepository)
+	}{
+		{"InsertAndGet", testInsertAndGet},
+		{"DuplicateEmail", testDuplicateEmail},
+		{"NotFound", testNotFound},
+		{"Pagination", testPagination},
+		{"OnlyActive", testOnlyActive},
+		{"UpdateUser", testUpdateUser},
+		{"EmailVerification", testEmailVerification},
+		{"UpdateUserPassword", testUpdateUserPassword},
+		{"IncrementUserTokenVersion", testIncrementUserTokenVersion},
+		{"LoginLockout", testLoginLockout},
+		{"DeleteUser", testDeleteUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Helpers ==========
+
+func newUser(n int, isActive bool) *entity.User {
+	return &entity.User{
+		Email:    fmt.Sprintf("user%d@example.com", n),
+		Username: fmt.Sprintf("user%d", n),
+		Password: "hashed_password",
+		Name:     fmt.Sprintf("User %d", n),
+		Role:     entity.RoleUser,
+		IsActive: isActive,
+	}
+}
+
+func insertUser(t *testing.T, repo UserRepository, user *entity.User) int {
+	t.Helper()
+
+	id, err := repo.InsertUser(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	return id
+}
+
+func getUser(t *testing.T, repo UserRepository, id int) *entity.User {
+	t.Helper()
+
+	user, err := repo.GetUserById(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get user %d: %v", id, err)
+	}
+	return user
+}
+
+func userIds(users []*entity.User) []int {
+	ids := make([]int, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.Id)
+	}
+	return ids
+}
+
+// ========== Cases ==========
+
+func testInsertAndGet(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	input := newUser(1, true)
+
+	id, err := repo.InsertUser(ctx, input)
+	assert.NoError(t, err)
+	assert.Greater(t, id, 0)
+
+	user, err := repo.GetUserById(ctx, id)
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, id, user.Id)
+		assert.Equal(t, input.Email, user.Email)
+		assert.Equal(t, input.Username, user.Username)
+		assert.Equal(t, input.Password, user.Password)
+		assert.Equal(t, input.Name, user.Name)
+		assert.Equal(t, input.Role, user.Role)
+		assert.True(t, user.IsActive)
+		assert.Equal(t, 0, user.TokenVersion)
+		assert.Nil(t, user.EmailVerifiedAt)
+		assert.Nil(t, user.PendingEmail)
+		assert.False(t, user.CreatedAt.IsZero())
+		assert.False(t, user.UpdatedAt.IsZero())
+	}
+
+	byEmail, err := repo.GetUserByEmail(ctx, input.Email)
+	assert.NoError(t, err)
+	if assert.NotNil(t, byEmail) {
+		assert.Equal(t, id, byEmail.Id)
+	}
+
+	exists, err := repo.ExistsUserByEmail(ctx, input.Email)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.ExistsUserByEmail(ctx, "missing@example.com")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testDuplicateEmail(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	insertUser(t, repo, newUser(1, true))
+	id := insertUser(t, repo, newUser(2, true))
+
+	duplicate := newUser(3, true)
+	duplicate.Email = newUser(1, true).Email
+	_, err := repo.InsertUser(ctx, duplicate)
+	assert.ErrorIs(t, err, repository.ErrDuplicateEmail)
+
+	user := getUser(t, repo, id)
+	user.Email = newUser(1, true).Email
+	assert.ErrorIs(t, repo.UpdateUser(ctx, user), repository.ErrDuplicateEmail)
+
+	count, err := repo.GetUserCount(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func testNotFound(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	const missingId = 99999
+
+	user, err := repo.GetUserById(ctx, missingId)
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+	user, err = repo.GetUserByEmail(ctx, "missing@example.com")
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+	missing := newUser(1, true)
+	missing.Id = missingId
+	assert.ErrorIs(t, repo.UpdateUser(ctx, missing), repository.ErrUserNotFound)
+	assert.ErrorIs(t, repo.UpdateUserPassword(ctx, missingId, "hash"), repository.ErrUserNotFound)
+	assert.ErrorIs(t, repo.IncrementUserTokenVersion(ctx, missingId), repository.ErrUserNotFound)
+	assert.ErrorIs(t, repo.DeleteUserById(ctx, missingId), repository.ErrUserNotFound)
+}
+
+func testPagination(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+
+	// Inserted oldest first; listed newest first
+	ids := make([]int, 5)
+	for i := range ids {
+		ids[i] = insertUser(t, repo, newUser(i, true))
+		time.Sleep(timestampGap)
+	}
+	newestFirst := []int{ids[4], ids[3], ids[2], ids[1], ids[0]}
+
+	tests := []struct {
+		name    string
+		offset  int
+		limit   int
+		wantIds []int
+	}{
+		{name: "first page", offset: 0, limit: 2, wantIds: newestFirst[0:2]},
+		{name: "second page", offset: 2, limit: 2, wantIds: newestFirst[2:4]},
+		{name: "last partial page", offset: 4, limit: 2, wantIds: newestFirst[4:]},
+		{name: "offset past end", offset: 10, limit: 2, wantIds: []int{}},
+		{name: "no limit", offset: 1, limit: 0, wantIds: newestFirst[1:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := repo.GetUsers(ctx, tt.offset, tt.limit, false)
+
+			assert.NoError(t, err)
+			assert.NotNil(t, users)
+			assert.Equal(t, tt.wantIds, userIds(users))
+		})
+	}
+
+	count, err := repo.GetUserCount(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, len(ids), count)
+}
+
+func testOnlyActive(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	active := insertUser(t, repo, newUser(1, true))
+	time.Sleep(timestampGap)
+	insertUser(t, repo, newUser(2, false))
+
+	users, err := repo.GetUsers(ctx, 0, 10, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{active}, userIds(users))
+
+	users, err = repo.GetUsers(ctx, 0, 10, false)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	count, err := repo.GetUserCount(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = repo.GetUserCount(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func testUpdateUser(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	id := insertUser(t, repo, newUser(1, true))
+	before := getUser(t, repo, id)
+	time.Sleep(timestampGap)
+
+	update := *before
+	update.Email = "updated@example.com"
+	update.Username = "updated"
+	update.Name = "Updated Name"
+	update.Role = entity.RoleAdmin
+	update.Password = "ignored"
+	assert.NoError(t, repo.UpdateUser(ctx, &update))
+
+	after := getUser(t, repo, id)
+	assert.Equal(t, "updated@example.com", after.Email)
+	assert.Equal(t, "updated", after.Username)
+	assert.Equal(t, "Updated Name", after.Name)
+	assert.Equal(t, entity.RoleAdmin, after.Role)
+	assert.Equal(t, before.Password, after.Password, "UpdateUser must not change the password")
+	assert.Equal(t, before.TokenVersion, after.TokenVersion)
+	assert.True(t, after.CreatedAt.Equal(before.CreatedAt), "created_at must not change")
+	assert.True(t, after.UpdatedAt.After(before.UpdatedAt), "updated_at must advance")
+
+	// Deactivation revokes issued tokens; reactivation does not restore them
+	update = *after
+	update.IsActive = false
+	assert.NoError(t, repo.UpdateUser(ctx, &update))
+	update.IsActive = true
+	assert.NoError(t, repo.UpdateUser(ctx, &update))
+
+	reactivated := getUser(t, repo, id)
+	assert.True(t, reactivated.IsActive)
+	assert.Equal(t, before.TokenVersion+1, reactivated.TokenVersion)
+}
+
+func testEmailVerification(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	// Pre-verified users keep their verification time
+	input := newUser(1, true)
+	input.EmailVerifiedAt = &verifiedAt
+	verified := getUser(t, repo, insertUser(t, repo, input))
+	if assert.NotNil(t, verified.EmailVerifiedAt) {
+		assert.WithinDuration(t, verifiedAt, *verified.EmailVerifiedAt, time.Millisecond)
+	}
+
+	// Request an email change
+	user := getUser(t, repo, insertUser(t, repo, newUser(2, true)))
+	pendingEmail := "new@example.com"
+	user.PendingEmail = &pendingEmail
+	assert.NoError(t, repo.UpdateUser(ctx, user))
+
+	pending := getUser(t, repo, user.Id)
+	assert.Equal(t, newUser(2, true).Email, pending.Email, "the old email stays active")
+	if assert.NotNil(t, pending.PendingEmail) {
+		assert.Equal(t, pendingEmail, *pending.PendingEmail)
+	}
+	assert.Nil(t, pending.EmailVerifiedAt)
+
+	// Confirm it
+	pending.Email = pendingEmail
+	pending.PendingEmail = nil
+	pending.EmailVerifiedAt = &verifiedAt
+	assert.NoError(t, repo.UpdateUser(ctx, pending))
+
+	confirmed := getUser(t, repo, user.Id)
+	assert.Equal(t, pendingEmail, confirmed.Email)
+	assert.Nil(t, confirmed.PendingEmail)
+	if assert.NotNil(t, confirmed.EmailVerifiedAt) {
+		assert.WithinDuration(t, verifiedAt, *confirmed.EmailVerifiedAt, time.Millisecond)
+	}
+
+	// Unverify, e.g. after an administrative email change
+	confirmed.EmailVerifiedAt = nil
+	assert.NoError(t, repo.UpdateUser(ctx, confirmed))
+	assert.Nil(t, getUser(t, repo, user.Id).EmailVerifiedAt)
+}
+
+func testUpdateUserPassword(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	id := insertUser(t, repo, newUser(1, true))
+	before := getUser(t, repo, id)
+	time.Sleep(timestampGap)
+
+	assert.NoError(t, repo.UpdateUserPassword(ctx, id, "new_hash"))
+
+	after := getUser(t, repo, id)
+	assert.Equal(t, "new_hash", after.Password)
+	assert.True(t, after.UpdatedAt.After(before.UpdatedAt), "updated_at must advance")
+}
+
+func testIncrementUserTokenVersion(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	id := insertUser(t, repo, newUser(1, true))
+
+	assert.NoError(t, repo.IncrementUserTokenVersion(ctx, id))
+	assert.NoError(t, repo.IncrementUserTokenVersion(ctx, id))
+
+	assert.Equal(t, 2, getUser(t, repo, id).TokenVersion)
+}
+
+func testLoginLockout(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	id := insertUser(t, repo, newUser(1, true))
+	until := time.Now().Add(time.Hour)
+
+	user := getUser(t, repo, id)
+	assert.Equal(t, 0, user.FailedLoginAttempts)
+	assert.Nil(t, user.LockedUntil)
+
+	for want := 1; want <= 2; want++ {
+		attempts, err := repo.IncrementFailedLoginAttempts(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, attempts)
+	}
+	assert.NoError(t, repo.LockUser(ctx, id, until))
+
+	locked := getUser(t, repo, id)
+	assert.Equal(t, 2, locked.FailedLoginAttempts)
+	if assert.NotNil(t, locked.LockedUntil) {
+		assert.WithinDuration(t, until, *locked.LockedUntil, time.Millisecond)
+	}
+
+	// UpdateUser leaves the lockout state alone
+	locked.FailedLoginAttempts = 0
+	locked.LockedUntil = nil
+	assert.NoError(t, repo.UpdateUser(ctx, locked))
+	assert.NotNil(t, getUser(t, repo, id).LockedUntil)
+
+	assert.NoError(t, repo.ResetFailedLoginAttempts(ctx, id))
+
+	reset := getUser(t, repo, id)
+	assert.Equal(t, 0, reset.FailedLoginAttempts)
+	assert.Nil(t, reset.LockedUntil)
+
+	_, err := repo.IncrementFailedLoginAttempts(ctx, id+1000)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	assert.ErrorIs(t, repo.LockUser(ctx, id+1000, until), repository.ErrUserNotFound)
+	assert.ErrorIs(t, repo.ResetFailedLoginAttempts(ctx, id+1000), repository.ErrUserNotFound)
+}
+
+func testDeleteUser(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+	id := insertUser(t, repo, newUser(1, true))
+	other := insertUser(t, repo, newUser(2, true))
+
+	assert.NoError(t, repo.DeleteUserById(ctx, id))
+
+	_, err := repo.GetUserById(ctx, id)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	exists, err := repo.ExistsUserByEmail(ctx, newUser(1, true).Email)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	getUser(t, repo, other)
+	count, err := repo.GetUserCount(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// The email can be reused once the user is gone
+	insertUser(t, repo, newUser(1, true))
+}
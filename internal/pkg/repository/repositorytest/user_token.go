@@ -0,0 +1,162 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// UserTokenRepository is the user token data access that every backend must provide.
+type UserTokenRepository interface {
+	UserRepository
+	InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error)
+	GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error)
+	ConsumeUserToken(ctx context.Context, id int) error
+	DeleteUserTokens(ctx context.Context, userId int, purpose string) error
+	DeleteExpiredUserTokens(ctx context.Context) error
+}
+
+// UserTokenFactory returns an empty repository for a single test, like Factory.
+type UserTokenFactory func(t *testing.T) UserTokenRepository
+
+// TestUserTokenRepository runs the user token repository conformance suite against the repositories created by newRepo.
+func TestUserTokenRepository(t *testing.T, newRepo UserTokenFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo UserTokenRepository)
+	}{
+		{"InsertAndGet", testUserTokenInsertAndGet},
+		{"ConsumeOnce", testUserTokenConsumeOnce},
+		{"DeleteByPurpose", testUserTokenDeleteByPurpose},
+		{"DeleteExpired", testUserTokenDeleteExpired},
+		{"CascadeOnUserDelete", testUserTokenCascade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// ========== Helpers ==========
+
+const testTokenPurposeOther = "other"
+
+func insertUserToken(t *testing.T, repo UserTokenRepository, userId int, purpose, hash string, expiresAt time.Time) int {
+	t.Helper()
+
+	id, err := repo.InsertUserToken(context.Background(), &entity.UserToken{
+		UserId:    userId,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert user token: %v", err)
+	}
+	return id
+}
+
+// ========== Cases ==========
+
+func testUserTokenInsertAndGet(t *testing.T, repo UserTokenRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	id := insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "hash", expiresAt)
+	assert.Greater(t, id, 0)
+
+	token, err := repo.GetUserTokenByHash(ctx, "hash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, token) {
+		assert.Equal(t, id, token.Id)
+		assert.Equal(t, userId, token.UserId)
+		assert.Equal(t, entity.TokenPurposePasswordReset, token.Purpose)
+		assert.Equal(t, "hash", token.TokenHash)
+		assert.True(t, expiresAt.Equal(token.ExpiresAt), "expires_at: want %v, got %v", expiresAt, token.ExpiresAt)
+		assert.False(t, token.IsUsed())
+		assert.False(t, token.CreatedAt.IsZero())
+	}
+
+	_, err = repo.GetUserTokenByHash(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrUserTokenNotFound)
+
+	_, err = repo.InsertUserToken(ctx, &entity.UserToken{
+		UserId:    userId,
+		Purpose:   entity.TokenPurposePasswordReset,
+		TokenHash: "hash",
+		ExpiresAt: expiresAt,
+	})
+	assert.Error(t, err, "token hashes must be unique")
+}
+
+func testUserTokenConsumeOnce(t *testing.T, repo UserTokenRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	id := insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "hash", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.ConsumeUserToken(ctx, id))
+	assert.ErrorIs(t, repo.ConsumeUserToken(ctx, id), repository.ErrUserTokenNotFound)
+	assert.ErrorIs(t, repo.ConsumeUserToken(ctx, id+1000), repository.ErrUserTokenNotFound)
+
+	token, err := repo.GetUserTokenByHash(ctx, "hash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, token) {
+		assert.True(t, token.IsUsed())
+	}
+}
+
+func testUserTokenDeleteByPurpose(t *testing.T, repo UserTokenRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	otherUserId := insertUser(t, repo, newUser(2, true))
+	expiresAt := time.Now().Add(time.Hour)
+
+	insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "first", expiresAt)
+	insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "second", expiresAt)
+	insertUserToken(t, repo, userId, testTokenPurposeOther, "other-purpose", expiresAt)
+	insertUserToken(t, repo, otherUserId, entity.TokenPurposePasswordReset, "other-user", expiresAt)
+
+	assert.NoError(t, repo.DeleteUserTokens(ctx, userId, entity.TokenPurposePasswordReset))
+
+	for _, hash := range []string{"first", "second"} {
+		_, err := repo.GetUserTokenByHash(ctx, hash)
+		assert.ErrorIs(t, err, repository.ErrUserTokenNotFound, hash)
+	}
+	for _, hash := range []string{"other-purpose", "other-user"} {
+		_, err := repo.GetUserTokenByHash(ctx, hash)
+		assert.NoError(t, err, hash)
+	}
+}
+
+func testUserTokenDeleteExpired(t *testing.T, repo UserTokenRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+
+	insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "expired", time.Now().Add(-time.Hour))
+	insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "valid", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.DeleteExpiredUserTokens(ctx))
+
+	_, err := repo.GetUserTokenByHash(ctx, "expired")
+	assert.ErrorIs(t, err, repository.ErrUserTokenNotFound)
+	_, err = repo.GetUserTokenByHash(ctx, "valid")
+	assert.NoError(t, err)
+}
+
+func testUserTokenCascade(t *testing.T, repo UserTokenRepository) {
+	ctx := context.Background()
+	userId := insertUser(t, repo, newUser(1, true))
+	insertUserToken(t, repo, userId, entity.TokenPurposePasswordReset, "hash", time.Now().Add(time.Hour))
+
+	assert.NoError(t, repo.DeleteUserById(ctx, userId))
+
+	_, err := repo.GetUserTokenByHash(ctx, "hash")
+	assert.ErrorIs(t, err, repository.ErrUserTokenNotFound)
+}
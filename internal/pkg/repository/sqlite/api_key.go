@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertAPIKey stores a new API key and returns the created key ID.
+// Returns repository.ErrDuplicateAPIKey if the prefix is already taken.
+func (r *Repository) InsertAPIKey(ctx context.Context, key *entity.APIKey) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertAPIKey")
+	defer cancel()
+
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		key.UserId,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		strings.Join(key.Scopes, " "),
+		utc(key.ExpiresAt),
+		now(),
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, repository.ErrDuplicateAPIKey
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAPIKeysByUserId retrieves the API keys of a user, revoked ones included, oldest first.
+func (r *Repository) GetAPIKeysByUserId(ctx context.Context, userId int) ([]*entity.APIKey, error) {
+	ctx, cancel := r.GetContext(ctx, "GetAPIKeysByUserId")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = ?1
+		ORDER BY id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*entity.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its public prefix.
+func (r *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	ctx, cancel := r.GetContext(ctx, "GetAPIKeyByPrefix")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE prefix = ?1
+	`
+
+	key, err := scanAPIKey(r.conn(ctx).QueryRowContext(ctx, query, prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// UpdateAPIKeyLastUsed records when an API key was last used.
+// Returns repository.ErrAPIKeyNotFound if the key does not exist.
+func (r *Repository) UpdateAPIKeyLastUsed(ctx context.Context, id int, usedAt time.Time) error {
+	ctx, cancel := r.GetContext(ctx, "UpdateAPIKeyLastUsed")
+	defer cancel()
+
+	query := `UPDATE api_keys SET last_used_at = ?2 WHERE id = ?1`
+
+	return r.execAPIKeyUpdate(ctx, query, id, usedAt.UTC())
+}
+
+// RevokeAPIKey revokes an API key of a user. Revoked keys are kept for auditing.
+// Returns repository.ErrAPIKeyNotFound if the user has no unrevoked key with that ID.
+func (r *Repository) RevokeAPIKey(ctx context.Context, userId, id int) error {
+	ctx, cancel := r.GetContext(ctx, "RevokeAPIKey")
+	defer cancel()
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = ?3
+		WHERE id = ?1 AND user_id = ?2 AND revoked_at IS NULL
+	`
+
+	return r.execAPIKeyUpdate(ctx, query, id, userId, now())
+}
+
+func (r *Repository) execAPIKeyUpdate(ctx context.Context, query string, args ...any) error {
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+	var scopes string
+	err := row.Scan(
+		&key.Id,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Scopes are stored space-separated, like OAuth scopes
+	key.Scopes = strings.Fields(scopes)
+	return key, nil
+}
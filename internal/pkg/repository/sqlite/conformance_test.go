@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/your-org/go-backend-template/internal/pkg/repository/repositorytest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	repositorytest.TestUserRepository(t, func(t *testing.T) repositorytest.UserRepository {
+		return setupTestDB(t)
+	})
+}
+
+func TestRepository_UserTokenConformance(t *testing.T) {
+	repositorytest.TestUserTokenRepository(t, func(t *testing.T) repositorytest.UserTokenRepository {
+		return setupTestDB(t)
+	})
+}
+
+func TestRepository_InvitationConformance(t *testing.T) {
+	repositorytest.TestInvitationRepository(t, func(t *testing.T) repositorytest.InvitationRepository {
+		return setupTestDB(t)
+	})
+}
+
+func TestRepository_MFAConformance(t *testing.T) {
+	repositorytest.TestMFARepository(t, func(t *testing.T) repositorytest.MFARepository {
+		return setupTestDB(t)
+	})
+}
+
+func TestRepository_PasskeyConformance(t *testing.T) {
+	repositorytest.TestPasskeyRepository(t, func(t *testing.T) repositorytest.PasskeyRepository {
+		return setupTestDB(t)
+	})
+}
+
+func TestRepository_APIKeyConformance(t *testing.T) {
+	repositorytest.TestAPIKeyRepository(t, func(t *testing.T) repositorytest.APIKeyRepository {
+		return setupTestDB(t)
+	})
+}
+
+func TestRepository_RoleConformance(t *testing.T) {
+	repositorytest.TestRoleRepository(t, func(t *testing.T) repositorytest.RoleRepository {
+		return setupTestDB(t)
+	})
+}
@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertInvitation stores a new invitation and returns the created invitation ID.
+func (r *Repository) InsertInvitation(ctx context.Context, invitation *entity.Invitation) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertInvitation")
+	defer cancel()
+
+	query := `
+		INSERT INTO invitations (email, role, token_hash, expires_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.ExpiresAt.UTC(),
+		now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetInvitationByHash retrieves an invitation (accepted or not) by its token hash.
+func (r *Repository) GetInvitationByHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	ctx, cancel := r.GetContext(ctx, "GetInvitationByHash")
+	defer cancel()
+
+	query := `
+		SELECT id, email, role, token_hash, expires_at, used_at, created_at
+		FROM invitations
+		WHERE token_hash = ?1
+	`
+
+	invitation := &entity.Invitation{}
+	err := r.conn(ctx).QueryRowContext(ctx, query, tokenHash).Scan(
+		&invitation.Id,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.ExpiresAt,
+		&invitation.UsedAt,
+		&invitation.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// ConsumeInvitation marks an unused invitation as used.
+// Returns repository.ErrInvitationNotFound if no unused invitation matched.
+func (r *Repository) ConsumeInvitation(ctx context.Context, id int) error {
+	ctx, cancel := r.GetContext(ctx, "ConsumeInvitation")
+	defer cancel()
+
+	query := `
+		UPDATE invitations
+		SET used_at = ?1
+		WHERE id = ?2 AND used_at IS NULL
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// DeleteInvitations deletes every invitation sent to the given email.
+func (r *Repository) DeleteInvitations(ctx context.Context, email string) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteInvitations")
+	defer cancel()
+
+	query := `DELETE FROM invitations WHERE email = ?1`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, email)
+	return err
+}
+
+// DeleteExpiredInvitations removes invitations that can no longer be accepted.
+func (r *Repository) DeleteExpiredInvitations(ctx context.Context) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteExpiredInvitations")
+	defer cancel()
+
+	query := `DELETE FROM invitations WHERE expires_at < ?1`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, now())
+	return err
+}
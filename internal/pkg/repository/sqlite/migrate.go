@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strconv"
+
+	"github.com/your-org/go-backend-template/internal/pkg/migrate"
+)
+
+// Schema changes live in migrations/ as "<version>_<name>.<up|down>.sql".
+// Applied migrations must never be edited; add a new version instead.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// dialect implements migrate.IDialect for SQLite.
+type dialect struct{}
+
+// Lock is a no-op: SQLite serializes writers, and the database file belongs to a single process.
+func (dialect) Lock(ctx context.Context, conn *sql.Conn) error {
+	return nil
+}
+
+func (dialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+	return nil
+}
+
+func (dialect) Placeholder(n int) string {
+	return "?" + strconv.Itoa(n)
+}
+
+// Migrations returns the embedded schema migrations.
+func Migrations() ([]migrate.Migration, error) {
+	return migrate.Load(migrationsFS, "migrations")
+}
+
+// Migrator returns a migrator for the embedded schema migrations.
+func (r *Repository) Migrator() (*migrate.Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return migrate.New(r.db, dialect{}, migrations)
+}
+
+// Migrate applies all pending migrations.
+func (r *Repository) Migrate(ctx context.Context) error {
+	migrator, err := r.Migrator()
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(ctx)
+	return err
+}
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME;
+ALTER TABLE users ADD COLUMN pending_email TEXT;
+
+-- Accounts created before email verification existed keep signing in
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
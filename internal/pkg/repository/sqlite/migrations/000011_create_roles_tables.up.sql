@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    is_system BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_name, permission)
+);
+
+-- Built-in roles, matching the previously hard-coded behaviour
+INSERT OR IGNORE INTO roles (name, description, is_system) VALUES
+    ('admin', 'Manages users and roles', 1),
+    ('user', 'Regular user', 1),
+    ('viewer', 'Read-only user', 1);
+
+INSERT OR IGNORE INTO role_permissions (role_name, permission) VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:write'),
+    ('admin', 'roles:read'),
+    ('admin', 'roles:write');
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertRefreshToken stores a new refresh token and returns the created token ID.
+func (r *Repository) InsertRefreshToken(ctx context.Context, token *entity.RefreshToken) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertRefreshToken")
+	defer cancel()
+
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		token.UserId,
+		token.FamilyId,
+		token.TokenHash,
+		token.ExpiresAt.UTC(),
+		now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token (revoked or not) by its hash.
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	ctx, cancel := r.GetContext(ctx, "GetRefreshTokenByHash")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?1
+	`
+
+	token := &entity.RefreshToken{}
+	err := r.conn(ctx).QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.FamilyId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// RevokeRefreshToken revokes a single active refresh token.
+// Returns repository.ErrRefreshTokenNotFound if no active token matched,
+// which means the token was already revoked (e.g. by a concurrent rotation).
+func (r *Repository) RevokeRefreshToken(ctx context.Context, id int) error {
+	ctx, cancel := r.GetContext(ctx, "RevokeRefreshToken")
+	defer cancel()
+
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?2
+		WHERE id = ?1 AND revoked_at IS NULL
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, id, now())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrRefreshTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every active token in the given family.
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyId string) error {
+	ctx, cancel := r.GetContext(ctx, "RevokeRefreshTokenFamily")
+	defer cancel()
+
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?2
+		WHERE family_id = ?1 AND revoked_at IS NULL
+	`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, familyId, now())
+	return err
+}
+
+// RevokeUserRefreshTokens revokes every active refresh token of the given user.
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userId int) error {
+	ctx, cancel := r.GetContext(ctx, "RevokeUserRefreshTokens")
+	defer cancel()
+
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?2
+		WHERE user_id = ?1 AND revoked_at IS NULL
+	`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, userId, now())
+	return err
+}
@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	_ "modernc.org/sqlite"
+)
+
+var (
+	ErrEmptyPath = errors.New("empty path")
+
+	operationTimeout = 20 // seconds
+	busyTimeout      = 5000
+	driverSQLite     = "sqlite"
+	dbSystem         = "sqlite" // db.system span attribute
+)
+
+// Config holds database configuration.
+type Config struct {
+	Path string // database file, or ":memory:" for a private in-memory database
+
+	QueryObserver repository.QueryObserver // optional; receives the duration of every repository method
+}
+
+// Validate checks if the configuration is valid.
+func (c *Config) Validate() error {
+	if c.Path == "" {
+		return ErrEmptyPath
+	}
+	return nil
+}
+
+// Repository provides database access methods.
+//
+// SQLite allows a single writer, so the pool is limited to one connection: statements are
+// serialized instead of failing with SQLITE_BUSY. Inside TxManager.RunInTx, every repository
+// call must use the context passed to fn, or it waits for the transaction to finish.
+type Repository struct {
+	db           *sql.DB
+	observeQuery repository.QueryObserver
+}
+
+// New creates a new Repository instance with the given configuration.
+func New(config *Config) (*Repository, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	db, err := sql.Open(driverSQLite, dsn(config.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Configure connection pool; the single connection is kept open so that
+	// ":memory:" databases live as long as the repository
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &Repository{db: db, observeQuery: config.QueryObserver}, nil
+}
+
+// dsn builds the connection string: foreign keys are enforced (for ON DELETE CASCADE) and
+// times are stored in a format that sorts lexicographically, as long as they are all UTC.
+func dsn(path string) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout))
+	if path != ":memory:" {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+	params.Set("_time_format", "sqlite")
+
+	return "file:" + path + "?" + params.Encode()
+}
+
+// Close closes the database connection.
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+// GetContext returns a child of ctx bounded by the operation timeout for the named repository method.
+// Cancelling ctx (e.g. when the client disconnects) cancels the query.
+// The returned cancel func also reports the duration of the method to the query observer,
+// and the method names the spans of its statements when ctx is traced.
+func (r *Repository) GetContext(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(repository.TraceMethod(ctx, method), time.Duration(operationTimeout)*time.Second)
+	if r.observeQuery == nil {
+		return ctx, cancel
+	}
+
+	start := time.Now()
+	return ctx, func() {
+		cancel()
+		r.observeQuery(method, time.Since(start))
+	}
+}
+
+// DB returns the underlying database connection.
+// Use TxManager to run repository methods inside a transaction.
+func (r *Repository) DB() *sql.DB {
+	return r.db
+}
+
+// now returns the current time in UTC, which every stored timestamp uses.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// utc converts an optional timestamp to UTC for storage.
+func utc(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
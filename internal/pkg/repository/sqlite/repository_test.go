@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ========== Test Helpers ==========
+
+// setupTestDB creates a migrated database in a temporary directory.
+func setupTestDB(t *testing.T) *Repository {
+	t.Helper()
+
+	repo, err := New(&Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	if err := repo.Migrate(context.Background()); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	return repo
+}
+
+func createTestUser(t *testing.T, repo *Repository, email string) int {
+	t.Helper()
+
+	id, err := repo.InsertUser(context.Background(), &entity.User{
+		Email:    email,
+		Username: "testuser",
+		Password: "hashed_password",
+		Name:     "Test User",
+		Role:     entity.RoleUser,
+		IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+	return id
+}
+
+// ========== Tests ==========
+
+func TestConfig_Validate(t *testing.T) {
+	assert.ErrorIs(t, (&Config{}).Validate(), ErrEmptyPath)
+	assert.NoError(t, (&Config{Path: ":memory:"}).Validate())
+}
+
+func TestRepository_QueryObserver(t *testing.T) {
+	var methods []string
+	repo, err := New(&Config{
+		Path: ":memory:",
+		QueryObserver: func(method string, duration time.Duration) {
+			methods = append(methods, method)
+			assert.Positive(t, duration)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	if err := repo.Migrate(context.Background()); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	userId := createTestUser(t, repo, "test@example.com")
+	_, err = repo.GetUserById(context.Background(), userId)
+	assert.NoError(t, err)
+	_, err = repo.GetUserById(context.Background(), userId+1)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+	assert.Equal(t, []string{"InsertUser", "GetUserById", "GetUserById"}, methods)
+}
+
+func TestRepository_TracesStatements(t *testing.T) {
+	repo := setupTestDB(t)
+	userId := createTestUser(t, repo, "test@example.com")
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, root := provider.Tracer("test").Start(context.Background(), "root")
+
+	_, err := repo.GetUserById(ctx, userId)
+	assert.NoError(t, err)
+	_, err = repo.GetUserById(ctx, userId+1)
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	root.End()
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	for _, span := range spans[:2] {
+		assert.Equal(t, "sqlite.GetUserById", span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID())
+		// A missing row is an expected outcome
+		assert.Equal(t, codes.Unset, span.Status().Code)
+
+		attributes := map[string]string{}
+		for _, kv := range span.Attributes() {
+			attributes[string(kv.Key)] = kv.Value.Emit()
+		}
+		assert.Equal(t, "SELECT", attributes["db.operation"])
+		assert.NotContains(t, attributes["db.statement"], "\n")
+	}
+}
+
+func TestRepository_DeleteUser_CascadesTokens(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+	userId := createTestUser(t, repo, "test@example.com")
+
+	_, err := repo.InsertRefreshToken(ctx, &entity.RefreshToken{UserId: userId, FamilyId: "family", TokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "jti", userId, time.Now().Add(time.Hour)))
+
+	assert.NoError(t, repo.DeleteUserById(ctx, userId))
+
+	_, err = repo.GetRefreshTokenByHash(ctx, "hash")
+	assert.ErrorIs(t, err, repository.ErrRefreshTokenNotFound)
+	exists, err := repo.ExistsRevokedToken(ctx, "jti")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestRepository_RefreshTokens(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+	userId := createTestUser(t, repo, "test@example.com")
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+
+	id, err := repo.InsertRefreshToken(ctx, &entity.RefreshToken{UserId: userId, FamilyId: "family", TokenHash: "hash", ExpiresAt: expiresAt})
+	assert.NoError(t, err)
+
+	token, err := repo.GetRefreshTokenByHash(ctx, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, id, token.Id)
+	assert.True(t, token.ExpiresAt.Equal(expiresAt))
+	assert.False(t, token.IsRevoked())
+
+	// A token can only be revoked once
+	assert.NoError(t, repo.RevokeRefreshToken(ctx, id))
+	assert.ErrorIs(t, repo.RevokeRefreshToken(ctx, id), repository.ErrRefreshTokenNotFound)
+
+	token, err = repo.GetRefreshTokenByHash(ctx, "hash")
+	assert.NoError(t, err)
+	assert.True(t, token.IsRevoked())
+}
+
+func TestRepository_DeleteExpiredRevokedTokens(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+	userId := createTestUser(t, repo, "test@example.com")
+
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "expired", userId, time.Now().Add(-time.Minute)))
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "active", userId, time.Now().Add(time.Minute)))
+	assert.NoError(t, repo.InsertRevokedToken(ctx, "active", userId, time.Now().Add(time.Minute)), "idempotent")
+
+	assert.NoError(t, repo.DeleteExpiredRevokedTokens(ctx))
+
+	exists, _ := repo.ExistsRevokedToken(ctx, "expired")
+	assert.False(t, exists)
+	exists, _ = repo.ExistsRevokedToken(ctx, "active")
+	assert.True(t, exists)
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// txKey is the context key of the transaction started by TxManager.
+type txKey struct{}
+
+// dbtx is the query interface shared by *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction carried by ctx, or the connection pool if there is none.
+// This makes every repository method join a transaction started by TxManager.RunInTx.
+// Statements are traced when ctx carries a recording span.
+func (r *Repository) conn(ctx context.Context) dbtx {
+	var conn dbtx = r.db
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		conn = tx
+	}
+	if trace.SpanFromContext(ctx).IsRecording() {
+		return tracedConn{conn}
+	}
+	return conn
+}
+
+// tracedConn starts a span around every statement run on the wrapped connection.
+// Query spans end once the first rows are available, so they do not cover reading the result.
+type tracedConn struct {
+	dbtx
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := repository.StartStatement(ctx, dbSystem, query)
+	result, err := c.dbtx.ExecContext(ctx, query, args...)
+	telemetry.End(span, err)
+	return result, err
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := repository.StartStatement(ctx, dbSystem, query)
+	rows, err := c.dbtx.QueryContext(ctx, query, args...)
+	telemetry.End(span, err)
+	return rows, err
+}
+
+// QueryRowContext records the query error; sql.ErrNoRows is only reported by Scan and
+// is an expected outcome, so it is not recorded.
+func (c tracedConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := repository.StartStatement(ctx, dbSystem, query)
+	row := c.dbtx.QueryRowContext(ctx, query, args...)
+	telemetry.End(span, row.Err())
+	return row
+}
+
+// TxManager runs functions inside database transactions.
+// Transactions are serializable and never conflict because the repository
+// uses a single connection, so unlike the postgres manager there is no retry.
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a new transaction manager for the repository's database.
+func NewTxManager(repo *Repository) *TxManager {
+	return &TxManager{db: repo.db}
+}
+
+// RunInTx runs fn inside a transaction. Repository calls made with the context passed to fn
+// use the transaction. The transaction is committed if fn returns nil and rolled back otherwise.
+// Nested calls join the outer transaction.
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
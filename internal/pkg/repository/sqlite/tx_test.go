@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+)
+
+func TestTxManager_RunInTx_Commit(t *testing.T) {
+	repo := setupTestDB(t)
+	m := NewTxManager(repo)
+	ctx := context.Background()
+
+	err := m.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := repo.InsertUser(ctx, &entity.User{Email: "test@example.com", Role: entity.RoleUser})
+		return err
+	})
+
+	assert.NoError(t, err)
+	exists, _ := repo.ExistsUserByEmail(ctx, "test@example.com")
+	assert.True(t, exists)
+}
+
+func TestTxManager_RunInTx_Rollback(t *testing.T) {
+	repo := setupTestDB(t)
+	m := NewTxManager(repo)
+	ctx := context.Background()
+	errFail := errors.New("fail")
+
+	err := m.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := repo.InsertUser(ctx, &entity.User{Email: "test@example.com", Role: entity.RoleUser}); err != nil {
+			return err
+		}
+		// Nested calls join the outer transaction
+		return m.RunInTx(ctx, func(ctx context.Context) error {
+			return errFail
+		})
+	})
+
+	assert.ErrorIs(t, err, errFail)
+	exists, _ := repo.ExistsUserByEmail(ctx, "test@example.com")
+	assert.False(t, exists)
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/your-org/go-backend-template/internal/pkg/entity"
+	"github.com/your-org/go-backend-template/internal/pkg/repository"
+)
+
+// InsertUserToken stores a new user token and returns the created token ID.
+func (r *Repository) InsertUserToken(ctx context.Context, token *entity.UserToken) (int, error) {
+	ctx, cancel := r.GetContext(ctx, "InsertUserToken")
+	defer cancel()
+
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)
+		RETURNING id
+	`
+
+	var id int
+	err := r.conn(ctx).QueryRowContext(ctx, query,
+		token.UserId,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt.UTC(),
+		now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetUserTokenByHash retrieves a user token (used or not) by its hash.
+func (r *Repository) GetUserTokenByHash(ctx context.Context, tokenHash string) (*entity.UserToken, error) {
+	ctx, cancel := r.GetContext(ctx, "GetUserTokenByHash")
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE token_hash = ?1
+	`
+
+	token := &entity.UserToken{}
+	err := r.conn(ctx).QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrUserTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ConsumeUserToken marks an unused user token as used.
+// Returns repository.ErrUserTokenNotFound if no unused token matched,
+// which means the token was already used (e.g. by a concurrent request).
+func (r *Repository) ConsumeUserToken(ctx context.Context, id int) error {
+	ctx, cancel := r.GetContext(ctx, "ConsumeUserToken")
+	defer cancel()
+
+	query := `
+		UPDATE user_tokens
+		SET used_at = ?1
+		WHERE id = ?2 AND used_at IS NULL
+	`
+
+	result, err := r.conn(ctx).ExecContext(ctx, query, now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrUserTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteUserTokens deletes every token of the given user issued for the given purpose.
+func (r *Repository) DeleteUserTokens(ctx context.Context, userId int, purpose string) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteUserTokens")
+	defer cancel()
+
+	query := `DELETE FROM user_tokens WHERE user_id = ?1 AND purpose = ?2`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, userId, purpose)
+	return err
+}
+
+// DeleteExpiredUserTokens removes user tokens that can no longer be redeemed.
+func (r *Repository) DeleteExpiredUserTokens(ctx context.Context) error {
+	ctx, cancel := r.GetContext(ctx, "DeleteExpiredUserTokens")
+	defer cancel()
+
+	query := `DELETE FROM user_tokens WHERE expires_at < ?1`
+
+	_, err := r.conn(ctx).ExecContext(ctx, query, now())
+	return err
+}
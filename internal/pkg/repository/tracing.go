@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/your-org/go-backend-template/internal/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// methodKey is the context key of the repository method name set by TraceMethod.
+type methodKey struct{}
+
+// TraceMethod returns a copy of ctx carrying the name of the repository method being run,
+// which names the statement spans started by StartStatement.
+// ctx is returned unchanged if it is not traced.
+func TraceMethod(ctx context.Context, method string) context.Context {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx
+	}
+	return context.WithValue(ctx, methodKey{}, method)
+}
+
+// StartStatement starts a client span for a SQL statement run by the repository method in ctx.
+// system is the database, e.g. "postgresql". The span records nothing if ctx is not traced.
+func StartStatement(ctx context.Context, system, query string) (context.Context, trace.Span) {
+	// Collapse the indentation of multi-line queries
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+	operation = strings.ToUpper(operation)
+
+	name := operation
+	if method, ok := ctx.Value(methodKey{}).(string); ok {
+		name = system + "." + method
+	}
+
+	return telemetry.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", system),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		),
+	)
+}
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Start starts a span as a child of the span in ctx, with the tracer provider of that span.
+// Without a span in ctx the returned span records nothing.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(ScopeName)
+	return tracer.Start(ctx, name, opts...)
+}
+
+// RecordError records err on span. Domain errors are classified by their HTTP status in the
+// error.type and error.http_status attributes; those below 500 are expected outcomes such as
+// a wrong password, so only other errors record an exception event and fail the span.
+func RecordError(span trace.Span, err error) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	var domainErr domain.DomainError
+	if errors.As(err, &domainErr) {
+		status := domainErr.HTTPStatus()
+		span.SetAttributes(
+			attribute.String("error.type", fmt.Sprintf("%T", domainErr)),
+			attribute.Int("error.http_status", status),
+		)
+		if status < http.StatusInternalServerError {
+			return
+		}
+	} else {
+		span.SetAttributes(attribute.String("error.type", fmt.Sprintf("%T", err)))
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End records err, if not nil, and ends span.
+// It is meant to be deferred with a named error result:
+//
+//	defer func() { telemetry.End(span, err) }()
+func End(span trace.Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
@@ -0,0 +1,117 @@
+// Package telemetry sets up OpenTelemetry tracing and holds the helpers shared by instrumented code.
+//
+// Spans are carried in the context: Start starts a child of the span in ctx with that span's
+// tracer provider. Without a span in ctx it returns a span that records nothing, so
+// instrumented code needs no checks when tracing is disabled or outside of a request.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// ScopeName is the instrumentation scope of the spans started by this application.
+const ScopeName = "github.com/your-org/go-backend-template"
+
+// Supported exporters.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// fileBatchTimeout keeps stdout and files close to real time while testing
+const fileBatchTimeout = time.Second
+
+var (
+	ErrUnknownExporter = errors.New("unknown tracing exporter")
+	ErrEmptyFilePath   = errors.New("empty tracing file path")
+)
+
+// Config holds tracing configuration. The otlp exporter is configured by the standard
+// OTEL_EXPORTER_OTLP_* environment variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT.
+type Config struct {
+	Exporter    string  // none, otlp, stdout or file
+	ServiceName string  // service.name of all spans
+	SampleRatio float64 // fraction of new traces to record, 0 to 1; incoming traces keep the caller's decision
+	FilePath    string  // output file of the file exporter, appended to
+}
+
+// NewTracerProvider creates a tracer provider for config. It returns nil for the none exporter.
+// The provider must be shut down to export pending spans.
+func NewTracerProvider(ctx context.Context, config Config) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var batchOptions []sdktrace.BatchSpanProcessorOption
+
+	switch config.Exporter {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterOTLP:
+		otlpExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		exporter = otlpExporter
+	case ExporterStdout:
+		stdoutExporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		exporter = stdoutExporter
+		batchOptions = append(batchOptions, sdktrace.WithBatchTimeout(fileBatchTimeout))
+	case ExporterFile:
+		if config.FilePath == "" {
+			return nil, ErrEmptyFilePath
+		}
+		f, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open tracing file: %w", err)
+		}
+		fileExporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		exporter = closingExporter{SpanExporter: fileExporter, closer: f}
+		batchOptions = append(batchOptions, sdktrace.WithBatchTimeout(fileBatchTimeout))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, config.Exporter)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(config.ServiceName)),
+	)
+	if err != nil {
+		exporter.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, batchOptions...),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	), nil
+}
+
+// closingExporter closes the file it writes to once it is shut down.
+type closingExporter struct {
+	sdktrace.SpanExporter
+	closer io.Closer
+}
+
+func (e closingExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.closer.Close())
+}
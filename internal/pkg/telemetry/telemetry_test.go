@@ -0,0 +1,181 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/your-org/go-backend-template/internal/pkg/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// ========== Test Helpers ==========
+
+func newTestProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+// attributeValue returns the value of the attribute with the given key, or nil.
+func attributeValue(span sdktrace.ReadOnlySpan, key string) any {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsInterface()
+		}
+	}
+	return nil
+}
+
+// ========== Span Tests ==========
+
+func TestStart_ChildOfSpanInContext(t *testing.T) {
+	provider, recorder := newTestProvider()
+
+	ctx, root := provider.Tracer("test").Start(context.Background(), "root")
+	_, child := Start(ctx, "child")
+	child.End()
+	root.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "child", spans[0].Name())
+		assert.Equal(t, ScopeName, spans[0].InstrumentationScope().Name)
+		assert.Equal(t, root.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		assert.Equal(t, root.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	}
+}
+
+func TestStart_WithoutSpanInContext(t *testing.T) {
+	_, span := Start(context.Background(), "orphan")
+
+	// Safe to use, but records nothing
+	assert.False(t, span.IsRecording())
+	span.SetAttributes(attribute.String("key", "value"))
+	End(span, errors.New("failed"))
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     codes.Code
+		wantHTTPStatus any
+		wantType       string
+	}{
+		{
+			name:           "client domain error is an expected outcome",
+			err:            domain.InvalidCredentialsError{},
+			wantStatus:     codes.Unset,
+			wantHTTPStatus: int64(401),
+			wantType:       "domain.InvalidCredentialsError",
+		},
+		{
+			name:           "wrapped domain error",
+			err:            fmt.Errorf("login: %w", domain.UserNotFoundError{}),
+			wantStatus:     codes.Unset,
+			wantHTTPStatus: int64(404),
+			wantType:       "domain.UserNotFoundError",
+		},
+		{
+			name:           "server domain error fails the span",
+			err:            domain.InternalServerError{Msg: "failed to get user", Err: errors.New("connection refused")},
+			wantStatus:     codes.Error,
+			wantHTTPStatus: int64(500),
+			wantType:       "domain.InternalServerError",
+		},
+		{
+			name:       "other error fails the span",
+			err:        errors.New("boom"),
+			wantStatus: codes.Error,
+			wantType:   "*errors.errorString",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, recorder := newTestProvider()
+
+			_, span := provider.Tracer("test").Start(context.Background(), "span")
+			End(span, tt.err)
+
+			data := recorder.Ended()[0]
+			assert.Equal(t, tt.wantStatus, data.Status().Code)
+			assert.Equal(t, tt.wantType, attributeValue(data, "error.type"))
+			assert.Equal(t, tt.wantHTTPStatus, attributeValue(data, "error.http_status"))
+
+			if tt.wantStatus == codes.Error {
+				assert.Equal(t, tt.err.Error(), data.Status().Description)
+				if assert.Len(t, data.Events(), 1) {
+					assert.Equal(t, "exception", data.Events()[0].Name)
+				}
+			} else {
+				assert.Empty(t, data.Events())
+			}
+		})
+	}
+}
+
+// ========== Provider Tests ==========
+
+func TestNewTracerProvider_None(t *testing.T) {
+	provider, err := NewTracerProvider(context.Background(), Config{Exporter: ExporterNone})
+
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+}
+
+func TestNewTracerProvider_InvalidConfig(t *testing.T) {
+	_, err := NewTracerProvider(context.Background(), Config{Exporter: "zipkin"})
+	assert.ErrorIs(t, err, ErrUnknownExporter)
+
+	_, err = NewTracerProvider(context.Background(), Config{Exporter: ExporterFile})
+	assert.ErrorIs(t, err, ErrEmptyFilePath)
+}
+
+func TestNewTracerProvider_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	provider, err := NewTracerProvider(context.Background(), Config{
+		Exporter:    ExporterFile,
+		ServiceName: "test-service",
+		SampleRatio: 1,
+		FilePath:    path,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tracer provider: %v", err)
+	}
+
+	_, span := provider.Tracer("test").Start(context.Background(), "request")
+	span.End()
+	// Shutdown exports pending spans and closes the file
+	assert.NoError(t, provider.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read traces: %v", err)
+	}
+	assert.True(t, strings.Contains(string(data), `"Name":"request"`), "span is written")
+	assert.True(t, strings.Contains(string(data), "test-service"), "service name is written")
+}
+
+func TestNewTracerProvider_SampleRatio(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	provider, err := NewTracerProvider(context.Background(), Config{Exporter: ExporterFile, FilePath: path})
+	if err != nil {
+		t.Fatalf("Failed to create tracer provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	// Unsampled spans still propagate the trace
+	_, span := provider.Tracer("test").Start(context.Background(), "request")
+	assert.False(t, span.IsRecording())
+	assert.True(t, span.SpanContext().IsValid())
+}
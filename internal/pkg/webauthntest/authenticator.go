@@ -0,0 +1,207 @@
+// Package webauthntest provides a software authenticator that runs WebAuthn ceremonies
+// offline, for tests of code built on github.com/go-webauthn/webauthn.
+package webauthntest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+)
+
+var (
+	ErrCredentialExcluded = errors.New("authenticator already holds an excluded credential")
+	ErrNoCredential       = errors.New("authenticator holds no matching credential")
+)
+
+// Authenticator is a platform authenticator that keeps discoverable ES256 credentials in
+// memory and always verifies its user, unless told otherwise.
+type Authenticator struct {
+	Origin string // origin the browser reports in client data
+
+	// SkipUserVerification reports the user as present but not verified.
+	SkipUserVerification bool
+	// NoSignCount leaves signature counters at zero, like synced passkeys.
+	NoSignCount bool
+
+	Credentials []*Credential
+}
+
+// Credential is a credential held by an Authenticator.
+type Credential struct {
+	Id         []byte
+	RPID       string
+	UserHandle []byte
+	SignCount  uint32 // incremented before every assertion unless NoSignCount is set
+
+	key *ecdsa.PrivateKey
+}
+
+// New creates an authenticator used from origin.
+func New(origin string) *Authenticator {
+	return &Authenticator{Origin: origin}
+}
+
+// Register creates a credential for options and returns the browser's response, with a
+// "none" attestation statement.
+func (a *Authenticator) Register(options *protocol.PublicKeyCredentialCreationOptions) (*protocol.CredentialCreationResponse, error) {
+	for _, excluded := range options.CredentialExcludeList {
+		if a.find(options.RelyingParty.ID, [][]byte{excluded.CredentialID}) != nil {
+			return nil, ErrCredentialExcluded
+		}
+	}
+	userHandle, ok := options.User.ID.(protocol.URLEncodedBase64)
+	if !ok {
+		return nil, errors.New("user id is not binary")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	credential := &Credential{Id: id, RPID: options.RelyingParty.ID, UserHandle: userHandle, key: key}
+
+	clientData, err := a.clientData("webauthn.create", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := webauthncbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: key.X.FillBytes(make([]byte, 32)),
+		-3: key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Attested credential data: AAGUID (all zero), credential ID length and ID, COSE key
+	attested := make([]byte, 16, 16+2+len(id)+len(publicKey))
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, publicKey...)
+	authData := a.authenticatorData(credential, 0x40, attested)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.Credentials = append(a.Credentials, credential)
+
+	return &protocol.CredentialCreationResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{
+				ID:   protocol.URLEncodedBase64(id).String(),
+				Type: string(protocol.PublicKeyCredentialType),
+			},
+			RawID: id,
+		},
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientData},
+			AttestationObject:     attestationObject,
+			Transports:            []string{"internal"},
+		},
+	}, nil
+}
+
+// Authenticate signs options with the first credential that matches them and returns the
+// browser's response.
+func (a *Authenticator) Authenticate(options *protocol.PublicKeyCredentialRequestOptions) (*protocol.CredentialAssertionResponse, error) {
+	allowed := make([][]byte, 0, len(options.AllowedCredentials))
+	for _, descriptor := range options.AllowedCredentials {
+		allowed = append(allowed, descriptor.CredentialID)
+	}
+	credential := a.find(options.RelyingPartyID, allowed)
+	if credential == nil {
+		return nil, ErrNoCredential
+	}
+
+	clientData, err := a.clientData("webauthn.get", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.NoSignCount {
+		credential.SignCount++
+	}
+	authData := a.authenticatorData(credential, 0, nil)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, credential.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{
+				ID:   protocol.URLEncodedBase64(credential.Id).String(),
+				Type: string(protocol.PublicKeyCredentialType),
+			},
+			RawID: credential.Id,
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientData},
+			AuthenticatorData:     authData,
+			Signature:             signature,
+			UserHandle:            credential.UserHandle,
+		},
+	}, nil
+}
+
+func (a *Authenticator) find(rpId string, allowed [][]byte) *Credential {
+	for _, credential := range a.Credentials {
+		if credential.RPID != rpId {
+			continue
+		}
+		if len(allowed) == 0 {
+			return credential
+		}
+		for _, id := range allowed {
+			if bytes.Equal(id, credential.Id) {
+				return credential
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) clientData(ceremonyType string, challenge protocol.URLEncodedBase64) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":        ceremonyType,
+		"challenge":   challenge.String(),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+}
+
+func (a *Authenticator) authenticatorData(credential *Credential, flags byte, attested []byte) []byte {
+	flags |= 0x01 // user present
+	if !a.SkipUserVerification {
+		flags |= 0x04
+	}
+
+	rpIdHash := sha256.Sum256([]byte(credential.RPID))
+	data := append(rpIdHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, credential.SignCount)
+	return append(data, attested...)
+}